@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// visiblePost returns the post if u can see it: they follow or own its
+// feed. Otherwise it returns sql.ErrNoRows, so that handlers answer 404
+// exactly as for a post that does not exist, and another user's inbox or
+// private feed is not given away by its post IDs.
+func visiblePost(ctx context.Context, ac apiConfig, u database.User, postID uuid.UUID) (database.Post, error) {
+	post, err := ac.DB.GetPost(ctx, postID)
+	if err != nil {
+		return post, err
+	}
+	ok, err := canSeeFeed(ctx, ac, u, post.FeedID)
+	if err != nil {
+		return post, err
+	}
+	if !ok {
+		return database.Post{}, sql.ErrNoRows
+	}
+	return post, nil
+}
+
+// canSeeFeed reports whether u follows or owns the feed.
+func canSeeFeed(ctx context.Context, ac apiConfig, u database.User, feedID uuid.UUID) (bool, error) {
+	follows, err := ac.DB.UserFollowsFeed(ctx, database.UserFollowsFeedParams{UserID: u.ID, FeedID: feedID})
+	if err != nil || follows {
+		return follows, err
+	}
+	feed, err := ac.DB.GetFeed(ctx, feedID)
+	if err != nil {
+		return false, err
+	}
+	return feed.UserID == u.ID, nil
+}
+
+// requestUser returns the user the request's API key belongs to, for
+// endpoints that are public but show more to some users. A missing,
+// unknown or suspended key is treated as no user at all.
+func requestUser(r *http.Request, ac apiConfig) (database.User, bool) {
+	fields := strings.Fields(r.Header.Get("Authorization"))
+	if len(fields) != 2 || fields[0] != "ApiKey" {
+		return database.User{}, false
+	}
+	user, err := ac.DB.GetUserByApiKey(r.Context(), fields[1])
+	if err != nil || user.BannedAt.Valid {
+		return database.User{}, false
+	}
+	return user, true
+}
+
+// getVisibleFeed loads the feed the request's path names, responding with
+// an error if there is none. Anyone may see a public feed, but a private
+// or inbox feed only exists for an authenticated user who follows or owns
+// it; everyone else gets a 404, since its fetch errors and URLs carry its
+// credentials.
+func getVisibleFeed(w http.ResponseWriter, r *http.Request, ac apiConfig) (database.Feed, bool) {
+	feedID, err := parseFeedID(r.Context(), ac.DB, chi.URLParam(r, "feedID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return database.Feed{}, false
+	}
+	feed, err := ac.DB.GetFeed(r.Context(), feedID)
+	if err == nil && (isPrivateFeed(feed) || feed.Kind == "inbox") {
+		u, ok := requestUser(r, ac)
+		visible := false
+		if ok {
+			visible, err = canSeeFeed(r.Context(), ac, u, feed.ID)
+		}
+		if err == nil && !visible {
+			err = sql.ErrNoRows
+		}
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Feed not found")
+		return database.Feed{}, false
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve feed")
+		return database.Feed{}, false
+	}
+	return feed, true
+}
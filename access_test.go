@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+func TestPostHandlersHideOtherUsersPosts(t *testing.T) {
+	ac, _ := newClockTestConfig()
+	// Nothing is sent: the mail is refused before it would be.
+	ac.Mailer = &mailer{addr: "127.0.0.1:1", from: "rssagg@example.com"}
+	owner, feed := seedClockTestFeed(t, ac, true)
+	post := seedClockTestPost(t, ac, feed)
+	stranger, err := ac.DB.CreateUser(context.Background(), database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: clockTestStart,
+		UpdatedAt: clockTestStart,
+		Name:      "stranger",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	until := clockTestStart.Add(time.Hour).Format(time.RFC3339)
+	tests := []struct {
+		name    string
+		method  string
+		body    string
+		handler func(http.ResponseWriter, *http.Request, database.User, apiConfig)
+		// sends is set for handlers that would reach out on success, which
+		// are only checked for the stranger.
+		sends bool
+	}{
+		{"content", http.MethodGet, "", handlePostContentGet, false},
+		{"email", http.MethodPost, `{"to":"friend@example.com"}`, handlePostEmail, true},
+		{"suggested tags", http.MethodGet, "", handlePostSuggestedTags, false},
+		{"snooze", http.MethodPost, `{"until":"` + until + `"}`, handlePostSnooze, false},
+		{"queue", http.MethodPost, `{"post_id":"` + post.ID.String() + `"}`, handleQueuePost, false},
+	}
+	call := func(handler func(http.ResponseWriter, *http.Request, database.User, apiConfig), method, body string, u database.User) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/v1/posts/"+post.ID.String(), strings.NewReader(body))
+		w := httptest.NewRecorder()
+		handler(w, withURLParam(r, "postID", post.ID.String()), u, ac)
+		return w
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := call(tt.handler, tt.method, tt.body, stranger); w.Code != http.StatusNotFound {
+				t.Errorf("stranger got %d, want 404: %s", w.Code, w.Body.String())
+			}
+			if tt.sends {
+				return
+			}
+			if w := call(tt.handler, tt.method, tt.body, owner); w.Code >= 300 {
+				t.Errorf("follower got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPerFeedEndpointsHidePrivateFeeds(t *testing.T) {
+	ac, _ := newClockTestConfig()
+	ctx := context.Background()
+	owner, public := seedClockTestFeed(t, ac, true)
+	private, err := ac.DB.CreateFeed(ctx, database.CreateFeedParams{
+		ID:          uuid.New(),
+		CreatedAt:   clockTestStart,
+		UpdatedAt:   clockTestStart,
+		Name:        "Private feed",
+		Url:         "https://example.com/private/rss",
+		UserID:      owner.ID,
+		Credentials: []byte("sealed"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inbox, err := ac.DB.CreateInboxFeed(ctx, database.CreateInboxFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: clockTestStart,
+		UpdatedAt: clockTestStart,
+		Name:      "Inbox",
+		Url:       "inbox:" + owner.ID.String(),
+		UserID:    owner.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stranger, err := ac.DB.CreateUser(ctx, database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: clockTestStart,
+		UpdatedAt: clockTestStart,
+		Name:      "stranger",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handlers := map[string]func(http.ResponseWriter, *http.Request, apiConfig){
+		"health":      handleFeedHealthGet,
+		"fetches":     handleFeedFetchesGet,
+		"icon":        handleFeedIconGet,
+		"url_history": handleFeedUrlHistoryGet,
+	}
+	// found reports whether the handler admitted the feed exists. The icon
+	// endpoint 404s for a feed without an icon too, so it is told apart by
+	// the message.
+	found := func(handler func(http.ResponseWriter, *http.Request, apiConfig), feed database.Feed, apiKey string) bool {
+		r := httptest.NewRequest(http.MethodGet, "/v1/feeds/"+feed.ID.String(), nil)
+		if apiKey != "" {
+			r.Header.Set("Authorization", "ApiKey "+apiKey)
+		}
+		w := httptest.NewRecorder()
+		handler(w, withURLParam(r, "feedID", feed.ID.String()), ac)
+		return !strings.Contains(w.Body.String(), "Feed not found")
+	}
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			if !found(handler, public, "") {
+				t.Errorf("public feed hidden from anonymous users")
+			}
+			for _, feed := range []database.Feed{private, inbox} {
+				if found(handler, feed, "") {
+					t.Errorf("%s shown to anonymous users", feed.Name)
+				}
+				if found(handler, feed, stranger.ApiKey) {
+					t.Errorf("%s shown to a user who does not follow it", feed.Name)
+				}
+				if !found(handler, feed, owner.ApiKey) {
+					t.Errorf("%s hidden from its owner", feed.Name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// handleAdminPostsPatch corrects a post's title or URL. The previous values
+// are kept as a revision and the change is written to the audit log, all in
+// the same transaction as the update.
+func handleAdminPostsPatch(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	postID, err := parsePostID(r.Context(), ac.DB, chi.URLParam(r, "postID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	type adminPostsPatchRequest struct {
+		Title     *string `json:"title"`
+		URL       *string `json:"url"`
+		Sensitive *bool   `json:"sensitive"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := adminPostsPatchRequest{}
+	err = decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+
+	tx, err := ac.DB.Begin(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to update post")
+		return
+	}
+	defer tx.Rollback()
+
+	post, err := tx.GetPost(r.Context(), postID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Post not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve post")
+		return
+	}
+	params := database.UpdatePostMetadataParams{
+		ID:        post.ID,
+		Title:     post.Title,
+		Url:       post.Url,
+		Sensitive: post.Sensitive,
+		UpdatedAt: time.Now(),
+	}
+	if req.Title != nil {
+		params.Title = strings.TrimSpace(*req.Title)
+		if params.Title == "" {
+			respondWithError(w, http.StatusBadRequest, "Title is required")
+			return
+		}
+	}
+	if req.URL != nil {
+		postURL, err := url.Parse(*req.URL)
+		if err != nil || (postURL.Scheme != "http" && postURL.Scheme != "https") || postURL.Host == "" {
+			respondWithError(w, http.StatusBadRequest, "Invalid URL")
+			return
+		}
+		params.Url = postURL.String()
+	}
+	if req.Sensitive != nil {
+		params.Sensitive = *req.Sensitive
+	}
+	if params.Title == post.Title && params.Url == post.Url && params.Sensitive == post.Sensitive {
+		respondWithJSON(w, http.StatusOK, newPostResponse(post))
+		return
+	}
+
+	_, err = tx.CreatePostRevision(r.Context(), database.CreatePostRevisionParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		PostID:    post.ID,
+		EditorID:  uuid.NullUUID{UUID: u.ID, Valid: true},
+		Title:     post.Title,
+		Url:       post.Url,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save post revision")
+		return
+	}
+	updated, err := tx.UpdatePostMetadata(r.Context(), params)
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "Another post already has that URL")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to update post")
+		return
+	}
+	type change struct {
+		From interface{} `json:"from"`
+		To   interface{} `json:"to"`
+	}
+	details := map[string]change{}
+	if updated.Title != post.Title {
+		details["title"] = change{From: post.Title, To: updated.Title}
+	}
+	if updated.Url != post.Url {
+		details["url"] = change{From: post.Url, To: updated.Url}
+	}
+	if updated.Sensitive != post.Sensitive {
+		details["sensitive"] = change{From: post.Sensitive, To: updated.Sensitive}
+	}
+	err = recordAudit(r.Context(), tx, u.ID, "post.update", "post", post.ID, details)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to record audit log entry")
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to update post")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, newPostResponse(updated))
+}
+
+func handleAdminPostRevisionsGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	postID, err := parsePostID(r.Context(), ac.DB, chi.URLParam(r, "postID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	revisions, err := ac.DB.GetPostRevisions(r.Context(), postID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve post revisions")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, newPostRevisionResponses(revisions))
+}
+
+// postDeleteBatchSize bounds how many posts each transaction of a bulk
+// delete removes, so a large delete never holds locks for long.
+const postDeleteBatchSize = 1000
+
+// handleAdminPostsDelete deletes every post of a feed, every post fetched
+// before a date, or both, in batches. At least one filter is required. With
+// dry_run=true it only reports how many posts would go.
+func handleAdminPostsDelete(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	query := r.URL.Query()
+	params := database.DeletePostsBatchParams{Limit: postDeleteBatchSize}
+	if v := query.Get("feed_id"); v != "" {
+		feedID, err := parseFeedID(r.Context(), ac.DB, v)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid feed ID")
+			return
+		}
+		params.FeedID = uuid.NullUUID{UUID: feedID, Valid: true}
+	}
+	if v := query.Get("before"); v != "" {
+		before, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			before, err = time.Parse(time.DateOnly, v)
+		}
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "before must be an RFC 3339 timestamp or a date")
+			return
+		}
+		params.Before = sql.NullTime{Time: before, Valid: true}
+	}
+	if !params.FeedID.Valid && !params.Before.Valid {
+		respondWithError(w, http.StatusBadRequest, "feed_id or before is required")
+		return
+	}
+	dryRun := query.Get("dry_run") == "true"
+
+	type response struct {
+		DryRun  bool  `json:"dry_run"`
+		Matched int64 `json:"matched"`
+		Deleted int64 `json:"deleted"`
+	}
+	matched, err := ac.DB.CountPostsForDeletion(r.Context(), database.CountPostsForDeletionParams{
+		FeedID: params.FeedID,
+		Before: params.Before,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to count posts")
+		return
+	}
+	resp := response{DryRun: dryRun, Matched: matched}
+	if dryRun {
+		respondWithJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	var deleteErr error
+	for {
+		var n int64
+		n, deleteErr = deletePostsBatch(r.Context(), ac, params)
+		resp.Deleted += n
+		if deleteErr != nil || n < postDeleteBatchSize {
+			break
+		}
+	}
+
+	targetType, targetID := "instance", uuid.Nil
+	if params.FeedID.Valid {
+		targetType, targetID = "feed", params.FeedID.UUID
+	}
+	details := map[string]interface{}{"deleted": resp.Deleted}
+	if params.Before.Valid {
+		details["before"] = params.Before.Time
+	}
+	err = recordAudit(r.Context(), ac.DB, u.ID, "post.bulk_delete", targetType, targetID, details)
+	if err != nil {
+		logError("api", "Could not record bulk delete of %d posts: %v", resp.Deleted, err)
+	}
+	if deleteErr != nil {
+		logError("api", "Bulk post delete stopped after %d posts: %v", resp.Deleted, deleteErr)
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Unable to delete posts; %d were deleted before the failure", resp.Deleted))
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// deletePostsBatch deletes one batch in its own transaction.
+func deletePostsBatch(ctx context.Context, ac apiConfig, params database.DeletePostsBatchParams) (int64, error) {
+	tx, err := ac.DB.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	n, err := tx.DeletePostsBatch(ctx, params)
+	if err != nil {
+		return 0, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
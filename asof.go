@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// parseAsOf reads the as_of query parameter: an RFC 3339 timestamp, or a
+// date meaning midnight UTC at its start.
+func parseAsOf(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t, err = time.Parse(time.DateOnly, s)
+	}
+	return t, err
+}
+
+// titleAndUrlAsOf returns what a post's title and URL were at asOf. Each
+// revision holds the values a correction replaced, so the first revision
+// made after asOf holds the ones shown then; with none, the post is
+// unchanged since.
+func titleAndUrlAsOf(ctx context.Context, ac apiConfig, postID uuid.UUID, title, url string, asOf time.Time) (string, string, error) {
+	revisions, err := ac.DB.GetPostRevisions(ctx, postID)
+	if err != nil {
+		return "", "", err
+	}
+	// Revisions are newest first.
+	for _, rev := range revisions {
+		if !rev.CreatedAt.After(asOf) {
+			break
+		}
+		title, url = rev.Title, rev.Url
+	}
+	return title, url, nil
+}
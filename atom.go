@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// atomFeed is the subset of RFC 4287 that maps onto feedData.
+type atomFeed struct {
+	XMLName  xml.Name     `xml:"feed"`
+	Lang     string       `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Title    string       `xml:"title"`
+	Subtitle string       `xml:"subtitle"`
+	Updated  string       `xml:"updated"`
+	Icon     string       `xml:"icon"`
+	Logo     string       `xml:"logo"`
+	Links    []atomLink   `xml:"link"`
+	Authors  []atomPerson `xml:"author"`
+	Entries  []struct {
+		Title     string       `xml:"title"`
+		ID        string       `xml:"id"`
+		Published string       `xml:"published"`
+		Updated   string       `xml:"updated"`
+		Summary   string       `xml:"summary"`
+		Content   string       `xml:"content"`
+		Links     []atomLink   `xml:"link"`
+		Authors   []atomPerson `xml:"author"`
+		Category  []struct {
+			Term string `xml:"term,attr"`
+		} `xml:"category"`
+	} `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email"`
+}
+
+type atomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// alternateLink returns the entry or feed's HTML page: the link with
+// rel="alternate", or with no rel at all, which Atom defines as the same.
+func alternateLink(links []atomLink) string {
+	for _, l := range links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return l.Href
+		}
+	}
+	return ""
+}
+
+// enclosureLink returns an entry's first rel="enclosure" link, Atom's
+// equivalent of an RSS enclosure.
+func enclosureLink(links []atomLink) feedEnclosure {
+	for _, l := range links {
+		if l.Rel == "enclosure" {
+			return feedEnclosure{URL: l.Href, Type: l.Type, Length: l.Length}
+		}
+	}
+	return feedEnclosure{}
+}
+
+// parseFeed decodes an RSS 2.0, RSS 1.0 (RDF), Atom or JSON Feed document.
+// All but the first are converted to feedData so that the rest of ingestion
+// only deals with one shape; their dates are rewritten as RFC 1123 pubDates,
+// preferring the publication date over the modification date.
+func parseFeed(body []byte) (feedData, error) {
+	if looksLikeJSON(body) {
+		return parseJSONFeed(body)
+	}
+	fd := feedData{}
+	root, err := rootElement(body)
+	if err != nil {
+		return fd, err
+	}
+	switch root.Local {
+	case "rss":
+		err = newXMLDecoder(body).Decode(&fd)
+		return fd, err
+	case "feed":
+		af := atomFeed{}
+		err = newXMLDecoder(body).Decode(&af)
+		if err != nil {
+			return fd, err
+		}
+		return atomToFeedData(af), nil
+	case "RDF":
+		rf := rdfFeed{}
+		err = newXMLDecoder(body).Decode(&rf)
+		if err != nil {
+			return fd, err
+		}
+		return rdfToFeedData(rf), nil
+	}
+	return fd, fmt.Errorf("unsupported feed format <%s>", root.Local)
+}
+
+// feedFormat names the format parseFeed would read body as.
+func feedFormat(body []byte) string {
+	if looksLikeJSON(body) {
+		return "json"
+	}
+	root, err := rootElement(body)
+	if err != nil {
+		return "unknown"
+	}
+	switch root.Local {
+	case "rss":
+		return "rss"
+	case "feed":
+		return "atom"
+	case "RDF":
+		return "rdf"
+	}
+	return "unknown"
+}
+
+func rootElement(body []byte) (xml.Name, error) {
+	d := newXMLDecoder(body)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return xml.Name{}, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name, nil
+		}
+	}
+}
+
+func atomToFeedData(af atomFeed) feedData {
+	fd := feedData{}
+	fd.Channel.Title = strings.TrimSpace(af.Title)
+	fd.Channel.Description = strings.TrimSpace(af.Subtitle)
+	fd.Channel.Link.Href = alternateLink(af.Links)
+	fd.Channel.Link.Text = fd.Channel.Link.Href
+	fd.Channel.LastBuildDate = atomDateToRSS(af.Updated)
+	fd.Channel.Language = af.Lang
+	fd.Channel.Image.URL = strings.TrimSpace(af.Icon)
+	if fd.Channel.Image.URL == "" {
+		fd.Channel.Image.URL = strings.TrimSpace(af.Logo)
+	}
+	for _, e := range af.Entries {
+		item := feedItem{
+			Title:       strings.TrimSpace(e.Title),
+			Link:        alternateLink(e.Links),
+			Guid:        e.ID,
+			Description: strings.TrimSpace(e.Summary),
+			Enclosure:   enclosureLink(e.Links),
+			Author:      atomAuthor(e.Authors, af.Authors),
+		}
+		if item.Description == "" {
+			item.Description = strings.TrimSpace(e.Content)
+		}
+		if e.Published != "" {
+			item.PubDate = atomDateToRSS(e.Published)
+		} else {
+			item.PubDate = atomDateToRSS(e.Updated)
+		}
+		for _, c := range e.Category {
+			item.Category = append(item.Category, c.Term)
+		}
+		fd.Channel.Item = append(fd.Channel.Item, item)
+	}
+	return fd
+}
+
+// atomAuthor names an entry's first author, or the feed's, which entries
+// without their own inherit.
+func atomAuthor(entryAuthors, feedAuthors []atomPerson) string {
+	for _, authors := range [][]atomPerson{entryAuthors, feedAuthors} {
+		for _, a := range authors {
+			if name := strings.TrimSpace(a.Name); name != "" {
+				return name
+			}
+			if email := strings.TrimSpace(a.Email); email != "" {
+				return email
+			}
+		}
+	}
+	return ""
+}
+
+// atomDateToRSS converts an RFC 3339 date, or anything else parseFeedDate
+// understands, to the RFC 1123 form RSS uses. Unparseable dates come back
+// empty, as if the feed had none.
+func atomDateToRSS(s string) string {
+	t, err := parseFeedDate(s)
+	if err != nil {
+		return ""
+	}
+	return t.Format(time.RFC1123Z)
+}
+
+func isFeedMediaType(t string) bool {
+	switch strings.ToLower(strings.TrimSpace(t)) {
+	case "application/rss+xml", "application/rdf+xml", "application/atom+xml", "application/feed+json":
+		return true
+	}
+	return false
+}
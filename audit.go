@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const defaultAuditLogLimit = 100
+
+// recordAudit appends an entry to the audit log. Callers pass the same
+// Querier they used for the change itself so that, inside a transaction, the
+// entry commits or rolls back with it.
+func recordAudit(ctx context.Context, q database.Querier, actorID uuid.UUID, action string, targetType string, targetID uuid.UUID, details interface{}) error {
+	data, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+	_, err = q.CreateAuditLogEntry(ctx, database.CreateAuditLogEntryParams{
+		ID:         uuid.New(),
+		CreatedAt:  time.Now(),
+		ActorID:    uuid.NullUUID{UUID: actorID, Valid: true},
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Details:    data,
+	})
+	return err
+}
+
+func handleAdminAuditLogGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	limit := defaultAuditLogLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = parsed
+	}
+	entries, err := ac.DB.ListAuditLog(r.Context(), int32(limit))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve audit log")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, newAuditLogResponses(entries))
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// An authBackend checks a username and password against a directory of
+// users kept outside the aggregator. Users it vouches for get an account,
+// and an API key, the first time they log in; API keys remain the only way
+// to authenticate requests.
+type authBackend interface {
+	// name identifies the backend in user_identities, so that the same
+	// username in two directories is two users.
+	name() string
+	authenticate(ctx context.Context, username, password string) (authIdentity, error)
+}
+
+// authIdentity is who a backend says a user is. Subject is stable across
+// logins. Admin, if set, is whether the directory says the user is an
+// admin; if nil, the directory has no say and admins are made as usual.
+type authIdentity struct {
+	Subject string
+	Name    string
+	Admin   *bool
+}
+
+var errInvalidCredentials = errors.New("invalid username or password")
+
+// newAuthBackendFromEnv reads AUTH_BACKEND: "local", the default, for API
+// keys handed out at sign up only, or "ldap" to let users log in with their
+// directory password too.
+func newAuthBackendFromEnv() (authBackend, error) {
+	switch os.Getenv("AUTH_BACKEND") {
+	case "", "local":
+		return nil, nil
+	case "ldap":
+		b, err := newLDAPBackendFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("Invalid AUTH_BACKEND: must be local or ldap")
+}
+
+// handleLoginPost checks a username and password with the auth backend
+// and responds with the user, and so their API key, creating the user on
+// their first login.
+func handleLoginPost(w http.ResponseWriter, r *http.Request, ac apiConfig) {
+	if ac.Auth == nil {
+		respondWithError(w, http.StatusNotFound, "Password login is not enabled")
+		return
+	}
+	type loginRequest struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := loginRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Could not decode json request")
+		return
+	}
+	identity, err := ac.Auth.authenticate(r.Context(), req.Username, req.Password)
+	if errors.Is(err, errInvalidCredentials) {
+		logWarn("auth", "Failed %s login for %q", ac.Auth.name(), req.Username)
+		respondWithError(w, http.StatusUnauthorized, "Invalid username or password")
+		return
+	}
+	if err != nil {
+		logError("auth", "Could not check %s login for %q: %v", ac.Auth.name(), req.Username, err)
+		respondWithError(w, http.StatusBadGateway, "Unable to check credentials with the user directory")
+		return
+	}
+
+	tx, err := ac.DB.Begin(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to log in")
+		return
+	}
+	defer tx.Rollback()
+	now := time.Now()
+	user, err := tx.GetUserByIdentity(r.Context(), database.GetUserByIdentityParams{
+		Backend: ac.Auth.name(),
+		Subject: identity.Subject,
+	})
+	if errors.Is(err, sql.ErrNoRows) && ac.SCIMToken != "" {
+		// A user provisioned over SCIM has no identity until they first
+		// log in, so it is claimed by name.
+		user, err = tx.GetUnclaimedSCIMUser(r.Context(), database.GetUnclaimedSCIMUserParams{
+			Name:    identity.Name,
+			Backend: ac.Auth.name(),
+		})
+		if err == nil {
+			err = tx.CreateUserIdentity(r.Context(), database.CreateUserIdentityParams{
+				Backend:   ac.Auth.name(),
+				Subject:   identity.Subject,
+				UserID:    user.ID,
+				CreatedAt: now,
+			})
+		}
+		if err == nil {
+			logInfo("auth", "Linked %s login %q to provisioned user %s", ac.Auth.name(), identity.Subject, user.ID)
+		}
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		user, err = tx.CreateUser(r.Context(), database.CreateUserParams{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			Name:      identity.Name,
+		})
+		if isUniqueViolation(err) {
+			respondWithError(w, http.StatusConflict, "Name already taken by another user")
+			return
+		}
+		if err == nil {
+			err = tx.CreateUserIdentity(r.Context(), database.CreateUserIdentityParams{
+				Backend:   ac.Auth.name(),
+				Subject:   identity.Subject,
+				UserID:    user.ID,
+				CreatedAt: now,
+			})
+		}
+		if err == nil {
+			logInfo("auth", "Created user %s for %s login %q", user.ID, ac.Auth.name(), identity.Subject)
+		}
+	}
+	if err != nil {
+		logError("auth", "Could not look up user for %s login %q: %v", ac.Auth.name(), identity.Subject, err)
+		respondWithError(w, http.StatusInternalServerError, "Unable to log in")
+		return
+	}
+	if user.BannedAt.Valid {
+		respondWithError(w, http.StatusForbidden, "Account suspended")
+		return
+	}
+	if identity.Admin != nil && *identity.Admin != user.IsAdmin {
+		err = tx.SetUserAdmin(r.Context(), database.SetUserAdminParams{ID: user.ID, IsAdmin: *identity.Admin, UpdatedAt: now})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to log in")
+			return
+		}
+		logInfo("auth", "Set admin to %t for %s from the directory's groups", *identity.Admin, user.Name)
+		user.IsAdmin = *identity.Admin
+	}
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to log in")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, newUserResponse(user))
+}
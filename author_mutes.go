@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// maxAuthorLength bounds a muted author's name, which is compared against
+// posts' authors on every timeline read.
+const maxAuthorLength = 200
+
+// rssAuthorPattern matches the RSS 2.0 author form, an email address
+// followed by the name in parentheses.
+var rssAuthorPattern = regexp.MustCompile(`^\S+@\S+\s*\((.+)\)$`)
+
+// itemAuthor names an item's author: its dc:creator, which is a name, or
+// else its author, from which the name is taken when it is in the RSS
+// "email (name)" form.
+func itemAuthor(item feedItem) string {
+	if creator := normalizeAuthor(item.Creator); creator != "" {
+		return creator
+	}
+	author := normalizeAuthor(item.Author)
+	if m := rssAuthorPattern.FindStringSubmatch(author); m != nil {
+		return normalizeAuthor(m[1])
+	}
+	return author
+}
+
+// normalizeAuthor trims an author's name and collapses its whitespace, so
+// that a mute matches however the feed happens to space it. Case is kept
+// for display; matching ignores it.
+func normalizeAuthor(author string) string {
+	return strings.Join(strings.Fields(author), " ")
+}
+
+type authorMuteResponse struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Author    string    `json:"author"`
+}
+
+func newAuthorMuteResponse(m database.AuthorMute) authorMuteResponse {
+	return authorMuteResponse{ID: m.ID, CreatedAt: m.CreatedAt, Author: m.Author}
+}
+
+// handleAuthorMutesPost mutes an author for the user: posts by them are
+// left out of the user's timeline, from every feed they write for.
+func handleAuthorMutesPost(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	type authorMuteRequest struct {
+		Author string `json:"author"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := authorMuteRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	author := normalizeAuthor(req.Author)
+	if author == "" {
+		respondWithError(w, http.StatusBadRequest, "Author is required")
+		return
+	}
+	if len(author) > maxAuthorLength {
+		respondWithError(w, http.StatusBadRequest, "Author is too long")
+		return
+	}
+	mute, err := ac.DB.CreateAuthorMute(r.Context(), database.CreateAuthorMuteParams{
+		ID:        uuid.New(),
+		CreatedAt: ac.Clock.Now(),
+		UserID:    u.ID,
+		Author:    author,
+	})
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "Author is already muted")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to mute author")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, newAuthorMuteResponse(mute))
+}
+
+func handleAuthorMutesGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	mutes, err := ac.DB.GetUserAuthorMutes(r.Context(), u.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve muted authors")
+		return
+	}
+	resp := make([]authorMuteResponse, 0, len(mutes))
+	for _, m := range mutes {
+		resp = append(resp, newAuthorMuteResponse(m))
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func handleAuthorMutesDelete(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	muteID, err := uuid.Parse(chi.URLParam(r, "muteID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	err = ac.DB.DeleteAuthorMute(r.Context(), database.DeleteAuthorMuteParams{
+		ID:     muteID,
+		UserID: u.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Problem unmuting author")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
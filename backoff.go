@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const (
+	// backoffBase is the wait after the first failure; it doubles with
+	// each further consecutive failure up to backoffMax.
+	backoffBase           = 5 * time.Minute
+	backoffMax            = 24 * time.Hour
+	defaultFeedPauseAfter = 10
+	maxFetchErrorLength   = 500
+)
+
+func newFeedPauseAfterFromEnv() (int, error) {
+	v := os.Getenv("FEED_PAUSE_AFTER")
+	if v == "" {
+		return defaultFeedPauseAfter, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("Invalid FEED_PAUSE_AFTER")
+	}
+	return n, nil
+}
+
+// fetchBackoff is how long to leave a feed alone after its nth consecutive
+// failure.
+func fetchBackoff(failures int32) time.Duration {
+	d := backoffBase
+	for i := int32(1); i < failures && d < backoffMax; i++ {
+		d *= 2
+	}
+	return min(d, backoffMax)
+}
+
+// recordFetchFailure backs a failing feed off exponentially and pauses it
+// once it has failed PauseAfter times in a row. A paused feed is not
+// fetched again until it is resumed.
+func recordFetchFailure(ctx context.Context, ac apiConfig, f database.Feed, fetchErr error) {
+	failures := f.FetchFailures + 1
+	msg := fetchErr.Error()
+	if len(msg) > maxFetchErrorLength {
+		msg = msg[:maxFetchErrorLength]
+	}
+	now := ac.Clock.Now()
+	params := database.RecordFeedFetchFailureParams{
+		ID:             f.ID,
+		FetchFailures:  failures,
+		LastFetchError: msg,
+		RetryAt:        sql.NullTime{Time: now.Add(fetchBackoff(failures)), Valid: true},
+	}
+	if int(failures) >= ac.FeedPauseAfter {
+		params.PausedAt = sql.NullTime{Time: now, Valid: true}
+		logWarn("fetch", "Pausing %s after %d consecutive failures: %s", f.Name, failures, msg)
+	}
+	err := ac.DB.RecordFeedFetchFailure(ctx, params)
+	if err != nil {
+		logError("fetch", "Could not record fetch failure for %s: %v", f.Name, err)
+	}
+}
+
+func recordFetchSuccess(ctx context.Context, ac apiConfig, f database.Feed) {
+	if f.FetchFailures == 0 {
+		return
+	}
+	err := ac.DB.ClearFeedFetchFailures(ctx, f.ID)
+	if err != nil {
+		logError("fetch", "Could not clear fetch failures for %s: %v", f.Name, err)
+	}
+}
+
+// handleAdminFeedResume un-pauses a feed and forgets its failures, so it is
+// fetched on the next tick.
+func handleAdminFeedResume(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	feedID, err := parseFeedID(r.Context(), ac.DB, chi.URLParam(r, "feedID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+
+	tx, err := ac.DB.Begin(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to resume feed")
+		return
+	}
+	defer tx.Rollback()
+
+	feed, err := tx.ResumeFeed(r.Context(), database.ResumeFeedParams{
+		ID:        feedID,
+		UpdatedAt: time.Now(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Feed not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to resume feed")
+		return
+	}
+	err = recordAudit(r.Context(), tx, u.ID, "feed.resume", "feed", feed.ID, map[string]string{"url": feed.Url})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to record audit log entry")
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to resume feed")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, newFeedResponse(feed))
+}
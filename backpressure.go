@@ -0,0 +1,80 @@
+package main
+
+import (
+	"time"
+)
+
+const (
+	// Above ingestSlowLatency, the average time to write one post, the
+	// fetch worker halves its concurrency and batch size; above
+	// ingestCriticalLatency it fetches one feed at a time, in small batches.
+	ingestSlowLatency     = 100 * time.Millisecond
+	ingestCriticalLatency = 500 * time.Millisecond
+	// ingestLatencyWeight is how much each write moves the average, so that
+	// one slow write does not throttle fetching on its own.
+	ingestLatencyWeight = 0.2
+	criticalBatchSize   = 2
+)
+
+// ingestDegradation is how far fetching is being held back because the
+// database is slow to take new posts.
+type ingestDegradation int64
+
+const (
+	ingestNormal ingestDegradation = iota
+	ingestSlowed
+	ingestThrottled
+)
+
+func (d ingestDegradation) String() string {
+	switch d {
+	case ingestSlowed:
+		return "slowed"
+	case ingestThrottled:
+		return "throttled"
+	}
+	return "normal"
+}
+
+// recordIngest folds the time one post write took into the moving average
+// of IngestLatency.
+func (s *workerStats) recordIngest(d time.Duration) {
+	if s == nil {
+		return
+	}
+	for {
+		old := s.IngestLatency.Load()
+		next := int64(d)
+		if old != 0 {
+			next = old + int64(ingestLatencyWeight*float64(int64(d)-old))
+		}
+		if s.IngestLatency.CompareAndSwap(old, next) {
+			return
+		}
+	}
+}
+
+// ingestDegradation reports how far fetching should be held back, given
+// the current average post write latency.
+func (s *workerStats) ingestDegradation() ingestDegradation {
+	latency := time.Duration(s.IngestLatency.Load())
+	switch {
+	case latency > ingestCriticalLatency:
+		return ingestThrottled
+	case latency > ingestSlowLatency:
+		return ingestSlowed
+	}
+	return ingestNormal
+}
+
+// fetchLimits returns how many feeds to fetch at once, and how many per
+// batch, at degradation d.
+func fetchLimits(workers int, d ingestDegradation) (int, int) {
+	switch d {
+	case ingestSlowed:
+		return max(workers/2, 1), fetchBatchSize / 2
+	case ingestThrottled:
+		return 1, criticalBatchSize
+	}
+	return workers, fetchBatchSize
+}
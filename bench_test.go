@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+	"github.com/pmwals09/rss-aggregator/internal/memstore"
+)
+
+const (
+	// benchFeeds is how many feeds a generated dataset spreads its posts
+	// over. The benchmark user follows all of them.
+	benchFeeds = 100
+	// maxMemstoreBenchPosts caps the datasets generated in the memory
+	// store, whose inserts check uniqueness by scanning every post. Set
+	// TEST_DB_URL to a migrated Postgres database to run the larger ones.
+	maxMemstoreBenchPosts = 10_000
+)
+
+// benchSizes are the dataset sizes, in posts, that listing and inserting
+// are measured at.
+var benchSizes = []int{10_000, 100_000, 1_000_000}
+
+// A benchDataset is a generated user following benchFeeds feeds with
+// posts spread evenly over them. It is written inside a transaction that
+// is never committed, so benchmarks are safe to point at a real database.
+type benchDataset struct {
+	tx      database.Tx
+	user    database.User
+	feedIDs []uuid.UUID
+	posts   int
+}
+
+var (
+	benchDB       *sql.DB
+	benchDatasets = map[int]*benchDataset{}
+)
+
+// loadBenchDataset returns the dataset of n posts, generating it the first
+// time it is asked for, since a benchmark function runs several times as
+// b.N grows.
+func loadBenchDataset(b *testing.B, n int) *benchDataset {
+	if d, ok := benchDatasets[n]; ok {
+		return d
+	}
+	var store database.Store = memstore.New()
+	if dbURL := os.Getenv("TEST_DB_URL"); dbURL != "" {
+		if benchDB == nil {
+			db, err := sql.Open("postgres", dbURL)
+			if err != nil {
+				b.Fatal(err)
+			}
+			benchDB = db
+		}
+		store = database.NewStore(benchDB)
+	} else if n > maxMemstoreBenchPosts {
+		b.Skipf("%d posts is too many for the memory store; set TEST_DB_URL", n)
+	}
+	ctx := context.Background()
+	tx, err := store.Begin(ctx)
+	if err != nil {
+		b.Fatal(err)
+	}
+	d := &benchDataset{tx: tx}
+	d.user, d.feedIDs, err = benchSeed(ctx, tx, n)
+	if err != nil {
+		b.Fatal(err)
+	}
+	d.posts = n
+	benchDatasets[n] = d
+	return d
+}
+
+func BenchmarkParseFeed(b *testing.B) {
+	body := benchRSS(100)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(body)))
+	for i := 0; i < b.N; i++ {
+		fd := feedData{}
+		if err := xml.Unmarshal(body, &fd); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkListPosts measures GET /v1/posts. It is declared before
+// BenchmarkInsertPosts, and so runs first, so that it sees exactly the
+// generated posts.
+func BenchmarkListPosts(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprintf("posts=%d", n), func(b *testing.B) {
+			d := loadBenchDataset(b, n)
+			ac := apiConfig{DB: benchStore{d.tx}}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				w := httptest.NewRecorder()
+				handlePostsGet(w, httptest.NewRequest(http.MethodGet, "/v1/posts", nil), d.user, ac)
+				if w.Code != http.StatusOK {
+					b.Fatalf("GET /v1/posts: %d %s", w.Code, w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkInsertPosts(b *testing.B) {
+	ctx := context.Background()
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprintf("posts=%d", n), func(b *testing.B) {
+			d := loadBenchDataset(b, n)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, err := d.tx.CreatePost(ctx, benchPost(d.feedIDs[d.posts%len(d.feedIDs)], d.posts))
+				if err != nil {
+					b.Fatal(err)
+				}
+				d.posts++
+			}
+		})
+	}
+}
+
+func benchSeed(ctx context.Context, q database.Querier, n int) (database.User, []uuid.UUID, error) {
+	now := time.Now()
+	user, err := q.CreateUser(ctx, database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Name:      "bench-" + uuid.NewString(),
+	})
+	if err != nil {
+		return user, nil, err
+	}
+	feedIDs := make([]uuid.UUID, 0, benchFeeds)
+	for i := 0; i < benchFeeds; i++ {
+		feed, err := q.CreateFeed(ctx, database.CreateFeedParams{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			Name:      fmt.Sprintf("Bench feed %d", i),
+			Url:       fmt.Sprintf("https://bench.invalid/%s/%d/rss", user.ID, i),
+			UserID:    user.ID,
+		})
+		if err != nil {
+			return user, nil, err
+		}
+		_, err = q.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			UserID:    user.ID,
+			FeedID:    feed.ID,
+		})
+		if err != nil {
+			return user, nil, err
+		}
+		feedIDs = append(feedIDs, feed.ID)
+	}
+	for i := 0; i < n; i++ {
+		_, err := q.CreatePost(ctx, benchPost(feedIDs[i%len(feedIDs)], i))
+		if err != nil {
+			return user, nil, err
+		}
+	}
+	return user, feedIDs, nil
+}
+
+func benchPost(feedID uuid.UUID, i int) database.CreatePostParams {
+	t := time.Unix(1700000000, 0).Add(time.Duration(i) * time.Minute)
+	return database.CreatePostParams{
+		ID:          uuid.New(),
+		CreatedAt:   t,
+		UpdatedAt:   t,
+		Title:       fmt.Sprintf("Bench post %d", i),
+		Url:         fmt.Sprintf("https://bench.invalid/%s/posts/%d", feedID, i),
+		Description: sql.NullString{String: "Generated for benchmarking.", Valid: true},
+		PublishedAt: sql.NullTime{Time: t, Valid: true},
+		FeedID:      feedID,
+	}
+}
+
+func benchRSS(items int) []byte {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0"?><rss version="2.0"><channel><title>Bench</title><link>https://bench.invalid/</link><description>Generated</description>`)
+	t := time.Unix(1700000000, 0).UTC()
+	for i := 0; i < items; i++ {
+		fmt.Fprintf(&sb, `<item><title>Item %d</title><link>https://bench.invalid/posts/%d</link><pubDate>%s</pubDate><description>Generated item %d with a little text.</description></item>`,
+			i, i, t.Add(time.Duration(i)*time.Minute).Format(time.RFC1123Z), i)
+	}
+	sb.WriteString(`</channel></rss>`)
+	return []byte(sb.String())
+}
+
+// benchStore lets handlers run against a transaction that the benchmark
+// never commits. Transactions they begin are folded into it.
+type benchStore struct {
+	database.Tx
+}
+
+func (s benchStore) Begin(ctx context.Context) (database.Tx, error) {
+	return nestedTx{s.Tx}, nil
+}
+
+type nestedTx struct {
+	database.Tx
+}
+
+func (nestedTx) Commit() error {
+	return nil
+}
+
+func (nestedTx) Rollback() error {
+	return nil
+}
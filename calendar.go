@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const (
+	// calendarWindow is how far back a feed's posts are looked at to learn
+	// which days it never posts on.
+	calendarWindow = 8 * 7 * 24 * time.Hour
+	// calendarMinPosts is how many posts the window needs before a quiet
+	// day means anything.
+	calendarMinPosts = 20
+	// quietDayInterval is how often a feed is polled on a day it has not
+	// posted on in the whole window, in case its habits change.
+	quietDayInterval = 6 * time.Hour
+)
+
+// quietWeekdays returns a bitmask, bit d set for time.Weekday d, of the
+// days (in UTC) with no posts, or 0 if there are too few posts to tell.
+func quietWeekdays(counts []database.CountFeedPostsByWeekdayRow) int32 {
+	total := int64(0)
+	posted := int32(0)
+	for _, c := range counts {
+		total += c.PostCount
+		posted |= 1 << c.Weekday
+	}
+	if total < calendarMinPosts {
+		return 0
+	}
+	return ^posted & 0x7f
+}
+
+func isQuietWeekday(mask int32, d time.Weekday) bool {
+	return mask&(1<<d) != 0
+}
+
+func quietWeekdayNames(mask int32) []string {
+	names := []string{}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if isQuietWeekday(mask, d) {
+			names = append(names, d.String())
+		}
+	}
+	return names
+}
+
+// learnFeedCalendar recomputes which weekdays a feed is quiet on from the
+// last calendarWindow of its posts. A feed younger than the window is not
+// judged, since a day it has not posted on yet may just not have come
+// round often enough.
+func learnFeedCalendar(ctx context.Context, ac apiConfig, feedID uuid.UUID) {
+	feed, err := ac.DB.GetFeed(ctx, feedID)
+	if err != nil {
+		logError("fetch", "Could not load feed %s to learn its calendar: %v", feedID, err)
+		return
+	}
+	since := ac.Clock.Now().Add(-calendarWindow)
+	mask := int32(0)
+	if feed.CreatedAt.Before(since) {
+		counts, err := ac.DB.CountFeedPostsByWeekday(ctx, database.CountFeedPostsByWeekdayParams{
+			FeedID: feedID,
+			Since:  since,
+		})
+		if err != nil {
+			logError("fetch", "Could not count posts by weekday on %s: %v", feed.Name, err)
+			return
+		}
+		mask = quietWeekdays(counts)
+	}
+	if mask == feed.QuietWeekdays {
+		return
+	}
+	logInfo("fetch", "%s is now quiet on %v", feed.Name, quietWeekdayNames(mask))
+	err = ac.DB.SetFeedQuietWeekdays(ctx, database.SetFeedQuietWeekdaysParams{
+		ID:            feedID,
+		QuietWeekdays: mask,
+	})
+	if err != nil {
+		logError("fetch", "Could not store quiet weekdays on %s: %v", feed.Name, err)
+	}
+}
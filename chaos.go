@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// chaosConfig describes the faults injected by FETCH_CHAOS, for example
+// "timeout=0.1,5xx=0.05,malformed=0.05,slow=0.2,delay=3s". Rates are
+// probabilities per fetch. Timeouts, 5xx responses and malformed bodies are
+// mutually exclusive, so their rates may add up to at most 1. Slowness is
+// drawn separately and delays a fetch that otherwise proceeds normally.
+type chaosConfig struct {
+	Timeout   float64
+	ServerErr float64
+	Malformed float64
+	Slow      float64
+	Delay     time.Duration
+}
+
+func parseChaosConfig(s string) (chaosConfig, error) {
+	c := chaosConfig{Delay: 5 * time.Second}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return c, fmt.Errorf("FETCH_CHAOS: expected key=value, got %q", part)
+		}
+		if key == "delay" {
+			d, err := time.ParseDuration(value)
+			if err != nil || d < 0 {
+				return c, fmt.Errorf("FETCH_CHAOS: invalid delay %q", value)
+			}
+			c.Delay = d
+			continue
+		}
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return c, fmt.Errorf("FETCH_CHAOS: %s must be a rate between 0 and 1", key)
+		}
+		switch key {
+		case "timeout":
+			c.Timeout = rate
+		case "5xx":
+			c.ServerErr = rate
+		case "malformed":
+			c.Malformed = rate
+		case "slow":
+			c.Slow = rate
+		default:
+			return c, fmt.Errorf("FETCH_CHAOS: unknown fault %q", key)
+		}
+	}
+	if c.Timeout+c.ServerErr+c.Malformed > 1 {
+		return c, fmt.Errorf("FETCH_CHAOS: timeout, 5xx and malformed rates add up to more than 1")
+	}
+	return c, nil
+}
+
+// chaosFetchFeed wraps a fetcher so that it fails or stalls the way real
+// feeds do. It is for exercising the scheduler and never for production.
+func chaosFetchFeed(c chaosConfig, next fetchFunc) fetchFunc {
+	return func(ctx context.Context, url string, cache cacheValidators, headers http.Header) (feedData, error) {
+		if rand.Float64() < c.Slow {
+			select {
+			case <-time.After(c.Delay):
+			case <-ctx.Done():
+				return feedData{}, ctx.Err()
+			}
+		}
+		roll := rand.Float64()
+		switch {
+		case roll < c.Timeout:
+			select {
+			case <-time.After(c.Delay):
+			case <-ctx.Done():
+				return feedData{}, ctx.Err()
+			}
+			return feedData{}, fmt.Errorf("chaos: fetching %s: timed out after %s", url, c.Delay)
+		case roll < c.Timeout+c.ServerErr:
+			return feedData{}, fmt.Errorf("chaos: fetching %s: 503 Service Unavailable", url)
+		case roll < c.Timeout+c.ServerErr+c.Malformed:
+			fd := feedData{}
+			err := xml.Unmarshal([]byte(`<rss version="2.0"><channel><title>Truncated`), &fd)
+			return fd, err
+		}
+		return next(ctx, url, cache, headers)
+	}
+}
+
+// newChaosFetchFeedFromEnv wraps next when FETCH_CHAOS is set and returns it
+// unchanged otherwise.
+func newChaosFetchFeedFromEnv(next fetchFunc) (fetchFunc, error) {
+	spec := os.Getenv("FETCH_CHAOS")
+	if spec == "" {
+		return next, nil
+	}
+	c, err := parseChaosConfig(spec)
+	if err != nil {
+		return nil, err
+	}
+	logWarn("fetch", "Fetch chaos enabled: %+v", c)
+	return chaosFetchFeed(c, next), nil
+}
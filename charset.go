@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// xmlEncodingPattern matches the encoding named in an XML declaration.
+var xmlEncodingPattern = regexp.MustCompile(`^<\?xml[^>]*\sencoding\s*=\s*["']([^"']*)["']`)
+
+// windows1252High maps bytes 0x80-0x9F in windows-1252 to runes; every
+// other byte is the rune of the same value. The five unassigned bytes map
+// to the C1 controls, as browsers do.
+var windows1252High = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '\u008d', 'Ž', '\u008f',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '\u009d', 'ž', 'Ÿ',
+}
+
+// decodeCharset converts text in the named character set to UTF-8. Only
+// the encodings feeds are commonly served in are known. ISO-8859-1 is read
+// as windows-1252, its superset, since feeds that claim the former often
+// contain the latter's curly quotes and dashes.
+func decodeCharset(label string, b []byte) ([]byte, error) {
+	switch strings.ToLower(strings.TrimSpace(label)) {
+	case "", "utf-8", "utf8", "unicode-1-1-utf-8", "us-ascii", "ascii":
+		return b, nil
+	case "iso-8859-1", "iso8859-1", "iso_8859-1", "latin1", "l1", "windows-1252", "cp1252", "x-cp1252":
+		out := make([]byte, 0, len(b)+len(b)/8)
+		for _, c := range b {
+			r := rune(c)
+			if c >= 0x80 && c <= 0x9F {
+				r = windows1252High[c-0x80]
+			}
+			out = utf8.AppendRune(out, r)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unsupported charset %q", label)
+}
+
+// charsetReader lets an xml.Decoder read documents whose declaration names
+// an encoding other than UTF-8.
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	b, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	b, err = decodeCharset(label, b)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(b), nil
+}
+
+// newXMLDecoder returns a decoder for a feed body that understands the
+// encodings decodeCharset does.
+func newXMLDecoder(body []byte) *xml.Decoder {
+	d := xml.NewDecoder(bytes.NewReader(body))
+	d.CharsetReader = charsetReader
+	return d
+}
+
+// feedBodyToUTF8 converts a fetched feed body to UTF-8 using the charset in
+// its Content-Type. The body's own say wins: a UTF-8 byte order mark or an
+// XML declaration naming an encoding is left for the parser to honor, since
+// servers often label every text/xml response with a default charset. For
+// the same reason a charset that isn't known leaves the body as it is.
+func feedBodyToUTF8(body []byte, contentType string) []byte {
+	if bytes.HasPrefix(body, []byte("\xEF\xBB\xBF")) || xmlEncodingPattern.Match(body) {
+		return body
+	}
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return body
+	}
+	decoded, err := decodeCharset(params["charset"], body)
+	if err != nil {
+		return body
+	}
+	return decoded
+}
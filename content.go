@@ -0,0 +1,196 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const maxProxiedImageBytes = 10 << 20
+
+var defaultIframeHosts = []string{"www.youtube.com", "www.youtube-nocookie.com", "player.vimeo.com"}
+
+// contentPolicy is the instance's policy for rendering post HTML. Posts are
+// sanitized once when they are stored and again, under the current policy,
+// each time they are rendered, so tightening the policy applies to posts
+// already stored.
+type contentPolicy struct {
+	IframeHosts []string
+	// ProxyImages sends rendered images through the image proxy, so that
+	// reading a post does not reveal the reader to the image's host.
+	ProxyImages bool
+	ProxyKey    []byte
+}
+
+// newContentPolicyFromEnv reads CONTENT_IFRAME_HOSTS, a comma-separated
+// list of hosts whose iframes are kept ("none" for none; YouTube and Vimeo
+// by default), CONTENT_PROXY_IMAGES, true by default, and
+// IMAGE_PROXY_SECRET, the key proxied image URLs are signed with. Without
+// it a random key is generated, which only means rendered posts' image
+// URLs stop working after a restart.
+func newContentPolicyFromEnv() (contentPolicy, error) {
+	p := contentPolicy{IframeHosts: defaultIframeHosts, ProxyImages: true}
+	if v := os.Getenv("CONTENT_IFRAME_HOSTS"); v != "" {
+		p.IframeHosts = []string{}
+		if v != "none" {
+			for _, host := range strings.Split(v, ",") {
+				host = strings.ToLower(strings.TrimSpace(host))
+				if host == "" || strings.ContainsAny(host, "/:* ") {
+					return p, fmt.Errorf("Invalid CONTENT_IFRAME_HOSTS: %q is not a host name", host)
+				}
+				p.IframeHosts = append(p.IframeHosts, host)
+			}
+		}
+	}
+	if v := os.Getenv("CONTENT_PROXY_IMAGES"); v != "" {
+		switch v {
+		case "true":
+		case "false":
+			p.ProxyImages = false
+		default:
+			return p, fmt.Errorf("Invalid CONTENT_PROXY_IMAGES")
+		}
+	}
+	if secret := os.Getenv("IMAGE_PROXY_SECRET"); secret != "" {
+		p.ProxyKey = []byte(secret)
+	} else {
+		p.ProxyKey = make([]byte, 32)
+		rand.Read(p.ProxyKey)
+	}
+	return p, nil
+}
+
+// ingestOptions sanitize HTML for storing.
+func (p contentPolicy) ingestOptions() sanitizeOptions {
+	return sanitizeOptions{IframeHosts: p.IframeHosts}
+}
+
+func (p contentPolicy) signImageURL(imageURL string) string {
+	mac := hmac.New(sha256.New, p.ProxyKey)
+	mac.Write([]byte(imageURL))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// renderHTML sanitizes stored post HTML under the current policy for
+// serving. Relative image URLs are resolved against base, the post's URL.
+func (p contentPolicy) renderHTML(s string, base *url.URL) string {
+	return sanitizeHTML(s, sanitizeOptions{
+		IframeHosts: p.IframeHosts,
+		ImageURL: func(src string) string {
+			u, err := base.Parse(src)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				return ""
+			}
+			if !p.ProxyImages {
+				return u.String()
+			}
+			return "/v1/image_proxy?" + url.Values{"url": {u.String()}, "sig": {p.signImageURL(u.String())}}.Encode()
+		},
+	})
+}
+
+// contentSecurityPolicy allows rendered posts images from the image proxy,
+// or anywhere if images are not proxied, and frames from the allowed
+// hosts, and nothing else: no scripts, styles, fonts or forms.
+func (p contentPolicy) contentSecurityPolicy() string {
+	imgSrc := "'self'"
+	if !p.ProxyImages {
+		imgSrc = "https: http:"
+	}
+	frameSrc := "'none'"
+	if len(p.IframeHosts) > 0 {
+		frameSrc = "https://" + strings.Join(p.IframeHosts, " https://")
+	}
+	return "default-src 'none'; img-src " + imgSrc + "; frame-src " + frameSrc + "; base-uri 'none'; form-action 'none'"
+}
+
+// handlePostContentGet serves a post's description as a standalone HTML
+// document for an embedding UI to show, say in a sandboxed iframe. Links
+// open outside the frame.
+func handlePostContentGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	postID, err := parsePostID(r.Context(), ac.DB, chi.URLParam(r, "postID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	post, err := visiblePost(r.Context(), ac, u, postID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Post not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve post")
+		return
+	}
+	base, err := url.Parse(post.Url)
+	if err != nil {
+		base = &url.URL{}
+	}
+	body := ac.Content.renderHTML(post.Description.String, base)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", ac.Content.contentSecurityPolicy())
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><base target=\"_blank\"><title>%s</title></head>\n<body>%s</body></html>\n", html.EscapeString(post.Title), body)
+}
+
+// handleImageProxyGet fetches an image a rendered post links to. Only URLs
+// the server signed are fetched, so the proxy cannot be used for anything
+// else, and only raster images are passed on, judged by their content.
+func handleImageProxyGet(w http.ResponseWriter, r *http.Request, ac apiConfig) {
+	imageURL := r.URL.Query().Get("url")
+	sig := r.URL.Query().Get("sig")
+	if !hmac.Equal([]byte(sig), []byte(ac.Content.signImageURL(imageURL))) {
+		respondWithError(w, http.StatusForbidden, "Invalid signature")
+		return
+	}
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, imageURL, nil)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid image URL")
+		return
+	}
+	res, err := ac.HTTPClient.Do(req)
+	if err != nil {
+		respondWithError(w, http.StatusBadGateway, "Unable to fetch image")
+		return
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		respondWithError(w, http.StatusBadGateway, fmt.Sprintf("Image host answered %s", res.Status))
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxProxiedImageBytes+1))
+	if err != nil {
+		respondWithError(w, http.StatusBadGateway, "Unable to fetch image")
+		return
+	}
+	if len(data) > maxProxiedImageBytes {
+		respondWithError(w, http.StatusBadGateway, "Image too large")
+		return
+	}
+	contentType := http.DetectContentType(data)
+	if !strings.HasPrefix(contentType, "image/") {
+		respondWithError(w, http.StatusBadGateway, "Not an image")
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// feedDateLayouts are the date shapes seen in the wild, most common first.
+// RSS asks for RFC 822 but feeds send everything from RFC 1123 with or
+// without the weekday to bare ISO 8601 dates.
+var feedDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 02 Jan 2006 15:04 -0700",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"02 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	time.RFC822Z,
+	time.RFC822,
+	time.RFC850,
+	time.ANSIC,
+	time.UnixDate,
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// timezoneOffsets resolves the abbreviations RFC 822 allows, plus a few
+// common European ones. Go's time.Parse accepts any abbreviation but only
+// knows the offset of the local zone's, so "EST" would otherwise read as
+// UTC.
+var timezoneOffsets = map[string]string{
+	"UT":   "+0000",
+	"UTC":  "+0000",
+	"GMT":  "+0000",
+	"Z":    "+0000",
+	"EST":  "-0500",
+	"EDT":  "-0400",
+	"CST":  "-0600",
+	"CDT":  "-0500",
+	"MST":  "-0700",
+	"MDT":  "-0600",
+	"PST":  "-0800",
+	"PDT":  "-0700",
+	"BST":  "+0100",
+	"CET":  "+0100",
+	"CEST": "+0200",
+	"EET":  "+0200",
+	"EEST": "+0300",
+}
+
+// parseFeedDate parses an RSS pubDate or Atom/JSON Feed timestamp in any of
+// feedDateLayouts. A trailing timezone abbreviation is replaced by its
+// numeric offset first.
+func parseFeedDate(s string) (time.Time, error) {
+	t, _, err := parseFeedDateLayout(s)
+	return t, err
+}
+
+// parseFeedDateLayout is parseFeedDate that also says which layout matched.
+func parseFeedDateLayout(s string) (time.Time, string, error) {
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return time.Time{}, "", fmt.Errorf("empty date")
+	}
+	if i := strings.LastIndexByte(s, ' '); i >= 0 {
+		if offset, ok := timezoneOffsets[strings.ToUpper(s[i+1:])]; ok {
+			s = s[:i+1] + offset
+		}
+	}
+	for _, layout := range feedDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, layout, nil
+		}
+	}
+	return time.Time{}, "", fmt.Errorf("unrecognised date %q", s)
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	defaultDBBreakerFailures      = 5
+	defaultDBBreakerProbeInterval = 2 * time.Second
+	defaultDBBreakerTimeout       = 2 * time.Second
+)
+
+var errDBUnavailable = errors.New("database unavailable: circuit breaker is open")
+
+// dbBreaker is a circuit breaker around the database. After Failures
+// queries in a row fail because the database is down or not answering, it
+// opens, and queries fail at once with errDBUnavailable rather than each
+// waiting out the driver's timeouts. It closes again as soon as a query,
+// or the probe it runs every ProbeInterval, succeeds. The probe is also
+// what notices a database that hangs rather than refuses connections:
+// pings that take longer than Timeout count as failures.
+type dbBreaker struct {
+	Failures      int
+	ProbeInterval time.Duration
+	Timeout       time.Duration
+
+	mu       sync.Mutex
+	open     bool
+	failures int
+	openedAt time.Time
+	trips    int64
+}
+
+// newDBBreakerFromEnv reads DB_BREAKER_FAILURES, the consecutive failures
+// that open the breaker, DB_BREAKER_PROBE_INTERVAL, how often the database
+// is pinged, and DB_BREAKER_TIMEOUT, how long a ping may take. It returns
+// nil if DB_BREAKER_FAILURES is 0, which turns the breaker off.
+func newDBBreakerFromEnv() (*dbBreaker, error) {
+	b := &dbBreaker{
+		Failures:      defaultDBBreakerFailures,
+		ProbeInterval: defaultDBBreakerProbeInterval,
+		Timeout:       defaultDBBreakerTimeout,
+	}
+	if v := os.Getenv("DB_BREAKER_FAILURES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("Invalid DB_BREAKER_FAILURES")
+		}
+		if n == 0 {
+			return nil, nil
+		}
+		b.Failures = n
+	}
+	if v := os.Getenv("DB_BREAKER_PROBE_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("Invalid DB_BREAKER_PROBE_INTERVAL")
+		}
+		b.ProbeInterval = d
+	}
+	if v := os.Getenv("DB_BREAKER_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("Invalid DB_BREAKER_TIMEOUT")
+		}
+		b.Timeout = d
+	}
+	return b, nil
+}
+
+func (b *dbBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.open {
+		return errDBUnavailable
+	}
+	return nil
+}
+
+func (b *dbBreaker) Record(ctx context.Context, err error) {
+	// The caller gave up, say because the client went away, so the query
+	// says nothing about the database.
+	if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !isDBOutage(err) {
+		if b.open {
+			logInfo("db", "Database is answering again after %s, closing circuit breaker", time.Since(b.openedAt).Round(time.Second))
+			b.open = false
+		}
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if !b.open && b.failures >= b.Failures {
+		logError("db", "Opening circuit breaker after %d database failures in a row: %v", b.failures, err)
+		b.open = true
+		b.openedAt = time.Now()
+		b.trips++
+	}
+}
+
+// isDBOutage reports whether err means the database could not be reached
+// or did not answer in time, as opposed to answering, perhaps with an
+// error about the query itself such as a constraint violation.
+func isDBOutage(err error) bool {
+	if err == nil {
+		return false
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// Connection exceptions, insufficient resources, and the server
+		// shutting down.
+		switch pqErr.Code.Class() {
+		case "08", "53", "57":
+			return true
+		}
+		return false
+	}
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &netErr)
+}
+
+// probe pings db every ProbeInterval and records the result, until ctx is
+// done. lib/pq does not give up on a connection handshake when the context
+// is done, so a ping is not waited on for longer than Timeout; one that is
+// still going counts as a failure at every tick until it returns, and no
+// other ping is started meanwhile.
+func (b *dbBreaker) probe(ctx context.Context, db *sql.DB) {
+	ticker := time.NewTicker(b.ProbeInterval)
+	defer ticker.Stop()
+	var pinging chan error
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if pinging == nil {
+			pinging = make(chan error, 1)
+			go func(done chan<- error) {
+				pingCtx, cancel := context.WithTimeout(ctx, b.Timeout)
+				defer cancel()
+				done <- db.PingContext(pingCtx)
+			}(pinging)
+		}
+		timer := time.NewTimer(b.Timeout)
+		select {
+		case err := <-pinging:
+			pinging = nil
+			b.Record(ctx, err)
+		case <-timer.C:
+			b.Record(ctx, context.DeadlineExceeded)
+		}
+		timer.Stop()
+	}
+}
+
+type dbBreakerState struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at"`
+	Trips               int64      `json:"trips"`
+}
+
+func (b *dbBreaker) state() dbBreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := dbBreakerState{State: "closed", ConsecutiveFailures: b.failures, Trips: b.trips}
+	if b.open {
+		s.State = "open"
+		openedAt := b.openedAt
+		s.OpenedAt = &openedAt
+	}
+	return s
+}
+
+// middlewareDBBreaker answers 503 straight away while the database
+// breaker is open, since almost every route needs the database. Readiness
+// checks still get through, to report it.
+func (ac *apiConfig) middlewareDBBreaker(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b := ac.DBBreaker
+		if b == nil || r.URL.Path == "/v1/readiness" || b.Allow() == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		retryAfter := int(math.Ceil(b.ProbeInterval.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		respondWithError(w, http.StatusServiceUnavailable, "Database is unavailable, try again later")
+	})
+}
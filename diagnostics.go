@@ -0,0 +1,104 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// workerStats is updated by the background workers so that diagnostics can
+// show what they are doing. A fetch that never finishes stays counted in
+// FetchesInFlight, which is how stuck fetch goroutines show up.
+type workerStats struct {
+	FetchesInFlight atomic.Int64
+	LastBatchSize   atomic.Int64
+	LastBatchAt     atomic.Int64
+	// IngestLatency is the moving average time to write a post, in
+	// nanoseconds, and Degradation the ingestDegradation the fetch worker
+	// last acted on.
+	IngestLatency atomic.Int64
+	Degradation   atomic.Int64
+}
+
+func handleAdminDiagnosticsGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	type memStats struct {
+		HeapAlloc   uint64 `json:"heap_alloc"`
+		HeapInuse   uint64 `json:"heap_inuse"`
+		HeapObjects uint64 `json:"heap_objects"`
+		Sys         uint64 `json:"sys"`
+		NumGC       uint32 `json:"num_gc"`
+	}
+	type workers struct {
+		FetchesInFlight int64      `json:"fetches_in_flight"`
+		LastBatchSize   int64      `json:"last_batch_size"`
+		LastBatchAt     *time.Time `json:"last_batch_at"`
+		IngestLatencyMs float64    `json:"ingest_latency_ms"`
+		Degradation     string     `json:"degradation"`
+	}
+	type dbStats struct {
+		OpenConnections int             `json:"open_connections"`
+		InUse           int             `json:"in_use"`
+		Idle            int             `json:"idle"`
+		WaitCount       int64           `json:"wait_count"`
+		Breaker         *dbBreakerState `json:"breaker"`
+	}
+	type requests struct {
+		InFlight int64 `json:"in_flight"`
+		Shed     int64 `json:"shed"`
+	}
+	type response struct {
+		Goroutines int       `json:"goroutines"`
+		Memory     memStats  `json:"memory"`
+		Workers    workers   `json:"workers"`
+		DB         *dbStats  `json:"db"`
+		Requests   *requests `json:"requests"`
+	}
+
+	ms := runtime.MemStats{}
+	runtime.ReadMemStats(&ms)
+	resp := response{
+		Goroutines: runtime.NumGoroutine(),
+		Memory: memStats{
+			HeapAlloc:   ms.HeapAlloc,
+			HeapInuse:   ms.HeapInuse,
+			HeapObjects: ms.HeapObjects,
+			Sys:         ms.Sys,
+			NumGC:       ms.NumGC,
+		},
+	}
+	if ac.Stats != nil {
+		resp.Workers.FetchesInFlight = ac.Stats.FetchesInFlight.Load()
+		resp.Workers.LastBatchSize = ac.Stats.LastBatchSize.Load()
+		if at := ac.Stats.LastBatchAt.Load(); at != 0 {
+			t := time.Unix(at, 0)
+			resp.Workers.LastBatchAt = &t
+		}
+		resp.Workers.IngestLatencyMs = float64(ac.Stats.IngestLatency.Load()) / float64(time.Millisecond)
+		resp.Workers.Degradation = ingestDegradation(ac.Stats.Degradation.Load()).String()
+	}
+	if ac.Shedder != nil {
+		resp.Requests = &requests{
+			InFlight: ac.Shedder.inFlight.Load(),
+			Shed:     ac.Shedder.shed.Load(),
+		}
+	}
+	// Only the Postgres store has a connection pool.
+	if s, ok := ac.DB.(interface{ Stats() sql.DBStats }); ok {
+		st := s.Stats()
+		resp.DB = &dbStats{
+			OpenConnections: st.OpenConnections,
+			InUse:           st.InUse,
+			Idle:            st.Idle,
+			WaitCount:       st.WaitCount,
+		}
+		if ac.DBBreaker != nil {
+			breaker := ac.DBBreaker.state()
+			resp.DB.Breaker = &breaker
+		}
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+var errDomainNotAllowed = errors.New("feed domain is not allowed on this instance")
+
+// checkFeedDomain applies the instance's domain rules to a feed URL. A rule
+// matches its domain and every subdomain of it. Deny rules always win; once
+// any allow rule exists, only hosts matching an allow rule are accepted.
+func checkFeedDomain(rules []database.DomainRule, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return fmt.Errorf("invalid feed url %q", rawURL)
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	hasAllow := false
+	allowed := false
+	for _, rule := range rules {
+		matches := host == rule.Domain || strings.HasSuffix(host, "."+rule.Domain)
+		switch rule.Kind {
+		case "deny":
+			if matches {
+				return errDomainNotAllowed
+			}
+		case "allow":
+			hasAllow = true
+			allowed = allowed || matches
+		}
+	}
+	if hasAllow && !allowed {
+		return errDomainNotAllowed
+	}
+	return nil
+}
+
+func handleAdminDomainRulesGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	rules, err := ac.DB.ListDomainRules(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve domain rules")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, newDomainRuleResponses(rules))
+}
+
+func handleAdminDomainRulesPost(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	type domainRuleRequest struct {
+		Domain string `json:"domain"`
+		Kind   string `json:"kind"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := domainRuleRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	if req.Kind != "allow" && req.Kind != "deny" {
+		respondWithError(w, http.StatusBadRequest, "Kind must be allow or deny")
+		return
+	}
+	domain := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(req.Domain)), ".")
+	if domain == "" || strings.ContainsAny(domain, "/:@ ") {
+		respondWithError(w, http.StatusBadRequest, "Invalid domain")
+		return
+	}
+
+	tx, err := ac.DB.Begin(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save domain rule")
+		return
+	}
+	defer tx.Rollback()
+
+	rule, err := tx.CreateDomainRule(r.Context(), database.CreateDomainRuleParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		Domain:    domain,
+		Kind:      req.Kind,
+	})
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "A rule for that domain already exists")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save domain rule")
+		return
+	}
+	err = recordAudit(r.Context(), tx, u.ID, "domain_rule.create", "domain_rule", rule.ID, rule)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to record audit log entry")
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save domain rule")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, newDomainRuleResponse(rule))
+}
+
+func handleAdminDomainRulesDelete(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	ruleID, err := uuid.Parse(chi.URLParam(r, "ruleID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+
+	tx, err := ac.DB.Begin(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Problem deleting domain rule")
+		return
+	}
+	defer tx.Rollback()
+
+	rule, err := tx.DeleteDomainRule(r.Context(), ruleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Domain rule not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Problem deleting domain rule")
+		return
+	}
+	err = recordAudit(r.Context(), tx, u.ID, "domain_rule.delete", "domain_rule", rule.ID, rule)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to record audit log entry")
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Problem deleting domain rule")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
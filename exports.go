@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// exportLag holds back the newest posts from an export, so that a post
+// whose transaction commits just after the export starts is picked up by
+// the next one rather than missed.
+const exportLag = time.Minute
+
+// exportConfig describes the instance's scheduled export of new posts for
+// downstream analytics. Schedule is nil, and nothing is exported, unless
+// EXPORT_SCHEDULE is set. Each run writes the posts ingested since the
+// last run to the same destination as one new file.
+type exportConfig struct {
+	Schedule    *cronSchedule
+	Format      string
+	Destination exportDestination
+}
+
+// An exportDestination stores finished export files. String identifies
+// the destination, so that changing it starts its exports from scratch.
+type exportDestination interface {
+	put(ctx context.Context, name, contentType string, data []byte) (string, error)
+	String() string
+}
+
+// dirDestination writes export files to a local directory.
+type dirDestination struct {
+	Dir string
+}
+
+func (d dirDestination) String() string {
+	return d.Dir
+}
+
+// put writes the file under a temporary name first, so that whatever
+// picks files up from the directory never sees a partial one.
+func (d dirDestination) put(ctx context.Context, name, contentType string, data []byte) (string, error) {
+	err := os.MkdirAll(d.Dir, 0o755)
+	if err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(d.Dir, ".export-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(d.Dir, name)
+	return path, os.Rename(tmp.Name(), path)
+}
+
+// s3Destination uploads export files to a bucket, under Prefix.
+type s3Destination struct {
+	s3Bucket
+	Prefix string
+}
+
+func (d s3Destination) String() string {
+	return "s3://" + d.Bucket + "/" + d.Prefix
+}
+
+func (d s3Destination) put(ctx context.Context, name, contentType string, data []byte) (string, error) {
+	key := d.Prefix + name
+	return "s3://" + d.Bucket + "/" + key, d.s3Bucket.put(ctx, key, contentType, data)
+}
+
+// newExportConfigFromEnv reads EXPORT_SCHEDULE, a cron expression in the
+// instance's time zone; EXPORT_FORMAT, json (JSON lines, the default), csv
+// or parquet; and EXPORT_DESTINATION, a local directory or an
+// s3://bucket/prefix URL. S3 credentials come from the usual AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN and AWS_REGION, and
+// EXPORT_S3_ENDPOINT points at an S3-compatible store instead of AWS.
+func newExportConfigFromEnv() (exportConfig, error) {
+	c := exportConfig{Format: "json"}
+	spec := os.Getenv("EXPORT_SCHEDULE")
+	if spec == "" {
+		return c, nil
+	}
+	schedule, err := parseCron(spec)
+	if err != nil {
+		return c, fmt.Errorf("Invalid EXPORT_SCHEDULE: %v", err)
+	}
+	c.Schedule = &schedule
+	if v := os.Getenv("EXPORT_FORMAT"); v != "" {
+		c.Format = strings.ToLower(v)
+	}
+	if _, ok := exportExtensions[c.Format]; !ok {
+		return c, fmt.Errorf("Invalid EXPORT_FORMAT: must be json, csv or parquet")
+	}
+	dest := os.Getenv("EXPORT_DESTINATION")
+	if dest == "" {
+		return c, fmt.Errorf("EXPORT_DESTINATION is required with EXPORT_SCHEDULE")
+	}
+	bucketPath, isS3 := strings.CutPrefix(dest, "s3://")
+	if !isS3 {
+		c.Destination = dirDestination{Dir: dest}
+		return c, nil
+	}
+	bucket, prefix, _ := strings.Cut(bucketPath, "/")
+	if bucket == "" {
+		return c, fmt.Errorf("Invalid EXPORT_DESTINATION: no bucket")
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	d := s3Destination{
+		s3Bucket: s3Bucket{
+			Bucket:       bucket,
+			Region:       os.Getenv("AWS_REGION"),
+			Endpoint:     os.Getenv("EXPORT_S3_ENDPOINT"),
+			AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		},
+		Prefix: prefix,
+	}
+	if d.Region == "" {
+		d.Region = "us-east-1"
+	}
+	if d.AccessKey == "" || d.SecretKey == "" {
+		return c, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required for an S3 EXPORT_DESTINATION")
+	}
+	c.Destination = d
+	return c, nil
+}
+
+// exportWorker runs an export in every minute the schedule names.
+func exportWorker(ac apiConfig, c exportConfig) {
+	logInfo("export", "Starting export worker...")
+	var last time.Time
+	for t := range ac.Clock.Tick(time.Minute) {
+		minute := t.Truncate(time.Minute)
+		if minute.Equal(last) || !c.Schedule.matches(minute) {
+			continue
+		}
+		last = minute
+		if ac.Maintenance.active() {
+			continue
+		}
+		err := runExport(context.Background(), ac, c, t)
+		if err != nil {
+			logError("export", "Could not export posts to %s: %v", c.Destination, err)
+		}
+	}
+}
+
+// runExport writes the posts ingested since the destination's last export
+// as one file. Virtual feed copies are left out, since their originals are
+// exported. A run with no new posts writes nothing.
+func runExport(ctx context.Context, ac apiConfig, c exportConfig, now time.Time) error {
+	after := time.Time{}
+	latest, err := ac.DB.GetLatestPostExport(ctx, c.Destination.String())
+	if err == nil {
+		after = latest.ExportedUntil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	until := now.Add(-exportLag)
+	posts, err := ac.DB.ListPostsForExport(ctx, database.ListPostsForExportParams{
+		After: after,
+		Until: until,
+	})
+	if err != nil {
+		return err
+	}
+	if len(posts) == 0 {
+		logDebug("export", "No new posts to export")
+		return nil
+	}
+	data, contentType, err := encodeExport(c.Format, posts)
+	if err != nil {
+		return err
+	}
+	name := "posts-" + until.UTC().Format("20060102T150405Z") + "." + exportExtensions[c.Format]
+	location, err := c.Destination.put(ctx, name, contentType, data)
+	if err != nil {
+		return err
+	}
+	_, err = ac.DB.CreatePostExport(ctx, database.CreatePostExportParams{
+		ID:            uuid.New(),
+		CreatedAt:     now,
+		Destination:   c.Destination.String(),
+		Format:        c.Format,
+		ExportedAfter: after,
+		ExportedUntil: until,
+		PostCount:     int32(len(posts)),
+		Location:      location,
+	})
+	if err != nil {
+		return err
+	}
+	logInfo("export", "Exported %d posts to %s", len(posts), location)
+	return nil
+}
+
+var exportExtensions = map[string]string{
+	"json":    "jsonl",
+	"csv":     "csv",
+	"parquet": "parquet",
+}
+
+// An exported post has the same fields in every format:
+//
+//	id            UUID, as a string
+//	feed_id       UUID, as a string
+//	feed_url      string
+//	title         string
+//	url           string
+//	description   string, sanitized HTML; null in Parquet if the post has none
+//	guid          string
+//	published_at  timestamp, or null if the feed gave no date
+//	created_at    timestamp the post was ingested
+//	sensitive     boolean
+//
+// In Parquet, strings are UTF-8 byte arrays and timestamps are int64
+// microseconds since the epoch, adjusted to UTC.
+type exportedPost struct {
+	ID          uuid.UUID  `json:"id"`
+	FeedID      uuid.UUID  `json:"feed_id"`
+	FeedUrl     string     `json:"feed_url"`
+	Title       string     `json:"title"`
+	Url         string     `json:"url"`
+	Description string     `json:"description"`
+	Guid        string     `json:"guid"`
+	PublishedAt *time.Time `json:"published_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Sensitive   bool       `json:"sensitive"`
+}
+
+var exportCSVHeader = []string{"id", "feed_id", "feed_url", "title", "url", "description", "guid", "published_at", "created_at", "sensitive"}
+
+// encodeExport encodes posts as JSON lines, as CSV with a header row, or
+// as Parquet. Times in JSON and CSV are RFC 3339 in UTC; a post with no
+// publication date has a null published_at in JSON and an empty one in CSV.
+func encodeExport(format string, posts []database.ListPostsForExportRow) ([]byte, string, error) {
+	var buf bytes.Buffer
+	switch format {
+	case "json":
+		enc := json.NewEncoder(&buf)
+		for _, p := range posts {
+			e := exportedPost{
+				ID:          p.ID,
+				FeedID:      p.FeedID,
+				FeedUrl:     p.FeedUrl,
+				Title:       p.Title,
+				Url:         p.Url,
+				Description: p.Description.String,
+				Guid:        p.Guid,
+				CreatedAt:   p.CreatedAt.UTC(),
+				Sensitive:   p.Sensitive,
+			}
+			if p.PublishedAt.Valid {
+				published := p.PublishedAt.Time.UTC()
+				e.PublishedAt = &published
+			}
+			err := enc.Encode(e)
+			if err != nil {
+				return nil, "", err
+			}
+		}
+		return buf.Bytes(), "application/x-ndjson", nil
+	case "csv":
+		w := csv.NewWriter(&buf)
+		w.Write(exportCSVHeader)
+		for _, p := range posts {
+			published := ""
+			if p.PublishedAt.Valid {
+				published = p.PublishedAt.Time.UTC().Format(time.RFC3339)
+			}
+			w.Write([]string{
+				p.ID.String(),
+				p.FeedID.String(),
+				p.FeedUrl,
+				p.Title,
+				p.Url,
+				p.Description.String,
+				p.Guid,
+				published,
+				p.CreatedAt.UTC().Format(time.RFC3339),
+				strconv.FormatBool(p.Sensitive),
+			})
+		}
+		w.Flush()
+		return buf.Bytes(), "text/csv; charset=utf-8", w.Error()
+	case "parquet":
+		return encodeExportParquet(posts), "application/vnd.apache.parquet", nil
+	}
+	return nil, "", fmt.Errorf("unknown export format %q", format)
+}
+
+func encodeExportParquet(posts []database.ListPostsForExportRow) []byte {
+	columns := []*parquetColumn{
+		newParquetColumn("id", parquetByteArray, false),
+		newParquetColumn("feed_id", parquetByteArray, false),
+		newParquetColumn("feed_url", parquetByteArray, false),
+		newParquetColumn("title", parquetByteArray, false),
+		newParquetColumn("url", parquetByteArray, false),
+		newParquetColumn("description", parquetByteArray, true),
+		newParquetColumn("guid", parquetByteArray, false),
+		newParquetColumn("published_at", parquetInt64, true),
+		newParquetColumn("created_at", parquetInt64, false),
+		newParquetColumn("sensitive", parquetBoolean, false),
+	}
+	columns[7].timestamp = true
+	columns[8].timestamp = true
+	for _, p := range posts {
+		columns[0].addString(p.ID.String(), true)
+		columns[1].addString(p.FeedID.String(), true)
+		columns[2].addString(p.FeedUrl, true)
+		columns[3].addString(p.Title, true)
+		columns[4].addString(p.Url, true)
+		columns[5].addString(p.Description.String, p.Description.Valid)
+		columns[6].addString(p.Guid, true)
+		columns[7].addInt64(p.PublishedAt.Time.UnixMicro(), p.PublishedAt.Valid)
+		columns[8].addInt64(p.CreatedAt.UnixMicro(), true)
+		columns[9].addBool(p.Sensitive)
+	}
+	return encodeParquet(columns, len(posts))
+}
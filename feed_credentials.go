@@ -0,0 +1,144 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// feedCredentials are what a private feed needs to be fetched: a username
+// and password for HTTP Basic auth, or a bearer token.
+type feedCredentials struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+func (c feedCredentials) empty() bool {
+	return c == feedCredentials{}
+}
+
+func (c feedCredentials) validate() error {
+	if c.Token != "" && (c.Username != "" || c.Password != "") {
+		return errors.New("Credentials take a username and password or a token, not both")
+	}
+	if c.Token == "" && c.Username == "" {
+		return errors.New("Credentials need a username or a token")
+	}
+	if strings.Contains(c.Username, ":") {
+		return errors.New("Username cannot contain a colon")
+	}
+	for _, v := range []string{c.Username, c.Password, c.Token} {
+		if len(v) > maxFetchHeaderValueSize || strings.ContainsAny(v, "\r\n\x00") {
+			return errors.New("Invalid credentials")
+		}
+	}
+	return nil
+}
+
+// isPrivateFeed reports whether f is fetched with its owner's credentials.
+// Such a feed, and its posts, are the owner's alone: it is not listed,
+// matched by URL, followed, republished or served to anyone else.
+func isPrivateFeed(f database.Feed) bool {
+	return f.Credentials != nil
+}
+
+// authorization returns the Authorization header value for c.
+func (c feedCredentials) authorization() string {
+	if c.Token != "" {
+		return "Bearer " + c.Token
+	}
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(c.Username+":"+c.Password))
+}
+
+// credentialBox encrypts feed credentials at rest with AES-256-GCM. Each
+// sealed value is bound to its feed's ID, so it cannot be copied onto
+// another feed.
+type credentialBox struct {
+	aead cipher.AEAD
+}
+
+// newCredentialBoxFromEnv reads FEED_CREDENTIALS_KEY, 32 random bytes in
+// base64. It returns nil when the key is not set, and feeds cannot then be
+// given credentials.
+func newCredentialBoxFromEnv() (*credentialBox, error) {
+	encoded := os.Getenv("FEED_CREDENTIALS_KEY")
+	if encoded == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("Invalid FEED_CREDENTIALS_KEY: must be 32 bytes in base64")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &credentialBox{aead: aead}, nil
+}
+
+func (b *credentialBox) seal(feedID uuid.UUID, c feedCredentials) ([]byte, error) {
+	plaintext, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, b.aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+	return b.aead.Seal(nonce, nonce, plaintext, feedID[:]), nil
+}
+
+func (b *credentialBox) open(feedID uuid.UUID, sealed []byte) (feedCredentials, error) {
+	c := feedCredentials{}
+	if len(sealed) < b.aead.NonceSize() {
+		return c, errors.New("sealed credentials are too short")
+	}
+	nonce, ciphertext := sealed[:b.aead.NonceSize()], sealed[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, feedID[:])
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(plaintext, &c)
+	return c, err
+}
+
+// fetchHeaders returns the headers to send when fetching f: its fetch
+// headers, plus Authorization if it has credentials. Credentials that
+// cannot be decrypted are left off, so the fetch fails as unauthorized
+// rather than not happening at all.
+func (ac apiConfig) fetchHeaders(f database.Feed) http.Header {
+	h := feedFetchHeaders(f)
+	if f.Credentials == nil {
+		return h
+	}
+	if ac.Credentials == nil {
+		logWarn("fetch", "Cannot use credentials on %s without FEED_CREDENTIALS_KEY", f.Name)
+		return h
+	}
+	c, err := ac.Credentials.open(f.ID, f.Credentials)
+	if err != nil {
+		logWarn("fetch", "Could not decrypt credentials on %s: %v", f.Name, err)
+		return h
+	}
+	if h == nil {
+		h = http.Header{}
+	}
+	h.Set("Authorization", c.authorization())
+	return h
+}
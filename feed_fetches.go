@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const (
+	// feedFetchesKept is how many fetch attempts each feed's history holds;
+	// older ones are pruned as new ones are recorded.
+	feedFetchesKept         = 100
+	defaultFeedFetchesLimit = 20
+)
+
+// recordFeedFetch logs one attempt to fetch a feed, successful or not, so
+// that users can see why a feed is not updating, and keeps its status on
+// the feed for the feeds list.
+func recordFeedFetch(ctx context.Context, ac apiConfig, feedID uuid.UUID, fd feedData, duration time.Duration, fetchErr error) {
+	params := database.CreateFeedFetchParams{
+		ID:         uuid.New(),
+		FeedID:     feedID,
+		FetchedAt:  ac.Clock.Now(),
+		DurationMs: int32(duration.Milliseconds()),
+		ItemCount:  int32(len(fd.Channel.Item)),
+	}
+	if fd.StatusCode != 0 {
+		params.StatusCode = sql.NullInt32{Int32: int32(fd.StatusCode), Valid: true}
+	}
+	if fetchErr != nil {
+		params.Error = fetchErr.Error()
+	}
+	err := ac.DB.SetFeedLastFetchStatus(ctx, database.SetFeedLastFetchStatusParams{
+		ID:              feedID,
+		LastFetchStatus: params.StatusCode,
+	})
+	if err != nil {
+		logError("fetch", "Could not set fetch status of %s: %v", feedID, err)
+	}
+	err = ac.DB.CreateFeedFetch(ctx, params)
+	if err != nil {
+		logError("fetch", "Could not record fetch of %s: %v", feedID, err)
+		return
+	}
+	err = ac.DB.PruneFeedFetches(ctx, database.PruneFeedFetchesParams{
+		FeedID: feedID,
+		Keep:   feedFetchesKept,
+	})
+	if err != nil {
+		logError("fetch", "Could not prune fetches of %s: %v", feedID, err)
+	}
+}
+
+type feedFetchResponse struct {
+	FetchedAt  time.Time `json:"fetched_at"`
+	StatusCode *int32    `json:"status_code"`
+	DurationMs int32     `json:"duration_ms"`
+	ItemCount  int32     `json:"item_count"`
+	Error      string    `json:"error"`
+}
+
+// handleFeedFetchesGet lists a feed's most recent fetch attempts, newest
+// first. A null status code means the server never answered.
+func handleFeedFetchesGet(w http.ResponseWriter, r *http.Request, ac apiConfig) {
+	limit := defaultFeedFetchesLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 || parsed > feedFetchesKept {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = parsed
+	}
+	feed, ok := getVisibleFeed(w, r, ac)
+	if !ok {
+		return
+	}
+	fetches, err := ac.DB.ListFeedFetches(r.Context(), database.ListFeedFetchesParams{
+		FeedID: feed.ID,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve fetches")
+		return
+	}
+	resp := make([]feedFetchResponse, 0, len(fetches))
+	for _, f := range fetches {
+		item := feedFetchResponse{
+			FetchedAt:  f.FetchedAt,
+			DurationMs: f.DurationMs,
+			ItemCount:  f.ItemCount,
+			Error:      f.Error,
+		}
+		if f.StatusCode.Valid {
+			code := f.StatusCode.Int32
+			item.StatusCode = &code
+		}
+		resp = append(resp, item)
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
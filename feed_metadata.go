@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// fillFeedMetadata fills in a new feed's details from what the feed says
+// about itself. The feed's own title is preferred to the name the user
+// gave, since every follower sees the same name; that name, then the URL,
+// is used only when the feed has no title.
+func fillFeedMetadata(params *database.CreateFeedParams, fd feedData) {
+	if title := strings.TrimSpace(fd.Channel.Title); title != "" {
+		params.Name = title
+	}
+	if params.Name == "" {
+		params.Name = params.Url
+	}
+	params.Description = strings.TrimSpace(sanitizeHTML(fd.Channel.Description, sanitizeOptions{}))
+	params.SiteUrl = feedSiteURL(params.Url, fd.Channel.Link.Text)
+	params.Language, params.Country = parseLanguageTag(fd.Channel.Language)
+}
+
+// feedSiteURL resolves a feed's link to its site against the feed's own
+// URL, returning "" unless the result is an http(s) URL.
+func feedSiteURL(feedURL, link string) string {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return ""
+	}
+	base, err := url.Parse(feedURL)
+	if err != nil {
+		return ""
+	}
+	u, err := base.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return u.String()
+}
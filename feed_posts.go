@@ -0,0 +1,84 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const (
+	defaultFeedPostsLimit = 20
+	maxFeedPostsLimit     = 100
+)
+
+// handleFeedPostsGet lists a feed's newest posts without authentication,
+// in JSON or, by Accept header, as a re-served RSS or Atom feed. This is
+// also how virtual feeds are exported. Sensitive posts are left out, since
+// there is no user whose settings would allow them, and inbox feeds are
+// private to their owner.
+func handleFeedPostsGet(w http.ResponseWriter, r *http.Request, ac apiConfig) {
+	feedID, err := parseFeedID(r.Context(), ac.DB, chi.URLParam(r, "feedID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	limit := defaultFeedPostsLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 || parsed > maxFeedPostsLimit {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = parsed
+	}
+	feed, err := ac.DB.GetFeed(r.Context(), feedID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && (feed.Kind == "inbox" || isPrivateFeed(feed))) {
+		respondWithError(w, http.StatusNotFound, "Feed not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve feed")
+		return
+	}
+	posts := []database.Post{}
+	if !feed.Sensitive {
+		posts, err = ac.DB.GetPostsByFeed(r.Context(), database.GetPostsByFeedParams{
+			FeedID: feed.ID,
+			Limit:  int32(limit),
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to retrieve posts")
+			return
+		}
+	}
+	responses := make([]postResponse, 0, len(posts))
+	for _, post := range posts {
+		if post.Sensitive {
+			continue
+		}
+		responses = append(responses, newPostResponse(post))
+	}
+
+	w.Header().Set("Vary", "Accept")
+	if format := negotiateFormat(r); format != formatJSON {
+		description := feed.Description
+		if description == "" {
+			description = "Posts from " + feed.Url
+		}
+		f := syndicationFeed{Title: feed.Name, Link: requestURL(r), Description: description, Updated: time.Now()}
+		if len(responses) > 0 {
+			f.Updated = responses[0].CreatedAt
+		}
+		for _, post := range responses {
+			f.Items = append(f.Items, postSyndicationItem(post, false))
+		}
+		respondWithFeed(w, format, f)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, responses)
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+var feedReportReasons = []string{"spam", "broken", "other"}
+
+func handleFeedReportPost(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	feedID, err := parseFeedID(r.Context(), ac.DB, chi.URLParam(r, "feedID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	type feedReportRequest struct {
+		Reason  string `json:"reason"`
+		Comment string `json:"comment"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := feedReportRequest{}
+	err = decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	if !slices.Contains(feedReportReasons, req.Reason) {
+		respondWithError(w, http.StatusBadRequest, "Reason must be one of: spam, broken, other")
+		return
+	}
+
+	feed, err := ac.DB.GetFeed(r.Context(), feedID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Feed not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve feed")
+		return
+	}
+	report, err := ac.DB.CreateFeedReport(r.Context(), database.CreateFeedReportParams{
+		ID:         uuid.New(),
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+		FeedID:     feed.ID,
+		ReporterID: u.ID,
+		Reason:     req.Reason,
+		Comment:    strings.TrimSpace(req.Comment),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save report")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, newFeedReportResponse(report))
+}
+
+func handleAdminReportsGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = "open"
+	}
+	reports, err := ac.DB.ListFeedReportsByStatus(r.Context(), status)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve reports")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, newFeedReportResponses(reports))
+}
+
+// handleAdminReportResolve closes an open report with one of the moderation
+// actions. Disabling a feed stops the scraper from fetching it; banning the
+// submitter suspends the user who originally added the feed.
+func handleAdminReportResolve(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	reportID, err := uuid.Parse(chi.URLParam(r, "reportID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	type reportResolveRequest struct {
+		Action string `json:"action"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := reportResolveRequest{}
+	err = decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	statuses := map[string]string{
+		"dismiss":       "dismissed",
+		"disable_feed":  "feed_disabled",
+		"ban_submitter": "submitter_banned",
+	}
+	status, ok := statuses[req.Action]
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Action must be one of: dismiss, disable_feed, ban_submitter")
+		return
+	}
+
+	tx, err := ac.DB.Begin(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to resolve report")
+		return
+	}
+	defer tx.Rollback()
+
+	report, err := tx.GetFeedReport(r.Context(), reportID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Report not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve report")
+		return
+	}
+	if report.Status != "open" {
+		respondWithError(w, http.StatusConflict, "Report has already been resolved")
+		return
+	}
+	now := time.Now()
+	switch req.Action {
+	case "disable_feed":
+		err = tx.SetFeedDisabled(r.Context(), database.SetFeedDisabledParams{
+			ID:         report.FeedID,
+			DisabledAt: sql.NullTime{Time: now, Valid: true},
+			UpdatedAt:  now,
+		})
+		if err == nil {
+			err = recordAudit(r.Context(), tx, u.ID, "feed.disable", "feed", report.FeedID, map[string]uuid.UUID{"report_id": report.ID})
+		}
+	case "ban_submitter":
+		var feed database.Feed
+		feed, err = tx.GetFeed(r.Context(), report.FeedID)
+		if err != nil {
+			break
+		}
+		if feed.UserID == u.ID {
+			respondWithError(w, http.StatusBadRequest, "You cannot ban yourself")
+			return
+		}
+		err = tx.SetUserBanned(r.Context(), database.SetUserBannedParams{
+			ID:        feed.UserID,
+			BannedAt:  sql.NullTime{Time: now, Valid: true},
+			UpdatedAt: now,
+		})
+		if err == nil {
+			err = recordAudit(r.Context(), tx, u.ID, "user.ban", "user", feed.UserID, map[string]uuid.UUID{"report_id": report.ID})
+		}
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to apply moderation action")
+		return
+	}
+	resolved, err := tx.ResolveFeedReport(r.Context(), database.ResolveFeedReportParams{
+		ID:         report.ID,
+		Status:     status,
+		ResolvedBy: uuid.NullUUID{UUID: u.ID, Valid: true},
+		ResolvedAt: sql.NullTime{Time: now, Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to resolve report")
+		return
+	}
+	err = recordAudit(r.Context(), tx, u.ID, "report.resolve", "feed_report", report.ID, map[string]string{"action": req.Action})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to record audit log entry")
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to resolve report")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, newFeedReportResponse(resolved))
+}
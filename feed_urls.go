@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// normalizeFeedURL puts a feed URL in the form it is stored in, so that
+// spellings of the same URL are recognised as one feed: the scheme and
+// host lowercased, a default port dropped, an empty path made "/", and any
+// fragment, which is never sent to the server, removed. URLs that do not
+// parse are returned as they are.
+func normalizeFeedURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		u.Host = u.Hostname()
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String()
+}
+
+// getFeedByURL finds the feed at raw, or that used to be, under its
+// normalized form or, for feeds stored before URLs were normalized, as
+// given.
+func getFeedByURL(ctx context.Context, q database.Querier, raw string) (database.Feed, error) {
+	normalized := normalizeFeedURL(raw)
+	feed, err := q.GetFeedByUrl(ctx, normalized)
+	if errors.Is(err, sql.ErrNoRows) && normalized != raw {
+		feed, err = q.GetFeedByUrl(ctx, raw)
+	}
+	return feed, err
+}
+
+// followFeed makes userID a follower of feedID, returning their existing
+// follow if they already are one.
+func followFeed(ctx context.Context, q database.Querier, userID, feedID uuid.UUID) (database.FeedFollow, error) {
+	follows, err := q.GetUserFeedFollows(ctx, userID)
+	if err != nil {
+		return database.FeedFollow{}, err
+	}
+	for _, follow := range follows {
+		if follow.FeedID == feedID {
+			return follow, nil
+		}
+	}
+	return q.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    userID,
+		FeedID:    feedID,
+	})
+}
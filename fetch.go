@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// runFetch implements "rssagg fetch --feed <url-or-id>". It fetches and
+// ingests one feed exactly as the worker would, then prints a JSON summary.
+// It returns the fetch's error, if any, after printing the summary, so a
+// cron job sees a failed fetch as a failed command.
+func runFetch(ac apiConfig, args []string) error {
+	fs := flag.NewFlagSet("fetch", flag.ContinueOnError)
+	feedArg := fs.String("feed", "", "URL or ID of the feed to fetch")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if *feedArg == "" {
+		return fmt.Errorf("usage: rssagg fetch --feed <url-or-id>")
+	}
+	ctx := context.Background()
+	feed, err := lookupFeed(ctx, ac.DB, *feedArg)
+	if err != nil {
+		return err
+	}
+	summary, err := fetchNow(ctx, ac, feed)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(summary)
+	if err != nil {
+		return err
+	}
+	if summary.Error != "" {
+		return errors.New(summary.Error)
+	}
+	return nil
+}
+
+// fetchSummary reports how fetching one feed on demand went.
+type fetchSummary struct {
+	FeedID      uuid.UUID    `json:"feed_id"`
+	Name        string       `json:"name"`
+	Url         string       `json:"url"`
+	MovedTo     string       `json:"moved_to,omitempty"`
+	NotModified bool         `json:"not_modified"`
+	Items       int          `json:"items"`
+	Posts       ingestResult `json:"posts"`
+	DurationMs  int64        `json:"duration_ms"`
+	Error       string       `json:"error,omitempty"`
+}
+
+// fetchNow fetches and ingests feed straight away, whether or not it is
+// due, exactly as the worker would. A failed fetch is reported in the
+// summary; the error is for a feed that could not be fetched at all.
+func fetchNow(ctx context.Context, ac apiConfig, feed database.Feed) (fetchSummary, error) {
+	rules, err := ac.DB.ListDomainRules(ctx)
+	if err != nil {
+		return fetchSummary{}, err
+	}
+	if err := checkFeedDomain(rules, feed.Url); err != nil {
+		return fetchSummary{}, err
+	}
+	start := time.Now()
+	err = ac.DB.MarkFeedFetched(ctx, database.MarkFeedFetchedParams{
+		LastFetchedAt: sql.NullTime{Time: ac.Clock.Now(), Valid: true},
+		ID:            feed.ID,
+	})
+	if err != nil {
+		return fetchSummary{}, err
+	}
+	result := fetchDueFeed(ctx, ac, rules, feed)
+	summary := fetchSummary{
+		FeedID:      feed.ID,
+		Name:        feed.Name,
+		Url:         feed.Url,
+		MovedTo:     result.Data.MovedTo,
+		NotModified: result.Data.NotModified,
+		Items:       len(result.Data.Channel.Item),
+	}
+	if result.Err != nil {
+		summary.Error = result.Err.Error()
+	} else {
+		summary.Posts = storeFeedData(ctx, ac, result.Data)
+	}
+	summary.DurationMs = time.Since(start).Milliseconds()
+	return summary, nil
+}
+
+// refreshCooldown is how soon after its last fetch a feed can be refreshed
+// again, so that refreshing cannot be used to hammer its publisher.
+const refreshCooldown = time.Minute
+
+// handleFeedRefreshPost fetches a feed the user follows straight away, say
+// to get a newly added blog's backlog without waiting for the worker, and
+// responds with how the fetch went.
+func handleFeedRefreshPost(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	feedID, err := parseFeedID(r.Context(), ac.DB, chi.URLParam(r, "feedID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	feed, err := ac.DB.GetFeed(r.Context(), feedID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Feed not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve feed")
+		return
+	}
+	follows, err := ac.DB.GetUserFeedFollows(r.Context(), u.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve feed follows")
+		return
+	}
+	if !slices.ContainsFunc(follows, func(f database.FeedFollow) bool { return f.FeedID == feed.ID }) {
+		respondWithError(w, http.StatusForbidden, "Only the feed's followers can refresh it")
+		return
+	}
+	if feed.Kind != "remote" {
+		respondWithError(w, http.StatusBadRequest, "Only remote feeds can be refreshed")
+		return
+	}
+	if feed.DisabledAt.Valid {
+		respondWithError(w, http.StatusConflict, "Feed is disabled")
+		return
+	}
+	if wait := feed.LastFetchedAt.Time.Add(refreshCooldown).Sub(ac.Clock.Now()); feed.LastFetchedAt.Valid && wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		respondWithError(w, http.StatusTooManyRequests, "Feed was fetched less than a minute ago")
+		return
+	}
+	summary, err := fetchNow(r.Context(), ac, feed)
+	if errors.Is(err, errDomainNotAllowed) {
+		respondWithError(w, http.StatusForbidden, "Feed domain is not allowed on this instance")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to refresh feed")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, summary)
+}
+
+// lookupFeed finds a feed by its URL, UUID or public ID.
+func lookupFeed(ctx context.Context, q database.Querier, s string) (database.Feed, error) {
+	if strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") {
+		feed, err := q.GetFeedByUrl(ctx, s)
+		if errors.Is(err, sql.ErrNoRows) {
+			return feed, fmt.Errorf("no feed with URL %s", s)
+		}
+		return feed, err
+	}
+	feedID, err := parseFeedID(ctx, q, s)
+	if err != nil {
+		return database.Feed{}, err
+	}
+	feed, err := q.GetFeed(ctx, feedID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return feed, fmt.Errorf("no feed with ID %s", s)
+	}
+	return feed, err
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"net/http"
+)
+
+// fetchFunc fetches and parses a feed, sending headers along with the
+// request and giving up when ctx is done. The worker's is httpFetchFeed,
+// swapped out in demo mode and wrapped by FETCH_CHAOS.
+type fetchFunc func(ctx context.Context, url string, cache cacheValidators, headers http.Header) (feedData, error)
+
+// cacheValidators are the HTTP validators of the last body fetched for a
+// feed, stored so the next fetch can be conditional.
+type cacheValidators struct {
+	ETag         string
+	LastModified string
+}
+
+func (c cacheValidators) apply(req *http.Request) {
+	if c.ETag != "" {
+		req.Header.Set("If-None-Match", c.ETag)
+	}
+	if c.LastModified != "" {
+		req.Header.Set("If-Modified-Since", c.LastModified)
+	}
+}
+
+func cacheValidatorsFrom(res *http.Response) cacheValidators {
+	return cacheValidators{
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+	}
+}
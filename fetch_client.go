@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	defaultFetchTimeout        = 30 * time.Second
+	defaultFetchConnectTimeout = 10 * time.Second
+	defaultUserAgent           = "rss-aggregator/1.0"
+	defaultNotifyTimeout       = 10 * time.Second
+)
+
+// newFetchClientFromEnv builds the client feeds are fetched with, so that a
+// server that never answers counts as a failure rather than holding a
+// worker slot forever. FETCH_CONNECT_TIMEOUT bounds connecting, including
+// the TLS handshake, and FETCH_TIMEOUT the whole request, body included.
+//
+// Fetches go through the proxy named by the usual HTTP_PROXY, HTTPS_PROXY
+// and NO_PROXY, or through FETCH_PROXY, which takes precedence for feeds
+// only: an http, https or socks5 URL, with credentials if the proxy needs
+// them.
+//
+// Requests identify themselves with FETCH_USER_AGENT. Without it, the
+// User-Agent names this program, with FETCH_CONTACT_URL appended so that
+// publishers who block unknown clients can find who to ask.
+//
+// Connections to private addresses are refused, unless allowPrivate is
+// set, so that users cannot have the server fetch from its own network.
+// Proxies are exempt, but a request sent through one has its target
+// checked before each hop instead, since the proxy does the connecting.
+func newFetchClientFromEnv(allowPrivate bool) (*http.Client, error) {
+	timeout := defaultFetchTimeout
+	if v := os.Getenv("FETCH_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("Invalid FETCH_TIMEOUT")
+		}
+		timeout = d
+	}
+	connectTimeout := defaultFetchConnectTimeout
+	if v := os.Getenv("FETCH_CONNECT_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("Invalid FETCH_CONNECT_TIMEOUT")
+		}
+		connectTimeout = d
+	}
+	dialer := &net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}
+	proxies := envProxies()
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSHandshakeTimeout = connectTimeout
+	transport.ResponseHeaderTimeout = timeout
+	if v := os.Getenv("FETCH_PROXY"); v != "" {
+		proxyURL, err := url.Parse(v)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("Invalid FETCH_PROXY")
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("Invalid FETCH_PROXY: scheme must be http, https, socks5 or socks5h")
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+		proxies = []*url.URL{proxyURL}
+	}
+	var next http.RoundTripper = transport
+	transport.DialContext = dialer.DialContext
+	if !allowPrivate {
+		transport.DialContext = newGuardedDialer(dialer, proxies).DialContext
+		next = proxiedAddressCheck{next: transport, proxy: transport.Proxy}
+	}
+	userAgent := os.Getenv("FETCH_USER_AGENT")
+	if userAgent == "" {
+		userAgent = defaultUserAgent
+		if contact := os.Getenv("FETCH_CONTACT_URL"); contact != "" {
+			userAgent += " (+" + contact + ")"
+		}
+	}
+	if strings.ContainsAny(userAgent, "\r\n\x00") {
+		return nil, fmt.Errorf("Invalid FETCH_USER_AGENT")
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: userAgentTransport{next: next, userAgent: userAgent},
+	}, nil
+}
+
+// newNotifyClient builds the client notifications are sent with. Webhook,
+// homeserver and push endpoint URLs are supplied by users, so, as with feeds, connections
+// to private addresses are refused unless allowPrivate is set. Redirects
+// are not followed: a notification is delivered to the URL that was given
+// or not at all.
+func newNotifyClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: defaultFetchConnectTimeout, KeepAlive: 30 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSHandshakeTimeout = defaultFetchConnectTimeout
+	transport.ResponseHeaderTimeout = defaultNotifyTimeout
+	var next http.RoundTripper = transport
+	transport.DialContext = dialer.DialContext
+	if !allowPrivate {
+		transport.DialContext = newGuardedDialer(dialer, envProxies()).DialContext
+		next = proxiedAddressCheck{next: transport, proxy: transport.Proxy}
+	}
+	return &http.Client{
+		Timeout:   defaultNotifyTimeout,
+		Transport: next,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// userAgentTransport sets the User-Agent on requests that don't have one,
+// so a feed's own fetch headers can still override it.
+type userAgentTransport struct {
+	next      http.RoundTripper
+	userAgent string
+}
+
+func (t userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// httpFetchFeed returns the fetchFunc that fetches over HTTP with client.
+func httpFetchFeed(client *http.Client) fetchFunc {
+	return func(ctx context.Context, url string, cache cacheValidators, headers http.Header) (feedData, error) {
+		return getFeedConditional(ctx, client, url, cache, headers)
+	}
+}
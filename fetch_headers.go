@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const (
+	maxFetchHeaders         = 20
+	maxFetchHeaderValueSize = 1024
+)
+
+// reservedFetchHeaders are set by the fetcher or the HTTP client itself and
+// cannot be overridden per feed.
+var reservedFetchHeaders = map[string]bool{
+	"Connection":        true,
+	"Content-Length":    true,
+	"Host":              true,
+	"If-Modified-Since": true,
+	"If-None-Match":     true,
+	"Te":                true,
+	"Trailer":           true,
+	"Transfer-Encoding": true,
+	"Upgrade":           true,
+}
+
+// credentialFetchHeaders carry credentials, which belong in a feed's
+// encrypted credentials: those keep the feed private to its owner, while
+// fetch headers are stored in the clear on a feed anyone may follow.
+var credentialFetchHeaders = map[string]bool{
+	"Authorization":       true,
+	"Cookie":              true,
+	"Proxy-Authorization": true,
+}
+
+// validateFetchHeaders checks the extra request headers a feed's owner
+// wants sent on every fetch and returns them with canonical names.
+func validateFetchHeaders(headers map[string]string) (map[string]string, error) {
+	if len(headers) > maxFetchHeaders {
+		return nil, fmt.Errorf("A feed can have at most %d fetch headers", maxFetchHeaders)
+	}
+	valid := make(map[string]string, len(headers))
+	for name, value := range headers {
+		if !isHeaderToken(name) {
+			return nil, fmt.Errorf("Invalid header name %q", name)
+		}
+		name = http.CanonicalHeaderKey(name)
+		if reservedFetchHeaders[name] {
+			return nil, fmt.Errorf("Header %s cannot be set", name)
+		}
+		if credentialFetchHeaders[name] {
+			return nil, fmt.Errorf("Header %s cannot be set; use the feed's credentials instead", name)
+		}
+		if len(value) > maxFetchHeaderValueSize || strings.ContainsAny(value, "\r\n\x00") {
+			return nil, fmt.Errorf("Invalid value for header %s", name)
+		}
+		valid[name] = value
+	}
+	return valid, nil
+}
+
+func isHeaderToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", c):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// feedFetchHeaders returns the extra headers stored for f. Like any header
+// other than Authorization and Cookie, they follow redirects to other hosts.
+// Credential headers saved before they were refused are left out.
+func feedFetchHeaders(f database.Feed) http.Header {
+	headers := map[string]string{}
+	if err := json.Unmarshal(f.FetchHeaders, &headers); err != nil {
+		logWarn("fetch", "Ignoring invalid fetch headers on %s: %v", f.Name, err)
+		return nil
+	}
+	h := http.Header{}
+	for name, value := range headers {
+		if credentialFetchHeaders[http.CanonicalHeaderKey(name)] {
+			continue
+		}
+		h.Set(name, value)
+	}
+	return h
+}
+
+// fetchHeaderNames lists a feed's fetch headers without their values, which
+// may be credentials.
+func fetchHeaderNames(raw json.RawMessage) []string {
+	headers := map[string]string{}
+	json.Unmarshal(raw, &headers)
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+func TestValidateFetchHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		wantErr string
+	}{
+		{"custom", map[string]string{"x-api-version": "2"}, ""},
+		{"reserved", map[string]string{"host": "example.com"}, "cannot be set"},
+		{"authorization", map[string]string{"authorization": "Bearer secret"}, "credentials"},
+		{"cookie", map[string]string{"Cookie": "session=secret"}, "credentials"},
+		{"proxy authorization", map[string]string{"Proxy-Authorization": "Basic secret"}, "credentials"},
+		{"bad value", map[string]string{"X-Test": "a\r\nb"}, "Invalid value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validateFetchHeaders(tt.headers)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFeedFetchHeadersDropsStoredCredentials(t *testing.T) {
+	raw, _ := json.Marshal(map[string]string{"Authorization": "Bearer secret", "cookie": "a=b", "X-Api-Version": "2"})
+	h := feedFetchHeaders(database.Feed{FetchHeaders: raw})
+	if h.Get("Authorization") != "" || h.Get("Cookie") != "" {
+		t.Errorf("credential headers sent: %v", h)
+	}
+	if h.Get("X-Api-Version") != "2" {
+		t.Errorf("custom header dropped: %v", h)
+	}
+}
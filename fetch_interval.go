@@ -0,0 +1,193 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const (
+	minFetchInterval = time.Minute
+	maxFetchInterval = 24 * time.Hour
+)
+
+// handleFeedsPatch lets a feed's owner pin how often it is polled, choose
+// its scheduler, and set extra headers or credentials to send when fetching
+// it. Once others follow the feed, only an admin can change its interval,
+// schedule or headers, since they apply to every follower.
+// fetch_interval_seconds of 0 goes back to the default, where the interval
+// follows the highest priority any follower gave the feed. schedule is one
+// of fixed, adaptive, push or manual; "" goes back to the instance's
+// FETCH_SCHEDULE. fetch_headers replaces the whole set; {} clears it, as it
+// does credentials.
+func handleFeedsPatch(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	feedID, err := parseFeedID(r.Context(), ac.DB, chi.URLParam(r, "feedID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	type feedsPatchRequest struct {
+		FetchIntervalSeconds *int32             `json:"fetch_interval_seconds"`
+		FetchHeaders         *map[string]string `json:"fetch_headers"`
+		Schedule             *string            `json:"schedule"`
+		Credentials          *feedCredentials   `json:"credentials"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := feedsPatchRequest{}
+	err = decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	if req.FetchIntervalSeconds == nil && req.FetchHeaders == nil && req.Schedule == nil && req.Credentials == nil {
+		respondWithError(w, http.StatusBadRequest, "Nothing to update")
+		return
+	}
+	interval := sql.NullInt32{}
+	if req.FetchIntervalSeconds != nil && *req.FetchIntervalSeconds != 0 {
+		d := time.Duration(*req.FetchIntervalSeconds) * time.Second
+		if d < minFetchInterval || d > maxFetchInterval {
+			respondWithError(w, http.StatusBadRequest, "Fetch interval must be between 60 and 86400 seconds")
+			return
+		}
+		interval = sql.NullInt32{Int32: *req.FetchIntervalSeconds, Valid: true}
+	}
+	if req.Schedule != nil && *req.Schedule != "" && !validSchedule(*req.Schedule) {
+		respondWithError(w, http.StatusBadRequest, "Schedule must be fixed, adaptive, push or manual")
+		return
+	}
+	if c := req.Credentials; c != nil && !c.empty() {
+		if ac.Credentials == nil {
+			respondWithError(w, http.StatusBadRequest, "Feed credentials are not enabled on this instance")
+			return
+		}
+		if err := c.validate(); err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	var headers json.RawMessage
+	if req.FetchHeaders != nil {
+		valid, err := validateFetchHeaders(*req.FetchHeaders)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		headers, err = json.Marshal(valid)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to update feed")
+			return
+		}
+	}
+
+	feed, err := ac.DB.GetFeed(r.Context(), feedID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Feed not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve feed")
+		return
+	}
+	// Admins may change how any feed is fetched, but only its owner can
+	// give it credentials.
+	if feed.UserID != u.ID && (!u.IsAdmin || req.Credentials != nil) {
+		respondWithError(w, http.StatusForbidden, "Only the feed's owner can change it")
+		return
+	}
+	others, err := ac.DB.CountOtherFeedFollowers(r.Context(), database.CountOtherFeedFollowersParams{
+		FeedID: feed.ID,
+		UserID: u.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve feed")
+		return
+	}
+	// Credentials make a feed private to its owner, which would cut off
+	// everyone else who follows it.
+	if c := req.Credentials; c != nil && !c.empty() && !isPrivateFeed(feed) && others > 0 {
+		respondWithError(w, http.StatusConflict, "Feed has other followers; add it again with credentials instead")
+		return
+	}
+	// Whoever added a feed first does not get to decide how it is fetched
+	// for everyone who followed it since.
+	if others > 0 && !u.IsAdmin && (req.FetchIntervalSeconds != nil || req.FetchHeaders != nil || req.Schedule != nil) {
+		respondWithError(w, http.StatusForbidden, "Feed has other followers; only an admin can change how it is fetched")
+		return
+	}
+
+	tx, err := ac.DB.Begin(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to update feed")
+		return
+	}
+	defer tx.Rollback()
+	if req.FetchIntervalSeconds != nil {
+		feed, err = tx.SetFeedFetchInterval(r.Context(), database.SetFeedFetchIntervalParams{
+			ID:                   feed.ID,
+			FetchIntervalSeconds: interval,
+			UpdatedAt:            time.Now(),
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to update feed")
+			return
+		}
+	}
+	if headers != nil {
+		feed, err = tx.SetFeedFetchHeaders(r.Context(), database.SetFeedFetchHeadersParams{
+			ID:           feed.ID,
+			FetchHeaders: headers,
+			UpdatedAt:    time.Now(),
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to update feed")
+			return
+		}
+	}
+	if req.Schedule != nil {
+		feed, err = tx.SetFeedSchedule(r.Context(), database.SetFeedScheduleParams{
+			ID:        feed.ID,
+			Schedule:  *req.Schedule,
+			UpdatedAt: time.Now(),
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to update feed")
+			return
+		}
+	}
+	if c := req.Credentials; c != nil {
+		var sealed []byte
+		if !c.empty() {
+			sealed, err = ac.Credentials.seal(feed.ID, *c)
+			if err != nil {
+				respondWithError(w, http.StatusInternalServerError, "Unable to update feed")
+				return
+			}
+		}
+		feed, err = tx.SetFeedCredentials(r.Context(), database.SetFeedCredentialsParams{
+			ID:          feed.ID,
+			Credentials: sealed,
+			UpdatedAt:   time.Now(),
+		})
+		if isUniqueViolation(err) {
+			respondWithError(w, http.StatusConflict, "Another feed already has this URL; follow it instead")
+			return
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to update feed")
+			return
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to update feed")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, newFeedResponse(feed))
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+func TestFeedsPatchOnSharedFeedsNeedsAnAdmin(t *testing.T) {
+	ac, _ := newClockTestConfig()
+	ctx := context.Background()
+	owner, feed := seedClockTestFeed(t, ac, true)
+	newUser := func(name string, admin bool) database.User {
+		u, err := ac.DB.CreateUser(ctx, database.CreateUserParams{
+			ID:        uuid.New(),
+			CreatedAt: clockTestStart,
+			UpdatedAt: clockTestStart,
+			Name:      name,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if admin {
+			err = ac.DB.SetUserAdmin(ctx, database.SetUserAdminParams{ID: u.ID, IsAdmin: true, UpdatedAt: clockTestStart})
+			if err != nil {
+				t.Fatal(err)
+			}
+			u.IsAdmin = true
+		}
+		return u
+	}
+	follower, admin := newUser("follower", false), newUser("admin", true)
+	patch := func(u database.User, body string) int {
+		r := httptest.NewRequest(http.MethodPatch, "/v1/feeds/"+feed.ID.String(), strings.NewReader(body))
+		w := httptest.NewRecorder()
+		handleFeedsPatch(w, withURLParam(r, "feedID", feed.ID.String()), u, ac)
+		return w.Code
+	}
+
+	bodies := map[string]string{
+		"interval": `{"fetch_interval_seconds":60}`,
+		"headers":  `{"fetch_headers":{"X-Debug":"1"}}`,
+		"schedule": `{"schedule":"manual"}`,
+	}
+	for name, body := range bodies {
+		if code := patch(owner, body); code != http.StatusOK {
+			t.Errorf("%s: sole follower got %d, want 200", name, code)
+		}
+	}
+	_, err := ac.DB.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: clockTestStart,
+		UpdatedAt: clockTestStart,
+		UserID:    follower.ID,
+		FeedID:    feed.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, body := range bodies {
+		if code := patch(owner, body); code != http.StatusForbidden {
+			t.Errorf("%s: owner of a shared feed got %d, want 403", name, code)
+		}
+		if code := patch(follower, body); code != http.StatusForbidden {
+			t.Errorf("%s: follower got %d, want 403", name, code)
+		}
+		if code := patch(admin, body); code != http.StatusOK {
+			t.Errorf("%s: admin got %d, want 200", name, code)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const (
+	fetchBatchSize      = 10
+	defaultFetchWorkers = 5
+)
+
+func newFetchWorkersFromEnv() (int, error) {
+	v := os.Getenv("FETCH_WORKERS")
+	if v == "" {
+		return defaultFetchWorkers, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("Invalid FETCH_WORKERS")
+	}
+	return n, nil
+}
+
+// fetchResult is what one fetch of a feed produced.
+type fetchResult struct {
+	Feed database.Feed
+	Data feedData
+	Err  error
+}
+
+// getFeedsWorker fetches the feeds that are due, a batch each minute. At
+// most FetchWorkers feeds are fetched at once; every result is drained and
+// stored before the next batch starts, one feed at a time, so the batch's
+// writes don't contend with each other. While the database is slow to take
+// posts, both the concurrency and the batch shrink, rather than fetched
+// feeds piling up waiting to be stored.
+func getFeedsWorker(ac apiConfig) {
+	logInfo("fetch", "Starting feeds worker...")
+	ctx := context.Background()
+	for range ac.Clock.Tick(time.Minute) {
+		if ac.Maintenance.active() {
+			logDebug("fetch", "Maintenance mode, skipping fetch")
+			continue
+		}
+		degradation := ac.Stats.ingestDegradation()
+		if old := ingestDegradation(ac.Stats.Degradation.Swap(int64(degradation))); old != degradation {
+			logWarn("fetch", "Post writes averaging %s, fetching %s", time.Duration(ac.Stats.IngestLatency.Load()), degradation)
+		}
+		workers, batchSize := fetchLimits(ac.FetchWorkers, degradation)
+		feeds, err := dueFeeds(ctx, ac, ac.Clock.Now(), batchSize)
+		if err != nil {
+			logError("fetch", "Could not get next feeds: %v", err)
+			continue
+		}
+		rules, err := ac.DB.ListDomainRules(ctx)
+		if err != nil {
+			logError("fetch", "Could not get domain rules: %v", err)
+			continue
+		}
+		logDebug("fetch", "Processing batch of %d feeds", len(feeds))
+		ac.Stats.LastBatchSize.Store(int64(len(feeds)))
+		ac.Stats.LastBatchAt.Store(ac.Clock.Now().Unix())
+		for result := range fetchBatch(ctx, ac, rules, feeds, workers) {
+			if result.Err != nil {
+				logWarn("fetch", "Could not fetch %s: %v", result.Feed.Name, result.Err)
+				continue
+			}
+			ingest := storeFeedData(ctx, ac, result.Data)
+			logDebug("fetch", "Stored %s: %d new, %d known, %d failed", result.Feed.Name, ingest.New, ingest.Known, ingest.Failed)
+		}
+	}
+}
+
+// fetchBatch fetches feeds on a pool of workers goroutines and returns a
+// channel of their results, closed once every feed is done. The channel is
+// buffered to hold the whole batch, so no fetch waits on the reader.
+func fetchBatch(ctx context.Context, ac apiConfig, rules []database.DomainRule, feeds []database.Feed, workers int) <-chan fetchResult {
+	jobs := make(chan database.Feed)
+	results := make(chan fetchResult, len(feeds))
+	wg := sync.WaitGroup{}
+	for i := 0; i < min(workers, len(feeds)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range jobs {
+				results <- fetchDueFeed(ctx, ac, rules, f)
+			}
+		}()
+	}
+	go func() {
+		for _, feed := range feeds {
+			// Blocked feeds are still marked fetched so that they do not
+			// hold a slot in every batch.
+			err := ac.DB.MarkFeedFetched(ctx, database.MarkFeedFetchedParams{
+				LastFetchedAt: sql.NullTime{Time: ac.Clock.Now(), Valid: true},
+				ID:            feed.ID,
+			})
+			if err != nil {
+				logError("fetch", "Could not mark feed fetched: %v", err)
+			}
+			if err := checkFeedDomain(rules, feed.Url); err != nil {
+				logInfo("fetch", "Skipping %s feed: %v", feed.Name, err)
+				continue
+			}
+			jobs <- feed
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+// fetchDueFeed fetches one feed and records how the fetch went: its fetch
+// history, its snapshot, its backoff state and, if it has moved, its new URL.
+func fetchDueFeed(ctx context.Context, ac apiConfig, rules []database.DomainRule, f database.Feed) fetchResult {
+	logDebug("fetch", "Processing %s feed", f.Name)
+	ac.Stats.FetchesInFlight.Add(1)
+	defer ac.Stats.FetchesInFlight.Add(-1)
+	start := time.Now()
+	fd, err := ac.FetchFeed(ctx, f.Url, cacheValidators{ETag: f.Etag, LastModified: f.LastModified}, ac.fetchHeaders(f))
+	fd.FeedID = f.ID
+	recordFeedFetch(ctx, ac, f.ID, fd, time.Since(start), err)
+	saveFetchSnapshot(ctx, ac, f.ID, fd)
+	if err != nil {
+		recordFetchFailure(ctx, ac, f, err)
+		return fetchResult{Feed: f, Data: fd, Err: err}
+	}
+	recordFetchSuccess(ctx, ac, f)
+	if fd.MovedTo != "" {
+		moveFeed(ctx, ac, rules, f, fd.MovedTo)
+	}
+	return fetchResult{Feed: f, Data: fd}
+}
+
+// ingestResult counts what became of a fetched feed's items: stored as
+// new posts, already known, or not stored because of an error.
+type ingestResult struct {
+	New    int `json:"new"`
+	Known  int `json:"known"`
+	Failed int `json:"failed"`
+}
+
+// storeFeedData stores a fetched feed's items as posts and passes the new
+// ones on to notifications and virtual feeds.
+func storeFeedData(ctx context.Context, ac apiConfig, feed feedData) ingestResult {
+	result := ingestResult{}
+	if feed.NotModified {
+		return result
+	}
+	if language, country := parseLanguageTag(feed.Channel.Language); language != "" {
+		err := ac.DB.SetFeedLocale(ctx, database.SetFeedLocaleParams{
+			ID:        feed.FeedID,
+			Language:  language,
+			Country:   country,
+			UpdatedAt: ac.Clock.Now(),
+		})
+		if err != nil {
+			logError("fetch", "Could not set feed language: %v", err)
+		}
+	}
+	hadPosts, err := ac.DB.FeedHasPosts(ctx, feed.FeedID)
+	if err != nil {
+		logError("fetch", "Could not check feed posts: %v", err)
+	}
+	newPosts := []database.Post{}
+	for _, item := range feed.Channel.Item {
+		logDebug("fetch", "Adding %s to posts...", item.Title)
+		createParams := database.UpsertPostParams{
+			ID:              uuid.New(),
+			CreatedAt:       ac.Clock.Now(),
+			UpdatedAt:       ac.Clock.Now(),
+			Title:           item.Title,
+			Url:             stripTrackingParams(item.Link),
+			FeedID:          feed.FeedID,
+			Sensitive:       hasSensitiveCategory(item.Category),
+			Guid:            itemGuid(item),
+			EnclosureUrl:    strings.TrimSpace(item.Enclosure.URL),
+			EnclosureType:   strings.TrimSpace(item.Enclosure.Type),
+			EnclosureLength: enclosureLength(item.Enclosure.Length),
+			Author:          itemAuthor(item),
+		}
+		if description := strings.TrimSpace(sanitizeHTML(item.Description, ac.Content.ingestOptions())); description != "" {
+			createParams.Description = sql.NullString{String: description, Valid: true}
+		}
+
+		if pubTime, err := parseFeedDate(item.PubDate); err == nil {
+			createParams.PublishedAt = sql.NullTime{Time: pubTime, Valid: true}
+		} else if item.PubDate != "" {
+			logDebug("fetch", "Ignoring pubDate of %s: %v", item.Title, err)
+		}
+
+		// An item already stored under its GUID comes back as an update if
+		// the feed edited it, or not at all if it did not.
+		start := time.Now()
+		post, err := ac.DB.UpsertPost(ctx, createParams)
+		ac.Stats.recordIngest(time.Since(start))
+		switch {
+		case err == nil && post.ID == createParams.ID:
+			newPosts = append(newPosts, post)
+		case err == nil:
+			logDebug("fetch", "Updated %s", post.Title)
+			result.Known++
+		case errors.Is(err, sql.ErrNoRows), isUniqueViolation(err):
+			result.Known++
+		default:
+			logError("fetch", "Could not store %s: %v", item.Title, err)
+			result.Failed++
+		}
+	}
+	ac.notifyNewPosts(ctx, feed.FeedID, feed.Channel.Title, newPosts)
+	ac.addToVirtualFeeds(ctx, feed.FeedID, newPosts)
+	result.New = len(newPosts)
+	if result.New > 0 {
+		learnFeedCalendar(ctx, ac, feed.FeedID)
+	}
+	if isFeedGap(hadPosts, len(feed.Channel.Item), result.Known) {
+		recordFeedGap(ctx, ac, feed.FeedID)
+	}
+	// Only now that the items are stored, so that a fetch which fails half
+	// way is retried in full rather than answered with a 304.
+	err = ac.DB.SetFeedCacheValidators(ctx, database.SetFeedCacheValidatorsParams{
+		ID:           feed.FeedID,
+		Etag:         feed.Cache.ETag,
+		LastModified: feed.Cache.LastModified,
+	})
+	if err != nil {
+		logError("fetch", "Could not store cache validators: %v", err)
+	}
+	return result
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const (
+	// gapMinPageSize keeps one-item feeds, where every fetch is all new by
+	// design, from looking like they overflowed.
+	gapMinPageSize = 2
+	// gapFirstInterval is where tightening starts; each further gap halves
+	// it, down to gapMinInterval.
+	gapFirstInterval = 5 * time.Minute
+	gapMinInterval   = time.Minute
+	// gapWarningFor is how long a detected gap stays a health warning.
+	gapWarningFor = 24 * time.Hour
+)
+
+// isFeedGap reports whether a fetch suggests items were missed: the feed
+// had posts already, but none of the fetched page overlaps them, so the
+// feed published more than a page since the last fetch.
+func isFeedGap(hadPosts bool, fetched, known int) bool {
+	return hadPosts && fetched >= gapMinPageSize && known == 0
+}
+
+// nextGapInterval tightens a feed's gap interval, in seconds, after another
+// gap.
+func nextGapInterval(current int32) int32 {
+	next := gapFirstInterval
+	if current > 0 {
+		next = time.Duration(current) * time.Second / 2
+	}
+	if next < gapMinInterval {
+		next = gapMinInterval
+	}
+	return int32(next / time.Second)
+}
+
+// recordFeedGap flags a feed for possible missed items and polls it more
+// often from now on.
+func recordFeedGap(ctx context.Context, ac apiConfig, feedID uuid.UUID) {
+	feed, err := ac.DB.GetFeed(ctx, feedID)
+	if err != nil {
+		logError("fetch", "Could not load feed %s after a gap: %v", feedID, err)
+		return
+	}
+	interval := nextGapInterval(feed.GapIntervalSeconds)
+	logWarn("fetch", "Possible missed items on %s, polling every %s", feed.Name, time.Duration(interval)*time.Second)
+	err = ac.DB.RecordFeedGap(ctx, database.RecordFeedGapParams{
+		ID:                 feedID,
+		MissedItemsAt:      sql.NullTime{Time: ac.Clock.Now(), Valid: true},
+		GapIntervalSeconds: interval,
+	})
+	if err != nil {
+		logError("fetch", "Could not record gap on %s: %v", feed.Name, err)
+	}
+}
+
+func handleFeedHealthGet(w http.ResponseWriter, r *http.Request, ac apiConfig) {
+	feed, ok := getVisibleFeed(w, r, ac)
+	if !ok {
+		return
+	}
+	type response struct {
+		FeedID             uuid.UUID  `json:"feed_id"`
+		LastFetchedAt      *time.Time `json:"last_fetched_at"`
+		DisabledAt         *time.Time `json:"disabled_at"`
+		MissedItemsAt      *time.Time `json:"missed_items_at"`
+		GapIntervalSeconds *int32     `json:"gap_interval_seconds"`
+		QuietWeekdays      []string   `json:"quiet_weekdays"`
+		Warnings           []string   `json:"warnings"`
+	}
+	resp := response{
+		FeedID:        feed.ID,
+		LastFetchedAt: nullTimePtr(feed.LastFetchedAt),
+		DisabledAt:    nullTimePtr(feed.DisabledAt),
+		MissedItemsAt: nullTimePtr(feed.MissedItemsAt),
+		QuietWeekdays: quietWeekdayNames(feed.QuietWeekdays),
+		Warnings:      []string{},
+	}
+	if feed.GapIntervalSeconds > 0 {
+		resp.GapIntervalSeconds = &feed.GapIntervalSeconds
+	}
+	if feed.DisabledAt.Valid {
+		resp.Warnings = append(resp.Warnings, "disabled")
+	}
+	if feed.PausedAt.Valid {
+		resp.Warnings = append(resp.Warnings, "paused after repeated fetch failures")
+	}
+	if feed.MissedItemsAt.Valid && ac.Clock.Now().Sub(feed.MissedItemsAt.Time) < gapWarningFor {
+		resp.Warnings = append(resp.Warnings, "possible missed items")
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const (
+	// silentFactor is how many of its usual gaps between posts a feed must
+	// go without one to count as unusually silent.
+	silentFactor = 4
+	// silentMinPosts is how many posts a feed needs before its usual gap is
+	// worth trusting.
+	silentMinPosts = 5
+	// minSilence keeps feeds that post many times a day from being called
+	// silent after a quiet afternoon.
+	minSilence = 24 * time.Hour
+	// maxDigestFeeds caps how many feeds each section of a digest message
+	// lists; the remainder is summarised as a count.
+	maxDigestFeeds = 20
+)
+
+// newHealthDigestIntervalFromEnv reads HEALTH_DIGEST_INTERVAL, how often
+// admins are sent a digest of feed health. Unset or zero turns it off.
+func newHealthDigestIntervalFromEnv() (time.Duration, error) {
+	v := os.Getenv("HEALTH_DIGEST_INTERVAL")
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("Invalid HEALTH_DIGEST_INTERVAL")
+	}
+	return d, nil
+}
+
+type digestFeed struct {
+	FeedID uuid.UUID `json:"feed_id"`
+	Name   string    `json:"name"`
+	Url    string    `json:"url"`
+}
+
+type failingFeed struct {
+	digestFeed
+	FailingSince time.Time `json:"failing_since"`
+	Failures     int32     `json:"failures"`
+	LastError    string    `json:"last_error"`
+}
+
+type pausedFeed struct {
+	digestFeed
+	PausedAt  time.Time `json:"paused_at"`
+	Failures  int32     `json:"failures"`
+	LastError string    `json:"last_error"`
+}
+
+type silentFeed struct {
+	digestFeed
+	LastPostAt time.Time `json:"last_post_at"`
+	// UsualGapSeconds is the feed's average time between posts.
+	UsualGapSeconds int64 `json:"usual_gap_seconds"`
+}
+
+// healthDigest is what went wrong with feeds between Since and Until:
+// feeds that started failing, feeds paused after failing too often in a
+// row, and feeds that are fetched fine but have gone quiet for much longer
+// than they usually do. Each feed is reported once, in the digest for the
+// period its trouble began, rather than in every digest until it is fixed.
+type healthDigest struct {
+	Since   time.Time     `json:"since"`
+	Until   time.Time     `json:"until"`
+	Failing []failingFeed `json:"failing"`
+	Paused  []pausedFeed  `json:"paused"`
+	Silent  []silentFeed  `json:"silent"`
+}
+
+func buildHealthDigest(ctx context.Context, ac apiConfig, since, until time.Time) (healthDigest, error) {
+	d := healthDigest{Since: since, Until: until, Failing: []failingFeed{}, Paused: []pausedFeed{}, Silent: []silentFeed{}}
+	failing, err := ac.DB.ListNewlyFailingFeeds(ctx, since)
+	if err != nil {
+		return d, err
+	}
+	for _, f := range failing {
+		if f.FailingSince.After(until) {
+			continue
+		}
+		d.Failing = append(d.Failing, failingFeed{
+			digestFeed:   digestFeed{FeedID: f.ID, Name: f.Name, Url: f.Url},
+			FailingSince: f.FailingSince,
+			Failures:     f.FetchFailures,
+			LastError:    f.LastFetchError,
+		})
+	}
+	paused, err := ac.DB.ListFeedsPausedSince(ctx, since)
+	if err != nil {
+		return d, err
+	}
+	for _, f := range paused {
+		if f.PausedAt.Time.After(until) {
+			continue
+		}
+		d.Paused = append(d.Paused, pausedFeed{
+			digestFeed: digestFeed{FeedID: f.ID, Name: f.Name, Url: f.Url},
+			PausedAt:   f.PausedAt.Time,
+			Failures:   f.FetchFailures,
+			LastError:  f.LastFetchError,
+		})
+	}
+	stats, err := ac.DB.ListFeedPostingStats(ctx, silentMinPosts)
+	if err != nil {
+		return d, err
+	}
+	for _, s := range stats {
+		// Only feeds still being fetched since their last post can be said
+		// to have gone quiet rather than to have not been looked at.
+		if !s.LastFetchedAt.Valid || !s.LastFetchedAt.Time.After(s.LastPostAt) {
+			continue
+		}
+		gap := s.LastPostAt.Sub(s.FirstPostAt) / time.Duration(s.PostCount-1)
+		silentFrom := s.LastPostAt.Add(max(silentFactor*gap, minSilence))
+		if !silentFrom.After(since) || silentFrom.After(until) {
+			continue
+		}
+		d.Silent = append(d.Silent, silentFeed{
+			digestFeed:      digestFeed{FeedID: s.ID, Name: s.Name, Url: s.Url},
+			LastPostAt:      s.LastPostAt,
+			UsualGapSeconds: int64(gap.Seconds()),
+		})
+	}
+	return d, nil
+}
+
+func (d healthDigest) empty() bool {
+	return len(d.Failing) == 0 && len(d.Paused) == 0 && len(d.Silent) == 0
+}
+
+// text formats the digest as a plain-text message, for email and chat.
+func (d healthDigest) text() string {
+	msg := strings.Builder{}
+	fmt.Fprintf(&msg, "Feed health from %s to %s\n", d.Since.UTC().Format(time.RFC3339), d.Until.UTC().Format(time.RFC3339))
+	section := func(title string, n int, line func(i int) string) {
+		if n == 0 {
+			return
+		}
+		fmt.Fprintf(&msg, "\n%s (%d):\n", title, n)
+		for i := 0; i < n; i++ {
+			if i == maxDigestFeeds {
+				fmt.Fprintf(&msg, "…and %d more\n", n-i)
+				break
+			}
+			fmt.Fprintf(&msg, "- %s\n", line(i))
+		}
+	}
+	section("Started failing", len(d.Failing), func(i int) string {
+		f := d.Failing[i]
+		return fmt.Sprintf("%s %s: %d failures, last: %s", f.Name, f.Url, f.Failures, f.LastError)
+	})
+	section("Paused after repeated failures", len(d.Paused), func(i int) string {
+		f := d.Paused[i]
+		return fmt.Sprintf("%s %s: %s", f.Name, f.Url, f.LastError)
+	})
+	section("Unusually silent", len(d.Silent), func(i int) string {
+		f := d.Silent[i]
+		gap := time.Duration(f.UsualGapSeconds) * time.Second
+		return fmt.Sprintf("%s %s: no posts since %s, usually every %s", f.Name, f.Url, f.LastPostAt.UTC().Format(time.DateOnly), gap.Round(time.Minute))
+	})
+	return strings.TrimSuffix(msg.String(), "\n")
+}
+
+// healthDigestWorker sends admins a digest of feed health every interval,
+// covering the interval just past. Nothing is sent when all is well.
+func healthDigestWorker(ac apiConfig, interval time.Duration) {
+	logInfo("health", "Starting feed health digest worker...")
+	for t := range ac.Clock.Tick(interval) {
+		if ac.Maintenance.active() {
+			continue
+		}
+		ctx := context.Background()
+		d, err := buildHealthDigest(ctx, ac, t.Add(-interval), t)
+		if err != nil {
+			logError("health", "Could not build feed health digest: %v", err)
+			continue
+		}
+		if d.empty() {
+			continue
+		}
+		sendHealthDigest(ctx, ac, d)
+	}
+}
+
+// sendHealthDigest sends the digest to every admin, by email if mail is
+// configured and they have an address, and to their notification channels.
+func sendHealthDigest(ctx context.Context, ac apiConfig, d healthDigest) {
+	admins, err := ac.DB.ListAdmins(ctx)
+	if err != nil {
+		logError("health", "Could not list admins: %v", err)
+		return
+	}
+	text := d.text()
+	subject := fmt.Sprintf("Feed health: %d failing, %d paused, %d silent", len(d.Failing), len(d.Paused), len(d.Silent))
+	msg := notification{Event: "feed_health_digest", Text: text, Posts: []notificationPost{}}
+	for _, admin := range admins {
+		if ac.Mailer != nil && admin.Email.Valid {
+			err := ac.Mailer.send(admin.Email.String, subject, text)
+			if err != nil {
+				logWarn("health", "Could not email feed health digest to %s: %v", admin.Name, err)
+			}
+		}
+		channels, err := ac.DB.GetUserNotificationChannels(ctx, admin.ID)
+		if err != nil {
+			logError("health", "Could not get notification channels of %s: %v", admin.Name, err)
+			continue
+		}
+		for _, channel := range channels {
+			err := ac.sendNotification(ctx, channel, msg)
+			if err != nil {
+				logWarn("health", "Could not notify channel %s: %v", channel.ID, err)
+			}
+		}
+	}
+}
+
+// handleAdminFeedHealthGet returns the digest for the period from since,
+// by default a day ago, to now, whether or not digests are being sent.
+func handleAdminFeedHealthGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	now := ac.Clock.Now()
+	since := now.Add(-24 * time.Hour)
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, err := parseAsOf(v)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "since must be an RFC 3339 timestamp or a date")
+			return
+		}
+		since = t
+	}
+	d, err := buildHealthDigest(r.Context(), ac, since, now)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to check feed health")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, d)
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const (
+	// iconRefreshAfter is how long an icon, or the lack of one, is kept
+	// before the feed's icon is looked up again.
+	iconRefreshAfter = 7 * 24 * time.Hour
+	iconBatchSize    = 20
+	maxIconBytes     = 256 << 10
+)
+
+// iconWorker looks up icons for feeds that have none yet or whose icon is
+// stale, a batch at a time, so clients can show them without each one
+// fetching every site's favicon.
+func iconWorker(ac apiConfig) {
+	logInfo("icon", "Starting icon worker...")
+	for range ac.Clock.Tick(10 * time.Minute) {
+		if ac.Maintenance.active() {
+			continue
+		}
+		ctx := context.Background()
+		feeds, err := ac.DB.ListFeedsNeedingIcons(ctx, database.ListFeedsNeedingIconsParams{
+			StaleBefore: ac.Clock.Now().Add(-iconRefreshAfter),
+			Limit:       iconBatchSize,
+		})
+		if err != nil {
+			logError("icon", "Could not get feeds needing icons: %v", err)
+			continue
+		}
+		for _, f := range feeds {
+			refreshFeedIcon(ctx, ac, f)
+		}
+	}
+}
+
+// refreshFeedIcon stores the first icon candidate that yields an image. A
+// feed with none gets an empty icon, so it is not tried again until the
+// icon would have gone stale.
+func refreshFeedIcon(ctx context.Context, ac apiConfig, f database.Feed) {
+	params := database.UpsertFeedIconParams{
+		FeedID:    f.ID,
+		Data:      []byte{},
+		FetchedAt: ac.Clock.Now(),
+	}
+	for _, candidate := range iconCandidates(ctx, ac, f) {
+		data, contentType, err := fetchIcon(ctx, ac.HTTPClient, candidate)
+		if err != nil {
+			logDebug("icon", "No icon at %s: %v", candidate, err)
+			continue
+		}
+		params.SourceUrl = candidate
+		params.ContentType = contentType
+		params.Data = data
+		break
+	}
+	if params.SourceUrl == "" {
+		logDebug("icon", "No icon found for %s", f.Name)
+	}
+	err := ac.DB.UpsertFeedIcon(ctx, params)
+	if err != nil {
+		logError("icon", "Could not save icon for %s: %v", f.Name, err)
+	}
+}
+
+// iconCandidates lists where a feed's icon might be, best first: the icons
+// its site's home page links to, the site's /favicon.ico, the image the
+// feed itself names, and /favicon.ico on the feed's own host.
+func iconCandidates(ctx context.Context, ac apiConfig, f database.Feed) []string {
+	feedURL, err := url.Parse(f.Url)
+	if err != nil {
+		return nil
+	}
+	candidates := []string{}
+	add := func(base *url.URL, ref string) {
+		u, err := base.Parse(strings.TrimSpace(ref))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return
+		}
+		if !slices.Contains(candidates, u.String()) {
+			candidates = append(candidates, u.String())
+		}
+	}
+
+	fd, err := ac.FetchFeed(ctx, f.Url, cacheValidators{}, ac.fetchHeaders(f))
+	if err == nil {
+		site := strings.TrimSpace(fd.Channel.Link.Text)
+		if siteURL, err := feedURL.Parse(site); err == nil && site != "" {
+			for _, href := range siteIconLinks(ctx, ac.HTTPClient, siteURL) {
+				add(siteURL, href)
+			}
+			add(siteURL, "/favicon.ico")
+		}
+		if fd.Channel.Image.URL != "" {
+			add(feedURL, fd.Channel.Image.URL)
+		}
+	}
+	add(feedURL, "/favicon.ico")
+	return candidates
+}
+
+// siteIconLinks returns the hrefs of a page's <link rel="icon"> tags,
+// including "shortcut icon" and "apple-touch-icon". The page's URL comes
+// from the feed, so it is fetched with the same client as feeds are.
+func siteIconLinks(ctx context.Context, client *http.Client, pageURL *url.URL) []string {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL.String(), nil)
+	if err != nil {
+		return nil
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxDiscoveryBytes))
+	if err != nil {
+		return nil
+	}
+	hrefs := []string{}
+	for _, tag := range linkTagPattern.FindAllString(string(body), -1) {
+		attrs := map[string]string{}
+		for _, m := range linkAttrPattern.FindAllStringSubmatch(tag, -1) {
+			attrs[strings.ToLower(m[1])] = strings.Trim(m[2], `"'`)
+		}
+		if strings.Contains(strings.ToLower(attrs["rel"]), "icon") && attrs["href"] != "" {
+			hrefs = append(hrefs, attrs["href"])
+		}
+	}
+	return hrefs
+}
+
+// fetchIcon downloads an image, judging its type by its content rather than
+// what the server says. SVG is not accepted, since it can carry script.
+func fetchIcon(ctx context.Context, client *http.Client, iconURL string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, iconURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("%s", res.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxIconBytes+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxIconBytes {
+		return nil, "", errors.New("icon too large")
+	}
+	contentType := http.DetectContentType(data)
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, "", fmt.Errorf("not an image: %s", contentType)
+	}
+	return data, contentType, nil
+}
+
+func handleFeedIconGet(w http.ResponseWriter, r *http.Request, ac apiConfig) {
+	feed, ok := getVisibleFeed(w, r, ac)
+	if !ok {
+		return
+	}
+	icon, err := ac.DB.GetFeedIcon(r.Context(), feed.ID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && len(icon.Data) == 0) {
+		respondWithError(w, http.StatusNotFound, "Icon not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve icon")
+		return
+	}
+	w.Header().Set("Content-Type", icon.ContentType)
+	cacheControl := "public, max-age=86400"
+	if isPrivateFeed(feed) || feed.Kind == "inbox" {
+		cacheControl = "private, max-age=86400"
+	}
+	w.Header().Set("Cache-Control", cacheControl)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	w.Write(icon.Data)
+}
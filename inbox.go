@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const inboxFeedName = "Saved from web"
+
+// getOrCreateInboxFeed returns the user's inbox pseudo-feed, creating it and
+// a follow of it on first use. Inbox feeds have kind "inbox": they are never
+// fetched and are left out of the public feed list. Their URL only needs to
+// be unique, so it is derived from the user ID.
+func getOrCreateInboxFeed(ctx context.Context, q database.Querier, userID uuid.UUID) (database.Feed, error) {
+	feed, err := q.GetInboxFeed(ctx, userID)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return feed, err
+	}
+	feed, err = q.CreateInboxFeed(ctx, database.CreateInboxFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Name:      inboxFeedName,
+		Url:       "inbox:" + userID.String(),
+		UserID:    userID,
+	})
+	if err != nil {
+		return feed, err
+	}
+	_, err = q.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    userID,
+		FeedID:    feed.ID,
+	})
+	return feed, err
+}
+
+// handleInboxPost stores a link pushed from an external tool, such as a
+// browser extension or a share sheet, as a post in the user's inbox feed.
+func handleInboxPost(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	type inboxRequest struct {
+		URL         string `json:"url"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := inboxRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	link, err := url.Parse(strings.TrimSpace(req.URL))
+	if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL")
+		return
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = link.String()
+	}
+
+	tx, err := ac.DB.Begin(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save link")
+		return
+	}
+	defer tx.Rollback()
+
+	feed, err := getOrCreateInboxFeed(r.Context(), tx, u.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to get inbox feed")
+		return
+	}
+	params := database.CreatePostParams{
+		ID:          uuid.New(),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+		Title:       title,
+		Url:         link.String(),
+		PublishedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		FeedID:      feed.ID,
+	}
+	if description := strings.TrimSpace(sanitizeHTML(req.Description, ac.Content.ingestOptions())); description != "" {
+		params.Description = sql.NullString{String: description, Valid: true}
+	}
+	post, err := tx.CreatePost(r.Context(), params)
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "That link is already in your inbox")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save link")
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save link")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, newPostResponse(post))
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const defaultIntegrityCheckInterval = 24 * time.Hour
+
+// integrityChecks are the invariants the integrity checker knows about.
+// Foreign keys rule most of them out in Postgres, but restores, manual
+// edits and other stores can still produce them.
+var integrityChecks = []struct {
+	Name        string
+	Description string
+	Count       func(database.Querier, context.Context) (int64, error)
+	Repair      func(database.Querier, context.Context) (int64, error)
+}{
+	{"orphan_feed_follows", "follows whose user or feed no longer exists",
+		database.Querier.CountOrphanFeedFollows, database.Querier.DeleteOrphanFeedFollows},
+	{"foreign_inbox_follows", "follows of another user's inbox feed",
+		database.Querier.CountForeignInboxFollows, database.Querier.DeleteForeignInboxFollows},
+	{"orphan_posts", "posts whose feed no longer exists",
+		database.Querier.CountOrphanPosts, database.Querier.DeleteOrphanPosts},
+	{"orphan_post_snoozes", "snoozes whose user or post no longer exists",
+		database.Querier.CountOrphanPostSnoozes, database.Querier.DeleteOrphanPostSnoozes},
+	{"orphan_queue_items", "reading queue items whose user or post no longer exists",
+		database.Querier.CountOrphanQueueItems, database.Querier.DeleteOrphanQueueItems},
+	{"orphan_post_emails", "post email records whose user or post no longer exists",
+		database.Querier.CountOrphanPostEmails, database.Querier.DeleteOrphanPostEmails},
+}
+
+type integrityFinding struct {
+	Check       string `json:"check"`
+	Description string `json:"description"`
+	Count       int64  `json:"count"`
+	Repaired    int64  `json:"repaired"`
+}
+
+type integrityReport struct {
+	CheckedAt time.Time          `json:"checked_at"`
+	Repair    bool               `json:"repair"`
+	Problems  int64              `json:"problems"`
+	Findings  []integrityFinding `json:"findings"`
+}
+
+// checkIntegrity runs every check against q and, with repair set, deletes
+// the offending rows. Checks run in order, so repairing orphan posts before
+// the post-state checks also surfaces the rows that hung off them.
+func checkIntegrity(ctx context.Context, q database.Querier, repair bool, now time.Time) (integrityReport, error) {
+	report := integrityReport{CheckedAt: now, Repair: repair, Findings: []integrityFinding{}}
+	for _, check := range integrityChecks {
+		count, err := check.Count(q, ctx)
+		if err != nil {
+			return report, fmt.Errorf("%s: %w", check.Name, err)
+		}
+		finding := integrityFinding{Check: check.Name, Description: check.Description, Count: count}
+		if repair && count > 0 {
+			finding.Repaired, err = check.Repair(q, ctx)
+			if err != nil {
+				return report, fmt.Errorf("%s: %w", check.Name, err)
+			}
+		}
+		report.Problems += count
+		report.Findings = append(report.Findings, finding)
+	}
+	return report, nil
+}
+
+func handleAdminIntegrityGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	report, err := checkIntegrity(r.Context(), ac.DB, false, ac.Clock.Now())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to check integrity")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, report)
+}
+
+func handleAdminIntegrityRepair(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	tx, err := ac.DB.Begin(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to repair integrity")
+		return
+	}
+	defer tx.Rollback()
+
+	report, err := checkIntegrity(r.Context(), tx, true, ac.Clock.Now())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to repair integrity")
+		return
+	}
+	err = recordAudit(r.Context(), tx, u.ID, "integrity.repair", "instance", uuid.Nil, report)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to record audit log entry")
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to repair integrity")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, report)
+}
+
+// newIntegrityCheckIntervalFromEnv reads INTEGRITY_CHECK_INTERVAL. Zero
+// turns the periodic check off.
+func newIntegrityCheckIntervalFromEnv() (time.Duration, error) {
+	v := os.Getenv("INTEGRITY_CHECK_INTERVAL")
+	if v == "" {
+		return defaultIntegrityCheckInterval, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("Invalid INTEGRITY_CHECK_INTERVAL")
+	}
+	return d, nil
+}
+
+// integrityWorker runs the checks periodically and logs what it finds. It
+// never repairs; that is left to an admin.
+func integrityWorker(ac apiConfig, interval time.Duration) {
+	logInfo("integrity", "Starting integrity worker...")
+	for range ac.Clock.Tick(interval) {
+		if ac.Maintenance.active() {
+			continue
+		}
+		report, err := checkIntegrity(context.Background(), ac.DB, false, ac.Clock.Now())
+		if err != nil {
+			logError("integrity", "Could not check integrity: %v", err)
+			continue
+		}
+		for _, f := range report.Findings {
+			if f.Count > 0 {
+				logWarn("integrity", "%d %s", f.Count, f.Description)
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Package clock lets the background workers take their notion of time from
+// somewhere other than the time package, so that a test or a simulation can
+// step through a day of scheduling without waiting for it.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the subset of the time package the workers use.
+type Clock interface {
+	Now() time.Time
+	// Tick behaves like time.Tick: the channel receives the current time
+	// every d, and ticks are dropped while the receiver is busy.
+	Tick(d time.Duration) <-chan time.Time
+}
+
+// Real is the wall clock.
+type Real struct{}
+
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+func (Real) Tick(d time.Duration) <-chan time.Time {
+	return time.Tick(d)
+}
+
+// Fake is a Clock that only moves when Advance or Set is called.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+type fakeTicker struct {
+	c      chan time.Time
+	period time.Duration
+	next   time.Time
+}
+
+// NewFake returns a Fake clock stopped at now.
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *Fake) Tick(d time.Duration) <-chan time.Time {
+	if d <= 0 {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := &fakeTicker{c: make(chan time.Time, 1), period: d, next: f.now.Add(d)}
+	f.tickers = append(f.tickers, t)
+	return t.c
+}
+
+// Advance moves the clock forward by d, firing every tick that falls due on
+// the way in time order. Like a real ticker, a tick is dropped if the
+// previous one has not been received yet.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	target := f.now.Add(d)
+	f.mu.Unlock()
+	f.Set(target)
+}
+
+// Set moves the clock to t. Moving backwards fires nothing.
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for {
+		due := []*fakeTicker{}
+		for _, ticker := range f.tickers {
+			if !ticker.next.After(t) {
+				due = append(due, ticker)
+			}
+		}
+		if len(due) == 0 {
+			break
+		}
+		sort.Slice(due, func(i, j int) bool { return due[i].next.Before(due[j].next) })
+		ticker := due[0]
+		if ticker.next.After(f.now) {
+			f.now = ticker.next
+		}
+		select {
+		case ticker.c <- f.now:
+		default:
+		}
+		ticker.next = ticker.next.Add(ticker.period)
+	}
+	f.now = t
+}
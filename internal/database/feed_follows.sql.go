@@ -7,15 +7,32 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const countOtherFeedFollowers = `-- name: CountOtherFeedFollowers :one
+SELECT COUNT(*) FROM feed_follows WHERE feed_id = $1 AND user_id <> $2
+`
+
+type CountOtherFeedFollowersParams struct {
+	FeedID uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) CountOtherFeedFollowers(ctx context.Context, arg CountOtherFeedFollowersParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOtherFeedFollowers, arg.FeedID, arg.UserID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createFeedFollow = `-- name: CreateFeedFollow :one
 INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
 VALUES ($1, $2, $3, $4, $5)
-RETURNING id, created_at, updated_at, user_id, feed_id
+RETURNING id, created_at, updated_at, user_id, feed_id, priority, order_by_ingested
 `
 
 type CreateFeedFollowParams struct {
@@ -41,6 +58,8 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.FeedID,
+		&i.Priority,
+		&i.OrderByIngested,
 	)
 	return i, err
 }
@@ -54,8 +73,17 @@ func (q *Queries) DeleteFeedFollow(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const deleteUserFeedFollows = `-- name: DeleteUserFeedFollows :exec
+DELETE FROM feed_follows WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserFeedFollows(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUserFeedFollows, userID)
+	return err
+}
+
 const getUserFeedFollows = `-- name: GetUserFeedFollows :many
-SELECT id, created_at, updated_at, user_id, feed_id FROM feed_follows WHERE user_id = $1
+SELECT id, created_at, updated_at, user_id, feed_id, priority, order_by_ingested FROM feed_follows WHERE user_id = $1
 `
 
 func (q *Queries) GetUserFeedFollows(ctx context.Context, userID uuid.UUID) ([]FeedFollow, error) {
@@ -73,6 +101,8 @@ func (q *Queries) GetUserFeedFollows(ctx context.Context, userID uuid.UUID) ([]F
 			&i.UpdatedAt,
 			&i.UserID,
 			&i.FeedID,
+			&i.Priority,
+			&i.OrderByIngested,
 		); err != nil {
 			return nil, err
 		}
@@ -86,3 +116,108 @@ func (q *Queries) GetUserFeedFollows(ctx context.Context, userID uuid.UUID) ([]F
 	}
 	return items, nil
 }
+
+const getUserFeedFollowsPage = `-- name: GetUserFeedFollowsPage :many
+SELECT id, created_at, updated_at, user_id, feed_id, priority, order_by_ingested FROM feed_follows
+WHERE user_id = $1
+AND ($2::timestamp IS NULL OR (created_at, id) > ($2, $3::uuid))
+ORDER BY created_at, id
+LIMIT $4
+`
+
+type GetUserFeedFollowsPageParams struct {
+	UserID  uuid.UUID
+	AfterAt sql.NullTime
+	AfterID uuid.NullUUID
+	Limit   int32
+}
+
+func (q *Queries) GetUserFeedFollowsPage(ctx context.Context, arg GetUserFeedFollowsPageParams) ([]FeedFollow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserFeedFollowsPage,
+		arg.UserID,
+		arg.AfterAt,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FeedFollow
+	for rows.Next() {
+		var i FeedFollow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.FeedID,
+			&i.Priority,
+			&i.OrderByIngested,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateFeedFollow = `-- name: UpdateFeedFollow :one
+UPDATE feed_follows
+SET priority = COALESCE($1, priority),
+    order_by_ingested = COALESCE($2, order_by_ingested),
+    updated_at = $3
+WHERE id = $4 AND user_id = $5
+RETURNING id, created_at, updated_at, user_id, feed_id, priority, order_by_ingested
+`
+
+type UpdateFeedFollowParams struct {
+	Priority        sql.NullString
+	OrderByIngested sql.NullBool
+	UpdatedAt       time.Time
+	ID              uuid.UUID
+	UserID          uuid.UUID
+}
+
+func (q *Queries) UpdateFeedFollow(ctx context.Context, arg UpdateFeedFollowParams) (FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, updateFeedFollow,
+		arg.Priority,
+		arg.OrderByIngested,
+		arg.UpdatedAt,
+		arg.ID,
+		arg.UserID,
+	)
+	var i FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.Priority,
+		&i.OrderByIngested,
+	)
+	return i, err
+}
+
+const userFollowsFeed = `-- name: UserFollowsFeed :one
+SELECT EXISTS(SELECT 1 FROM feed_follows WHERE user_id = $1 AND feed_id = $2)
+`
+
+type UserFollowsFeedParams struct {
+	UserID uuid.UUID
+	FeedID uuid.UUID
+}
+
+func (q *Queries) UserFollowsFeed(ctx context.Context, arg UserFollowsFeedParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, userFollowsFeed, arg.UserID, arg.FeedID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
@@ -8,18 +8,142 @@ package database
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const clearFeedFetchFailures = `-- name: ClearFeedFetchFailures :exec
+UPDATE feeds SET fetch_failures = 0, last_fetch_error = '', retry_at = NULL
+WHERE id = $1
+`
+
+func (q *Queries) ClearFeedFetchFailures(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, clearFeedFetchFailures, id)
+	return err
+}
+
+const countFeedPostsByWeekday = `-- name: CountFeedPostsByWeekday :many
+SELECT EXTRACT(DOW FROM published_at)::int AS weekday, COUNT(*) AS post_count
+FROM posts
+WHERE feed_id = $1 AND published_at >= $2::timestamp
+GROUP BY weekday
+`
+
+type CountFeedPostsByWeekdayParams struct {
+	FeedID uuid.UUID
+	Since  time.Time
+}
+
+type CountFeedPostsByWeekdayRow struct {
+	Weekday   int32
+	PostCount int64
+}
+
+func (q *Queries) CountFeedPostsByWeekday(ctx context.Context, arg CountFeedPostsByWeekdayParams) ([]CountFeedPostsByWeekdayRow, error) {
+	rows, err := q.db.QueryContext(ctx, countFeedPostsByWeekday, arg.FeedID, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountFeedPostsByWeekdayRow
+	for rows.Next() {
+		var i CountFeedPostsByWeekdayRow
+		if err := rows.Scan(&i.Weekday, &i.PostCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createFeed = `-- name: CreateFeed :one
-INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, sensitive, description, site_url, language, country, credentials)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, disabled_at, sensitive, kind, public_id, language, country, etag, last_modified, missed_items_at, gap_interval_seconds, fetch_interval_seconds, fetch_failures, last_fetch_error, retry_at, paused_at, fetch_headers, description, site_url, schedule, quiet_weekdays, credentials, last_fetch_status, unfollowed_at
 `
 
 type CreateFeedParams struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Name        string
+	Url         string
+	UserID      uuid.UUID
+	Sensitive   bool
+	Description string
+	SiteUrl     string
+	Language    string
+	Country     string
+	Credentials []byte
+}
+
+func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, createFeed,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Name,
+		arg.Url,
+		arg.UserID,
+		arg.Sensitive,
+		arg.Description,
+		arg.SiteUrl,
+		arg.Language,
+		arg.Country,
+		arg.Credentials,
+	)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.DisabledAt,
+		&i.Sensitive,
+		&i.Kind,
+		&i.PublicID,
+		&i.Language,
+		&i.Country,
+		&i.Etag,
+		&i.LastModified,
+		&i.MissedItemsAt,
+		&i.GapIntervalSeconds,
+		&i.FetchIntervalSeconds,
+		&i.FetchFailures,
+		&i.LastFetchError,
+		&i.RetryAt,
+		&i.PausedAt,
+		&i.FetchHeaders,
+		&i.Description,
+		&i.SiteUrl,
+		&i.Schedule,
+		&i.QuietWeekdays,
+		&i.Credentials,
+		&i.LastFetchStatus,
+		&i.UnfollowedAt,
+	)
+	return i, err
+}
+
+const createInboxFeed = `-- name: CreateInboxFeed :one
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, kind)
+VALUES ($1, $2, $3, $4, $5, $6, 'inbox')
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, disabled_at, sensitive, kind, public_id, language, country, etag, last_modified, missed_items_at, gap_interval_seconds, fetch_interval_seconds, fetch_failures, last_fetch_error, retry_at, paused_at, fetch_headers, description, site_url, schedule, quiet_weekdays, credentials, last_fetch_status, unfollowed_at
+`
+
+type CreateInboxFeedParams struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
@@ -28,8 +152,8 @@ type CreateFeedParams struct {
 	UserID    uuid.UUID
 }
 
-func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, createFeed,
+func (q *Queries) CreateInboxFeed(ctx context.Context, arg CreateInboxFeedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, createInboxFeed,
 		arg.ID,
 		arg.CreatedAt,
 		arg.UpdatedAt,
@@ -46,16 +170,275 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.DisabledAt,
+		&i.Sensitive,
+		&i.Kind,
+		&i.PublicID,
+		&i.Language,
+		&i.Country,
+		&i.Etag,
+		&i.LastModified,
+		&i.MissedItemsAt,
+		&i.GapIntervalSeconds,
+		&i.FetchIntervalSeconds,
+		&i.FetchFailures,
+		&i.LastFetchError,
+		&i.RetryAt,
+		&i.PausedAt,
+		&i.FetchHeaders,
+		&i.Description,
+		&i.SiteUrl,
+		&i.Schedule,
+		&i.QuietWeekdays,
+		&i.Credentials,
+		&i.LastFetchStatus,
+		&i.UnfollowedAt,
+	)
+	return i, err
+}
+
+const feedHasPosts = `-- name: FeedHasPosts :one
+SELECT EXISTS(SELECT 1 FROM posts WHERE feed_id = $1)
+`
+
+func (q *Queries) FeedHasPosts(ctx context.Context, feedID uuid.UUID) (bool, error) {
+	row := q.db.QueryRowContext(ctx, feedHasPosts, feedID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const getFeed = `-- name: GetFeed :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, disabled_at, sensitive, kind, public_id, language, country, etag, last_modified, missed_items_at, gap_interval_seconds, fetch_interval_seconds, fetch_failures, last_fetch_error, retry_at, paused_at, fetch_headers, description, site_url, schedule, quiet_weekdays, credentials, last_fetch_status, unfollowed_at FROM feeds WHERE id = $1
+`
+
+func (q *Queries) GetFeed(ctx context.Context, id uuid.UUID) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeed, id)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.DisabledAt,
+		&i.Sensitive,
+		&i.Kind,
+		&i.PublicID,
+		&i.Language,
+		&i.Country,
+		&i.Etag,
+		&i.LastModified,
+		&i.MissedItemsAt,
+		&i.GapIntervalSeconds,
+		&i.FetchIntervalSeconds,
+		&i.FetchFailures,
+		&i.LastFetchError,
+		&i.RetryAt,
+		&i.PausedAt,
+		&i.FetchHeaders,
+		&i.Description,
+		&i.SiteUrl,
+		&i.Schedule,
+		&i.QuietWeekdays,
+		&i.Credentials,
+		&i.LastFetchStatus,
+		&i.UnfollowedAt,
+	)
+	return i, err
+}
+
+const getFeedByPublicID = `-- name: GetFeedByPublicID :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, disabled_at, sensitive, kind, public_id, language, country, etag, last_modified, missed_items_at, gap_interval_seconds, fetch_interval_seconds, fetch_failures, last_fetch_error, retry_at, paused_at, fetch_headers, description, site_url, schedule, quiet_weekdays, credentials, last_fetch_status, unfollowed_at FROM feeds WHERE public_id = $1
+`
+
+func (q *Queries) GetFeedByPublicID(ctx context.Context, publicID string) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedByPublicID, publicID)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.DisabledAt,
+		&i.Sensitive,
+		&i.Kind,
+		&i.PublicID,
+		&i.Language,
+		&i.Country,
+		&i.Etag,
+		&i.LastModified,
+		&i.MissedItemsAt,
+		&i.GapIntervalSeconds,
+		&i.FetchIntervalSeconds,
+		&i.FetchFailures,
+		&i.LastFetchError,
+		&i.RetryAt,
+		&i.PausedAt,
+		&i.FetchHeaders,
+		&i.Description,
+		&i.SiteUrl,
+		&i.Schedule,
+		&i.QuietWeekdays,
+		&i.Credentials,
+		&i.LastFetchStatus,
+		&i.UnfollowedAt,
+	)
+	return i, err
+}
+
+const getFeedByUrl = `-- name: GetFeedByUrl :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, disabled_at, sensitive, kind, public_id, language, country, etag, last_modified, missed_items_at, gap_interval_seconds, fetch_interval_seconds, fetch_failures, last_fetch_error, retry_at, paused_at, fetch_headers, description, site_url, schedule, quiet_weekdays, credentials, last_fetch_status, unfollowed_at FROM feeds
+WHERE credentials IS NULL
+AND (url = $1
+OR id = (
+  SELECT feed_id FROM feed_url_history
+  WHERE old_url = $1
+  ORDER BY changed_at DESC
+  LIMIT 1
+))
+ORDER BY url = $1 DESC
+LIMIT 1
+`
+
+func (q *Queries) GetFeedByUrl(ctx context.Context, url string) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedByUrl, url)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.DisabledAt,
+		&i.Sensitive,
+		&i.Kind,
+		&i.PublicID,
+		&i.Language,
+		&i.Country,
+		&i.Etag,
+		&i.LastModified,
+		&i.MissedItemsAt,
+		&i.GapIntervalSeconds,
+		&i.FetchIntervalSeconds,
+		&i.FetchFailures,
+		&i.LastFetchError,
+		&i.RetryAt,
+		&i.PausedAt,
+		&i.FetchHeaders,
+		&i.Description,
+		&i.SiteUrl,
+		&i.Schedule,
+		&i.QuietWeekdays,
+		&i.Credentials,
+		&i.LastFetchStatus,
+		&i.UnfollowedAt,
+	)
+	return i, err
+}
+
+const getInboxFeed = `-- name: GetInboxFeed :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, disabled_at, sensitive, kind, public_id, language, country, etag, last_modified, missed_items_at, gap_interval_seconds, fetch_interval_seconds, fetch_failures, last_fetch_error, retry_at, paused_at, fetch_headers, description, site_url, schedule, quiet_weekdays, credentials, last_fetch_status, unfollowed_at FROM feeds WHERE user_id = $1 AND kind = 'inbox'
+ORDER BY created_at
+LIMIT 1
+`
+
+func (q *Queries) GetInboxFeed(ctx context.Context, userID uuid.UUID) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getInboxFeed, userID)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.DisabledAt,
+		&i.Sensitive,
+		&i.Kind,
+		&i.PublicID,
+		&i.Language,
+		&i.Country,
+		&i.Etag,
+		&i.LastModified,
+		&i.MissedItemsAt,
+		&i.GapIntervalSeconds,
+		&i.FetchIntervalSeconds,
+		&i.FetchFailures,
+		&i.LastFetchError,
+		&i.RetryAt,
+		&i.PausedAt,
+		&i.FetchHeaders,
+		&i.Description,
+		&i.SiteUrl,
+		&i.Schedule,
+		&i.QuietWeekdays,
+		&i.Credentials,
+		&i.LastFetchStatus,
+		&i.UnfollowedAt,
 	)
 	return i, err
 }
 
-const getNextFeedsToFetch = `-- name: GetNextFeedsToFetch :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds ORDER BY last_fetched_at NULLS FIRST LIMIT $1
+const listActiveFollowerCounts = `-- name: ListActiveFollowerCounts :many
+SELECT feed_follows.feed_id, COUNT(*) AS followers
+FROM feed_follows
+JOIN users ON users.id = feed_follows.user_id
+WHERE users.banned_at IS NULL
+GROUP BY feed_follows.feed_id
+`
+
+type ListActiveFollowerCountsRow struct {
+	FeedID    uuid.UUID
+	Followers int64
+}
+
+func (q *Queries) ListActiveFollowerCounts(ctx context.Context) ([]ListActiveFollowerCountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveFollowerCounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListActiveFollowerCountsRow
+	for rows.Next() {
+		var i ListActiveFollowerCountsRow
+		if err := rows.Scan(&i.FeedID, &i.Followers); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFeeds = `-- name: ListFeeds :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, disabled_at, sensitive, kind, public_id, language, country, etag, last_modified, missed_items_at, gap_interval_seconds, fetch_interval_seconds, fetch_failures, last_fetch_error, retry_at, paused_at, fetch_headers, description, site_url, schedule, quiet_weekdays, credentials, last_fetch_status, unfollowed_at FROM feeds
+WHERE kind = 'remote' AND credentials IS NULL
+AND ($1::uuid IS NULL OR id > $1)
+ORDER BY id
+LIMIT $2
 `
 
-func (q *Queries) GetNextFeedsToFetch(ctx context.Context, limit int32) ([]Feed, error) {
-	rows, err := q.db.QueryContext(ctx, getNextFeedsToFetch, limit)
+type ListFeedsParams struct {
+	AfterID uuid.NullUUID
+	Limit   sql.NullInt32
+}
+
+func (q *Queries) ListFeeds(ctx context.Context, arg ListFeedsParams) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, listFeeds, arg.AfterID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -71,6 +454,29 @@ func (q *Queries) GetNextFeedsToFetch(ctx context.Context, limit int32) ([]Feed,
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.DisabledAt,
+			&i.Sensitive,
+			&i.Kind,
+			&i.PublicID,
+			&i.Language,
+			&i.Country,
+			&i.Etag,
+			&i.LastModified,
+			&i.MissedItemsAt,
+			&i.GapIntervalSeconds,
+			&i.FetchIntervalSeconds,
+			&i.FetchFailures,
+			&i.LastFetchError,
+			&i.RetryAt,
+			&i.PausedAt,
+			&i.FetchHeaders,
+			&i.Description,
+			&i.SiteUrl,
+			&i.Schedule,
+			&i.QuietWeekdays,
+			&i.Credentials,
+			&i.LastFetchStatus,
+			&i.UnfollowedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -85,12 +491,82 @@ func (q *Queries) GetNextFeedsToFetch(ctx context.Context, limit int32) ([]Feed,
 	return items, nil
 }
 
-const listFeeds = `-- name: ListFeeds :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds ORDER BY id
+const listFeedsByLanguage = `-- name: ListFeedsByLanguage :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, disabled_at, sensitive, kind, public_id, language, country, etag, last_modified, missed_items_at, gap_interval_seconds, fetch_interval_seconds, fetch_failures, last_fetch_error, retry_at, paused_at, fetch_headers, description, site_url, schedule, quiet_weekdays, credentials, last_fetch_status, unfollowed_at FROM feeds
+WHERE kind = 'remote' AND credentials IS NULL AND language = ANY($1::text[])
+AND ($2::uuid IS NULL OR id > $2)
+ORDER BY id
+LIMIT $3
+`
+
+type ListFeedsByLanguageParams struct {
+	Languages []string
+	AfterID   uuid.NullUUID
+	Limit     sql.NullInt32
+}
+
+func (q *Queries) ListFeedsByLanguage(ctx context.Context, arg ListFeedsByLanguageParams) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, listFeedsByLanguage, pq.Array(arg.Languages), arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.DisabledAt,
+			&i.Sensitive,
+			&i.Kind,
+			&i.PublicID,
+			&i.Language,
+			&i.Country,
+			&i.Etag,
+			&i.LastModified,
+			&i.MissedItemsAt,
+			&i.GapIntervalSeconds,
+			&i.FetchIntervalSeconds,
+			&i.FetchFailures,
+			&i.LastFetchError,
+			&i.RetryAt,
+			&i.PausedAt,
+			&i.FetchHeaders,
+			&i.Description,
+			&i.SiteUrl,
+			&i.Schedule,
+			&i.QuietWeekdays,
+			&i.Credentials,
+			&i.LastFetchStatus,
+			&i.UnfollowedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFeedsPausedSince = `-- name: ListFeedsPausedSince :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, disabled_at, sensitive, kind, public_id, language, country, etag, last_modified, missed_items_at, gap_interval_seconds, fetch_interval_seconds, fetch_failures, last_fetch_error, retry_at, paused_at, fetch_headers, description, site_url, schedule, quiet_weekdays, credentials, last_fetch_status, unfollowed_at FROM feeds
+WHERE paused_at > $1::timestamp
+ORDER BY paused_at
 `
 
-func (q *Queries) ListFeeds(ctx context.Context) ([]Feed, error) {
-	rows, err := q.db.QueryContext(ctx, listFeeds)
+func (q *Queries) ListFeedsPausedSince(ctx context.Context, since time.Time) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, listFeedsPausedSince, since)
 	if err != nil {
 		return nil, err
 	}
@@ -106,6 +582,130 @@ func (q *Queries) ListFeeds(ctx context.Context) ([]Feed, error) {
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.DisabledAt,
+			&i.Sensitive,
+			&i.Kind,
+			&i.PublicID,
+			&i.Language,
+			&i.Country,
+			&i.Etag,
+			&i.LastModified,
+			&i.MissedItemsAt,
+			&i.GapIntervalSeconds,
+			&i.FetchIntervalSeconds,
+			&i.FetchFailures,
+			&i.LastFetchError,
+			&i.RetryAt,
+			&i.PausedAt,
+			&i.FetchHeaders,
+			&i.Description,
+			&i.SiteUrl,
+			&i.Schedule,
+			&i.QuietWeekdays,
+			&i.Credentials,
+			&i.LastFetchStatus,
+			&i.UnfollowedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFollowFetchIntervals = `-- name: ListFollowFetchIntervals :many
+SELECT feed_id, MIN(CASE priority
+  WHEN 'high' THEN 300
+  WHEN 'low' THEN 3600
+  ELSE 900
+END)::int AS interval_seconds
+FROM feed_follows
+GROUP BY feed_id
+`
+
+type ListFollowFetchIntervalsRow struct {
+	FeedID          uuid.UUID
+	IntervalSeconds int32
+}
+
+func (q *Queries) ListFollowFetchIntervals(ctx context.Context) ([]ListFollowFetchIntervalsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listFollowFetchIntervals)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFollowFetchIntervalsRow
+	for rows.Next() {
+		var i ListFollowFetchIntervalsRow
+		if err := rows.Scan(&i.FeedID, &i.IntervalSeconds); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSchedulableFeeds = `-- name: ListSchedulableFeeds :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, disabled_at, sensitive, kind, public_id, language, country, etag, last_modified, missed_items_at, gap_interval_seconds, fetch_interval_seconds, fetch_failures, last_fetch_error, retry_at, paused_at, fetch_headers, description, site_url, schedule, quiet_weekdays, credentials, last_fetch_status, unfollowed_at FROM feeds
+WHERE kind = 'remote'
+AND disabled_at IS NULL
+AND paused_at IS NULL
+AND (retry_at IS NULL OR retry_at <= $1::timestamp)
+ORDER BY last_fetched_at NULLS FIRST
+`
+
+func (q *Queries) ListSchedulableFeeds(ctx context.Context, now time.Time) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, listSchedulableFeeds, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.DisabledAt,
+			&i.Sensitive,
+			&i.Kind,
+			&i.PublicID,
+			&i.Language,
+			&i.Country,
+			&i.Etag,
+			&i.LastModified,
+			&i.MissedItemsAt,
+			&i.GapIntervalSeconds,
+			&i.FetchIntervalSeconds,
+			&i.FetchFailures,
+			&i.LastFetchError,
+			&i.RetryAt,
+			&i.PausedAt,
+			&i.FetchHeaders,
+			&i.Description,
+			&i.SiteUrl,
+			&i.Schedule,
+			&i.QuietWeekdays,
+			&i.Credentials,
+			&i.LastFetchStatus,
+			&i.UnfollowedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -133,3 +733,447 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams
 	_, err := q.db.ExecContext(ctx, markFeedFetched, arg.LastFetchedAt, arg.ID)
 	return err
 }
+
+const recordFeedFetchFailure = `-- name: RecordFeedFetchFailure :exec
+UPDATE feeds SET fetch_failures = $2, last_fetch_error = $3, retry_at = $4, paused_at = $5
+WHERE id = $1
+`
+
+type RecordFeedFetchFailureParams struct {
+	ID             uuid.UUID
+	FetchFailures  int32
+	LastFetchError string
+	RetryAt        sql.NullTime
+	PausedAt       sql.NullTime
+}
+
+func (q *Queries) RecordFeedFetchFailure(ctx context.Context, arg RecordFeedFetchFailureParams) error {
+	_, err := q.db.ExecContext(ctx, recordFeedFetchFailure,
+		arg.ID,
+		arg.FetchFailures,
+		arg.LastFetchError,
+		arg.RetryAt,
+		arg.PausedAt,
+	)
+	return err
+}
+
+const recordFeedGap = `-- name: RecordFeedGap :exec
+UPDATE feeds SET missed_items_at = $2, gap_interval_seconds = $3 WHERE id = $1
+`
+
+type RecordFeedGapParams struct {
+	ID                 uuid.UUID
+	MissedItemsAt      sql.NullTime
+	GapIntervalSeconds int32
+}
+
+func (q *Queries) RecordFeedGap(ctx context.Context, arg RecordFeedGapParams) error {
+	_, err := q.db.ExecContext(ctx, recordFeedGap, arg.ID, arg.MissedItemsAt, arg.GapIntervalSeconds)
+	return err
+}
+
+const resumeFeed = `-- name: ResumeFeed :one
+UPDATE feeds
+SET paused_at = NULL, fetch_failures = 0, last_fetch_error = '', retry_at = NULL, updated_at = $2
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, disabled_at, sensitive, kind, public_id, language, country, etag, last_modified, missed_items_at, gap_interval_seconds, fetch_interval_seconds, fetch_failures, last_fetch_error, retry_at, paused_at, fetch_headers, description, site_url, schedule, quiet_weekdays, credentials, last_fetch_status, unfollowed_at
+`
+
+type ResumeFeedParams struct {
+	ID        uuid.UUID
+	UpdatedAt time.Time
+}
+
+func (q *Queries) ResumeFeed(ctx context.Context, arg ResumeFeedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, resumeFeed, arg.ID, arg.UpdatedAt)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.DisabledAt,
+		&i.Sensitive,
+		&i.Kind,
+		&i.PublicID,
+		&i.Language,
+		&i.Country,
+		&i.Etag,
+		&i.LastModified,
+		&i.MissedItemsAt,
+		&i.GapIntervalSeconds,
+		&i.FetchIntervalSeconds,
+		&i.FetchFailures,
+		&i.LastFetchError,
+		&i.RetryAt,
+		&i.PausedAt,
+		&i.FetchHeaders,
+		&i.Description,
+		&i.SiteUrl,
+		&i.Schedule,
+		&i.QuietWeekdays,
+		&i.Credentials,
+		&i.LastFetchStatus,
+		&i.UnfollowedAt,
+	)
+	return i, err
+}
+
+const setFeedCacheValidators = `-- name: SetFeedCacheValidators :exec
+UPDATE feeds SET etag = $2, last_modified = $3 WHERE id = $1
+`
+
+type SetFeedCacheValidatorsParams struct {
+	ID           uuid.UUID
+	Etag         string
+	LastModified string
+}
+
+func (q *Queries) SetFeedCacheValidators(ctx context.Context, arg SetFeedCacheValidatorsParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedCacheValidators, arg.ID, arg.Etag, arg.LastModified)
+	return err
+}
+
+const setFeedCredentials = `-- name: SetFeedCredentials :one
+UPDATE feeds SET credentials = $2, updated_at = $3 WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, disabled_at, sensitive, kind, public_id, language, country, etag, last_modified, missed_items_at, gap_interval_seconds, fetch_interval_seconds, fetch_failures, last_fetch_error, retry_at, paused_at, fetch_headers, description, site_url, schedule, quiet_weekdays, credentials, last_fetch_status, unfollowed_at
+`
+
+type SetFeedCredentialsParams struct {
+	ID          uuid.UUID
+	Credentials []byte
+	UpdatedAt   time.Time
+}
+
+func (q *Queries) SetFeedCredentials(ctx context.Context, arg SetFeedCredentialsParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, setFeedCredentials, arg.ID, arg.Credentials, arg.UpdatedAt)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.DisabledAt,
+		&i.Sensitive,
+		&i.Kind,
+		&i.PublicID,
+		&i.Language,
+		&i.Country,
+		&i.Etag,
+		&i.LastModified,
+		&i.MissedItemsAt,
+		&i.GapIntervalSeconds,
+		&i.FetchIntervalSeconds,
+		&i.FetchFailures,
+		&i.LastFetchError,
+		&i.RetryAt,
+		&i.PausedAt,
+		&i.FetchHeaders,
+		&i.Description,
+		&i.SiteUrl,
+		&i.Schedule,
+		&i.QuietWeekdays,
+		&i.Credentials,
+		&i.LastFetchStatus,
+		&i.UnfollowedAt,
+	)
+	return i, err
+}
+
+const setFeedDisabled = `-- name: SetFeedDisabled :exec
+UPDATE feeds SET disabled_at = $2, updated_at = $3 WHERE id = $1
+`
+
+type SetFeedDisabledParams struct {
+	ID         uuid.UUID
+	DisabledAt sql.NullTime
+	UpdatedAt  time.Time
+}
+
+func (q *Queries) SetFeedDisabled(ctx context.Context, arg SetFeedDisabledParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedDisabled, arg.ID, arg.DisabledAt, arg.UpdatedAt)
+	return err
+}
+
+const setFeedFetchHeaders = `-- name: SetFeedFetchHeaders :one
+UPDATE feeds SET fetch_headers = $2, updated_at = $3 WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, disabled_at, sensitive, kind, public_id, language, country, etag, last_modified, missed_items_at, gap_interval_seconds, fetch_interval_seconds, fetch_failures, last_fetch_error, retry_at, paused_at, fetch_headers, description, site_url, schedule, quiet_weekdays, credentials, last_fetch_status, unfollowed_at
+`
+
+type SetFeedFetchHeadersParams struct {
+	ID           uuid.UUID
+	FetchHeaders json.RawMessage
+	UpdatedAt    time.Time
+}
+
+func (q *Queries) SetFeedFetchHeaders(ctx context.Context, arg SetFeedFetchHeadersParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, setFeedFetchHeaders, arg.ID, arg.FetchHeaders, arg.UpdatedAt)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.DisabledAt,
+		&i.Sensitive,
+		&i.Kind,
+		&i.PublicID,
+		&i.Language,
+		&i.Country,
+		&i.Etag,
+		&i.LastModified,
+		&i.MissedItemsAt,
+		&i.GapIntervalSeconds,
+		&i.FetchIntervalSeconds,
+		&i.FetchFailures,
+		&i.LastFetchError,
+		&i.RetryAt,
+		&i.PausedAt,
+		&i.FetchHeaders,
+		&i.Description,
+		&i.SiteUrl,
+		&i.Schedule,
+		&i.QuietWeekdays,
+		&i.Credentials,
+		&i.LastFetchStatus,
+		&i.UnfollowedAt,
+	)
+	return i, err
+}
+
+const setFeedFetchInterval = `-- name: SetFeedFetchInterval :one
+UPDATE feeds SET fetch_interval_seconds = $2, updated_at = $3 WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, disabled_at, sensitive, kind, public_id, language, country, etag, last_modified, missed_items_at, gap_interval_seconds, fetch_interval_seconds, fetch_failures, last_fetch_error, retry_at, paused_at, fetch_headers, description, site_url, schedule, quiet_weekdays, credentials, last_fetch_status, unfollowed_at
+`
+
+type SetFeedFetchIntervalParams struct {
+	ID                   uuid.UUID
+	FetchIntervalSeconds sql.NullInt32
+	UpdatedAt            time.Time
+}
+
+func (q *Queries) SetFeedFetchInterval(ctx context.Context, arg SetFeedFetchIntervalParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, setFeedFetchInterval, arg.ID, arg.FetchIntervalSeconds, arg.UpdatedAt)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.DisabledAt,
+		&i.Sensitive,
+		&i.Kind,
+		&i.PublicID,
+		&i.Language,
+		&i.Country,
+		&i.Etag,
+		&i.LastModified,
+		&i.MissedItemsAt,
+		&i.GapIntervalSeconds,
+		&i.FetchIntervalSeconds,
+		&i.FetchFailures,
+		&i.LastFetchError,
+		&i.RetryAt,
+		&i.PausedAt,
+		&i.FetchHeaders,
+		&i.Description,
+		&i.SiteUrl,
+		&i.Schedule,
+		&i.QuietWeekdays,
+		&i.Credentials,
+		&i.LastFetchStatus,
+		&i.UnfollowedAt,
+	)
+	return i, err
+}
+
+const setFeedLastFetchStatus = `-- name: SetFeedLastFetchStatus :exec
+UPDATE feeds SET last_fetch_status = $2 WHERE id = $1
+`
+
+type SetFeedLastFetchStatusParams struct {
+	ID              uuid.UUID
+	LastFetchStatus sql.NullInt32
+}
+
+func (q *Queries) SetFeedLastFetchStatus(ctx context.Context, arg SetFeedLastFetchStatusParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedLastFetchStatus, arg.ID, arg.LastFetchStatus)
+	return err
+}
+
+const setFeedLocale = `-- name: SetFeedLocale :exec
+UPDATE feeds SET language = $2, country = $3, updated_at = $4
+WHERE id = $1 AND (language <> $2 OR country <> $3)
+`
+
+type SetFeedLocaleParams struct {
+	ID        uuid.UUID
+	Language  string
+	Country   string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetFeedLocale(ctx context.Context, arg SetFeedLocaleParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedLocale,
+		arg.ID,
+		arg.Language,
+		arg.Country,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
+const setFeedQuietWeekdays = `-- name: SetFeedQuietWeekdays :exec
+UPDATE feeds SET quiet_weekdays = $2 WHERE id = $1
+`
+
+type SetFeedQuietWeekdaysParams struct {
+	ID            uuid.UUID
+	QuietWeekdays int32
+}
+
+func (q *Queries) SetFeedQuietWeekdays(ctx context.Context, arg SetFeedQuietWeekdaysParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedQuietWeekdays, arg.ID, arg.QuietWeekdays)
+	return err
+}
+
+const setFeedSchedule = `-- name: SetFeedSchedule :one
+UPDATE feeds SET schedule = $2, updated_at = $3 WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, disabled_at, sensitive, kind, public_id, language, country, etag, last_modified, missed_items_at, gap_interval_seconds, fetch_interval_seconds, fetch_failures, last_fetch_error, retry_at, paused_at, fetch_headers, description, site_url, schedule, quiet_weekdays, credentials, last_fetch_status, unfollowed_at
+`
+
+type SetFeedScheduleParams struct {
+	ID        uuid.UUID
+	Schedule  string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetFeedSchedule(ctx context.Context, arg SetFeedScheduleParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, setFeedSchedule, arg.ID, arg.Schedule, arg.UpdatedAt)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.DisabledAt,
+		&i.Sensitive,
+		&i.Kind,
+		&i.PublicID,
+		&i.Language,
+		&i.Country,
+		&i.Etag,
+		&i.LastModified,
+		&i.MissedItemsAt,
+		&i.GapIntervalSeconds,
+		&i.FetchIntervalSeconds,
+		&i.FetchFailures,
+		&i.LastFetchError,
+		&i.RetryAt,
+		&i.PausedAt,
+		&i.FetchHeaders,
+		&i.Description,
+		&i.SiteUrl,
+		&i.Schedule,
+		&i.QuietWeekdays,
+		&i.Credentials,
+		&i.LastFetchStatus,
+		&i.UnfollowedAt,
+	)
+	return i, err
+}
+
+const setFeedSensitive = `-- name: SetFeedSensitive :one
+UPDATE feeds SET sensitive = $2, updated_at = $3 WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, disabled_at, sensitive, kind, public_id, language, country, etag, last_modified, missed_items_at, gap_interval_seconds, fetch_interval_seconds, fetch_failures, last_fetch_error, retry_at, paused_at, fetch_headers, description, site_url, schedule, quiet_weekdays, credentials, last_fetch_status, unfollowed_at
+`
+
+type SetFeedSensitiveParams struct {
+	ID        uuid.UUID
+	Sensitive bool
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetFeedSensitive(ctx context.Context, arg SetFeedSensitiveParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, setFeedSensitive, arg.ID, arg.Sensitive, arg.UpdatedAt)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.DisabledAt,
+		&i.Sensitive,
+		&i.Kind,
+		&i.PublicID,
+		&i.Language,
+		&i.Country,
+		&i.Etag,
+		&i.LastModified,
+		&i.MissedItemsAt,
+		&i.GapIntervalSeconds,
+		&i.FetchIntervalSeconds,
+		&i.FetchFailures,
+		&i.LastFetchError,
+		&i.RetryAt,
+		&i.PausedAt,
+		&i.FetchHeaders,
+		&i.Description,
+		&i.SiteUrl,
+		&i.Schedule,
+		&i.QuietWeekdays,
+		&i.Credentials,
+		&i.LastFetchStatus,
+		&i.UnfollowedAt,
+	)
+	return i, err
+}
+
+const setFeedUnfollowedAt = `-- name: SetFeedUnfollowedAt :exec
+UPDATE feeds SET unfollowed_at = $2 WHERE id = $1
+`
+
+type SetFeedUnfollowedAtParams struct {
+	ID           uuid.UUID
+	UnfollowedAt sql.NullTime
+}
+
+func (q *Queries) SetFeedUnfollowedAt(ctx context.Context, arg SetFeedUnfollowedAtParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedUnfollowedAt, arg.ID, arg.UnfollowedAt)
+	return err
+}
+
+const updateFeedUrl = `-- name: UpdateFeedUrl :exec
+UPDATE feeds SET url = $2, updated_at = $3
+WHERE id = $1
+`
+
+type UpdateFeedUrlParams struct {
+	ID        uuid.UUID
+	Url       string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) UpdateFeedUrl(ctx context.Context, arg UpdateFeedUrlParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedUrl, arg.ID, arg.Url, arg.UpdatedAt)
+	return err
+}
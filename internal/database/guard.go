@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// A Guard is asked before every query whether the database may be used,
+// and told how each query went, so that callers can stop sending queries
+// to a database that is down instead of waiting on each one.
+type Guard interface {
+	// Allow returns an error, which the query fails with, if the database
+	// should not be used.
+	Allow() error
+	// Record is called with each query's error, nil if it succeeded, and
+	// the context it ran with.
+	Record(ctx context.Context, err error)
+}
+
+type guardedDB struct {
+	db    DBTX
+	guard Guard
+}
+
+func (g guardedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if err := g.guard.Allow(); err != nil {
+		return nil, err
+	}
+	res, err := g.db.ExecContext(ctx, query, args...)
+	g.guard.Record(ctx, err)
+	return res, err
+}
+
+func (g guardedDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	if err := g.guard.Allow(); err != nil {
+		return nil, err
+	}
+	stmt, err := g.db.PrepareContext(ctx, query)
+	g.guard.Record(ctx, err)
+	return stmt, err
+}
+
+func (g guardedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if err := g.guard.Allow(); err != nil {
+		return nil, err
+	}
+	rows, err := g.db.QueryContext(ctx, query, args...)
+	g.guard.Record(ctx, err)
+	return rows, err
+}
+
+func (g guardedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if err := g.guard.Allow(); err != nil {
+		// A *sql.Row can't be made with an error of our choosing, so the
+		// query is run with a cancelled context, which fails before it
+		// reaches the database.
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		return g.db.QueryRowContext(ctx, query, args...)
+	}
+	row := g.db.QueryRowContext(ctx, query, args...)
+	g.guard.Record(ctx, row.Err())
+	return row
+}
+
+// NewGuardedStore returns the Postgres-backed Store over an open database,
+// with every query, in transactions too, going through guard.
+func NewGuardedStore(db *sql.DB, guard Guard) Store {
+	return &sqlStore{Queries: New(guardedDB{db: db, guard: guard}), db: db, guard: guard}
+}
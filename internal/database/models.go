@@ -6,44 +6,291 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type AuditLog struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	ActorID    uuid.NullUUID
+	Action     string
+	TargetType string
+	TargetID   uuid.UUID
+	Details    json.RawMessage
+}
+
+type AuthorMute struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	Author    string
+}
+
+type DomainRule struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	Domain    string
+	Kind      string
+}
+
 type Feed struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Name                 string
+	Url                  string
+	UserID               uuid.UUID
+	LastFetchedAt        sql.NullTime
+	DisabledAt           sql.NullTime
+	Sensitive            bool
+	Kind                 string
+	PublicID             string
+	Language             string
+	Country              string
+	Etag                 string
+	LastModified         string
+	MissedItemsAt        sql.NullTime
+	GapIntervalSeconds   int32
+	FetchIntervalSeconds sql.NullInt32
+	FetchFailures        int32
+	LastFetchError       string
+	RetryAt              sql.NullTime
+	PausedAt             sql.NullTime
+	FetchHeaders         json.RawMessage
+	Description          string
+	SiteUrl              string
+	Schedule             string
+	QuietWeekdays        int32
+	Credentials          []byte
+	LastFetchStatus      sql.NullInt32
+	UnfollowedAt         sql.NullTime
+}
+
+type FeedFetch struct {
+	ID         uuid.UUID
+	FeedID     uuid.UUID
+	FetchedAt  time.Time
+	StatusCode sql.NullInt32
+	DurationMs int32
+	ItemCount  int32
+	Error      string
 }
 
 type FeedFollow struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	UserID          uuid.UUID
+	FeedID          uuid.UUID
+	Priority        string
+	OrderByIngested bool
+}
+
+type FeedUrlHistory struct {
+	ID        uuid.UUID
+	FeedID    uuid.UUID
+	OldUrl    string
+	NewUrl    string
+	ChangedAt time.Time
+}
+
+type FeedIcon struct {
+	FeedID      uuid.UUID
+	SourceUrl   string
+	ContentType string
+	Data        []byte
+	FetchedAt   time.Time
+}
+
+type FeedReport struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	FeedID     uuid.UUID
+	ReporterID uuid.UUID
+	Reason     string
+	Comment    string
+	Status     string
+	ResolvedBy uuid.NullUUID
+	ResolvedAt sql.NullTime
+}
+
+type FeedRepublish struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	UserID          uuid.UUID
+	FeedID          uuid.UUID
+	Token           string
+	IncludeKeywords []string
+	ExcludeKeywords []string
+}
+
+type FetchSnapshot struct {
+	ID          uuid.UUID
+	FeedID      uuid.UUID
+	FetchedAt   time.Time
+	ContentType string
+	Size        int32
+	Truncated   bool
+	Body        []byte
+}
+
+type NotificationChannel struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	UserID    uuid.UUID
-	FeedID    uuid.UUID
+	Kind      string
+	Config    json.RawMessage
 }
 
 type Post struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           string
+	Url             string
+	Description     sql.NullString
+	PublishedAt     sql.NullTime
+	FeedID          uuid.UUID
+	Sensitive       bool
+	PublicID        string
+	Guid            string
+	EnclosureUrl    string
+	EnclosureType   string
+	EnclosureLength int64
+	SourcePostID    uuid.NullUUID
+	Author          string
+	Inbox           bool
+}
+
+type PostEmail struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	Recipient string
+}
+
+type PostExport struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	Destination   string
+	Format        string
+	ExportedAfter time.Time
+	ExportedUntil time.Time
+	PostCount     int32
+	Location      string
+}
+
+type PostRevision struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	PostID    uuid.UUID
+	EditorID  uuid.NullUUID
+	Title     string
+	Url       string
+}
+
+type PostRule struct {
 	ID          uuid.UUID
 	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+	UserID      uuid.UUID
+	FeedID      uuid.NullUUID
+	Action      string
+	Pattern     string
+	Replacement string
 }
 
-type User struct {
+type PostSnooze struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UserID     uuid.UUID
+	PostID     uuid.UUID
+	WakeAt     time.Time
+	Notify     bool
+	NotifiedAt sql.NullTime
+}
+
+type PushSubscription struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	Name      string
-	ApiKey    string
+	UserID    uuid.UUID
+	Endpoint  string
+	P256dh    string
+	Auth      string
+}
+
+type ReadingQueueItem struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	Position  int32
+}
+
+type StarterPack struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Name        string
+	Description string
+}
+
+type StarterPackFeed struct {
+	StarterPackID uuid.UUID
+	FeedID        uuid.UUID
+}
+
+type User struct {
+	ID                 uuid.UUID
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	Name               string
+	ApiKey             string
+	IsAdmin            bool
+	Email              sql.NullString
+	AvatarUrl          sql.NullString
+	Bio                sql.NullString
+	BannedAt           sql.NullTime
+	ShowSensitive      bool
+	PreferredLanguages []string
+}
+
+type UserIdentity struct {
+	Backend   string
+	Subject   string
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type VirtualFeedFilter struct {
+	FeedID          uuid.UUID
+	Dedupe          bool
+	IncludeKeywords []string
+	ExcludeKeywords []string
+}
+
+type VirtualFeedSource struct {
+	VirtualFeedID uuid.UUID
+	SourceFeedID  uuid.UUID
+}
+
+type WebhookDelivery struct {
+	ID           uuid.UUID
+	ChannelID    uuid.UUID
+	DeliveryID   uuid.UUID
+	CreatedAt    time.Time
+	Event        string
+	RequestBody  string
+	StatusCode   int32
+	Error        string
+	DurationMs   int32
+	ResponseBody string
+	Redelivery   bool
 }
@@ -11,12 +11,31 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const countPostsForDeletion = `-- name: CountPostsForDeletion :one
+SELECT COUNT(*) FROM posts
+WHERE ($1::uuid IS NULL OR feed_id = $1)
+AND ($2::timestamp IS NULL OR created_at < $2)
+`
+
+type CountPostsForDeletionParams struct {
+	FeedID uuid.NullUUID
+	Before sql.NullTime
+}
+
+func (q *Queries) CountPostsForDeletion(ctx context.Context, arg CountPostsForDeletionParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPostsForDeletion, arg.FeedID, arg.Before)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, sensitive, guid, inbox)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $5, EXISTS(SELECT 1 FROM feeds WHERE id = $8 AND kind = 'inbox'))
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, sensitive, public_id, guid, enclosure_url, enclosure_type, enclosure_length, source_post_id, author, inbox
 `
 
 type CreatePostParams struct {
@@ -28,6 +47,7 @@ type CreatePostParams struct {
 	Description sql.NullString
 	PublishedAt sql.NullTime
 	FeedID      uuid.UUID
+	Sensitive   bool
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
@@ -40,6 +60,7 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.Sensitive,
 	)
 	var i Post
 	err := row.Scan(
@@ -51,42 +72,253 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.Sensitive,
+		&i.PublicID,
+		&i.Guid,
+		&i.EnclosureUrl,
+		&i.EnclosureType,
+		&i.EnclosureLength,
+		&i.SourcePostID,
+		&i.Author,
+		&i.Inbox,
 	)
 	return i, err
 }
 
+const deletePostsBatch = `-- name: DeletePostsBatch :execrows
+DELETE FROM posts
+WHERE id IN (
+  SELECT id FROM posts
+  WHERE ($1::uuid IS NULL OR feed_id = $1)
+  AND ($2::timestamp IS NULL OR created_at < $2)
+  LIMIT $3
+)
+`
+
+type DeletePostsBatchParams struct {
+	FeedID uuid.NullUUID
+	Before sql.NullTime
+	Limit  int32
+}
+
+func (q *Queries) DeletePostsBatch(ctx context.Context, arg DeletePostsBatchParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deletePostsBatch, arg.FeedID, arg.Before, arg.Limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getPost = `-- name: GetPost :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, sensitive, public_id, guid, enclosure_url, enclosure_type, enclosure_length, source_post_id, author, inbox FROM posts WHERE id = $1
+`
+
+func (q *Queries) GetPost(ctx context.Context, id uuid.UUID) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPost, id)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Sensitive,
+		&i.PublicID,
+		&i.Guid,
+		&i.EnclosureUrl,
+		&i.EnclosureType,
+		&i.EnclosureLength,
+		&i.SourcePostID,
+		&i.Author,
+		&i.Inbox,
+	)
+	return i, err
+}
+
+const getPostByPublicID = `-- name: GetPostByPublicID :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, sensitive, public_id, guid, enclosure_url, enclosure_type, enclosure_length, source_post_id, author, inbox FROM posts WHERE public_id = $1
+`
+
+func (q *Queries) GetPostByPublicID(ctx context.Context, publicID string) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByPublicID, publicID)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Sensitive,
+		&i.PublicID,
+		&i.Guid,
+		&i.EnclosureUrl,
+		&i.EnclosureType,
+		&i.EnclosureLength,
+		&i.SourcePostID,
+		&i.Author,
+		&i.Inbox,
+	)
+	return i, err
+}
+
+const getPostsByFeed = `-- name: GetPostsByFeed :many
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, sensitive, public_id, guid, enclosure_url, enclosure_type, enclosure_length, source_post_id, author, inbox FROM posts
+WHERE feed_id = $1
+ORDER BY created_at DESC
+LIMIT $2
+`
+
+type GetPostsByFeedParams struct {
+	FeedID uuid.UUID
+	Limit  int32
+}
+
+func (q *Queries) GetPostsByFeed(ctx context.Context, arg GetPostsByFeedParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsByFeed, arg.FeedID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Sensitive,
+			&i.PublicID,
+			&i.Guid,
+			&i.EnclosureUrl,
+			&i.EnclosureType,
+			&i.EnclosureLength,
+			&i.SourcePostID,
+			&i.Author,
+			&i.Inbox,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getPostsByUser = `-- name: GetPostsByUser :many
-SELECT posts.id, posts.created_at, posts.updated_at, title, url, description, published_at, posts.feed_id, feed_follows.id, feed_follows.created_at, feed_follows.updated_at, user_id, feed_follows.feed_id FROM posts
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.sensitive, posts.public_id, posts.guid, posts.enclosure_url, posts.enclosure_type, posts.enclosure_length, posts.source_post_id, posts.author, posts.inbox, feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feed_follows.priority, feed_follows.order_by_ingested, feeds.sensitive AS feed_sensitive FROM posts
 INNER JOIN feed_follows
 ON posts.feed_id = feed_follows.feed_id
-WHERE feed_follows.user_id = $1
-ORDER BY posts.updated_at NULLS LAST
-LIMIT $2
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+CROSS JOIN LATERAL (
+  SELECT CASE $1::text
+    WHEN 'ingested_at' THEN posts.created_at
+    WHEN 'published_at' THEN CASE
+      WHEN feed_follows.order_by_ingested THEN posts.created_at
+      ELSE COALESCE(posts.published_at, posts.created_at)
+    END
+    ELSE posts.updated_at
+  END AS sort_at
+) AS sort
+WHERE feed_follows.user_id = $2
+AND posts.created_at <= $3::timestamp
+AND feed_follows.created_at <= $3::timestamp
+AND NOT EXISTS (
+  SELECT 1 FROM post_snoozes
+  WHERE post_snoozes.post_id = posts.id
+  AND post_snoozes.user_id = feed_follows.user_id
+  AND post_snoozes.created_at <= $3::timestamp
+  AND post_snoozes.wake_at > $3::timestamp
+)
+AND NOT EXISTS (
+  SELECT 1 FROM author_mutes
+  WHERE author_mutes.user_id = feed_follows.user_id
+  AND lower(author_mutes.author) = lower(posts.author)
+  AND author_mutes.created_at <= $3::timestamp
+)
+AND ($4::timestamp IS NULL OR sort.sort_at > $4)
+AND ($5::timestamp IS NULL OR sort.sort_at <= $5)
+AND ($6::timestamp IS NULL OR CASE WHEN $7::boolean
+  THEN (sort.sort_at, posts.id) < ($6, $8::uuid)
+  ELSE (sort.sort_at, posts.id) > ($6, $8::uuid)
+END)
+AND (COALESCE(cardinality($9::uuid[]), 0) = 0 OR posts.feed_id = ANY($9::uuid[]))
+ORDER BY CASE WHEN $7::boolean THEN sort.sort_at END DESC,
+CASE WHEN $7::boolean THEN posts.id END DESC,
+sort.sort_at, posts.id
+LIMIT $10
 `
 
 type GetPostsByUserParams struct {
-	UserID uuid.UUID
-	Limit  int32
+	OrderBy  string
+	UserID   uuid.UUID
+	AsOf     time.Time
+	Since    sql.NullTime
+	Until    sql.NullTime
+	AfterAt  sql.NullTime
+	SortDesc bool
+	AfterID  uuid.NullUUID
+	FeedIds  []uuid.UUID
+	Limit    int32
 }
 
 type GetPostsByUserRow struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
-	ID_2        uuid.UUID
-	CreatedAt_2 time.Time
-	UpdatedAt_2 time.Time
-	UserID      uuid.UUID
-	FeedID_2    uuid.UUID
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           string
+	Url             string
+	Description     sql.NullString
+	PublishedAt     sql.NullTime
+	FeedID          uuid.UUID
+	Sensitive       bool
+	PublicID        string
+	Guid            string
+	EnclosureUrl    string
+	EnclosureType   string
+	EnclosureLength int64
+	SourcePostID    uuid.NullUUID
+	Author          string
+	Inbox           bool
+	ID_2            uuid.UUID
+	CreatedAt_2     time.Time
+	UpdatedAt_2     time.Time
+	UserID          uuid.UUID
+	FeedID_2        uuid.UUID
+	Priority        string
+	OrderByIngested bool
+	FeedSensitive   bool
 }
 
 func (q *Queries) GetPostsByUser(ctx context.Context, arg GetPostsByUserParams) ([]GetPostsByUserRow, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsByUser, arg.UserID, arg.Limit)
+	rows, err := q.db.QueryContext(ctx, getPostsByUser,
+		arg.OrderBy,
+		arg.UserID,
+		arg.AsOf,
+		arg.Since,
+		arg.Until,
+		arg.AfterAt,
+		arg.SortDesc,
+		arg.AfterID,
+		pq.Array(arg.FeedIds),
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -103,11 +335,80 @@ func (q *Queries) GetPostsByUser(ctx context.Context, arg GetPostsByUserParams)
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Sensitive,
+			&i.PublicID,
+			&i.Guid,
+			&i.EnclosureUrl,
+			&i.EnclosureType,
+			&i.EnclosureLength,
+			&i.SourcePostID,
+			&i.Author,
+			&i.Inbox,
 			&i.ID_2,
 			&i.CreatedAt_2,
 			&i.UpdatedAt_2,
 			&i.UserID,
 			&i.FeedID_2,
+			&i.Priority,
+			&i.OrderByIngested,
+			&i.FeedSensitive,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecentPostsByUser = `-- name: GetRecentPostsByUser :many
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, sensitive, public_id, guid, enclosure_url, enclosure_type, enclosure_length, source_post_id, author, inbox FROM posts
+WHERE feed_id IN (
+  SELECT feed_follows.feed_id FROM feed_follows WHERE feed_follows.user_id = $1
+)
+AND ($2::uuid IS NULL OR feed_id = $2)
+ORDER BY created_at DESC
+LIMIT $3
+`
+
+type GetRecentPostsByUserParams struct {
+	UserID uuid.UUID
+	FeedID uuid.NullUUID
+	Limit  int32
+}
+
+func (q *Queries) GetRecentPostsByUser(ctx context.Context, arg GetRecentPostsByUserParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getRecentPostsByUser, arg.UserID, arg.FeedID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Sensitive,
+			&i.PublicID,
+			&i.Guid,
+			&i.EnclosureUrl,
+			&i.EnclosureType,
+			&i.EnclosureLength,
+			&i.SourcePostID,
+			&i.Author,
+			&i.Inbox,
 		); err != nil {
 			return nil, err
 		}
@@ -121,3 +422,135 @@ func (q *Queries) GetPostsByUser(ctx context.Context, arg GetPostsByUserParams)
 	}
 	return items, nil
 }
+
+const postUrlExists = `-- name: PostUrlExists :one
+SELECT EXISTS(SELECT 1 FROM posts WHERE url = $1 AND source_post_id IS NULL AND NOT inbox)
+`
+
+func (q *Queries) PostUrlExists(ctx context.Context, url string) (bool, error) {
+	row := q.db.QueryRowContext(ctx, postUrlExists, url)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const updatePostMetadata = `-- name: UpdatePostMetadata :one
+UPDATE posts SET title = $2, url = $3, sensitive = $4, updated_at = $5
+WHERE id = $1
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, sensitive, public_id, guid, enclosure_url, enclosure_type, enclosure_length, source_post_id, author, inbox
+`
+
+type UpdatePostMetadataParams struct {
+	ID        uuid.UUID
+	Title     string
+	Url       string
+	Sensitive bool
+	UpdatedAt time.Time
+}
+
+func (q *Queries) UpdatePostMetadata(ctx context.Context, arg UpdatePostMetadataParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, updatePostMetadata,
+		arg.ID,
+		arg.Title,
+		arg.Url,
+		arg.Sensitive,
+		arg.UpdatedAt,
+	)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Sensitive,
+		&i.PublicID,
+		&i.Guid,
+		&i.EnclosureUrl,
+		&i.EnclosureType,
+		&i.EnclosureLength,
+		&i.SourcePostID,
+		&i.Author,
+		&i.Inbox,
+	)
+	return i, err
+}
+
+const upsertPost = `-- name: UpsertPost :one
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, sensitive, guid, enclosure_url, enclosure_type, enclosure_length, author)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+ON CONFLICT (feed_id, guid) DO UPDATE
+SET title = EXCLUDED.title,
+  url = EXCLUDED.url,
+  description = EXCLUDED.description,
+  published_at = EXCLUDED.published_at,
+  enclosure_url = EXCLUDED.enclosure_url,
+  enclosure_type = EXCLUDED.enclosure_type,
+  enclosure_length = EXCLUDED.enclosure_length,
+  author = EXCLUDED.author,
+  updated_at = EXCLUDED.updated_at
+WHERE (posts.title, posts.url, posts.description, posts.published_at, posts.enclosure_url, posts.enclosure_type, posts.enclosure_length, posts.author)
+  IS DISTINCT FROM (EXCLUDED.title, EXCLUDED.url, EXCLUDED.description, EXCLUDED.published_at, EXCLUDED.enclosure_url, EXCLUDED.enclosure_type, EXCLUDED.enclosure_length, EXCLUDED.author)
+AND NOT EXISTS (SELECT 1 FROM post_revisions WHERE post_revisions.post_id = posts.id)
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, sensitive, public_id, guid, enclosure_url, enclosure_type, enclosure_length, source_post_id, author, inbox
+`
+
+type UpsertPostParams struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           string
+	Url             string
+	Description     sql.NullString
+	PublishedAt     sql.NullTime
+	FeedID          uuid.UUID
+	Sensitive       bool
+	Guid            string
+	EnclosureUrl    string
+	EnclosureType   string
+	EnclosureLength int64
+	Author          string
+}
+
+func (q *Queries) UpsertPost(ctx context.Context, arg UpsertPostParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, upsertPost,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Title,
+		arg.Url,
+		arg.Description,
+		arg.PublishedAt,
+		arg.FeedID,
+		arg.Sensitive,
+		arg.Guid,
+		arg.EnclosureUrl,
+		arg.EnclosureType,
+		arg.EnclosureLength,
+		arg.Author,
+	)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Sensitive,
+		&i.PublicID,
+		&i.Guid,
+		&i.EnclosureUrl,
+		&i.EnclosureType,
+		&i.EnclosureLength,
+		&i.SourcePostID,
+		&i.Author,
+		&i.Inbox,
+	)
+	return i, err
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Store is the persistence boundary used by the HTTP handlers and the feed
+// fetcher. Querier, generated by sqlc from sql/queries, lists every
+// operation; its SQL is the reference for their behaviour. An alternative
+// backend must honour the same contract:
+//
+//   - :one methods return sql.ErrNoRows when nothing matches, and :exec
+//     methods that match nothing succeed silently.
+//   - Unique constraints from sql/schema (feed URLs, post URLs, user names
+//     and emails, ...) are enforced; violations surface as an error that the
+//     caller can recognise, for Postgres a *pq.Error with code 23505.
+//   - :many methods return rows in the ORDER BY of the query, and nil rather
+//     than an empty slice when there are none.
+//   - Begin starts a transaction. Writes through the returned Tx are only
+//     visible to other callers after Commit, and Rollback after Commit is a
+//     no-op, so it is always safe to defer.
+//
+// Package storetest checks a backend against this contract.
+type Store interface {
+	Querier
+	Begin(ctx context.Context) (Tx, error)
+}
+
+// Tx is a Querier bound to one transaction.
+type Tx interface {
+	Querier
+	Commit() error
+	Rollback() error
+}
+
+type sqlStore struct {
+	*Queries
+	db    *sql.DB
+	guard Guard
+}
+
+type sqlTx struct {
+	*Queries
+	tx *sql.Tx
+}
+
+// NewStore returns the Postgres-backed Store over an open database.
+func NewStore(db *sql.DB) Store {
+	return &sqlStore{Queries: New(db), db: db}
+}
+
+func (s *sqlStore) Begin(ctx context.Context) (Tx, error) {
+	if s.guard == nil {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &sqlTx{Queries: s.WithTx(tx), tx: tx}, nil
+	}
+	if err := s.guard.Allow(); err != nil {
+		return nil, err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	s.guard.Record(ctx, err)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlTx{Queries: New(guardedDB{db: tx, guard: s.guard}), tx: tx}, nil
+}
+
+func (t *sqlTx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *sqlTx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+// Stats reports the connection pool, for diagnostics.
+func (s *sqlStore) Stats() sql.DBStats {
+	return s.db.Stats()
+}
@@ -0,0 +1,30 @@
+package database_test
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+	"github.com/pmwals09/rss-aggregator/internal/database/storetest"
+)
+
+// TestStore runs the contract suite against the Postgres database at
+// TEST_DB_URL, which must be migrated to the latest schema. It is skipped
+// when TEST_DB_URL is not set.
+func TestStore(t *testing.T) {
+	dbURL := os.Getenv("TEST_DB_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DB_URL is not set")
+	}
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	storetest.Run(t, func(t *testing.T) database.Store { return database.NewStore(db) })
+}
@@ -0,0 +1,308 @@
+// Package storetest checks that a database.Store honours the contract
+// documented on it. Each backend runs the same suite from its own tests:
+//
+//	func TestStore(t *testing.T) {
+//		storetest.Run(t, func(t *testing.T) database.Store { return memstore.New() })
+//	}
+//
+// The suite only creates rows with fresh IDs and names, and deletes the users
+// it creates, so it can run against a shared database.
+package storetest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// Run runs the contract suite, calling open for a store for each test.
+func Run(t *testing.T, open func(t *testing.T) database.Store) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s database.Store)
+	}{
+		{"NoRows", testNoRows},
+		{"EmptyMany", testEmptyMany},
+		{"UniqueUserName", testUniqueUserName},
+		{"UniqueFeedURL", testUniqueFeedURL},
+		{"UniquePostURL", testUniquePostURL},
+		{"PostOrder", testPostOrder},
+		{"DeleteUserCascades", testDeleteUserCascades},
+		{"TxCommit", testTxCommit},
+		{"TxRollback", testTxRollback},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, open(t))
+		})
+	}
+}
+
+// now is truncated to what a Postgres TIMESTAMP keeps, so values read back
+// compare equal to those written.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func createUser(t *testing.T, q database.Querier) database.User {
+	t.Helper()
+	ctx := context.Background()
+	u, err := q.CreateUser(ctx, database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: now(),
+		UpdatedAt: now(),
+		Name:      "storetest-" + uuid.NewString(),
+	})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	return u
+}
+
+// cleanupUser deletes u, and with it everything it owns, when the test
+// ends.
+func cleanupUser(t *testing.T, s database.Store, u database.User) {
+	t.Cleanup(func() {
+		err := s.DeleteUser(context.Background(), u.ID)
+		if err != nil {
+			t.Errorf("DeleteUser: %v", err)
+		}
+	})
+}
+
+func createFeed(t *testing.T, q database.Querier, u database.User) database.Feed {
+	t.Helper()
+	f, err := q.CreateFeed(context.Background(), database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: now(),
+		UpdatedAt: now(),
+		Name:      "Feed",
+		Url:       "https://storetest.example/" + uuid.NewString() + "/feed.xml",
+		UserID:    u.ID,
+	})
+	if err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	return f
+}
+
+func postParams(f database.Feed, url string, createdAt time.Time) database.CreatePostParams {
+	return database.CreatePostParams{
+		ID:        uuid.New(),
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+		Title:     "Post",
+		Url:       url,
+		FeedID:    f.ID,
+	}
+}
+
+func testNoRows(t *testing.T, s database.Store) {
+	ctx := context.Background()
+	_, err := s.GetUser(ctx, uuid.New())
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUser of unknown ID: got %v, want sql.ErrNoRows", err)
+	}
+	_, err = s.GetFeedByUrl(ctx, "https://storetest.example/"+uuid.NewString())
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetFeedByUrl of unknown URL: got %v, want sql.ErrNoRows", err)
+	}
+	err = s.DeleteUser(ctx, uuid.New())
+	if err != nil {
+		t.Errorf("DeleteUser of unknown ID: got %v, want nil", err)
+	}
+}
+
+func testEmptyMany(t *testing.T, s database.Store) {
+	u := createUser(t, s)
+	cleanupUser(t, s, u)
+	follows, err := s.GetUserFeedFollows(context.Background(), u.ID)
+	if err != nil {
+		t.Fatalf("GetUserFeedFollows: %v", err)
+	}
+	if follows != nil {
+		t.Errorf("GetUserFeedFollows with none: got %#v, want nil", follows)
+	}
+}
+
+func testUniqueUserName(t *testing.T, s database.Store) {
+	u := createUser(t, s)
+	cleanupUser(t, s, u)
+	_, err := s.CreateUser(context.Background(), database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: now(),
+		UpdatedAt: now(),
+		Name:      u.Name,
+	})
+	if !isUniqueViolation(err) {
+		t.Errorf("CreateUser with a taken name: got %v, want a unique violation", err)
+	}
+}
+
+func testUniqueFeedURL(t *testing.T, s database.Store) {
+	u := createUser(t, s)
+	cleanupUser(t, s, u)
+	f := createFeed(t, s, u)
+	got, err := s.GetFeedByUrl(context.Background(), f.Url)
+	if err != nil || got.ID != f.ID {
+		t.Fatalf("GetFeedByUrl: got %v, %v; want feed %s", got.ID, err, f.ID)
+	}
+	_, err = s.CreateFeed(context.Background(), database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: now(),
+		UpdatedAt: now(),
+		Name:      "Duplicate",
+		Url:       f.Url,
+		UserID:    u.ID,
+	})
+	if !isUniqueViolation(err) {
+		t.Errorf("CreateFeed with a taken URL: got %v, want a unique violation", err)
+	}
+}
+
+// testUniquePostURL checks that post URLs are unique across feeds, except
+// for links saved to an inbox.
+func testUniquePostURL(t *testing.T, s database.Store) {
+	ctx := context.Background()
+	u := createUser(t, s)
+	cleanupUser(t, s, u)
+	f1 := createFeed(t, s, u)
+	f2 := createFeed(t, s, u)
+	url := "https://storetest.example/" + uuid.NewString()
+	_, err := s.CreatePost(ctx, postParams(f1, url, now()))
+	if err != nil {
+		t.Fatalf("CreatePost: %v", err)
+	}
+	_, err = s.CreatePost(ctx, postParams(f2, url, now()))
+	if !isUniqueViolation(err) {
+		t.Errorf("CreatePost with a taken URL: got %v, want a unique violation", err)
+	}
+
+	inbox, err := s.CreateInboxFeed(ctx, database.CreateInboxFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: now(),
+		UpdatedAt: now(),
+		Name:      "Inbox",
+		Url:       "inbox:" + u.ID.String(),
+		UserID:    u.ID,
+	})
+	if err != nil {
+		t.Fatalf("CreateInboxFeed: %v", err)
+	}
+	saved, err := s.CreatePost(ctx, postParams(inbox, url, now()))
+	if err != nil {
+		t.Fatalf("CreatePost in an inbox with a feed's URL: %v", err)
+	}
+	if !saved.Inbox {
+		t.Errorf("CreatePost in an inbox: Inbox is false")
+	}
+	_, err = s.CreatePost(ctx, postParams(inbox, url, now()))
+	if !isUniqueViolation(err) {
+		t.Errorf("CreatePost saving a link to an inbox twice: got %v, want a unique violation", err)
+	}
+}
+
+func testPostOrder(t *testing.T, s database.Store) {
+	ctx := context.Background()
+	u := createUser(t, s)
+	cleanupUser(t, s, u)
+	f := createFeed(t, s, u)
+	start := now()
+	ids := []uuid.UUID{}
+	for i := 0; i < 3; i++ {
+		p, err := s.CreatePost(ctx, postParams(f, "https://storetest.example/"+uuid.NewString(), start.Add(time.Duration(i)*time.Minute)))
+		if err != nil {
+			t.Fatalf("CreatePost: %v", err)
+		}
+		ids = append(ids, p.ID)
+	}
+	posts, err := s.GetPostsByFeed(ctx, database.GetPostsByFeedParams{FeedID: f.ID, Limit: 2})
+	if err != nil {
+		t.Fatalf("GetPostsByFeed: %v", err)
+	}
+	if len(posts) != 2 || posts[0].ID != ids[2] || posts[1].ID != ids[1] {
+		t.Errorf("GetPostsByFeed: want the two newest posts, newest first")
+	}
+}
+
+func testDeleteUserCascades(t *testing.T, s database.Store) {
+	ctx := context.Background()
+	u := createUser(t, s)
+	f := createFeed(t, s, u)
+	_, err := s.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: now(),
+		UpdatedAt: now(),
+		UserID:    u.ID,
+		FeedID:    f.ID,
+	})
+	if err != nil {
+		t.Fatalf("CreateFeedFollow: %v", err)
+	}
+	err = s.DeleteUser(ctx, u.ID)
+	if err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	follows, err := s.GetUserFeedFollows(ctx, u.ID)
+	if err != nil || len(follows) != 0 {
+		t.Errorf("GetUserFeedFollows after DeleteUser: got %d, %v; want none", len(follows), err)
+	}
+	_, err = s.GetFeed(ctx, f.ID)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetFeed of a deleted user's feed: got %v, want sql.ErrNoRows", err)
+	}
+}
+
+func testTxCommit(t *testing.T, s database.Store) {
+	ctx := context.Background()
+	tx, err := s.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	defer tx.Rollback()
+	u := createUser(t, tx)
+	_, err = s.GetUser(ctx, u.ID)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUser outside an open transaction: got %v, want sql.ErrNoRows", err)
+	}
+	if _, err := tx.GetUser(ctx, u.ID); err != nil {
+		t.Errorf("GetUser inside the transaction: %v", err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	cleanupUser(t, s, u)
+	tx.Rollback()
+	if _, err := s.GetUser(ctx, u.ID); err != nil {
+		t.Errorf("GetUser after Commit and Rollback: %v", err)
+	}
+}
+
+func testTxRollback(t *testing.T, s database.Store) {
+	ctx := context.Background()
+	tx, err := s.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	u := createUser(t, tx)
+	err = tx.Rollback()
+	if err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	_, err = s.GetUser(ctx, u.ID)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUser after Rollback: got %v, want sql.ErrNoRows", err)
+	}
+}
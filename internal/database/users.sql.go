@@ -7,15 +7,28 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const countUsers = `-- name: CountUsers :one
+SELECT COUNT(*) FROM users
+`
+
+func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUsers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (id, created_at, updated_at, name, api_key)
 VALUES ($1, $2, $3, $4, encode(sha256(random()::text::bytea), 'hex'))
-RETURNING id, created_at, updated_at, name, api_key
+RETURNING id, created_at, updated_at, name, api_key, is_admin, email, avatar_url, bio, banned_at, show_sensitive, preferred_languages
 `
 
 type CreateUserParams struct {
@@ -39,12 +52,52 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.UpdatedAt,
 		&i.Name,
 		&i.ApiKey,
+		&i.IsAdmin,
+		&i.Email,
+		&i.AvatarUrl,
+		&i.Bio,
+		&i.BannedAt,
+		&i.ShowSensitive,
+		pq.Array(&i.PreferredLanguages),
+	)
+	return i, err
+}
+
+const deleteUser = `-- name: DeleteUser :exec
+DELETE FROM users WHERE id = $1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUser, id)
+	return err
+}
+
+const getUser = `-- name: GetUser :one
+SELECT id, created_at, updated_at, name, api_key, is_admin, email, avatar_url, bio, banned_at, show_sensitive, preferred_languages FROM users WHERE id = $1
+`
+
+func (q *Queries) GetUser(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.ApiKey,
+		&i.IsAdmin,
+		&i.Email,
+		&i.AvatarUrl,
+		&i.Bio,
+		&i.BannedAt,
+		&i.ShowSensitive,
+		pq.Array(&i.PreferredLanguages),
 	)
 	return i, err
 }
 
 const getUserByApiKey = `-- name: GetUserByApiKey :one
-SELECT id, created_at, updated_at, name, api_key FROM users WHERE api_key = $1
+SELECT id, created_at, updated_at, name, api_key, is_admin, email, avatar_url, bio, banned_at, show_sensitive, preferred_languages FROM users WHERE api_key = $1
 `
 
 func (q *Queries) GetUserByApiKey(ctx context.Context, apiKey string) (User, error) {
@@ -56,6 +109,223 @@ func (q *Queries) GetUserByApiKey(ctx context.Context, apiKey string) (User, err
 		&i.UpdatedAt,
 		&i.Name,
 		&i.ApiKey,
+		&i.IsAdmin,
+		&i.Email,
+		&i.AvatarUrl,
+		&i.Bio,
+		&i.BannedAt,
+		&i.ShowSensitive,
+		pq.Array(&i.PreferredLanguages),
+	)
+	return i, err
+}
+
+const getUserByName = `-- name: GetUserByName :one
+SELECT id, created_at, updated_at, name, api_key, is_admin, email, avatar_url, bio, banned_at, show_sensitive, preferred_languages FROM users WHERE lower(name) = lower($1)
+`
+
+func (q *Queries) GetUserByName(ctx context.Context, lower string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByName, lower)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.ApiKey,
+		&i.IsAdmin,
+		&i.Email,
+		&i.AvatarUrl,
+		&i.Bio,
+		&i.BannedAt,
+		&i.ShowSensitive,
+		pq.Array(&i.PreferredLanguages),
 	)
 	return i, err
 }
+
+const listAdmins = `-- name: ListAdmins :many
+SELECT id, created_at, updated_at, name, api_key, is_admin, email, avatar_url, bio, banned_at, show_sensitive, preferred_languages FROM users WHERE is_admin AND banned_at IS NULL ORDER BY created_at, id
+`
+
+func (q *Queries) ListAdmins(ctx context.Context) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listAdmins)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.ApiKey,
+			&i.IsAdmin,
+			&i.Email,
+			&i.AvatarUrl,
+			&i.Bio,
+			&i.BannedAt,
+			&i.ShowSensitive,
+			pq.Array(&i.PreferredLanguages),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT id, created_at, updated_at, name, api_key, is_admin, email, avatar_url, bio, banned_at, show_sensitive, preferred_languages FROM users ORDER BY created_at, id LIMIT $1 OFFSET $2
+`
+
+type ListUsersParams struct {
+	Limit  int32
+	Offset int32
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsers, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.ApiKey,
+			&i.IsAdmin,
+			&i.Email,
+			&i.AvatarUrl,
+			&i.Bio,
+			&i.BannedAt,
+			&i.ShowSensitive,
+			pq.Array(&i.PreferredLanguages),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rotateUserApiKey = `-- name: RotateUserApiKey :exec
+UPDATE users SET api_key = encode(sha256(random()::text::bytea), 'hex'), updated_at = $2 WHERE id = $1
+`
+
+type RotateUserApiKeyParams struct {
+	ID        uuid.UUID
+	UpdatedAt time.Time
+}
+
+func (q *Queries) RotateUserApiKey(ctx context.Context, arg RotateUserApiKeyParams) error {
+	_, err := q.db.ExecContext(ctx, rotateUserApiKey, arg.ID, arg.UpdatedAt)
+	return err
+}
+
+const setUserAdmin = `-- name: SetUserAdmin :exec
+UPDATE users SET is_admin = $2, updated_at = $3 WHERE id = $1
+`
+
+type SetUserAdminParams struct {
+	ID        uuid.UUID
+	IsAdmin   bool
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetUserAdmin(ctx context.Context, arg SetUserAdminParams) error {
+	_, err := q.db.ExecContext(ctx, setUserAdmin, arg.ID, arg.IsAdmin, arg.UpdatedAt)
+	return err
+}
+
+const setUserBanned = `-- name: SetUserBanned :exec
+UPDATE users SET banned_at = $2, updated_at = $3 WHERE id = $1
+`
+
+type SetUserBannedParams struct {
+	ID        uuid.UUID
+	BannedAt  sql.NullTime
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetUserBanned(ctx context.Context, arg SetUserBannedParams) error {
+	_, err := q.db.ExecContext(ctx, setUserBanned, arg.ID, arg.BannedAt, arg.UpdatedAt)
+	return err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE users SET name = $2, email = $3, avatar_url = $4, bio = $5, show_sensitive = $6, updated_at = $7, preferred_languages = $8
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, api_key, is_admin, email, avatar_url, bio, banned_at, show_sensitive, preferred_languages
+`
+
+type UpdateUserProfileParams struct {
+	ID                 uuid.UUID
+	Name               string
+	Email              sql.NullString
+	AvatarUrl          sql.NullString
+	Bio                sql.NullString
+	ShowSensitive      bool
+	UpdatedAt          time.Time
+	PreferredLanguages []string
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserProfile,
+		arg.ID,
+		arg.Name,
+		arg.Email,
+		arg.AvatarUrl,
+		arg.Bio,
+		arg.ShowSensitive,
+		arg.UpdatedAt,
+		pq.Array(arg.PreferredLanguages),
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.ApiKey,
+		&i.IsAdmin,
+		&i.Email,
+		&i.AvatarUrl,
+		&i.Bio,
+		&i.BannedAt,
+		&i.ShowSensitive,
+		pq.Array(&i.PreferredLanguages),
+	)
+	return i, err
+}
+
+const userNameExists = `-- name: UserNameExists :one
+SELECT EXISTS(SELECT 1 FROM users WHERE lower(name) = lower($1))
+`
+
+func (q *Queries) UserNameExists(ctx context.Context, name string) (bool, error) {
+	row := q.db.QueryRowContext(ctx, userNameExists, name)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
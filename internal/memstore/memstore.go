@@ -0,0 +1,142 @@
+// Package memstore is an in-memory database.Store for demos. It mirrors the
+// behaviour of the queries in sql/queries closely enough to drive the API,
+// but nothing is persisted and transactions are snapshots: Begin copies the
+// data, and Commit replaces the store's data with the copy, so the last
+// transaction to commit wins. That is fine for a single-user demo and not for
+// anything else.
+package memstore
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"sync"
+
+	"github.com/lib/pq"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+type data struct {
+	auditLog             []database.AuditLog
+	authorMutes          []database.AuthorMute
+	domainRules          []database.DomainRule
+	feeds                []database.Feed
+	feedFetches          []database.FeedFetch
+	feedFollows          []database.FeedFollow
+	feedIcons            []database.FeedIcon
+	feedReports          []database.FeedReport
+	feedRepublishes      []database.FeedRepublish
+	feedUrlHistory       []database.FeedUrlHistory
+	fetchSnapshots       []database.FetchSnapshot
+	notificationChannels []database.NotificationChannel
+	posts                []database.Post
+	postEmails           []database.PostEmail
+	postExports          []database.PostExport
+	postRevisions        []database.PostRevision
+	postRules            []database.PostRule
+	postSnoozes          []database.PostSnooze
+	pushSubscriptions    []database.PushSubscription
+	queueItems           []database.ReadingQueueItem
+	starterPacks         []database.StarterPack
+	starterPackFeeds     []database.StarterPackFeed
+	users                []database.User
+	userIdentities       []database.UserIdentity
+	virtualFeedFilters   []database.VirtualFeedFilter
+	virtualFeedSources   []database.VirtualFeedSource
+	webhookDeliveries    []database.WebhookDelivery
+}
+
+func (d *data) clone() *data {
+	return &data{
+		auditLog:             append([]database.AuditLog(nil), d.auditLog...),
+		authorMutes:          append([]database.AuthorMute(nil), d.authorMutes...),
+		domainRules:          append([]database.DomainRule(nil), d.domainRules...),
+		feeds:                append([]database.Feed(nil), d.feeds...),
+		feedFetches:          append([]database.FeedFetch(nil), d.feedFetches...),
+		feedFollows:          append([]database.FeedFollow(nil), d.feedFollows...),
+		feedIcons:            append([]database.FeedIcon(nil), d.feedIcons...),
+		feedReports:          append([]database.FeedReport(nil), d.feedReports...),
+		feedRepublishes:      append([]database.FeedRepublish(nil), d.feedRepublishes...),
+		feedUrlHistory:       append([]database.FeedUrlHistory(nil), d.feedUrlHistory...),
+		fetchSnapshots:       append([]database.FetchSnapshot(nil), d.fetchSnapshots...),
+		notificationChannels: append([]database.NotificationChannel(nil), d.notificationChannels...),
+		posts:                append([]database.Post(nil), d.posts...),
+		postEmails:           append([]database.PostEmail(nil), d.postEmails...),
+		postExports:          append([]database.PostExport(nil), d.postExports...),
+		postRevisions:        append([]database.PostRevision(nil), d.postRevisions...),
+		postRules:            append([]database.PostRule(nil), d.postRules...),
+		postSnoozes:          append([]database.PostSnooze(nil), d.postSnoozes...),
+		pushSubscriptions:    append([]database.PushSubscription(nil), d.pushSubscriptions...),
+		queueItems:           append([]database.ReadingQueueItem(nil), d.queueItems...),
+		starterPacks:         append([]database.StarterPack(nil), d.starterPacks...),
+		starterPackFeeds:     append([]database.StarterPackFeed(nil), d.starterPackFeeds...),
+		users:                append([]database.User(nil), d.users...),
+		userIdentities:       append([]database.UserIdentity(nil), d.userIdentities...),
+		virtualFeedFilters:   append([]database.VirtualFeedFilter(nil), d.virtualFeedFilters...),
+		virtualFeedSources:   append([]database.VirtualFeedSource(nil), d.virtualFeedSources...),
+		webhookDeliveries:    append([]database.WebhookDelivery(nil), d.webhookDeliveries...),
+	}
+}
+
+// queries implements database.Querier over one copy of the data.
+type queries struct {
+	mu *sync.Mutex
+	d  *data
+}
+
+// Store is the in-memory database.Store.
+type Store struct {
+	queries
+}
+
+// Tx is a snapshot of a Store taken by Begin.
+type Tx struct {
+	queries
+	store *Store
+	done  bool
+}
+
+var _ database.Store = (*Store)(nil)
+
+func New() *Store {
+	return &Store{queries{mu: &sync.Mutex{}, d: &data{}}}
+}
+
+func (s *Store) Begin(ctx context.Context) (database.Tx, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &Tx{queries: queries{mu: &sync.Mutex{}, d: s.d.clone()}, store: s}, nil
+}
+
+func (t *Tx) Commit() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	t.store.d = t.d
+	return nil
+}
+
+func (t *Tx) Rollback() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	return nil
+}
+
+// errUniqueViolation matches what Postgres reports for a unique constraint,
+// so callers checking for duplicates behave the same against either store.
+func errUniqueViolation(constraint string) error {
+	return &pq.Error{Code: "23505", Constraint: constraint, Message: "duplicate key value violates unique constraint"}
+}
+
+// newAPIKey mirrors the column default on users.api_key: 64 hex characters.
+func newAPIKey() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,13 @@
+package memstore_test
+
+import (
+	"testing"
+
+	"github.com/pmwals09/rss-aggregator/internal/database"
+	"github.com/pmwals09/rss-aggregator/internal/database/storetest"
+	"github.com/pmwals09/rss-aggregator/internal/memstore"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) database.Store { return memstore.New() })
+}
@@ -0,0 +1,2807 @@
+package memstore
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+	"github.com/pmwals09/rss-aggregator/internal/publicid"
+)
+
+// Methods are in the same order as database.Querier. Each one follows the
+// query of the same name in sql/queries.
+
+func (q *queries) AddStarterPackFeed(ctx context.Context, arg database.AddStarterPackFeedParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, spf := range q.d.starterPackFeeds {
+		if spf == database.StarterPackFeed(arg) {
+			return nil
+		}
+	}
+	q.d.starterPackFeeds = append(q.d.starterPackFeeds, database.StarterPackFeed(arg))
+	return nil
+}
+
+func (q *queries) AddVirtualFeedSource(ctx context.Context, arg database.AddVirtualFeedSourceParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	source := database.VirtualFeedSource(arg)
+	if !slices.Contains(q.d.virtualFeedSources, source) {
+		q.d.virtualFeedSources = append(q.d.virtualFeedSources, source)
+	}
+	return nil
+}
+
+func (q *queries) ClearFeedFetchFailures(ctx context.Context, id uuid.UUID) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, f := range q.d.feeds {
+		if f.ID == id {
+			q.d.feeds[i].FetchFailures = 0
+			q.d.feeds[i].LastFetchError = ""
+			q.d.feeds[i].RetryAt = sql.NullTime{}
+		}
+	}
+	return nil
+}
+
+func (q *queries) CountFeedPostsByWeekday(ctx context.Context, arg database.CountFeedPostsByWeekdayParams) ([]database.CountFeedPostsByWeekdayRow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	counts := [7]int64{}
+	for _, p := range q.d.posts {
+		if p.FeedID == arg.FeedID && p.PublishedAt.Valid && !p.PublishedAt.Time.Before(arg.Since) {
+			counts[p.PublishedAt.Time.Weekday()]++
+		}
+	}
+	var items []database.CountFeedPostsByWeekdayRow
+	for weekday, n := range counts {
+		if n > 0 {
+			items = append(items, database.CountFeedPostsByWeekdayRow{Weekday: int32(weekday), PostCount: n})
+		}
+	}
+	return items, nil
+}
+
+func (q *queries) CountForeignInboxFollows(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return countFunc(q.d.feedFollows, q.foreignInboxFollow), nil
+}
+
+func (q *queries) CountOrphanFeedFollows(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return countFunc(q.d.feedFollows, q.orphanFeedFollow), nil
+}
+
+func (q *queries) CountOrphanPostEmails(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return countFunc(q.d.postEmails, q.orphanPostEmail), nil
+}
+
+func (q *queries) CountOrphanPostSnoozes(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return countFunc(q.d.postSnoozes, q.orphanPostSnooze), nil
+}
+
+func (q *queries) CountOrphanPosts(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return countFunc(q.d.posts, q.orphanPost), nil
+}
+
+func (q *queries) CountOrphanQueueItems(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return countFunc(q.d.queueItems, q.orphanQueueItem), nil
+}
+
+func (q *queries) CountOtherFeedFollowers(ctx context.Context, arg database.CountOtherFeedFollowersParams) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var count int64
+	for _, f := range q.d.feedFollows {
+		if f.FeedID == arg.FeedID && f.UserID != arg.UserID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *queries) CountPostEmailsSince(ctx context.Context, arg database.CountPostEmailsSinceParams) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var count int64
+	for _, e := range q.d.postEmails {
+		if e.UserID == arg.UserID && !e.CreatedAt.Before(arg.CreatedAt) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *queries) CountPostsForDeletion(ctx context.Context, arg database.CountPostsForDeletionParams) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return countFunc(q.d.posts, func(p database.Post) bool { return postMatchesDeletion(p, arg.FeedID, arg.Before) }), nil
+}
+
+func (q *queries) CountUsers(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return int64(len(q.d.users)), nil
+}
+
+func (q *queries) CreateAuditLogEntry(ctx context.Context, arg database.CreateAuditLogEntryParams) (database.AuditLog, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	entry := database.AuditLog(arg)
+	q.d.auditLog = append(q.d.auditLog, entry)
+	return entry, nil
+}
+
+func (q *queries) CreateAuthorMute(ctx context.Context, arg database.CreateAuthorMuteParams) (database.AuthorMute, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, m := range q.d.authorMutes {
+		if m.UserID == arg.UserID && strings.EqualFold(m.Author, arg.Author) {
+			return database.AuthorMute{}, errUniqueViolation("author_mutes_user_id_author_idx")
+		}
+	}
+	mute := database.AuthorMute(arg)
+	q.d.authorMutes = append(q.d.authorMutes, mute)
+	return mute, nil
+}
+
+func (q *queries) CreateDomainRule(ctx context.Context, arg database.CreateDomainRuleParams) (database.DomainRule, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, rule := range q.d.domainRules {
+		if rule.Domain == arg.Domain {
+			return database.DomainRule{}, errUniqueViolation("domain_rules_domain_key")
+		}
+	}
+	rule := database.DomainRule(arg)
+	q.d.domainRules = append(q.d.domainRules, rule)
+	return rule, nil
+}
+
+// publicFeedAt reports whether a feed other than exceptID without
+// credentials is at url, as the partial feeds_url_key index would.
+func (q *queries) publicFeedAt(url string, exceptID uuid.UUID) bool {
+	for _, f := range q.d.feeds {
+		if f.Url == url && f.ID != exceptID && f.Credentials == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *queries) createFeed(feed database.Feed) (database.Feed, error) {
+	for _, f := range q.d.feeds {
+		if f.Url == feed.Url && f.Credentials == nil && feed.Credentials == nil {
+			return database.Feed{}, errUniqueViolation("feeds_url_key")
+		}
+	}
+	feed.PublicID = publicid.New(feed.CreatedAt)
+	feed.FetchHeaders = json.RawMessage(`{}`)
+	q.d.feeds = append(q.d.feeds, feed)
+	return feed, nil
+}
+
+func (q *queries) CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.createFeed(database.Feed{
+		ID:          arg.ID,
+		CreatedAt:   arg.CreatedAt,
+		UpdatedAt:   arg.UpdatedAt,
+		Name:        arg.Name,
+		Url:         arg.Url,
+		UserID:      arg.UserID,
+		Sensitive:   arg.Sensitive,
+		Kind:        "remote",
+		Description: arg.Description,
+		SiteUrl:     arg.SiteUrl,
+		Language:    arg.Language,
+		Country:     arg.Country,
+		Credentials: arg.Credentials,
+	})
+}
+
+func (q *queries) CreateFeedFetch(ctx context.Context, arg database.CreateFeedFetchParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.d.feedFetches = append(q.d.feedFetches, database.FeedFetch(arg))
+	return nil
+}
+
+func (q *queries) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.FeedFollow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	follow := database.FeedFollow{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		UserID:    arg.UserID,
+		FeedID:    arg.FeedID,
+		Priority:  "normal",
+	}
+	q.d.feedFollows = append(q.d.feedFollows, follow)
+	return follow, nil
+}
+
+func (q *queries) CreateFeedReport(ctx context.Context, arg database.CreateFeedReportParams) (database.FeedReport, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	report := database.FeedReport{
+		ID:         arg.ID,
+		CreatedAt:  arg.CreatedAt,
+		UpdatedAt:  arg.UpdatedAt,
+		FeedID:     arg.FeedID,
+		ReporterID: arg.ReporterID,
+		Reason:     arg.Reason,
+		Comment:    arg.Comment,
+		Status:     "open",
+	}
+	q.d.feedReports = append(q.d.feedReports, report)
+	return report, nil
+}
+
+func (q *queries) CreateFeedRepublish(ctx context.Context, arg database.CreateFeedRepublishParams) (database.FeedRepublish, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, r := range q.d.feedRepublishes {
+		if r.Token == arg.Token {
+			return database.FeedRepublish{}, errUniqueViolation("feed_republishes_token_key")
+		}
+	}
+	republish := database.FeedRepublish(arg)
+	q.d.feedRepublishes = append(q.d.feedRepublishes, republish)
+	return republish, nil
+}
+
+func (q *queries) CreateFeedUrlHistoryEntry(ctx context.Context, arg database.CreateFeedUrlHistoryEntryParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.d.feedUrlHistory = append(q.d.feedUrlHistory, database.FeedUrlHistory(arg))
+	return nil
+}
+
+func (q *queries) CreateFetchSnapshot(ctx context.Context, arg database.CreateFetchSnapshotParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.d.fetchSnapshots = append(q.d.fetchSnapshots, database.FetchSnapshot{
+		ID:          arg.ID,
+		FeedID:      arg.FeedID,
+		FetchedAt:   arg.FetchedAt,
+		ContentType: arg.ContentType,
+		Size:        arg.Size,
+		Truncated:   arg.Truncated,
+		Body:        arg.Body,
+	})
+	return nil
+}
+
+func (q *queries) CreateInboxFeed(ctx context.Context, arg database.CreateInboxFeedParams) (database.Feed, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.createFeed(database.Feed{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		Name:      arg.Name,
+		Url:       arg.Url,
+		UserID:    arg.UserID,
+		Kind:      "inbox",
+	})
+}
+
+func (q *queries) CreateNotificationChannel(ctx context.Context, arg database.CreateNotificationChannelParams) (database.NotificationChannel, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	channel := database.NotificationChannel(arg)
+	q.d.notificationChannels = append(q.d.notificationChannels, channel)
+	return channel, nil
+}
+
+// urlKeyed reports whether p is covered by posts_url_key: copies in
+// virtual feeds and links saved to an inbox are not.
+func urlKeyed(p database.Post) bool {
+	return !p.SourcePostID.Valid && !p.Inbox
+}
+
+func (q *queries) createPost(post database.Post) (database.Post, error) {
+	for _, p := range q.d.posts {
+		if p.Url == post.Url && urlKeyed(p) && urlKeyed(post) {
+			return database.Post{}, errUniqueViolation("posts_url_key")
+		}
+		if p.FeedID == post.FeedID && p.Guid == post.Guid {
+			return database.Post{}, errUniqueViolation("posts_feed_id_guid_key")
+		}
+	}
+	post.PublicID = publicid.New(post.CreatedAt)
+	q.d.posts = append(q.d.posts, post)
+	return post, nil
+}
+
+func (q *queries) CreatePost(ctx context.Context, arg database.CreatePostParams) (database.Post, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.createPost(database.Post{
+		ID:          arg.ID,
+		CreatedAt:   arg.CreatedAt,
+		UpdatedAt:   arg.UpdatedAt,
+		Title:       arg.Title,
+		Url:         arg.Url,
+		Description: arg.Description,
+		PublishedAt: arg.PublishedAt,
+		FeedID:      arg.FeedID,
+		Sensitive:   arg.Sensitive,
+		Guid:        arg.Url,
+		Inbox:       slices.ContainsFunc(q.d.feeds, func(f database.Feed) bool { return f.ID == arg.FeedID && f.Kind == "inbox" }),
+	})
+}
+
+func (q *queries) CreatePostEmail(ctx context.Context, arg database.CreatePostEmailParams) (database.PostEmail, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	email := database.PostEmail(arg)
+	q.d.postEmails = append(q.d.postEmails, email)
+	return email, nil
+}
+
+func (q *queries) CreatePostExport(ctx context.Context, arg database.CreatePostExportParams) (database.PostExport, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	export := database.PostExport(arg)
+	q.d.postExports = append(q.d.postExports, export)
+	return export, nil
+}
+
+func (q *queries) CreatePostRevision(ctx context.Context, arg database.CreatePostRevisionParams) (database.PostRevision, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	revision := database.PostRevision(arg)
+	q.d.postRevisions = append(q.d.postRevisions, revision)
+	return revision, nil
+}
+
+func (q *queries) CreatePostRule(ctx context.Context, arg database.CreatePostRuleParams) (database.PostRule, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	rule := database.PostRule(arg)
+	q.d.postRules = append(q.d.postRules, rule)
+	return rule, nil
+}
+
+func (q *queries) CreatePushSubscription(ctx context.Context, arg database.CreatePushSubscriptionParams) (database.PushSubscription, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, sub := range q.d.pushSubscriptions {
+		if sub.Endpoint == arg.Endpoint {
+			sub.UpdatedAt = arg.UpdatedAt
+			sub.UserID = arg.UserID
+			sub.P256dh = arg.P256dh
+			sub.Auth = arg.Auth
+			q.d.pushSubscriptions[i] = sub
+			return sub, nil
+		}
+	}
+	sub := database.PushSubscription(arg)
+	q.d.pushSubscriptions = append(q.d.pushSubscriptions, sub)
+	return sub, nil
+}
+
+func (q *queries) CreateStarterPack(ctx context.Context, arg database.CreateStarterPackParams) (database.StarterPack, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	pack := database.StarterPack(arg)
+	q.d.starterPacks = append(q.d.starterPacks, pack)
+	return pack, nil
+}
+
+func (q *queries) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, u := range q.d.users {
+		if strings.EqualFold(u.Name, arg.Name) {
+			return database.User{}, errUniqueViolation("users_name_key")
+		}
+	}
+	user := database.User{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		Name:      arg.Name,
+		ApiKey:    newAPIKey(),
+	}
+	q.d.users = append(q.d.users, user)
+	return user, nil
+}
+
+func (q *queries) CreateUserIdentity(ctx context.Context, arg database.CreateUserIdentityParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, i := range q.d.userIdentities {
+		if i.Backend == arg.Backend && i.Subject == arg.Subject {
+			return errUniqueViolation("user_identities_pkey")
+		}
+	}
+	q.d.userIdentities = append(q.d.userIdentities, database.UserIdentity(arg))
+	return nil
+}
+
+func (q *queries) CreateVirtualFeed(ctx context.Context, arg database.CreateVirtualFeedParams) (database.Feed, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.createFeed(database.Feed{
+		ID:          arg.ID,
+		CreatedAt:   arg.CreatedAt,
+		UpdatedAt:   arg.UpdatedAt,
+		Name:        arg.Name,
+		Url:         arg.Url,
+		UserID:      arg.UserID,
+		Description: arg.Description,
+		Kind:        "virtual",
+	})
+}
+
+func (q *queries) CreateVirtualFeedFilter(ctx context.Context, arg database.CreateVirtualFeedFilterParams) (database.VirtualFeedFilter, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, f := range q.d.virtualFeedFilters {
+		if f.FeedID == arg.FeedID {
+			return database.VirtualFeedFilter{}, errUniqueViolation("virtual_feed_filters_pkey")
+		}
+	}
+	filter := database.VirtualFeedFilter(arg)
+	q.d.virtualFeedFilters = append(q.d.virtualFeedFilters, filter)
+	return filter, nil
+}
+
+func (q *queries) CreateVirtualFeedPost(ctx context.Context, arg database.CreateVirtualFeedPostParams) (database.Post, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, p := range q.d.posts {
+		if p.FeedID == arg.FeedID && p.Guid == arg.Guid {
+			return database.Post{}, sql.ErrNoRows
+		}
+	}
+	return q.createPost(database.Post{
+		ID:              arg.ID,
+		CreatedAt:       arg.CreatedAt,
+		UpdatedAt:       arg.UpdatedAt,
+		Title:           arg.Title,
+		Url:             arg.Url,
+		Description:     arg.Description,
+		PublishedAt:     arg.PublishedAt,
+		FeedID:          arg.FeedID,
+		Sensitive:       arg.Sensitive,
+		Guid:            arg.Guid,
+		EnclosureUrl:    arg.EnclosureUrl,
+		EnclosureType:   arg.EnclosureType,
+		EnclosureLength: arg.EnclosureLength,
+		SourcePostID:    arg.SourcePostID,
+		Author:          arg.Author,
+	})
+}
+
+func (q *queries) CreateWebhookDelivery(ctx context.Context, arg database.CreateWebhookDeliveryParams) (database.WebhookDelivery, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delivery := database.WebhookDelivery(arg)
+	q.d.webhookDeliveries = append(q.d.webhookDeliveries, delivery)
+	return delivery, nil
+}
+
+func (q *queries) DeleteAuthorMute(ctx context.Context, arg database.DeleteAuthorMuteParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.d.authorMutes = slices.DeleteFunc(q.d.authorMutes, func(m database.AuthorMute) bool {
+		return m.ID == arg.ID && m.UserID == arg.UserID
+	})
+	return nil
+}
+
+func (q *queries) DeleteDomainRule(ctx context.Context, id uuid.UUID) (database.DomainRule, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i := slices.IndexFunc(q.d.domainRules, func(r database.DomainRule) bool { return r.ID == id })
+	if i < 0 {
+		return database.DomainRule{}, sql.ErrNoRows
+	}
+	rule := q.d.domainRules[i]
+	q.d.domainRules = slices.Delete(q.d.domainRules, i, i+1)
+	return rule, nil
+}
+
+func (q *queries) DeleteFeedFollow(ctx context.Context, id uuid.UUID) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.d.feedFollows = slices.DeleteFunc(q.d.feedFollows, func(f database.FeedFollow) bool { return f.ID == id })
+	return nil
+}
+
+func (q *queries) DeleteFeedRepublish(ctx context.Context, arg database.DeleteFeedRepublishParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.d.feedRepublishes = slices.DeleteFunc(q.d.feedRepublishes, func(r database.FeedRepublish) bool {
+		return r.ID == arg.ID && r.UserID == arg.UserID
+	})
+	return nil
+}
+
+func (q *queries) DeleteForeignInboxFollows(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return deleteFunc(&q.d.feedFollows, q.foreignInboxFollow), nil
+}
+
+func (q *queries) DeleteNotificationChannel(ctx context.Context, arg database.DeleteNotificationChannelParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	deleted := map[uuid.UUID]bool{}
+	q.d.notificationChannels = slices.DeleteFunc(q.d.notificationChannels, func(c database.NotificationChannel) bool {
+		deleted[c.ID] = c.ID == arg.ID && c.UserID == arg.UserID
+		return deleted[c.ID]
+	})
+	q.d.webhookDeliveries = slices.DeleteFunc(q.d.webhookDeliveries, func(d database.WebhookDelivery) bool { return deleted[d.ChannelID] })
+	return nil
+}
+
+func (q *queries) DeleteOrphanFeedFollows(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return deleteFunc(&q.d.feedFollows, q.orphanFeedFollow), nil
+}
+
+func (q *queries) DeleteOrphanPostEmails(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return deleteFunc(&q.d.postEmails, q.orphanPostEmail), nil
+}
+
+func (q *queries) DeleteOrphanPostSnoozes(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return deleteFunc(&q.d.postSnoozes, q.orphanPostSnooze), nil
+}
+
+func (q *queries) DeleteOrphanPosts(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return deleteFunc(&q.d.posts, q.orphanPost), nil
+}
+
+func (q *queries) DeleteOrphanQueueItems(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return deleteFunc(&q.d.queueItems, q.orphanQueueItem), nil
+}
+
+func (q *queries) DeletePostRule(ctx context.Context, arg database.DeletePostRuleParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.d.postRules = slices.DeleteFunc(q.d.postRules, func(r database.PostRule) bool {
+		return r.ID == arg.ID && r.UserID == arg.UserID
+	})
+	return nil
+}
+
+func (q *queries) DeletePostSnooze(ctx context.Context, arg database.DeletePostSnoozeParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.d.postSnoozes = slices.DeleteFunc(q.d.postSnoozes, func(s database.PostSnooze) bool {
+		return s.UserID == arg.UserID && s.PostID == arg.PostID
+	})
+	return nil
+}
+
+func (q *queries) DeletePostsBatch(ctx context.Context, arg database.DeletePostsBatchParams) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	postIDs := map[uuid.UUID]bool{}
+	for _, p := range q.d.posts {
+		if len(postIDs) >= int(arg.Limit) {
+			break
+		}
+		if postMatchesDeletion(p, arg.FeedID, arg.Before) {
+			postIDs[p.ID] = true
+		}
+	}
+	deleted := int64(len(postIDs))
+	q.addPostCopies(postIDs)
+	q.d.posts = slices.DeleteFunc(q.d.posts, func(p database.Post) bool { return postIDs[p.ID] })
+	q.d.postEmails = slices.DeleteFunc(q.d.postEmails, func(e database.PostEmail) bool { return postIDs[e.PostID] })
+	q.d.postRevisions = slices.DeleteFunc(q.d.postRevisions, func(r database.PostRevision) bool { return postIDs[r.PostID] })
+	q.d.postSnoozes = slices.DeleteFunc(q.d.postSnoozes, func(s database.PostSnooze) bool { return postIDs[s.PostID] })
+	q.d.queueItems = slices.DeleteFunc(q.d.queueItems, func(i database.ReadingQueueItem) bool { return postIDs[i.PostID] })
+	return deleted, nil
+}
+
+func (q *queries) DeletePushSubscription(ctx context.Context, arg database.DeletePushSubscriptionParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.d.pushSubscriptions = slices.DeleteFunc(q.d.pushSubscriptions, func(s database.PushSubscription) bool {
+		return s.ID == arg.ID && s.UserID == arg.UserID
+	})
+	return nil
+}
+
+func (q *queries) DeletePushSubscriptionByEndpoint(ctx context.Context, endpoint string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.d.pushSubscriptions = slices.DeleteFunc(q.d.pushSubscriptions, func(s database.PushSubscription) bool {
+		return s.Endpoint == endpoint
+	})
+	return nil
+}
+
+func (q *queries) DeleteQueueItem(ctx context.Context, arg database.DeleteQueueItemParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.d.queueItems = slices.DeleteFunc(q.d.queueItems, func(i database.ReadingQueueItem) bool {
+		return i.UserID == arg.UserID && i.PostID == arg.PostID
+	})
+	return nil
+}
+
+func (q *queries) DeleteStarterPack(ctx context.Context, id uuid.UUID) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.d.starterPacks = slices.DeleteFunc(q.d.starterPacks, func(p database.StarterPack) bool { return p.ID == id })
+	q.d.starterPackFeeds = slices.DeleteFunc(q.d.starterPackFeeds, func(f database.StarterPackFeed) bool { return f.StarterPackID == id })
+	return nil
+}
+
+// DeleteUser also removes everything that references the user, as the
+// ON DELETE clauses in sql/schema do.
+func (q *queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	feedIDs := map[uuid.UUID]bool{}
+	for _, f := range q.d.feeds {
+		if f.UserID == id {
+			feedIDs[f.ID] = true
+		}
+	}
+	postIDs := map[uuid.UUID]bool{}
+	for _, p := range q.d.posts {
+		if feedIDs[p.FeedID] {
+			postIDs[p.ID] = true
+		}
+	}
+	q.addPostCopies(postIDs)
+	q.d.users = slices.DeleteFunc(q.d.users, func(u database.User) bool { return u.ID == id })
+	q.d.userIdentities = slices.DeleteFunc(q.d.userIdentities, func(i database.UserIdentity) bool { return i.UserID == id })
+	q.d.feeds = slices.DeleteFunc(q.d.feeds, func(f database.Feed) bool { return feedIDs[f.ID] })
+	q.d.posts = slices.DeleteFunc(q.d.posts, func(p database.Post) bool { return postIDs[p.ID] })
+	q.d.feedFollows = slices.DeleteFunc(q.d.feedFollows, func(f database.FeedFollow) bool {
+		return f.UserID == id || feedIDs[f.FeedID]
+	})
+	q.d.feedReports = slices.DeleteFunc(q.d.feedReports, func(r database.FeedReport) bool {
+		return r.ReporterID == id || feedIDs[r.FeedID]
+	})
+	q.d.feedIcons = slices.DeleteFunc(q.d.feedIcons, func(i database.FeedIcon) bool { return feedIDs[i.FeedID] })
+	q.d.feedRepublishes = slices.DeleteFunc(q.d.feedRepublishes, func(r database.FeedRepublish) bool {
+		return r.UserID == id || feedIDs[r.FeedID]
+	})
+	q.d.feedUrlHistory = slices.DeleteFunc(q.d.feedUrlHistory, func(h database.FeedUrlHistory) bool { return feedIDs[h.FeedID] })
+	q.d.feedFetches = slices.DeleteFunc(q.d.feedFetches, func(f database.FeedFetch) bool { return feedIDs[f.FeedID] })
+	q.d.fetchSnapshots = slices.DeleteFunc(q.d.fetchSnapshots, func(s database.FetchSnapshot) bool { return feedIDs[s.FeedID] })
+	q.d.virtualFeedFilters = slices.DeleteFunc(q.d.virtualFeedFilters, func(f database.VirtualFeedFilter) bool { return feedIDs[f.FeedID] })
+	q.d.virtualFeedSources = slices.DeleteFunc(q.d.virtualFeedSources, func(s database.VirtualFeedSource) bool {
+		return feedIDs[s.VirtualFeedID] || feedIDs[s.SourceFeedID]
+	})
+	for i, r := range q.d.feedReports {
+		if r.ResolvedBy.Valid && r.ResolvedBy.UUID == id {
+			q.d.feedReports[i].ResolvedBy = uuid.NullUUID{}
+		}
+	}
+	for i, e := range q.d.auditLog {
+		if e.ActorID.Valid && e.ActorID.UUID == id {
+			q.d.auditLog[i].ActorID = uuid.NullUUID{}
+		}
+	}
+	for i, r := range q.d.postRevisions {
+		if r.EditorID.Valid && r.EditorID.UUID == id {
+			q.d.postRevisions[i].EditorID = uuid.NullUUID{}
+		}
+	}
+	q.d.postRevisions = slices.DeleteFunc(q.d.postRevisions, func(r database.PostRevision) bool { return postIDs[r.PostID] })
+	channelIDs := map[uuid.UUID]bool{}
+	for _, c := range q.d.notificationChannels {
+		if c.UserID == id {
+			channelIDs[c.ID] = true
+		}
+	}
+	q.d.notificationChannels = slices.DeleteFunc(q.d.notificationChannels, func(c database.NotificationChannel) bool { return c.UserID == id })
+	q.d.webhookDeliveries = slices.DeleteFunc(q.d.webhookDeliveries, func(d database.WebhookDelivery) bool { return channelIDs[d.ChannelID] })
+	q.d.postEmails = slices.DeleteFunc(q.d.postEmails, func(e database.PostEmail) bool { return e.UserID == id || postIDs[e.PostID] })
+	q.d.postSnoozes = slices.DeleteFunc(q.d.postSnoozes, func(s database.PostSnooze) bool { return s.UserID == id || postIDs[s.PostID] })
+	q.d.authorMutes = slices.DeleteFunc(q.d.authorMutes, func(m database.AuthorMute) bool { return m.UserID == id })
+	q.d.postRules = slices.DeleteFunc(q.d.postRules, func(r database.PostRule) bool {
+		return r.UserID == id || (r.FeedID.Valid && feedIDs[r.FeedID.UUID])
+	})
+	q.d.pushSubscriptions = slices.DeleteFunc(q.d.pushSubscriptions, func(s database.PushSubscription) bool { return s.UserID == id })
+	q.d.queueItems = slices.DeleteFunc(q.d.queueItems, func(i database.ReadingQueueItem) bool { return i.UserID == id || postIDs[i.PostID] })
+	q.d.starterPackFeeds = slices.DeleteFunc(q.d.starterPackFeeds, func(f database.StarterPackFeed) bool { return feedIDs[f.FeedID] })
+	return nil
+}
+
+func (q *queries) DeleteUserFeedFollows(ctx context.Context, userID uuid.UUID) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.d.feedFollows = slices.DeleteFunc(q.d.feedFollows, func(f database.FeedFollow) bool { return f.UserID == userID })
+	return nil
+}
+
+func (q *queries) EnqueuePost(ctx context.Context, arg database.EnqueuePostParams) (database.ReadingQueueItem, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var maxPosition int32
+	for _, item := range q.d.queueItems {
+		if item.UserID != arg.UserID {
+			continue
+		}
+		if item.PostID == arg.PostID {
+			return item, nil
+		}
+		maxPosition = max(maxPosition, item.Position)
+	}
+	item := database.ReadingQueueItem{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UserID:    arg.UserID,
+		PostID:    arg.PostID,
+		Position:  maxPosition + 1,
+	}
+	q.d.queueItems = append(q.d.queueItems, item)
+	return item, nil
+}
+
+func (q *queries) FeedHasPosts(ctx context.Context, feedID uuid.UUID) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return slices.ContainsFunc(q.d.posts, func(p database.Post) bool { return p.FeedID == feedID }), nil
+}
+
+func (q *queries) GetDueSnoozeNotifications(ctx context.Context, wakeAt time.Time) ([]database.GetDueSnoozeNotificationsRow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.GetDueSnoozeNotificationsRow
+	for _, s := range q.d.postSnoozes {
+		if !s.Notify || s.NotifiedAt.Valid || s.WakeAt.After(wakeAt) {
+			continue
+		}
+		post, ok := q.post(s.PostID)
+		if !ok {
+			continue
+		}
+		items = append(items, database.GetDueSnoozeNotificationsRow{
+			ID:         s.ID,
+			CreatedAt:  s.CreatedAt,
+			UserID:     s.UserID,
+			PostID:     s.PostID,
+			WakeAt:     s.WakeAt,
+			Notify:     s.Notify,
+			NotifiedAt: s.NotifiedAt,
+			Title:      post.Title,
+			Url:        post.Url,
+		})
+	}
+	return items, nil
+}
+
+func (q *queries) feed(id uuid.UUID) (database.Feed, bool) {
+	for _, f := range q.d.feeds {
+		if f.ID == id {
+			return f, true
+		}
+	}
+	return database.Feed{}, false
+}
+
+func (q *queries) GetFeed(ctx context.Context, id uuid.UUID) (database.Feed, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	feed, ok := q.feed(id)
+	if !ok {
+		return database.Feed{}, sql.ErrNoRows
+	}
+	return feed, nil
+}
+
+func (q *queries) GetFeedByPublicID(ctx context.Context, publicID string) (database.Feed, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, f := range q.d.feeds {
+		if f.PublicID == publicID {
+			return f, nil
+		}
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
+
+// GetFeedByUrl finds public feeds only; feeds with credentials are private
+// to their owners.
+func (q *queries) GetFeedByUrl(ctx context.Context, url string) (database.Feed, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, f := range q.d.feeds {
+		if f.Url == url && f.Credentials == nil {
+			return f, nil
+		}
+	}
+	var moved *database.FeedUrlHistory
+	for i, h := range q.d.feedUrlHistory {
+		if h.OldUrl == url && (moved == nil || h.ChangedAt.After(moved.ChangedAt)) {
+			moved = &q.d.feedUrlHistory[i]
+		}
+	}
+	if moved != nil {
+		if f, ok := q.feed(moved.FeedID); ok && f.Credentials == nil {
+			return f, nil
+		}
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
+
+func (q *queries) GetFeedIcon(ctx context.Context, feedID uuid.UUID) (database.FeedIcon, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, i := range q.d.feedIcons {
+		if i.FeedID == feedID {
+			return i, nil
+		}
+	}
+	return database.FeedIcon{}, sql.ErrNoRows
+}
+
+func (q *queries) GetFeedReport(ctx context.Context, id uuid.UUID) (database.FeedReport, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, r := range q.d.feedReports {
+		if r.ID == id {
+			return r, nil
+		}
+	}
+	return database.FeedReport{}, sql.ErrNoRows
+}
+
+func (q *queries) GetFeedRepublishByToken(ctx context.Context, token string) (database.FeedRepublish, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, r := range q.d.feedRepublishes {
+		if r.Token == token {
+			return r, nil
+		}
+	}
+	return database.FeedRepublish{}, sql.ErrNoRows
+}
+
+func (q *queries) GetFetchSnapshot(ctx context.Context, arg database.GetFetchSnapshotParams) (database.FetchSnapshot, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, s := range q.d.fetchSnapshots {
+		if s.ID == arg.ID && s.FeedID == arg.FeedID {
+			return s, nil
+		}
+	}
+	return database.FetchSnapshot{}, sql.ErrNoRows
+}
+
+func (q *queries) GetHighPriorityPushSubscriptionsForFeed(ctx context.Context, feedID uuid.UUID) ([]database.PushSubscription, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.PushSubscription
+	for _, f := range q.d.feedFollows {
+		if f.FeedID != feedID || f.Priority != "high" {
+			continue
+		}
+		for _, s := range q.d.pushSubscriptions {
+			if s.UserID == f.UserID {
+				items = append(items, s)
+			}
+		}
+	}
+	return items, nil
+}
+
+func (q *queries) GetInboxFeed(ctx context.Context, userID uuid.UUID) (database.Feed, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var inbox *database.Feed
+	for i, f := range q.d.feeds {
+		if f.UserID == userID && f.Kind == "inbox" && (inbox == nil || f.CreatedAt.Before(inbox.CreatedAt)) {
+			inbox = &q.d.feeds[i]
+		}
+	}
+	if inbox == nil {
+		return database.Feed{}, sql.ErrNoRows
+	}
+	return *inbox, nil
+}
+
+func (q *queries) GetLatestPostExport(ctx context.Context, destination string) (database.PostExport, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var latest *database.PostExport
+	for i, e := range q.d.postExports {
+		if e.Destination == destination && (latest == nil || e.ExportedUntil.After(latest.ExportedUntil)) {
+			latest = &q.d.postExports[i]
+		}
+	}
+	if latest == nil {
+		return database.PostExport{}, sql.ErrNoRows
+	}
+	return *latest, nil
+}
+
+func (q *queries) GetNotificationChannel(ctx context.Context, arg database.GetNotificationChannelParams) (database.NotificationChannel, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, c := range q.d.notificationChannels {
+		if c.ID == arg.ID && c.UserID == arg.UserID {
+			return c, nil
+		}
+	}
+	return database.NotificationChannel{}, sql.ErrNoRows
+}
+
+func (q *queries) GetNotificationChannelsForFeed(ctx context.Context, feedID uuid.UUID) ([]database.NotificationChannel, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.NotificationChannel
+	for _, f := range q.d.feedFollows {
+		if f.FeedID != feedID || f.Priority == "low" {
+			continue
+		}
+		for _, c := range q.d.notificationChannels {
+			if c.UserID == f.UserID {
+				items = append(items, c)
+			}
+		}
+	}
+	return items, nil
+}
+
+func (q *queries) post(id uuid.UUID) (database.Post, bool) {
+	for _, p := range q.d.posts {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return database.Post{}, false
+}
+
+func (q *queries) GetPost(ctx context.Context, id uuid.UUID) (database.Post, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	post, ok := q.post(id)
+	if !ok {
+		return database.Post{}, sql.ErrNoRows
+	}
+	return post, nil
+}
+
+func (q *queries) GetPostByPublicID(ctx context.Context, publicID string) (database.Post, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, p := range q.d.posts {
+		if p.PublicID == publicID {
+			return p, nil
+		}
+	}
+	return database.Post{}, sql.ErrNoRows
+}
+
+func (q *queries) GetPostRevisions(ctx context.Context, postID uuid.UUID) ([]database.PostRevision, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.PostRevision
+	for _, r := range q.d.postRevisions {
+		if r.PostID == postID {
+			items = append(items, r)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.PostRevision) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	return items, nil
+}
+
+func (q *queries) GetPostsByFeed(ctx context.Context, arg database.GetPostsByFeedParams) ([]database.Post, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.Post
+	for _, p := range q.d.posts {
+		if p.FeedID == arg.FeedID {
+			items = append(items, p)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.Post) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	if len(items) > int(arg.Limit) {
+		items = items[:arg.Limit]
+	}
+	return items, nil
+}
+
+func (q *queries) GetPostsByUser(ctx context.Context, arg database.GetPostsByUserParams) ([]database.GetPostsByUserRow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	snoozed := map[uuid.UUID]bool{}
+	for _, s := range q.d.postSnoozes {
+		if s.UserID == arg.UserID && !s.CreatedAt.After(arg.AsOf) && s.WakeAt.After(arg.AsOf) {
+			snoozed[s.PostID] = true
+		}
+	}
+	muted := map[string]bool{}
+	for _, m := range q.d.authorMutes {
+		if m.UserID == arg.UserID && !m.CreatedAt.After(arg.AsOf) {
+			muted[strings.ToLower(m.Author)] = true
+		}
+	}
+	var items []database.GetPostsByUserRow
+	for _, f := range q.d.feedFollows {
+		if f.UserID != arg.UserID || f.CreatedAt.After(arg.AsOf) {
+			continue
+		}
+		if len(arg.FeedIds) > 0 && !slices.Contains(arg.FeedIds, f.FeedID) {
+			continue
+		}
+		feed, ok := q.feed(f.FeedID)
+		if !ok {
+			continue
+		}
+		for _, p := range q.d.posts {
+			if p.FeedID != f.FeedID || snoozed[p.ID] || muted[strings.ToLower(p.Author)] || p.CreatedAt.After(arg.AsOf) {
+				continue
+			}
+			row := database.GetPostsByUserRow{
+				ID:              p.ID,
+				CreatedAt:       p.CreatedAt,
+				UpdatedAt:       p.UpdatedAt,
+				Title:           p.Title,
+				Url:             p.Url,
+				Description:     p.Description,
+				PublishedAt:     p.PublishedAt,
+				FeedID:          p.FeedID,
+				Sensitive:       p.Sensitive,
+				PublicID:        p.PublicID,
+				Guid:            p.Guid,
+				EnclosureUrl:    p.EnclosureUrl,
+				EnclosureType:   p.EnclosureType,
+				EnclosureLength: p.EnclosureLength,
+				SourcePostID:    p.SourcePostID,
+				Author:          p.Author,
+				ID_2:            f.ID,
+				CreatedAt_2:     f.CreatedAt,
+				UpdatedAt_2:     f.UpdatedAt,
+				UserID:          f.UserID,
+				FeedID_2:        f.FeedID,
+				Priority:        f.Priority,
+				OrderByIngested: f.OrderByIngested,
+				FeedSensitive:   feed.Sensitive,
+			}
+			at := postSortTime(row, arg.OrderBy)
+			if (arg.Since.Valid && !at.After(arg.Since.Time)) || (arg.Until.Valid && at.After(arg.Until.Time)) {
+				continue
+			}
+			items = append(items, row)
+		}
+	}
+	// By the sort time, then the ID, in the direction asked for.
+	compare := func(aAt time.Time, aID uuid.UUID, bAt time.Time, bID uuid.UUID) int {
+		c := aAt.Compare(bAt)
+		if c == 0 {
+			c = compareUUIDs(aID, bID)
+		}
+		if arg.SortDesc {
+			return -c
+		}
+		return c
+	}
+	slices.SortFunc(items, func(a, b database.GetPostsByUserRow) int {
+		return compare(postSortTime(a, arg.OrderBy), a.ID, postSortTime(b, arg.OrderBy), b.ID)
+	})
+	if arg.AfterAt.Valid {
+		items = slices.DeleteFunc(items, func(p database.GetPostsByUserRow) bool {
+			return compare(postSortTime(p, arg.OrderBy), p.ID, arg.AfterAt.Time, arg.AfterID.UUID) <= 0
+		})
+	}
+	if len(items) > int(arg.Limit) {
+		items = items[:arg.Limit]
+	}
+	return items, nil
+}
+
+func (q *queries) GetRecentPostsByUser(ctx context.Context, arg database.GetRecentPostsByUserParams) ([]database.Post, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	followed := map[uuid.UUID]bool{}
+	for _, f := range q.d.feedFollows {
+		if f.UserID == arg.UserID {
+			followed[f.FeedID] = true
+		}
+	}
+	var items []database.Post
+	for _, p := range q.d.posts {
+		if !followed[p.FeedID] || (arg.FeedID.Valid && p.FeedID != arg.FeedID.UUID) {
+			continue
+		}
+		items = append(items, p)
+	}
+	slices.SortStableFunc(items, func(a, b database.Post) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	if len(items) > int(arg.Limit) {
+		items = items[:arg.Limit]
+	}
+	return items, nil
+}
+
+func (q *queries) GetSnapshotStorage(ctx context.Context) (database.GetSnapshotStorageRow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	row := database.GetSnapshotStorageRow{SnapshotCount: int64(len(q.d.fetchSnapshots))}
+	for _, s := range q.d.fetchSnapshots {
+		row.Bytes += int64(len(s.Body))
+	}
+	return row, nil
+}
+
+func (q *queries) GetStarterPack(ctx context.Context, id uuid.UUID) (database.StarterPack, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, p := range q.d.starterPacks {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return database.StarterPack{}, sql.ErrNoRows
+}
+
+func (q *queries) GetStarterPackFeeds(ctx context.Context, starterPackID uuid.UUID) ([]database.Feed, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.Feed
+	for _, spf := range q.d.starterPackFeeds {
+		if spf.StarterPackID != starterPackID {
+			continue
+		}
+		if feed, ok := q.feed(spf.FeedID); ok {
+			items = append(items, feed)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.Feed) int { return strings.Compare(a.Name, b.Name) })
+	return items, nil
+}
+
+func (q *queries) GetUnclaimedSCIMUser(ctx context.Context, arg database.GetUnclaimedSCIMUserParams) (database.User, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	provisioned, claimed := map[uuid.UUID]bool{}, map[uuid.UUID]bool{}
+	for _, i := range q.d.userIdentities {
+		switch i.Backend {
+		case "scim":
+			provisioned[i.UserID] = true
+		case arg.Backend:
+			claimed[i.UserID] = true
+		}
+	}
+	for _, u := range q.d.users {
+		if provisioned[u.ID] && !claimed[u.ID] && strings.EqualFold(u.Name, arg.Name) {
+			return u, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (q *queries) GetUser(ctx context.Context, id uuid.UUID) (database.User, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, u := range q.d.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (q *queries) GetUserAuthorMutes(ctx context.Context, userID uuid.UUID) ([]database.AuthorMute, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.AuthorMute
+	for _, m := range q.d.authorMutes {
+		if m.UserID == userID {
+			items = append(items, m)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.AuthorMute) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return items, nil
+}
+
+func (q *queries) GetUserByApiKey(ctx context.Context, apiKey string) (database.User, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, u := range q.d.users {
+		if u.ApiKey == apiKey {
+			return u, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (q *queries) GetUserByIdentity(ctx context.Context, arg database.GetUserByIdentityParams) (database.User, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, i := range q.d.userIdentities {
+		if i.Backend == arg.Backend && i.Subject == arg.Subject {
+			for _, u := range q.d.users {
+				if u.ID == i.UserID {
+					return u, nil
+				}
+			}
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (q *queries) GetUserByName(ctx context.Context, lower string) (database.User, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, u := range q.d.users {
+		if strings.EqualFold(u.Name, lower) {
+			return u, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (q *queries) GetUserFeedFollows(ctx context.Context, userID uuid.UUID) ([]database.FeedFollow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.FeedFollow
+	for _, f := range q.d.feedFollows {
+		if f.UserID == userID {
+			items = append(items, f)
+		}
+	}
+	return items, nil
+}
+
+func (q *queries) GetUserFeedFollowsPage(ctx context.Context, arg database.GetUserFeedFollowsPageParams) ([]database.FeedFollow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	compare := func(a, b database.FeedFollow) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return compareUUIDs(a.ID, b.ID)
+	}
+	after := database.FeedFollow{CreatedAt: arg.AfterAt.Time, ID: arg.AfterID.UUID}
+	var items []database.FeedFollow
+	for _, f := range q.d.feedFollows {
+		if f.UserID == arg.UserID && (!arg.AfterAt.Valid || compare(f, after) > 0) {
+			items = append(items, f)
+		}
+	}
+	slices.SortFunc(items, compare)
+	if len(items) > int(arg.Limit) {
+		items = items[:arg.Limit]
+	}
+	return items, nil
+}
+
+func (q *queries) GetUserFeedRepublishes(ctx context.Context, userID uuid.UUID) ([]database.FeedRepublish, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.FeedRepublish
+	for _, r := range q.d.feedRepublishes {
+		if r.UserID == userID {
+			items = append(items, r)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.FeedRepublish) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return items, nil
+}
+
+func (q *queries) GetUserNotificationChannels(ctx context.Context, userID uuid.UUID) ([]database.NotificationChannel, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.NotificationChannel
+	for _, c := range q.d.notificationChannels {
+		if c.UserID == userID {
+			items = append(items, c)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.NotificationChannel) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return items, nil
+}
+
+func (q *queries) GetUserPostRules(ctx context.Context, userID uuid.UUID) ([]database.PostRule, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.PostRule
+	for _, r := range q.d.postRules {
+		if r.UserID == userID {
+			items = append(items, r)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.PostRule) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return items, nil
+}
+
+func (q *queries) GetUserQueue(ctx context.Context, userID uuid.UUID) ([]database.GetUserQueueRow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.GetUserQueueRow
+	for _, item := range q.d.queueItems {
+		if item.UserID != userID {
+			continue
+		}
+		p, ok := q.post(item.PostID)
+		if !ok {
+			continue
+		}
+		items = append(items, database.GetUserQueueRow{
+			Position:        item.Position,
+			ID:              p.ID,
+			CreatedAt:       p.CreatedAt,
+			UpdatedAt:       p.UpdatedAt,
+			Title:           p.Title,
+			Url:             p.Url,
+			Description:     p.Description,
+			PublishedAt:     p.PublishedAt,
+			FeedID:          p.FeedID,
+			Sensitive:       p.Sensitive,
+			PublicID:        p.PublicID,
+			Guid:            p.Guid,
+			EnclosureUrl:    p.EnclosureUrl,
+			EnclosureType:   p.EnclosureType,
+			EnclosureLength: p.EnclosureLength,
+			SourcePostID:    p.SourcePostID,
+			Author:          p.Author,
+		})
+	}
+	slices.SortStableFunc(items, func(a, b database.GetUserQueueRow) int { return int(a.Position - b.Position) })
+	return items, nil
+}
+
+func (q *queries) GetUserVirtualFeeds(ctx context.Context, userID uuid.UUID) ([]database.Feed, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.Feed
+	for _, f := range q.d.feeds {
+		if f.UserID == userID && f.Kind == "virtual" {
+			items = append(items, f)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.Feed) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return items, nil
+}
+
+func (q *queries) GetVirtualFeedFilter(ctx context.Context, feedID uuid.UUID) (database.VirtualFeedFilter, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, f := range q.d.virtualFeedFilters {
+		if f.FeedID == feedID {
+			return f, nil
+		}
+	}
+	return database.VirtualFeedFilter{}, sql.ErrNoRows
+}
+
+func (q *queries) GetVirtualFeedSourceIDs(ctx context.Context, virtualFeedID uuid.UUID) ([]uuid.UUID, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []uuid.UUID
+	for _, s := range q.d.virtualFeedSources {
+		if s.VirtualFeedID == virtualFeedID {
+			items = append(items, s.SourceFeedID)
+		}
+	}
+	slices.SortFunc(items, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+	return items, nil
+}
+
+func (q *queries) GetVirtualFeedsForSource(ctx context.Context, sourceFeedID uuid.UUID) ([]database.VirtualFeedFilter, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.VirtualFeedFilter
+	for _, f := range q.d.virtualFeedFilters {
+		if slices.Contains(q.d.virtualFeedSources, database.VirtualFeedSource{VirtualFeedID: f.FeedID, SourceFeedID: sourceFeedID}) {
+			items = append(items, f)
+		}
+	}
+	return items, nil
+}
+
+func (q *queries) GetWebhookDelivery(ctx context.Context, arg database.GetWebhookDeliveryParams) (database.WebhookDelivery, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, d := range q.d.webhookDeliveries {
+		if d.ID == arg.ID && d.ChannelID == arg.ChannelID {
+			return d, nil
+		}
+	}
+	return database.WebhookDelivery{}, sql.ErrNoRows
+}
+
+func (q *queries) ListActiveFollowerCounts(ctx context.Context) ([]database.ListActiveFollowerCountsRow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	banned := map[uuid.UUID]bool{}
+	for _, u := range q.d.users {
+		banned[u.ID] = u.BannedAt.Valid
+	}
+	counts := map[uuid.UUID]int64{}
+	for _, f := range q.d.feedFollows {
+		if _, ok := banned[f.UserID]; ok && !banned[f.UserID] {
+			counts[f.FeedID]++
+		}
+	}
+	items := make([]database.ListActiveFollowerCountsRow, 0, len(counts))
+	for feedID, n := range counts {
+		items = append(items, database.ListActiveFollowerCountsRow{FeedID: feedID, Followers: n})
+	}
+	return items, nil
+}
+
+func (q *queries) ListAdmins(ctx context.Context) ([]database.User, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var users []database.User
+	for _, u := range q.d.users {
+		if u.IsAdmin && !u.BannedAt.Valid {
+			users = append(users, u)
+		}
+	}
+	slices.SortStableFunc(users, func(a, b database.User) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	return users, nil
+}
+
+func (q *queries) ListAuditLog(ctx context.Context, limit int32) ([]database.AuditLog, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	items := append([]database.AuditLog(nil), q.d.auditLog...)
+	slices.SortStableFunc(items, func(a, b database.AuditLog) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	if len(items) > int(limit) {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func (q *queries) ListDomainRules(ctx context.Context) ([]database.DomainRule, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	items := append([]database.DomainRule(nil), q.d.domainRules...)
+	slices.SortStableFunc(items, func(a, b database.DomainRule) int {
+		if c := strings.Compare(a.Kind, b.Kind); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Domain, b.Domain)
+	})
+	return items, nil
+}
+
+func (q *queries) ListFeedFetches(ctx context.Context, arg database.ListFeedFetchesParams) ([]database.FeedFetch, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	items := []database.FeedFetch{}
+	for _, f := range q.d.feedFetches {
+		if f.FeedID == arg.FeedID {
+			items = append(items, f)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.FeedFetch) int { return b.FetchedAt.Compare(a.FetchedAt) })
+	if len(items) > int(arg.Limit) {
+		items = items[:arg.Limit]
+	}
+	return items, nil
+}
+
+func (q *queries) ListFeedPostingStats(ctx context.Context, minPosts int64) ([]database.ListFeedPostingStatsRow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.ListFeedPostingStatsRow
+	for _, f := range q.d.feeds {
+		if f.Kind != "remote" || f.DisabledAt.Valid || f.PausedAt.Valid || f.FetchFailures > 0 {
+			continue
+		}
+		row := database.ListFeedPostingStatsRow{ID: f.ID, Name: f.Name, Url: f.Url, LastFetchedAt: f.LastFetchedAt}
+		for _, p := range q.d.posts {
+			if p.FeedID != f.ID {
+				continue
+			}
+			if row.PostCount == 0 || p.CreatedAt.Before(row.FirstPostAt) {
+				row.FirstPostAt = p.CreatedAt
+			}
+			if row.PostCount == 0 || p.CreatedAt.After(row.LastPostAt) {
+				row.LastPostAt = p.CreatedAt
+			}
+			row.PostCount++
+		}
+		if row.PostCount > 0 && row.PostCount >= minPosts {
+			items = append(items, row)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.ListFeedPostingStatsRow) int { return compareUUIDs(a.ID, b.ID) })
+	return items, nil
+}
+
+func (q *queries) ListFeedReportsByStatus(ctx context.Context, status string) ([]database.FeedReport, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.FeedReport
+	for _, r := range q.d.feedReports {
+		if r.Status == status {
+			items = append(items, r)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.FeedReport) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return items, nil
+}
+
+func (q *queries) ListFeedStorage(ctx context.Context, limit int32) ([]database.ListFeedStorageRow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	byFeed := map[uuid.UUID]*database.ListFeedStorageRow{}
+	items := make([]database.ListFeedStorageRow, len(q.d.feeds))
+	for i, f := range q.d.feeds {
+		items[i] = database.ListFeedStorageRow{ID: f.ID, Name: f.Name, Url: f.Url, UserID: f.UserID}
+		byFeed[f.ID] = &items[i]
+	}
+	for _, p := range q.d.posts {
+		if row, ok := byFeed[p.FeedID]; ok {
+			row.PostCount++
+			row.PostBytes += postBytes(p)
+		}
+	}
+	for _, s := range q.d.fetchSnapshots {
+		if row, ok := byFeed[s.FeedID]; ok {
+			row.SnapshotCount++
+			row.SnapshotBytes += int64(len(s.Body))
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.ListFeedStorageRow) int {
+		return int((b.PostBytes + b.SnapshotBytes) - (a.PostBytes + a.SnapshotBytes))
+	})
+	return items[:min(len(items), int(limit))], nil
+}
+
+func (q *queries) ListFeedUrlHistory(ctx context.Context, feedID uuid.UUID) ([]database.FeedUrlHistory, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.FeedUrlHistory
+	for _, h := range q.d.feedUrlHistory {
+		if h.FeedID == feedID {
+			items = append(items, h)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.FeedUrlHistory) int { return b.ChangedAt.Compare(a.ChangedAt) })
+	return items, nil
+}
+
+func (q *queries) ListFeeds(ctx context.Context, arg database.ListFeedsParams) ([]database.Feed, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.Feed
+	for _, f := range q.d.feeds {
+		if f.Kind == "remote" && f.Credentials == nil {
+			items = append(items, f)
+		}
+	}
+	return feedPage(items, arg.AfterID, arg.Limit), nil
+}
+
+func (q *queries) ListFeedsByLanguage(ctx context.Context, arg database.ListFeedsByLanguageParams) ([]database.Feed, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.Feed
+	for _, f := range q.d.feeds {
+		if f.Kind == "remote" && f.Credentials == nil && slices.Contains(arg.Languages, f.Language) {
+			items = append(items, f)
+		}
+	}
+	return feedPage(items, arg.AfterID, arg.Limit), nil
+}
+
+func (q *queries) ListFeedsNeedingIcons(ctx context.Context, arg database.ListFeedsNeedingIconsParams) ([]database.Feed, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	fetchedAt := map[uuid.UUID]time.Time{}
+	for _, i := range q.d.feedIcons {
+		fetchedAt[i.FeedID] = i.FetchedAt
+	}
+	var items []database.Feed
+	for _, f := range q.d.feeds {
+		t, ok := fetchedAt[f.ID]
+		if f.Kind == "remote" && (!ok || t.Before(arg.StaleBefore)) {
+			items = append(items, f)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.Feed) int { return fetchedAt[a.ID].Compare(fetchedAt[b.ID]) })
+	if len(items) > int(arg.Limit) {
+		items = items[:arg.Limit]
+	}
+	return items, nil
+}
+
+func (q *queries) ListFeedsPausedSince(ctx context.Context, since time.Time) ([]database.Feed, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.Feed
+	for _, f := range q.d.feeds {
+		if f.PausedAt.Valid && f.PausedAt.Time.After(since) {
+			items = append(items, f)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.Feed) int { return a.PausedAt.Time.Compare(b.PausedAt.Time) })
+	return items, nil
+}
+
+// ListNewlyFailingFeeds mirrors the SQL: a feed's current run of failures
+// is its failed fetches since its last successful one.
+
+func (q *queries) ListFetchSnapshots(ctx context.Context, feedID uuid.UUID) ([]database.ListFetchSnapshotsRow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.ListFetchSnapshotsRow
+	for _, s := range q.d.fetchSnapshots {
+		if s.FeedID == feedID {
+			items = append(items, database.ListFetchSnapshotsRow{
+				ID:          s.ID,
+				FeedID:      s.FeedID,
+				FetchedAt:   s.FetchedAt,
+				ContentType: s.ContentType,
+				Size:        s.Size,
+				Truncated:   s.Truncated,
+			})
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.ListFetchSnapshotsRow) int { return b.FetchedAt.Compare(a.FetchedAt) })
+	return items, nil
+}
+
+func (q *queries) ListFollowFetchIntervals(ctx context.Context) ([]database.ListFollowFetchIntervalsRow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	intervals := map[uuid.UUID]int32{}
+	for _, f := range q.d.feedFollows {
+		interval := int32(900)
+		switch f.Priority {
+		case "high":
+			interval = 300
+		case "low":
+			interval = 3600
+		}
+		if current, ok := intervals[f.FeedID]; !ok || interval < current {
+			intervals[f.FeedID] = interval
+		}
+	}
+	items := make([]database.ListFollowFetchIntervalsRow, 0, len(intervals))
+	for feedID, interval := range intervals {
+		items = append(items, database.ListFollowFetchIntervalsRow{FeedID: feedID, IntervalSeconds: interval})
+	}
+	return items, nil
+}
+
+func (q *queries) ListNewlyFailingFeeds(ctx context.Context, since time.Time) ([]database.ListNewlyFailingFeedsRow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.ListNewlyFailingFeedsRow
+	for _, f := range q.d.feeds {
+		if f.FetchFailures == 0 || f.PausedAt.Valid {
+			continue
+		}
+		var lastOK time.Time
+		for _, fetch := range q.d.feedFetches {
+			if fetch.FeedID == f.ID && fetch.Error == "" && fetch.FetchedAt.After(lastOK) {
+				lastOK = fetch.FetchedAt
+			}
+		}
+		var failingSince time.Time
+		for _, fetch := range q.d.feedFetches {
+			if fetch.FeedID != f.ID || fetch.Error == "" || !fetch.FetchedAt.After(lastOK) {
+				continue
+			}
+			if failingSince.IsZero() || fetch.FetchedAt.Before(failingSince) {
+				failingSince = fetch.FetchedAt
+			}
+		}
+		if failingSince.IsZero() || !failingSince.After(since) {
+			continue
+		}
+		items = append(items, database.ListNewlyFailingFeedsRow{
+			ID:             f.ID,
+			Name:           f.Name,
+			Url:            f.Url,
+			FetchFailures:  f.FetchFailures,
+			LastFetchError: f.LastFetchError,
+			FailingSince:   failingSince,
+		})
+	}
+	slices.SortStableFunc(items, func(a, b database.ListNewlyFailingFeedsRow) int { return a.FailingSince.Compare(b.FailingSince) })
+	return items, nil
+}
+
+func (q *queries) ListPostsForExport(ctx context.Context, arg database.ListPostsForExportParams) ([]database.ListPostsForExportRow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.ListPostsForExportRow
+	for _, p := range q.d.posts {
+		if !p.CreatedAt.After(arg.After) || p.CreatedAt.After(arg.Until) || p.SourcePostID.Valid {
+			continue
+		}
+		feed, ok := q.feed(p.FeedID)
+		if !ok {
+			continue
+		}
+		items = append(items, database.ListPostsForExportRow{
+			ID:              p.ID,
+			CreatedAt:       p.CreatedAt,
+			UpdatedAt:       p.UpdatedAt,
+			Title:           p.Title,
+			Url:             p.Url,
+			Description:     p.Description,
+			PublishedAt:     p.PublishedAt,
+			FeedID:          p.FeedID,
+			Sensitive:       p.Sensitive,
+			PublicID:        p.PublicID,
+			Guid:            p.Guid,
+			EnclosureUrl:    p.EnclosureUrl,
+			EnclosureType:   p.EnclosureType,
+			EnclosureLength: p.EnclosureLength,
+			SourcePostID:    p.SourcePostID,
+			Author:          p.Author,
+			FeedUrl:         feed.Url,
+		})
+	}
+	slices.SortStableFunc(items, func(a, b database.ListPostsForExportRow) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	return items, nil
+}
+
+func (q *queries) ListSchedulableFeeds(ctx context.Context, now time.Time) ([]database.Feed, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.Feed
+	for _, f := range q.d.feeds {
+		if f.Kind != "remote" || f.DisabledAt.Valid || f.PausedAt.Valid {
+			continue
+		}
+		if f.RetryAt.Valid && f.RetryAt.Time.After(now) {
+			continue
+		}
+		items = append(items, f)
+	}
+	slices.SortStableFunc(items, func(a, b database.Feed) int {
+		return compareNullTime(a.LastFetchedAt, b.LastFetchedAt, true)
+	})
+	return items, nil
+}
+
+func (q *queries) ListStarterPacks(ctx context.Context) ([]database.StarterPack, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	items := append([]database.StarterPack(nil), q.d.starterPacks...)
+	slices.SortStableFunc(items, func(a, b database.StarterPack) int { return strings.Compare(a.Name, b.Name) })
+	return items, nil
+}
+
+// ListTableSizes reports row counts only; nothing here is on disk.
+func (q *queries) ListTableSizes(ctx context.Context) ([]database.ListTableSizesRow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	counts := map[string]int{
+		"audit_log":             len(q.d.auditLog),
+		"author_mutes":          len(q.d.authorMutes),
+		"domain_rules":          len(q.d.domainRules),
+		"feed_fetches":          len(q.d.feedFetches),
+		"feed_follows":          len(q.d.feedFollows),
+		"feed_icons":            len(q.d.feedIcons),
+		"feed_reports":          len(q.d.feedReports),
+		"feed_republishes":      len(q.d.feedRepublishes),
+		"feed_url_history":      len(q.d.feedUrlHistory),
+		"feeds":                 len(q.d.feeds),
+		"fetch_snapshots":       len(q.d.fetchSnapshots),
+		"notification_channels": len(q.d.notificationChannels),
+		"post_emails":           len(q.d.postEmails),
+		"post_exports":          len(q.d.postExports),
+		"post_revisions":        len(q.d.postRevisions),
+		"post_rules":            len(q.d.postRules),
+		"post_snoozes":          len(q.d.postSnoozes),
+		"posts":                 len(q.d.posts),
+		"push_subscriptions":    len(q.d.pushSubscriptions),
+		"reading_queue_items":   len(q.d.queueItems),
+		"starter_pack_feeds":    len(q.d.starterPackFeeds),
+		"starter_packs":         len(q.d.starterPacks),
+		"user_identities":       len(q.d.userIdentities),
+		"users":                 len(q.d.users),
+		"virtual_feed_filters":  len(q.d.virtualFeedFilters),
+		"virtual_feed_sources":  len(q.d.virtualFeedSources),
+		"webhook_deliveries":    len(q.d.webhookDeliveries),
+	}
+	items := make([]database.ListTableSizesRow, 0, len(counts))
+	for name, n := range counts {
+		items = append(items, database.ListTableSizesRow{TableName: name, RowCount: int64(n)})
+	}
+	slices.SortFunc(items, func(a, b database.ListTableSizesRow) int { return strings.Compare(a.TableName, b.TableName) })
+	return items, nil
+}
+
+func (q *queries) ListUserStorage(ctx context.Context, limit int32) ([]database.ListUserStorageRow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	byUser := map[uuid.UUID]*database.ListUserStorageRow{}
+	items := make([]database.ListUserStorageRow, len(q.d.users))
+	for i, u := range q.d.users {
+		items[i] = database.ListUserStorageRow{ID: u.ID, Name: u.Name}
+		byUser[u.ID] = &items[i]
+	}
+	owners := map[uuid.UUID]uuid.UUID{}
+	for _, f := range q.d.feeds {
+		owners[f.ID] = f.UserID
+		if row, ok := byUser[f.UserID]; ok {
+			row.FeedCount++
+		}
+	}
+	for _, f := range q.d.feedFollows {
+		if row, ok := byUser[f.UserID]; ok {
+			row.FollowCount++
+		}
+	}
+	for _, i := range q.d.queueItems {
+		if row, ok := byUser[i.UserID]; ok {
+			row.SavedItemCount++
+		}
+	}
+	for _, p := range q.d.posts {
+		if row, ok := byUser[owners[p.FeedID]]; ok {
+			row.PostCount++
+			row.PostBytes += postBytes(p)
+		}
+	}
+	for _, s := range q.d.fetchSnapshots {
+		if row, ok := byUser[owners[s.FeedID]]; ok {
+			row.SnapshotBytes += int64(len(s.Body))
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.ListUserStorageRow) int {
+		return int((b.PostBytes + b.SnapshotBytes) - (a.PostBytes + a.SnapshotBytes))
+	})
+	return items[:min(len(items), int(limit))], nil
+}
+
+func (q *queries) ListUsers(ctx context.Context, arg database.ListUsersParams) ([]database.User, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	users := slices.Clone(q.d.users)
+	slices.SortStableFunc(users, func(a, b database.User) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	start := min(int(arg.Offset), len(users))
+	end := min(start+int(arg.Limit), len(users))
+	return users[start:end], nil
+}
+
+func (q *queries) ListWebhookDeliveries(ctx context.Context, arg database.ListWebhookDeliveriesParams) ([]database.WebhookDelivery, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []database.WebhookDelivery
+	for _, d := range q.d.webhookDeliveries {
+		if d.ChannelID == arg.ChannelID {
+			items = append(items, d)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.WebhookDelivery) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	if len(items) > int(arg.Limit) {
+		items = items[:arg.Limit]
+	}
+	return items, nil
+}
+
+func (q *queries) MarkFeedFetched(ctx context.Context, arg database.MarkFeedFetchedParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, f := range q.d.feeds {
+		if f.ID == arg.ID {
+			q.d.feeds[i].LastFetchedAt = arg.LastFetchedAt
+			q.d.feeds[i].UpdatedAt = arg.LastFetchedAt.Time
+		}
+	}
+	return nil
+}
+
+func (q *queries) MarkSnoozeNotified(ctx context.Context, arg database.MarkSnoozeNotifiedParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, s := range q.d.postSnoozes {
+		if s.ID == arg.ID {
+			q.d.postSnoozes[i].NotifiedAt = arg.NotifiedAt
+		}
+	}
+	return nil
+}
+
+func (q *queries) MoveFeedPosts(ctx context.Context, arg database.MoveFeedPostsParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	guids := map[string]bool{}
+	for _, p := range q.d.posts {
+		if p.FeedID == arg.TargetID {
+			guids[p.Guid] = true
+		}
+	}
+	for i, p := range q.d.posts {
+		if p.FeedID == arg.SourceID && !guids[p.Guid] {
+			q.d.posts[i].FeedID = arg.TargetID
+		}
+	}
+	return nil
+}
+
+func (q *queries) MoveUserAuditLog(ctx context.Context, arg database.MoveUserAuditLogParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, e := range q.d.auditLog {
+		if e.ActorID.Valid && e.ActorID.UUID == arg.SourceID {
+			q.d.auditLog[i].ActorID = uuid.NullUUID{UUID: arg.TargetID, Valid: true}
+		}
+	}
+	return nil
+}
+
+func (q *queries) MoveUserAuthorMutes(ctx context.Context, arg database.MoveUserAuthorMutesParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	targetAuthors := map[string]bool{}
+	for _, m := range q.d.authorMutes {
+		if m.UserID == arg.TargetID {
+			targetAuthors[strings.ToLower(m.Author)] = true
+		}
+	}
+	for i, m := range q.d.authorMutes {
+		if m.UserID == arg.SourceID && !targetAuthors[strings.ToLower(m.Author)] {
+			q.d.authorMutes[i].UserID = arg.TargetID
+		}
+	}
+	return nil
+}
+
+func (q *queries) MoveUserFeedFollows(ctx context.Context, arg database.MoveUserFeedFollowsParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	targetFeeds := map[uuid.UUID]bool{}
+	for _, f := range q.d.feedFollows {
+		if f.UserID == arg.TargetID {
+			targetFeeds[f.FeedID] = true
+		}
+	}
+	for i, f := range q.d.feedFollows {
+		if f.UserID == arg.SourceID && !targetFeeds[f.FeedID] {
+			q.d.feedFollows[i].UserID = arg.TargetID
+			q.d.feedFollows[i].UpdatedAt = arg.UpdatedAt
+		}
+	}
+	return nil
+}
+
+func (q *queries) MoveUserFeedReports(ctx context.Context, arg database.MoveUserFeedReportsParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, r := range q.d.feedReports {
+		if r.ReporterID == arg.SourceID {
+			q.d.feedReports[i].ReporterID = arg.TargetID
+		}
+	}
+	return nil
+}
+
+func (q *queries) MoveUserFeedRepublishes(ctx context.Context, arg database.MoveUserFeedRepublishesParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, r := range q.d.feedRepublishes {
+		if r.UserID == arg.SourceID {
+			q.d.feedRepublishes[i].UserID = arg.TargetID
+			q.d.feedRepublishes[i].UpdatedAt = arg.UpdatedAt
+		}
+	}
+	return nil
+}
+
+func (q *queries) MoveUserFeeds(ctx context.Context, arg database.MoveUserFeedsParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, f := range q.d.feeds {
+		if f.UserID == arg.SourceID && f.Kind != "inbox" {
+			q.d.feeds[i].UserID = arg.TargetID
+		}
+	}
+	return nil
+}
+
+func (q *queries) MoveUserIdentities(ctx context.Context, arg database.MoveUserIdentitiesParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, id := range q.d.userIdentities {
+		if id.UserID == arg.SourceID {
+			q.d.userIdentities[i].UserID = arg.TargetID
+		}
+	}
+	return nil
+}
+
+func (q *queries) MoveUserNotificationChannels(ctx context.Context, arg database.MoveUserNotificationChannelsParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, c := range q.d.notificationChannels {
+		if c.UserID == arg.SourceID {
+			q.d.notificationChannels[i].UserID = arg.TargetID
+		}
+	}
+	return nil
+}
+
+func (q *queries) MoveUserPostEmails(ctx context.Context, arg database.MoveUserPostEmailsParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, e := range q.d.postEmails {
+		if e.UserID == arg.SourceID {
+			q.d.postEmails[i].UserID = arg.TargetID
+		}
+	}
+	return nil
+}
+
+func (q *queries) MoveUserPostRules(ctx context.Context, arg database.MoveUserPostRulesParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, r := range q.d.postRules {
+		if r.UserID == arg.SourceID {
+			q.d.postRules[i].UserID = arg.TargetID
+		}
+	}
+	return nil
+}
+
+func (q *queries) MoveUserPostSnoozes(ctx context.Context, arg database.MoveUserPostSnoozesParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	targetPosts := map[uuid.UUID]bool{}
+	for _, s := range q.d.postSnoozes {
+		if s.UserID == arg.TargetID {
+			targetPosts[s.PostID] = true
+		}
+	}
+	for i, s := range q.d.postSnoozes {
+		if s.UserID == arg.SourceID && !targetPosts[s.PostID] {
+			q.d.postSnoozes[i].UserID = arg.TargetID
+		}
+	}
+	return nil
+}
+
+func (q *queries) MoveUserPushSubscriptions(ctx context.Context, arg database.MoveUserPushSubscriptionsParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, s := range q.d.pushSubscriptions {
+		if s.UserID == arg.SourceID {
+			q.d.pushSubscriptions[i].UserID = arg.TargetID
+		}
+	}
+	return nil
+}
+
+func (q *queries) MoveUserQueueItems(ctx context.Context, arg database.MoveUserQueueItemsParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var maxPosition int32
+	targetPosts := map[uuid.UUID]bool{}
+	for _, item := range q.d.queueItems {
+		if item.UserID == arg.TargetID {
+			targetPosts[item.PostID] = true
+			maxPosition = max(maxPosition, item.Position)
+		}
+	}
+	for i, item := range q.d.queueItems {
+		if item.UserID == arg.SourceID && !targetPosts[item.PostID] {
+			q.d.queueItems[i].UserID = arg.TargetID
+			q.d.queueItems[i].Position += maxPosition
+		}
+	}
+	return nil
+}
+
+func (q *queries) MoveUserReportResolutions(ctx context.Context, arg database.MoveUserReportResolutionsParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, r := range q.d.feedReports {
+		if r.ResolvedBy.Valid && r.ResolvedBy.UUID == arg.SourceID {
+			q.d.feedReports[i].ResolvedBy = uuid.NullUUID{UUID: arg.TargetID, Valid: true}
+		}
+	}
+	return nil
+}
+
+func (q *queries) PostUrlExists(ctx context.Context, url string) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return slices.ContainsFunc(q.d.posts, func(p database.Post) bool { return p.Url == url && urlKeyed(p) }), nil
+}
+
+func (q *queries) PruneFeedFetches(ctx context.Context, arg database.PruneFeedFetchesParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var times []time.Time
+	for _, f := range q.d.feedFetches {
+		if f.FeedID == arg.FeedID {
+			times = append(times, f.FetchedAt)
+		}
+	}
+	if len(times) <= int(arg.Keep) {
+		return nil
+	}
+	slices.SortFunc(times, func(a, b time.Time) int { return b.Compare(a) })
+	q.d.feedFetches = slices.DeleteFunc(q.d.feedFetches, func(f database.FeedFetch) bool {
+		return f.FeedID == arg.FeedID && (arg.Keep <= 0 || f.FetchedAt.Before(times[arg.Keep-1]))
+	})
+	return nil
+}
+
+func (q *queries) PruneFetchSnapshots(ctx context.Context, arg database.PruneFetchSnapshotsParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var times []time.Time
+	for _, s := range q.d.fetchSnapshots {
+		if s.FeedID == arg.FeedID {
+			times = append(times, s.FetchedAt)
+		}
+	}
+	if len(times) <= int(arg.Keep) {
+		return nil
+	}
+	slices.SortFunc(times, func(a, b time.Time) int { return b.Compare(a) })
+	q.d.fetchSnapshots = slices.DeleteFunc(q.d.fetchSnapshots, func(s database.FetchSnapshot) bool {
+		return s.FeedID == arg.FeedID && (arg.Keep <= 0 || s.FetchedAt.Before(times[arg.Keep-1]))
+	})
+	return nil
+}
+
+func (q *queries) PruneWebhookDeliveries(ctx context.Context, arg database.PruneWebhookDeliveriesParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var kept []database.WebhookDelivery
+	for _, d := range q.d.webhookDeliveries {
+		if d.ChannelID == arg.ChannelID {
+			kept = append(kept, d)
+		}
+	}
+	slices.SortStableFunc(kept, func(a, b database.WebhookDelivery) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	keep := map[uuid.UUID]bool{}
+	for i, d := range kept {
+		keep[d.ID] = i < int(arg.Limit)
+	}
+	q.d.webhookDeliveries = slices.DeleteFunc(q.d.webhookDeliveries, func(d database.WebhookDelivery) bool {
+		return d.ChannelID == arg.ChannelID && !keep[d.ID]
+	})
+	return nil
+}
+
+func (q *queries) RecordFeedFetchFailure(ctx context.Context, arg database.RecordFeedFetchFailureParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, f := range q.d.feeds {
+		if f.ID == arg.ID {
+			q.d.feeds[i].FetchFailures = arg.FetchFailures
+			q.d.feeds[i].LastFetchError = arg.LastFetchError
+			q.d.feeds[i].RetryAt = arg.RetryAt
+			q.d.feeds[i].PausedAt = arg.PausedAt
+		}
+	}
+	return nil
+}
+
+func (q *queries) RecordFeedGap(ctx context.Context, arg database.RecordFeedGapParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, f := range q.d.feeds {
+		if f.ID == arg.ID {
+			q.d.feeds[i].MissedItemsAt = arg.MissedItemsAt
+			q.d.feeds[i].GapIntervalSeconds = arg.GapIntervalSeconds
+		}
+	}
+	return nil
+}
+
+func (q *queries) RemoveStarterPackFeed(ctx context.Context, arg database.RemoveStarterPackFeedParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.d.starterPackFeeds = slices.DeleteFunc(q.d.starterPackFeeds, func(f database.StarterPackFeed) bool {
+		return f == database.StarterPackFeed(arg)
+	})
+	return nil
+}
+
+func (q *queries) ReorderQueueItems(ctx context.Context, arg database.ReorderQueueItemsParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for position, postID := range arg.PostIds {
+		for i, item := range q.d.queueItems {
+			if item.UserID == arg.UserID && item.PostID == postID {
+				q.d.queueItems[i].Position = int32(position + 1)
+			}
+		}
+	}
+	return nil
+}
+
+func (q *queries) ResolveFeedReport(ctx context.Context, arg database.ResolveFeedReportParams) (database.FeedReport, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, r := range q.d.feedReports {
+		if r.ID == arg.ID {
+			r.Status = arg.Status
+			r.ResolvedBy = arg.ResolvedBy
+			r.ResolvedAt = arg.ResolvedAt
+			r.UpdatedAt = arg.ResolvedAt.Time
+			q.d.feedReports[i] = r
+			return r, nil
+		}
+	}
+	return database.FeedReport{}, sql.ErrNoRows
+}
+
+func (q *queries) ResumeFeed(ctx context.Context, arg database.ResumeFeedParams) (database.Feed, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, f := range q.d.feeds {
+		if f.ID == arg.ID {
+			f.PausedAt = sql.NullTime{}
+			f.FetchFailures = 0
+			f.LastFetchError = ""
+			f.RetryAt = sql.NullTime{}
+			f.UpdatedAt = arg.UpdatedAt
+			q.d.feeds[i] = f
+			return f, nil
+		}
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
+
+func (q *queries) RotateUserApiKey(ctx context.Context, arg database.RotateUserApiKeyParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, u := range q.d.users {
+		if u.ID == arg.ID {
+			q.d.users[i].ApiKey = newAPIKey()
+			q.d.users[i].UpdatedAt = arg.UpdatedAt
+		}
+	}
+	return nil
+}
+
+func (q *queries) SetFeedCacheValidators(ctx context.Context, arg database.SetFeedCacheValidatorsParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, f := range q.d.feeds {
+		if f.ID == arg.ID {
+			q.d.feeds[i].Etag = arg.Etag
+			q.d.feeds[i].LastModified = arg.LastModified
+		}
+	}
+	return nil
+}
+
+func (q *queries) SetFeedCredentials(ctx context.Context, arg database.SetFeedCredentialsParams) (database.Feed, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, f := range q.d.feeds {
+		if f.ID == arg.ID {
+			if arg.Credentials == nil && q.publicFeedAt(f.Url, f.ID) {
+				return database.Feed{}, errUniqueViolation("feeds_url_key")
+			}
+			f.Credentials = arg.Credentials
+			f.UpdatedAt = arg.UpdatedAt
+			q.d.feeds[i] = f
+			return f, nil
+		}
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
+
+func (q *queries) SetFeedDisabled(ctx context.Context, arg database.SetFeedDisabledParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, f := range q.d.feeds {
+		if f.ID == arg.ID {
+			q.d.feeds[i].DisabledAt = arg.DisabledAt
+			q.d.feeds[i].UpdatedAt = arg.UpdatedAt
+		}
+	}
+	return nil
+}
+
+func (q *queries) SetFeedFetchHeaders(ctx context.Context, arg database.SetFeedFetchHeadersParams) (database.Feed, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, f := range q.d.feeds {
+		if f.ID == arg.ID {
+			f.FetchHeaders = arg.FetchHeaders
+			f.UpdatedAt = arg.UpdatedAt
+			q.d.feeds[i] = f
+			return f, nil
+		}
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
+
+func (q *queries) SetFeedFetchInterval(ctx context.Context, arg database.SetFeedFetchIntervalParams) (database.Feed, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, f := range q.d.feeds {
+		if f.ID == arg.ID {
+			f.FetchIntervalSeconds = arg.FetchIntervalSeconds
+			f.UpdatedAt = arg.UpdatedAt
+			q.d.feeds[i] = f
+			return f, nil
+		}
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
+
+func (q *queries) SetFeedLastFetchStatus(ctx context.Context, arg database.SetFeedLastFetchStatusParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, f := range q.d.feeds {
+		if f.ID == arg.ID {
+			q.d.feeds[i].LastFetchStatus = arg.LastFetchStatus
+			return nil
+		}
+	}
+	return nil
+}
+
+func (q *queries) SetFeedLocale(ctx context.Context, arg database.SetFeedLocaleParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, f := range q.d.feeds {
+		if f.ID == arg.ID && (f.Language != arg.Language || f.Country != arg.Country) {
+			q.d.feeds[i].Language = arg.Language
+			q.d.feeds[i].Country = arg.Country
+			q.d.feeds[i].UpdatedAt = arg.UpdatedAt
+		}
+	}
+	return nil
+}
+
+func (q *queries) SetFeedQuietWeekdays(ctx context.Context, arg database.SetFeedQuietWeekdaysParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, f := range q.d.feeds {
+		if f.ID == arg.ID {
+			q.d.feeds[i].QuietWeekdays = arg.QuietWeekdays
+		}
+	}
+	return nil
+}
+
+func (q *queries) SetFeedSchedule(ctx context.Context, arg database.SetFeedScheduleParams) (database.Feed, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, f := range q.d.feeds {
+		if f.ID == arg.ID {
+			f.Schedule = arg.Schedule
+			f.UpdatedAt = arg.UpdatedAt
+			q.d.feeds[i] = f
+			return f, nil
+		}
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
+
+func (q *queries) SetFeedSensitive(ctx context.Context, arg database.SetFeedSensitiveParams) (database.Feed, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, f := range q.d.feeds {
+		if f.ID == arg.ID {
+			f.Sensitive = arg.Sensitive
+			f.UpdatedAt = arg.UpdatedAt
+			q.d.feeds[i] = f
+			return f, nil
+		}
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
+
+func (q *queries) SetFeedUnfollowedAt(ctx context.Context, arg database.SetFeedUnfollowedAtParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, f := range q.d.feeds {
+		if f.ID == arg.ID {
+			q.d.feeds[i].UnfollowedAt = arg.UnfollowedAt
+			return nil
+		}
+	}
+	return nil
+}
+
+func (q *queries) SetUserAdmin(ctx context.Context, arg database.SetUserAdminParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, u := range q.d.users {
+		if u.ID == arg.ID {
+			q.d.users[i].IsAdmin = arg.IsAdmin
+			q.d.users[i].UpdatedAt = arg.UpdatedAt
+		}
+	}
+	return nil
+}
+
+func (q *queries) SetUserBanned(ctx context.Context, arg database.SetUserBannedParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, u := range q.d.users {
+		if u.ID == arg.ID {
+			q.d.users[i].BannedAt = arg.BannedAt
+			q.d.users[i].UpdatedAt = arg.UpdatedAt
+		}
+	}
+	return nil
+}
+
+func (q *queries) UpdateFeedFollow(ctx context.Context, arg database.UpdateFeedFollowParams) (database.FeedFollow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, f := range q.d.feedFollows {
+		if f.ID == arg.ID && f.UserID == arg.UserID {
+			if arg.Priority.Valid {
+				f.Priority = arg.Priority.String
+			}
+			if arg.OrderByIngested.Valid {
+				f.OrderByIngested = arg.OrderByIngested.Bool
+			}
+			f.UpdatedAt = arg.UpdatedAt
+			q.d.feedFollows[i] = f
+			return f, nil
+		}
+	}
+	return database.FeedFollow{}, sql.ErrNoRows
+}
+
+func (q *queries) UpdateFeedUrl(ctx context.Context, arg database.UpdateFeedUrlParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, f := range q.d.feeds {
+		if f.ID == arg.ID {
+			if q.publicFeedAt(arg.Url, f.ID) && f.Credentials == nil {
+				return errUniqueViolation("feeds_url_key")
+			}
+			q.d.feeds[i].Url = arg.Url
+			q.d.feeds[i].UpdatedAt = arg.UpdatedAt
+		}
+	}
+	return nil
+}
+
+func (q *queries) UpdateNotificationChannelConfig(ctx context.Context, arg database.UpdateNotificationChannelConfigParams) (database.NotificationChannel, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, c := range q.d.notificationChannels {
+		if c.ID == arg.ID && c.UserID == arg.UserID {
+			c.Config = arg.Config
+			c.UpdatedAt = arg.UpdatedAt
+			q.d.notificationChannels[i] = c
+			return c, nil
+		}
+	}
+	return database.NotificationChannel{}, sql.ErrNoRows
+}
+
+func (q *queries) UpdatePostMetadata(ctx context.Context, arg database.UpdatePostMetadataParams) (database.Post, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, p := range q.d.posts {
+		if p.ID == arg.ID {
+			if urlKeyed(p) && slices.ContainsFunc(q.d.posts, func(o database.Post) bool { return o.Url == arg.Url && o.ID != p.ID && urlKeyed(o) }) {
+				return database.Post{}, errUniqueViolation("posts_url_key")
+			}
+			p.Title = arg.Title
+			p.Url = arg.Url
+			p.Sensitive = arg.Sensitive
+			p.UpdatedAt = arg.UpdatedAt
+			q.d.posts[i] = p
+			return p, nil
+		}
+	}
+	return database.Post{}, sql.ErrNoRows
+}
+
+func (q *queries) UpdateUserProfile(ctx context.Context, arg database.UpdateUserProfileParams) (database.User, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, u := range q.d.users {
+		if u.ID == arg.ID {
+			continue
+		}
+		if strings.EqualFold(u.Name, arg.Name) {
+			return database.User{}, errUniqueViolation("users_name_key")
+		}
+		if arg.Email.Valid && u.Email.Valid && strings.EqualFold(u.Email.String, arg.Email.String) {
+			return database.User{}, errUniqueViolation("users_email_key")
+		}
+	}
+	for i, u := range q.d.users {
+		if u.ID == arg.ID {
+			u.Name = arg.Name
+			u.Email = arg.Email
+			u.AvatarUrl = arg.AvatarUrl
+			u.Bio = arg.Bio
+			u.ShowSensitive = arg.ShowSensitive
+			u.UpdatedAt = arg.UpdatedAt
+			u.PreferredLanguages = arg.PreferredLanguages
+			q.d.users[i] = u
+			return u, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (q *queries) UpsertFeedIcon(ctx context.Context, arg database.UpsertFeedIconParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	icon := database.FeedIcon(arg)
+	for i, existing := range q.d.feedIcons {
+		if existing.FeedID == arg.FeedID {
+			q.d.feedIcons[i] = icon
+			return nil
+		}
+	}
+	q.d.feedIcons = append(q.d.feedIcons, icon)
+	return nil
+}
+
+func (q *queries) UpsertPost(ctx context.Context, arg database.UpsertPostParams) (database.Post, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i := slices.IndexFunc(q.d.posts, func(p database.Post) bool { return p.FeedID == arg.FeedID && p.Guid == arg.Guid })
+	if i < 0 {
+		return q.createPost(database.Post{
+			ID:              arg.ID,
+			CreatedAt:       arg.CreatedAt,
+			UpdatedAt:       arg.UpdatedAt,
+			Title:           arg.Title,
+			Url:             arg.Url,
+			Description:     arg.Description,
+			PublishedAt:     arg.PublishedAt,
+			FeedID:          arg.FeedID,
+			Sensitive:       arg.Sensitive,
+			Guid:            arg.Guid,
+			EnclosureUrl:    arg.EnclosureUrl,
+			EnclosureType:   arg.EnclosureType,
+			EnclosureLength: arg.EnclosureLength,
+			Author:          arg.Author,
+		})
+	}
+	p := q.d.posts[i]
+	unchanged := p.Title == arg.Title && p.Url == arg.Url && p.Description == arg.Description &&
+		p.PublishedAt.Valid == arg.PublishedAt.Valid && p.PublishedAt.Time.Equal(arg.PublishedAt.Time) &&
+		p.EnclosureUrl == arg.EnclosureUrl && p.EnclosureType == arg.EnclosureType && p.EnclosureLength == arg.EnclosureLength &&
+		p.Author == arg.Author
+	corrected := slices.ContainsFunc(q.d.postRevisions, func(r database.PostRevision) bool { return r.PostID == p.ID })
+	if unchanged || corrected {
+		return database.Post{}, sql.ErrNoRows
+	}
+	if urlKeyed(p) && slices.ContainsFunc(q.d.posts, func(o database.Post) bool { return o.Url == arg.Url && o.ID != p.ID && urlKeyed(o) }) {
+		return database.Post{}, errUniqueViolation("posts_url_key")
+	}
+	p.Title = arg.Title
+	p.Url = arg.Url
+	p.Description = arg.Description
+	p.PublishedAt = arg.PublishedAt
+	p.EnclosureUrl = arg.EnclosureUrl
+	p.EnclosureType = arg.EnclosureType
+	p.EnclosureLength = arg.EnclosureLength
+	p.Author = arg.Author
+	p.UpdatedAt = arg.UpdatedAt
+	q.d.posts[i] = p
+	return p, nil
+}
+
+func (q *queries) UpsertPostSnooze(ctx context.Context, arg database.UpsertPostSnoozeParams) (database.PostSnooze, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, s := range q.d.postSnoozes {
+		if s.UserID == arg.UserID && s.PostID == arg.PostID {
+			s.WakeAt = arg.WakeAt
+			s.Notify = arg.Notify
+			s.NotifiedAt = sql.NullTime{}
+			q.d.postSnoozes[i] = s
+			return s, nil
+		}
+	}
+	snooze := database.PostSnooze{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UserID:    arg.UserID,
+		PostID:    arg.PostID,
+		WakeAt:    arg.WakeAt,
+		Notify:    arg.Notify,
+	}
+	q.d.postSnoozes = append(q.d.postSnoozes, snooze)
+	return snooze, nil
+}
+
+func (q *queries) UserFollowsFeed(ctx context.Context, arg database.UserFollowsFeedParams) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, f := range q.d.feedFollows {
+		if f.UserID == arg.UserID && f.FeedID == arg.FeedID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (q *queries) UserNameExists(ctx context.Context, name string) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, u := range q.d.users {
+		if strings.EqualFold(u.Name, name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// compareNullTime orders NULLs first or last like the SQL NULLS FIRST/LAST
+// clauses.
+func compareNullTime(a, b sql.NullTime, nullsFirst bool) int {
+	switch {
+	case !a.Valid && !b.Valid:
+		return 0
+	case !a.Valid:
+		if nullsFirst {
+			return -1
+		}
+		return 1
+	case !b.Valid:
+		if nullsFirst {
+			return 1
+		}
+		return -1
+	}
+	return a.Time.Compare(b.Time)
+}
+
+// The integrity queries look for rows that foreign keys would have removed
+// in Postgres. Nothing enforces those keys here, so they can actually occur.
+
+func (q *queries) userExists(id uuid.UUID) bool {
+	return slices.ContainsFunc(q.d.users, func(u database.User) bool { return u.ID == id })
+}
+
+func (q *queries) orphanFeedFollow(f database.FeedFollow) bool {
+	_, ok := q.feed(f.FeedID)
+	return !ok || !q.userExists(f.UserID)
+}
+
+func (q *queries) foreignInboxFollow(f database.FeedFollow) bool {
+	feed, ok := q.feed(f.FeedID)
+	return ok && feed.Kind == "inbox" && feed.UserID != f.UserID
+}
+
+// addPostCopies adds to postIDs the virtual feed copies of the posts in it,
+// which go when their source post does.
+func (q *queries) addPostCopies(postIDs map[uuid.UUID]bool) {
+	for _, p := range q.d.posts {
+		if p.SourcePostID.Valid && postIDs[p.SourcePostID.UUID] {
+			postIDs[p.ID] = true
+		}
+	}
+}
+
+func (q *queries) orphanPost(p database.Post) bool {
+	_, ok := q.feed(p.FeedID)
+	return !ok
+}
+
+func (q *queries) orphanPostEmail(e database.PostEmail) bool {
+	_, ok := q.post(e.PostID)
+	return !ok || !q.userExists(e.UserID)
+}
+
+func (q *queries) orphanPostSnooze(s database.PostSnooze) bool {
+	_, ok := q.post(s.PostID)
+	return !ok || !q.userExists(s.UserID)
+}
+
+func (q *queries) orphanQueueItem(i database.ReadingQueueItem) bool {
+	_, ok := q.post(i.PostID)
+	return !ok || !q.userExists(i.UserID)
+}
+
+func countFunc[T any](items []T, match func(T) bool) int64 {
+	var n int64
+	for _, item := range items {
+		if match(item) {
+			n++
+		}
+	}
+	return n
+}
+
+func deleteFunc[T any](items *[]T, match func(T) bool) int64 {
+	before := len(*items)
+	*items = slices.DeleteFunc(*items, match)
+	return int64(before - len(*items))
+}
+
+// feedPage orders feeds by ID and returns those after afterID, up to
+// limit, as ListFeeds does.
+func feedPage(feeds []database.Feed, afterID uuid.NullUUID, limit sql.NullInt32) []database.Feed {
+	slices.SortStableFunc(feeds, func(a, b database.Feed) int { return compareUUIDs(a.ID, b.ID) })
+	if afterID.Valid {
+		feeds = slices.DeleteFunc(feeds, func(f database.Feed) bool { return compareUUIDs(f.ID, afterID.UUID) <= 0 })
+	}
+	if limit.Valid && len(feeds) > int(limit.Int32) {
+		feeds = feeds[:limit.Int32]
+	}
+	return feeds
+}
+
+// compareUUIDs orders UUIDs as Postgres does, byte by byte.
+func compareUUIDs(a, b uuid.UUID) int {
+	return bytes.Compare(a[:], b[:])
+}
+
+// postSortTime mirrors the sort time of GetPostsByUser: ingestion time, the
+// publication date unless the follow opts out of trusting it, or else the
+// time the post was last updated.
+func postSortTime(row database.GetPostsByUserRow, orderBy string) time.Time {
+	switch orderBy {
+	case "ingested_at":
+		return row.CreatedAt
+	case "published_at":
+		if !row.OrderByIngested && row.PublishedAt.Valid {
+			return row.PublishedAt.Time
+		}
+		return row.CreatedAt
+	}
+	return row.UpdatedAt
+}
+
+// postMatchesDeletion mirrors the WHERE of CountPostsForDeletion and
+// DeletePostsBatch.
+func postMatchesDeletion(p database.Post, feedID uuid.NullUUID, before sql.NullTime) bool {
+	if feedID.Valid && p.FeedID != feedID.UUID {
+		return false
+	}
+	return !before.Valid || p.CreatedAt.Before(before.Time)
+}
+
+// postBytes mirrors the octet_length sum the storage queries use.
+func postBytes(p database.Post) int64 {
+	return int64(len(p.Title) + len(p.Url) + len(p.Description.String))
+}
@@ -0,0 +1,51 @@
+// Package publicid generates and recognises the public identifiers that
+// posts and feeds expose in URLs. They are ULIDs: 26 Crockford base32
+// characters, sortable by creation time, and unrelated to the UUID primary
+// keys. Postgres generates the same format with gen_public_id.
+package publicid
+
+import (
+	"crypto/rand"
+	"strings"
+	"time"
+)
+
+const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// Len is the length of every public ID.
+const Len = 26
+
+// New returns a public ID for something created at t.
+func New(t time.Time) string {
+	var b [Len]byte
+	ms := uint64(t.UnixMilli())
+	for i := 9; i >= 0; i-- {
+		b[i] = alphabet[ms%32]
+		ms /= 32
+	}
+	var random [16]byte
+	rand.Read(random[:])
+	for i, r := range random {
+		b[10+i] = alphabet[r%32]
+	}
+	return string(b[:])
+}
+
+// Valid reports whether s looks like a public ID. Lowercase is accepted;
+// Normalize maps it to the stored form.
+func Valid(s string) bool {
+	if len(s) != Len {
+		return false
+	}
+	for _, c := range strings.ToUpper(s) {
+		if !strings.ContainsRune(alphabet, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// Normalize returns s in the form public IDs are stored in.
+func Normalize(s string) string {
+	return strings.ToUpper(s)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonFeed is the subset of JSON Feed 1.0/1.1 (https://jsonfeed.org) that
+// maps onto feedData.
+type jsonFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url"`
+	Description string           `json:"description"`
+	Language    string           `json:"language"`
+	Favicon     string           `json:"favicon"`
+	Icon        string           `json:"icon"`
+	Author      *jsonFeedAuthor  `json:"author"`
+	Authors     []jsonFeedAuthor `json:"authors"`
+	Items       []struct {
+		ID            json.RawMessage  `json:"id"`
+		URL           string           `json:"url"`
+		ExternalURL   string           `json:"external_url"`
+		Title         string           `json:"title"`
+		ContentHTML   string           `json:"content_html"`
+		ContentText   string           `json:"content_text"`
+		Summary       string           `json:"summary"`
+		DatePublished string           `json:"date_published"`
+		DateModified  string           `json:"date_modified"`
+		Tags          []string         `json:"tags"`
+		Author        *jsonFeedAuthor  `json:"author"`
+		Authors       []jsonFeedAuthor `json:"authors"`
+		Attachments   []struct {
+			URL         string `json:"url"`
+			MimeType    string `json:"mime_type"`
+			SizeInBytes int64  `json:"size_in_bytes"`
+		} `json:"attachments"`
+	} `json:"items"`
+}
+
+// jsonFeedAuthor is an author object. Version 1.0 has a single author,
+// 1.1 a list of them.
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+// jsonFeedAuthorName names the first author with a name, trying an item's
+// authors before the feed's.
+func jsonFeedAuthorName(candidates ...[]jsonFeedAuthor) string {
+	for _, authors := range candidates {
+		for _, a := range authors {
+			if name := strings.TrimSpace(a.Name); name != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// looksLikeJSON reports whether body is a JSON document rather than XML.
+func looksLikeJSON(body []byte) bool {
+	body = bytes.TrimLeft(body, " \t\r\n\ufeff")
+	return len(body) > 0 && body[0] == '{'
+}
+
+// parseJSONFeed converts a JSON Feed to feedData. The item link is its url,
+// or external_url for link-blog items that have no page of their own; the
+// description is the summary, falling back to content_html and then
+// content_text.
+func parseJSONFeed(body []byte) (feedData, error) {
+	fd := feedData{}
+	jf := jsonFeed{}
+	err := json.Unmarshal(bytes.TrimLeft(body, " \t\r\n\ufeff"), &jf)
+	if err != nil {
+		return fd, err
+	}
+	if !strings.HasPrefix(jf.Version, "https://jsonfeed.org/version/") {
+		return fd, fmt.Errorf("not a JSON Feed: version %q", jf.Version)
+	}
+	fd.Channel.Title = strings.TrimSpace(jf.Title)
+	fd.Channel.Description = strings.TrimSpace(jf.Description)
+	fd.Channel.Link.Href = jf.HomePageURL
+	fd.Channel.Link.Text = jf.HomePageURL
+	fd.Channel.Language = jf.Language
+	fd.Channel.Image.URL = jf.Favicon
+	if fd.Channel.Image.URL == "" {
+		fd.Channel.Image.URL = jf.Icon
+	}
+	for _, it := range jf.Items {
+		item := feedItem{
+			Title:       strings.TrimSpace(it.Title),
+			Link:        it.URL,
+			Guid:        jsonFeedID(it.ID),
+			Description: strings.TrimSpace(it.Summary),
+			Category:    it.Tags,
+		}
+		item.Author = jsonFeedAuthorName(it.Authors, jsonFeedAuthors(it.Author), jf.Authors, jsonFeedAuthors(jf.Author))
+		if item.Link == "" {
+			item.Link = it.ExternalURL
+		}
+		if len(it.Attachments) > 0 {
+			a := it.Attachments[0]
+			item.Enclosure = feedEnclosure{URL: a.URL, Type: a.MimeType, Length: strconv.FormatInt(a.SizeInBytes, 10)}
+		}
+		if item.Description == "" {
+			item.Description = strings.TrimSpace(it.ContentHTML)
+		}
+		if item.Description == "" {
+			item.Description = strings.TrimSpace(it.ContentText)
+		}
+		if it.DatePublished != "" {
+			item.PubDate = atomDateToRSS(it.DatePublished)
+		} else {
+			item.PubDate = atomDateToRSS(it.DateModified)
+		}
+		fd.Channel.Item = append(fd.Channel.Item, item)
+	}
+	return fd, nil
+}
+
+func jsonFeedAuthors(a *jsonFeedAuthor) []jsonFeedAuthor {
+	if a == nil {
+		return nil
+	}
+	return []jsonFeedAuthor{*a}
+}
+
+// jsonFeedID reads an item id, which the spec says is a string but which
+// some generators emit as a number.
+func jsonFeedID(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	return string(raw)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+var errInvalidLanguage = errors.New("invalid language code")
+
+// parseLanguageTag splits a feed's declared language, such as "en-us" from
+// an RSS <language> or "pt_BR" from a sloppier one, into a lowercase ISO 639
+// language and an uppercase ISO 3166 country. Either comes back empty when
+// the tag does not carry it; script subtags like "Hant" are skipped.
+func parseLanguageTag(tag string) (language, country string) {
+	parts := strings.FieldsFunc(strings.TrimSpace(tag), func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) == 0 || !isLanguageCode(parts[0]) {
+		return "", ""
+	}
+	language = strings.ToLower(parts[0])
+	for _, p := range parts[1:] {
+		if len(p) == 2 && isAlpha(p) {
+			return language, strings.ToUpper(p)
+		}
+	}
+	return language, ""
+}
+
+func isLanguageCode(s string) bool {
+	return (len(s) == 2 || len(s) == 3) && isAlpha(s)
+}
+
+func isAlpha(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeLanguages reduces each of tags to its language code, dropping
+// blanks and duplicates, so that "en-US, en-GB, fr" filters on [en fr].
+func normalizeLanguages(tags []string) ([]string, error) {
+	languages := []string{}
+	for _, tag := range tags {
+		if strings.TrimSpace(tag) == "" {
+			continue
+		}
+		language, _ := parseLanguageTag(tag)
+		if language == "" {
+			return nil, errInvalidLanguage
+		}
+		if !slices.Contains(languages, language) {
+			languages = append(languages, language)
+		}
+	}
+	return languages, nil
+}
+
+// requestLanguages is the language filter for a directory listing: the
+// comma-separated language query parameter if given, otherwise the preferred
+// languages of the caller when the request carries a valid API key. The
+// listing is public, so a missing or bad key simply means no preference, and
+// language=* asks for every language regardless. An empty result means no
+// filtering.
+func requestLanguages(r *http.Request, ac apiConfig) ([]string, error) {
+	if q := r.URL.Query().Get("language"); q != "" {
+		if q == "*" {
+			return nil, nil
+		}
+		return normalizeLanguages(strings.Split(q, ","))
+	}
+	fields := strings.Fields(r.Header.Get("Authorization"))
+	if len(fields) != 2 || fields[0] != "ApiKey" {
+		return nil, nil
+	}
+	u, err := ac.DB.GetUserByApiKey(r.Context(), fields[1])
+	if err != nil || u.BannedAt.Valid {
+		return nil, nil
+	}
+	return u.PreferredLanguages, nil
+}
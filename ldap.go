@@ -0,0 +1,494 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const ldapTimeout = 10 * time.Second
+
+// LDAP result codes this client tells apart; see RFC 4511, section 4.1.9.
+const (
+	ldapSuccess            = 0
+	ldapSizeLimitExceeded  = 4
+	ldapInvalidCredentials = 49
+)
+
+const ldapStartTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// BER tags of the elements and LDAP messages this client sends and reads.
+const (
+	berBoolean     = 0x01
+	berInteger     = 0x02
+	berOctetString = 0x04
+	berEnumerated  = 0x0a
+	berSequence    = 0x30
+
+	ldapBindRequest         = 0x60
+	ldapBindResponse        = 0x61
+	ldapUnbindRequest       = 0x42
+	ldapSearchRequest       = 0x63
+	ldapSearchResultEntry   = 0x64
+	ldapSearchResultDone    = 0x65
+	ldapExtendedRequest     = 0x77
+	ldapExtendedResponse    = 0x78
+	ldapSimpleAuth          = 0x80
+	ldapExtendedRequestName = 0x80
+	ldapEqualityMatch       = 0xa3
+)
+
+// Search parameters: the whole subtree under the base DN, never
+// dereferencing aliases, and at most two entries, which is enough to tell
+// that a username is ambiguous.
+const (
+	ldapScopeWholeSubtree = 2
+	ldapDerefNever        = 0
+	ldapSearchSizeLimit   = 2
+)
+
+// ldapBackend authenticates users against an LDAP directory, such as
+// OpenLDAP or Active Directory. It looks the user up by UserAttribute,
+// binding as BindDN to search if one is set, then binds as the user with
+// their password. Members of any of AdminGroups, going by the user's
+// memberOf attribute, are made admins, and only they, when any are set.
+type ldapBackend struct {
+	URL           *url.URL
+	StartTLS      bool
+	BindDN        string
+	BindPassword  string
+	BaseDN        string
+	UserAttribute string
+	AdminGroups   []string
+}
+
+// newLDAPBackendFromEnv reads LDAP_URL (ldap:// or ldaps://), LDAP_BASE_DN,
+// LDAP_BIND_DN and LDAP_BIND_PASSWORD for the search, LDAP_USER_ATTRIBUTE
+// (uid by default; sAMAccountName for Active Directory), LDAP_STARTTLS, and
+// LDAP_ADMIN_GROUPS, the DNs of the groups whose members are admins,
+// separated by semicolons since DNs contain commas.
+func newLDAPBackendFromEnv() (*ldapBackend, error) {
+	b := &ldapBackend{
+		BindDN:        os.Getenv("LDAP_BIND_DN"),
+		BindPassword:  os.Getenv("LDAP_BIND_PASSWORD"),
+		BaseDN:        os.Getenv("LDAP_BASE_DN"),
+		UserAttribute: os.Getenv("LDAP_USER_ATTRIBUTE"),
+	}
+	u, err := url.Parse(os.Getenv("LDAP_URL"))
+	if err != nil || (u.Scheme != "ldap" && u.Scheme != "ldaps") || u.Host == "" {
+		return nil, fmt.Errorf("Invalid LDAP_URL: must be ldap://host[:port] or ldaps://host[:port]")
+	}
+	b.URL = u
+	if b.BaseDN == "" {
+		return nil, fmt.Errorf("Invalid LDAP_BASE_DN: must be set")
+	}
+	if b.UserAttribute == "" {
+		b.UserAttribute = "uid"
+	}
+	switch os.Getenv("LDAP_STARTTLS") {
+	case "", "false":
+	case "true":
+		if u.Scheme == "ldaps" {
+			return nil, fmt.Errorf("Invalid LDAP_STARTTLS: ldaps:// is already encrypted")
+		}
+		b.StartTLS = true
+	default:
+		return nil, fmt.Errorf("Invalid LDAP_STARTTLS")
+	}
+	for _, group := range strings.Split(os.Getenv("LDAP_ADMIN_GROUPS"), ";") {
+		if group = strings.TrimSpace(group); group != "" {
+			b.AdminGroups = append(b.AdminGroups, group)
+		}
+	}
+	return b, nil
+}
+
+func (b *ldapBackend) name() string {
+	return "ldap"
+}
+
+// verify connects to the directory and, if there is one, binds as the
+// search user, without looking anyone up.
+func (b *ldapBackend) verify(ctx context.Context) error {
+	conn, err := b.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.close()
+	if b.BindDN == "" {
+		return nil
+	}
+	err = conn.bind(b.BindDN, b.BindPassword)
+	if err != nil {
+		return fmt.Errorf("binding as %s: %w", b.BindDN, err)
+	}
+	return nil
+}
+
+func (b *ldapBackend) authenticate(ctx context.Context, username, password string) (authIdentity, error) {
+	// An empty password would make the user bind an unauthenticated bind,
+	// which servers accept for any DN.
+	if username == "" || password == "" {
+		return authIdentity{}, errInvalidCredentials
+	}
+	conn, err := b.dial(ctx)
+	if err != nil {
+		return authIdentity{}, err
+	}
+	defer conn.close()
+	if b.BindDN != "" {
+		err = conn.bind(b.BindDN, b.BindPassword)
+		if err != nil {
+			return authIdentity{}, fmt.Errorf("binding as %s: %w", b.BindDN, err)
+		}
+	}
+	entries, err := conn.search(b.BaseDN, b.UserAttribute, username, []string{b.UserAttribute, "memberOf"})
+	// Hitting the size limit means more than one entry matched, as does
+	// more than one entry coming back: the username is ambiguous, and
+	// logging in as it is refused like a wrong password.
+	if errors.Is(err, errLDAPSizeLimitExceeded) || (err == nil && len(entries) != 1) {
+		return authIdentity{}, errInvalidCredentials
+	}
+	if err != nil {
+		return authIdentity{}, fmt.Errorf("searching for %s: %w", username, err)
+	}
+	err = conn.bind(entries[0].DN, password)
+	if errors.Is(err, errLDAPInvalidCredentials) {
+		return authIdentity{}, errInvalidCredentials
+	}
+	if err != nil {
+		return authIdentity{}, err
+	}
+	// The directory matched the username case-insensitively; the user is
+	// named as the directory spells it, not as they happened to type it.
+	name := username
+	for _, v := range entries[0].Attributes[strings.ToLower(b.UserAttribute)] {
+		if strings.EqualFold(v, username) {
+			name = v
+		}
+	}
+	identity := authIdentity{Subject: strings.ToLower(name), Name: name}
+	if len(b.AdminGroups) > 0 {
+		admin := false
+		for _, group := range entries[0].Attributes["memberof"] {
+			for _, adminGroup := range b.AdminGroups {
+				admin = admin || strings.EqualFold(group, adminGroup)
+			}
+		}
+		identity.Admin = &admin
+	}
+	return identity, nil
+}
+
+var (
+	errLDAPInvalidCredentials = errors.New("invalid credentials")
+	errLDAPSizeLimitExceeded  = errors.New("size limit exceeded")
+)
+
+// ldapConn is a connection to an LDAP server that sends one request at a
+// time and waits for its response.
+type ldapConn struct {
+	conn      net.Conn
+	r         *bufio.Reader
+	messageID int64
+}
+
+func (b *ldapBackend) dial(ctx context.Context) (*ldapConn, error) {
+	host := b.URL.Host
+	if b.URL.Port() == "" {
+		port := "389"
+		if b.URL.Scheme == "ldaps" {
+			port = "636"
+		}
+		host = net.JoinHostPort(b.URL.Hostname(), port)
+	}
+	ctx, cancel := context.WithTimeout(ctx, ldapTimeout)
+	defer cancel()
+	d := net.Dialer{}
+	conn, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	if b.URL.Scheme == "ldaps" {
+		conn = tls.Client(conn, &tls.Config{ServerName: b.URL.Hostname()})
+	}
+	conn.SetDeadline(time.Now().Add(ldapTimeout))
+	c := &ldapConn{conn: conn, r: bufio.NewReader(conn)}
+	if b.StartTLS {
+		_, err := c.roundTrip(berTLV(ldapExtendedRequest, berTLV(ldapExtendedRequestName, []byte(ldapStartTLSOID))), ldapExtendedResponse)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("starting TLS: %w", err)
+		}
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: b.URL.Hostname()})
+		c.conn = tlsConn
+		c.r = bufio.NewReader(tlsConn)
+	}
+	return c, nil
+}
+
+func (c *ldapConn) close() {
+	c.messageID++
+	c.conn.Write(berTLV(berSequence, berInt(berInteger, c.messageID), berTLV(ldapUnbindRequest)))
+	c.conn.Close()
+}
+
+// send writes op as the next message.
+func (c *ldapConn) send(op []byte) (int64, error) {
+	c.messageID++
+	_, err := c.conn.Write(berTLV(berSequence, berInt(berInteger, c.messageID), op))
+	return c.messageID, err
+}
+
+// read reads the next message that answers id, returning its protocol op's
+// tag and content.
+func (c *ldapConn) read(id int64) (byte, []byte, error) {
+	for {
+		tag, content, err := readBER(c.r)
+		if err != nil {
+			return 0, nil, err
+		}
+		if tag != berSequence {
+			return 0, nil, errors.New("malformed LDAP message")
+		}
+		fields, err := parseBERs(content)
+		if err != nil || len(fields) < 2 || fields[0].tag != berInteger {
+			return 0, nil, errors.New("malformed LDAP message")
+		}
+		if berIntValue(fields[0].content) != id {
+			continue
+		}
+		return fields[1].tag, fields[1].content, nil
+	}
+}
+
+// roundTrip sends op and reads an LDAPResult in response, of the given
+// tag, returning its content.
+func (c *ldapConn) roundTrip(op []byte, responseTag byte) ([]byte, error) {
+	id, err := c.send(op)
+	if err != nil {
+		return nil, err
+	}
+	tag, content, err := c.read(id)
+	if err != nil {
+		return nil, err
+	}
+	if tag != responseTag {
+		return nil, fmt.Errorf("unexpected LDAP response %#x", tag)
+	}
+	return content, ldapResultError(content)
+}
+
+// ldapResultError returns the error an LDAPResult reports, if any.
+func ldapResultError(content []byte) error {
+	fields, err := parseBERs(content)
+	if err != nil || len(fields) < 3 || fields[0].tag != berEnumerated {
+		return errors.New("malformed LDAP result")
+	}
+	switch code := berIntValue(fields[0].content); code {
+	case ldapSuccess:
+		return nil
+	case ldapInvalidCredentials:
+		return errLDAPInvalidCredentials
+	case ldapSizeLimitExceeded:
+		return errLDAPSizeLimitExceeded
+	default:
+		if msg := string(fields[2].content); msg != "" {
+			return fmt.Errorf("LDAP error %d: %s", code, msg)
+		}
+		return fmt.Errorf("LDAP error %d", code)
+	}
+}
+
+func (c *ldapConn) bind(dn, password string) error {
+	_, err := c.roundTrip(berTLV(ldapBindRequest,
+		berInt(berInteger, 3),
+		berTLV(berOctetString, []byte(dn)),
+		berTLV(ldapSimpleAuth, []byte(password)),
+	), ldapBindResponse)
+	return err
+}
+
+// An ldapEntry is a search result. Attribute names are lowercased, since
+// LDAP compares them case-insensitively.
+type ldapEntry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// search finds the entries under baseDN whose attribute equals value. The
+// filter is encoded directly rather than written as text, so value needs
+// no escaping.
+func (c *ldapConn) search(baseDN, attribute, value string, attributes []string) ([]ldapEntry, error) {
+	attrs := [][]byte{}
+	for _, a := range attributes {
+		attrs = append(attrs, berTLV(berOctetString, []byte(a)))
+	}
+	id, err := c.send(berTLV(ldapSearchRequest,
+		berTLV(berOctetString, []byte(baseDN)),
+		berInt(berEnumerated, ldapScopeWholeSubtree),
+		berInt(berEnumerated, ldapDerefNever),
+		berInt(berInteger, ldapSearchSizeLimit),
+		berInt(berInteger, int64(ldapTimeout.Seconds())),
+		berTLV(berBoolean, []byte{0}),
+		berTLV(ldapEqualityMatch,
+			berTLV(berOctetString, []byte(attribute)),
+			berTLV(berOctetString, []byte(value)),
+		),
+		berTLV(berSequence, attrs...),
+	))
+	if err != nil {
+		return nil, err
+	}
+	entries := []ldapEntry{}
+	for {
+		tag, content, err := c.read(id)
+		if err != nil {
+			return nil, err
+		}
+		switch tag {
+		case ldapSearchResultEntry:
+			entry, err := parseLDAPEntry(content)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		case ldapSearchResultDone:
+			return entries, ldapResultError(content)
+		}
+		// Anything else is a search result reference, to a server this
+		// client does not follow referrals to.
+	}
+}
+
+func parseLDAPEntry(content []byte) (ldapEntry, error) {
+	malformed := errors.New("malformed LDAP search result")
+	fields, err := parseBERs(content)
+	if err != nil || len(fields) != 2 {
+		return ldapEntry{}, malformed
+	}
+	entry := ldapEntry{DN: string(fields[0].content), Attributes: map[string][]string{}}
+	attrs, err := parseBERs(fields[1].content)
+	if err != nil {
+		return ldapEntry{}, malformed
+	}
+	for _, attr := range attrs {
+		parts, err := parseBERs(attr.content)
+		if err != nil || len(parts) != 2 {
+			return ldapEntry{}, malformed
+		}
+		values, err := parseBERs(parts[1].content)
+		if err != nil {
+			return ldapEntry{}, malformed
+		}
+		name := strings.ToLower(string(parts[0].content))
+		for _, v := range values {
+			entry.Attributes[name] = append(entry.Attributes[name], string(v.content))
+		}
+	}
+	return entry, nil
+}
+
+// berTLV encodes a BER element with the given tag whose content is parts,
+// concatenated.
+func berTLV(tag byte, parts ...[]byte) []byte {
+	n := 0
+	for _, p := range parts {
+		n += len(p)
+	}
+	out := []byte{tag}
+	switch {
+	case n < 0x80:
+		out = append(out, byte(n))
+	case n < 0x100:
+		out = append(out, 0x81, byte(n))
+	case n < 0x10000:
+		out = append(out, 0x82, byte(n>>8), byte(n))
+	case n < 0x1000000:
+		out = append(out, 0x83, byte(n>>16), byte(n>>8), byte(n))
+	default:
+		out = append(out, 0x84, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+// berInt encodes a non-negative integer.
+func berInt(tag byte, n int64) []byte {
+	content := []byte{byte(n)}
+	for n > 0x7f {
+		n >>= 8
+		content = append([]byte{byte(n)}, content...)
+	}
+	return berTLV(tag, content)
+}
+
+func berIntValue(content []byte) int64 {
+	var n int64
+	for i, b := range content {
+		if i == 0 && b&0x80 != 0 {
+			n = -1
+		}
+		n = n<<8 | int64(b)
+	}
+	return n
+}
+
+type berElement struct {
+	tag     byte
+	content []byte
+}
+
+// readBER reads one BER element. Lengths past 16MB are refused, so a
+// confused server cannot make the client allocate without limit.
+func readBER(r io.Reader) (byte, []byte, error) {
+	header := make([]byte, 2)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return 0, nil, err
+	}
+	n := int(header[1])
+	if n&0x80 != 0 {
+		size := n & 0x7f
+		if size == 0 || size > 3 {
+			return 0, nil, errors.New("LDAP message too large")
+		}
+		lengthBytes := make([]byte, size)
+		_, err = io.ReadFull(r, lengthBytes)
+		if err != nil {
+			return 0, nil, err
+		}
+		n = 0
+		for _, b := range lengthBytes {
+			n = n<<8 | int(b)
+		}
+	}
+	content := make([]byte, n)
+	_, err = io.ReadFull(r, content)
+	return header[0], content, err
+}
+
+// parseBERs splits content into the BER elements it holds.
+func parseBERs(content []byte) ([]berElement, error) {
+	elements := []berElement{}
+	r := bytes.NewReader(content)
+	for r.Len() > 0 {
+		tag, c, err := readBER(r)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, berElement{tag: tag, content: c})
+	}
+	return elements, nil
+}
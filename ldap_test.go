@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// berSet is the tag of the SET OF values an attribute holds in a search
+// result entry, which this client only ever reads.
+const berSet = 0x31
+
+func encodeLDAPEntry(e ldapEntry) []byte {
+	attrs := [][]byte{}
+	for name, values := range e.Attributes {
+		vals := [][]byte{}
+		for _, v := range values {
+			vals = append(vals, berTLV(berOctetString, []byte(v)))
+		}
+		attrs = append(attrs, berTLV(berSequence, berTLV(berOctetString, []byte(name)), berTLV(berSet, vals...)))
+	}
+	return berTLV(ldapSearchResultEntry, berTLV(berOctetString, []byte(e.DN)), berTLV(berSequence, attrs...))
+}
+
+func encodeLDAPResult(tag byte, code int64) []byte {
+	return berTLV(tag, berInt(berEnumerated, code), berTLV(berOctetString), berTLV(berOctetString))
+}
+
+func TestBERRoundTrip(t *testing.T) {
+	for _, n := range []int64{0, 1, 0x7f, 0x80, 0xff, 0x100, 0x7fff, 0x8000, 1 << 31} {
+		tag, content, err := readBER(bytes.NewReader(berInt(berInteger, n)))
+		if err != nil {
+			t.Fatalf("%d: %v", n, err)
+		}
+		if tag != berInteger || berIntValue(content) != n {
+			t.Errorf("%d decoded as %#x %d", n, tag, berIntValue(content))
+		}
+	}
+	// Each length crosses into the next long form.
+	for _, n := range []int{0, 0x7f, 0x80, 0xff, 0x100, 0xffff, 0x10000} {
+		content := bytes.Repeat([]byte{'x'}, n)
+		tag, got, err := readBER(bytes.NewReader(berTLV(berOctetString, content)))
+		if err != nil {
+			t.Fatalf("length %d: %v", n, err)
+		}
+		if tag != berOctetString || !bytes.Equal(got, content) {
+			t.Errorf("length %d decoded as %#x with %d bytes", n, tag, len(got))
+		}
+	}
+}
+
+func TestParseLDAPEntryRoundTrip(t *testing.T) {
+	want := ldapEntry{
+		DN: "uid=Jane,ou=people,dc=example,dc=org",
+		Attributes: map[string][]string{
+			"uid":      {"Jane"},
+			"memberof": {"cn=admins,dc=example,dc=org", "cn=staff,dc=example,dc=org"},
+		},
+	}
+	op := encodeLDAPEntry(want)
+	_, content, err := readBER(bytes.NewReader(op))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parseLDAPEntry(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestLDAPResultError(t *testing.T) {
+	tests := []struct {
+		code int64
+		want error
+	}{
+		{ldapSuccess, nil},
+		{ldapSizeLimitExceeded, errLDAPSizeLimitExceeded},
+		{ldapInvalidCredentials, errLDAPInvalidCredentials},
+	}
+	for _, tt := range tests {
+		_, content, err := readBER(bytes.NewReader(encodeLDAPResult(ldapBindResponse, tt.code)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ldapResultError(content); !errors.Is(err, tt.want) {
+			t.Errorf("code %d: got %v, want %v", tt.code, err, tt.want)
+		}
+	}
+	if err := ldapResultError([]byte{0x04, 0x00}); err == nil {
+		t.Errorf("malformed result decoded without error")
+	}
+}
+
+func FuzzParseBERs(f *testing.F) {
+	f.Add(berInt(berInteger, 300))
+	f.Add(encodeLDAPResult(ldapSearchResultDone, ldapSizeLimitExceeded))
+	f.Add([]byte{berOctetString, 0x84, 0xff, 0xff, 0xff, 0xff})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		elements, err := parseBERs(data)
+		if err != nil {
+			return
+		}
+		// Whatever decodes must encode back to the same elements.
+		parts := [][]byte{}
+		for _, e := range elements {
+			parts = append(parts, berTLV(e.tag, e.content))
+		}
+		again, err := parseBERs(bytes.Join(parts, nil))
+		if err != nil || !reflect.DeepEqual(again, elements) {
+			t.Errorf("re-encoding %x gave %v, %v", data, again, err)
+		}
+	})
+}
+
+func FuzzParseLDAPEntry(f *testing.F) {
+	_, content, _ := readBER(bytes.NewReader(encodeLDAPEntry(ldapEntry{
+		DN:         "uid=jane,dc=example,dc=org",
+		Attributes: map[string][]string{"uid": {"jane"}},
+	})))
+	f.Add(content)
+	f.Add([]byte{})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// It must refuse what it cannot read, not panic.
+		parseLDAPEntry(data)
+		ldapResultError(data)
+	})
+}
+
+// fakeDirectory serves binds and searches on a local port until the test
+// ends. Binding as any DN with password succeeds; a search returns entries,
+// then a result with code done.
+type fakeDirectory struct {
+	password string
+	entries  []ldapEntry
+	done     int64
+}
+
+func (d fakeDirectory) serve(t *testing.T) *ldapBackend {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go d.handle(conn)
+		}
+	}()
+	return &ldapBackend{
+		URL:           &url.URL{Scheme: "ldap", Host: l.Addr().String()},
+		BaseDN:        "dc=example,dc=org",
+		UserAttribute: "uid",
+	}
+}
+
+func (d fakeDirectory) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		_, content, err := readBER(r)
+		if err != nil {
+			return
+		}
+		fields, err := parseBERs(content)
+		if err != nil || len(fields) < 2 {
+			return
+		}
+		reply := func(op []byte) {
+			conn.Write(berTLV(berSequence, berInt(berInteger, berIntValue(fields[0].content)), op))
+		}
+		switch fields[1].tag {
+		case ldapBindRequest:
+			parts, _ := parseBERs(fields[1].content)
+			code := int64(ldapInvalidCredentials)
+			if len(parts) == 3 && string(parts[2].content) == d.password {
+				code = ldapSuccess
+			}
+			reply(encodeLDAPResult(ldapBindResponse, code))
+		case ldapSearchRequest:
+			for _, e := range d.entries {
+				reply(encodeLDAPEntry(e))
+			}
+			reply(encodeLDAPResult(ldapSearchResultDone, d.done))
+		default:
+			return
+		}
+	}
+}
+
+func TestLDAPAuthenticate(t *testing.T) {
+	jane := ldapEntry{DN: "uid=Jane,dc=example,dc=org", Attributes: map[string][]string{"uid": {"Jane"}}}
+	jane2 := ldapEntry{DN: "uid=jane,ou=old,dc=example,dc=org", Attributes: map[string][]string{"uid": {"jane"}}}
+
+	t.Run("canonical name", func(t *testing.T) {
+		b := fakeDirectory{password: "secret", entries: []ldapEntry{jane}}.serve(t)
+		identity, err := b.authenticate(context.Background(), "JANE", "secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if identity.Name != "Jane" || identity.Subject != "jane" {
+			t.Errorf("identity = %+v, want the directory's name", identity)
+		}
+	})
+	tests := []struct {
+		name string
+		dir  fakeDirectory
+		pass string
+	}{
+		{"wrong password", fakeDirectory{password: "secret", entries: []ldapEntry{jane}}, "guess"},
+		{"no such user", fakeDirectory{password: "secret"}, "secret"},
+		{"ambiguous", fakeDirectory{password: "secret", entries: []ldapEntry{jane, jane2}}, "secret"},
+		{"size limit", fakeDirectory{password: "secret", entries: []ldapEntry{jane, jane2}, done: ldapSizeLimitExceeded}, "secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.dir.serve(t)
+			_, err := b.authenticate(context.Background(), "jane", tt.pass)
+			if !errors.Is(err, errInvalidCredentials) {
+				t.Errorf("got %v, want errInvalidCredentials", err)
+			}
+		})
+	}
+}
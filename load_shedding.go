@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultBackgroundShare = 0.5
+	// Shed interactive requests are retried sooner than background ones,
+	// which are better off waiting for the load to pass.
+	interactiveRetryAfter = time.Second
+	backgroundRetryAfter  = 30 * time.Second
+)
+
+// Clients send X-Request-Priority: background on requests no person is
+// waiting on, such as a periodic sync, so they can be put off under load.
+const requestPriorityHeader = "X-Request-Priority"
+
+// loadShedder caps the number of requests in flight. Background requests
+// are only let in while fewer than BackgroundLimit are in flight, leaving
+// the rest of the capacity to interactive ones, and once the server is
+// that busy no one user may have more than PerUser requests in flight, so
+// that one busy client cannot crowd out everyone else. Requests over the
+// limits get 503 with Retry-After.
+type loadShedder struct {
+	MaxInFlight     int64
+	BackgroundLimit int64
+	PerUser         int64
+
+	inFlight atomic.Int64
+	shed     atomic.Int64
+	mu       sync.Mutex
+	byUser   map[string]int64
+}
+
+// newLoadShedderFromEnv reads LOAD_SHED_MAX_IN_FLIGHT, the most requests
+// served at once, LOAD_SHED_BACKGROUND_SHARE, the share of those that
+// background requests may take (half by default), and LOAD_SHED_PER_USER,
+// the most one user may have in flight once the server is busy (a quarter
+// of the maximum by default). It returns nil, and nothing is shed, unless
+// LOAD_SHED_MAX_IN_FLIGHT is set.
+func newLoadShedderFromEnv() (*loadShedder, error) {
+	v := os.Getenv("LOAD_SHED_MAX_IN_FLIGHT")
+	if v == "" {
+		return nil, nil
+	}
+	maxInFlight, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || maxInFlight < 1 {
+		return nil, fmt.Errorf("Invalid LOAD_SHED_MAX_IN_FLIGHT")
+	}
+	share := defaultBackgroundShare
+	if v := os.Getenv("LOAD_SHED_BACKGROUND_SHARE"); v != "" {
+		share, err = strconv.ParseFloat(v, 64)
+		if err != nil || share <= 0 || share > 1 {
+			return nil, fmt.Errorf("Invalid LOAD_SHED_BACKGROUND_SHARE: must be more than 0 and at most 1")
+		}
+	}
+	s := &loadShedder{
+		MaxInFlight:     maxInFlight,
+		BackgroundLimit: int64(float64(maxInFlight) * share),
+		PerUser:         maxInFlight / 4,
+		byUser:          map[string]int64{},
+	}
+	if v := os.Getenv("LOAD_SHED_PER_USER"); v != "" {
+		s.PerUser, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || s.PerUser < 1 {
+			return nil, fmt.Errorf("Invalid LOAD_SHED_PER_USER")
+		}
+	}
+	s.BackgroundLimit = max(s.BackgroundLimit, 1)
+	s.PerUser = max(s.PerUser, 1)
+	return s, nil
+}
+
+// admit reserves a place for a request from user, "" if anonymous,
+// returning whether it may proceed. A request that is admitted must be
+// released.
+func (s *loadShedder) admit(user string, background bool) bool {
+	limit := s.MaxInFlight
+	if background {
+		limit = s.BackgroundLimit
+	}
+	n := s.inFlight.Add(1)
+	if n > limit {
+		s.inFlight.Add(-1)
+		return false
+	}
+	if user == "" {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n > s.BackgroundLimit && s.byUser[user] >= s.PerUser {
+		s.inFlight.Add(-1)
+		return false
+	}
+	s.byUser[user]++
+	return true
+}
+
+func (s *loadShedder) release(user string) {
+	s.inFlight.Add(-1)
+	if user == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byUser[user]--
+	if s.byUser[user] <= 0 {
+		delete(s.byUser, user)
+	}
+}
+
+// middlewareLoadShedding sheds requests over the load shedder's limits.
+// Readiness checks and admin routes are never shed, so that an overloaded
+// instance can still be seen to be up and be operated on.
+func (ac *apiConfig) middlewareLoadShedding(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := ac.Shedder
+		if s == nil || r.URL.Path == "/v1/readiness" || strings.HasPrefix(r.URL.Path, "/v1/admin/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		background := strings.EqualFold(r.Header.Get(requestPriorityHeader), "background")
+		user := r.Header.Get("Authorization")
+		if !s.admit(user, background) {
+			s.shed.Add(1)
+			retryAfter := interactiveRetryAfter
+			if background {
+				retryAfter = backgroundRetryAfter
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+			respondWithError(w, http.StatusServiceUnavailable, "Server is busy, try again later")
+			return
+		}
+		defer s.release(user)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type logLevel int
+
+const (
+	levelDebug logLevel = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+var logLevelNames = map[string]logLevel{
+	"debug": levelDebug,
+	"info":  levelInfo,
+	"warn":  levelWarn,
+	"error": levelError,
+}
+
+func (l logLevel) String() string {
+	switch l {
+	case levelDebug:
+		return "DEBUG"
+	case levelInfo:
+		return "INFO"
+	case levelWarn:
+		return "WARN"
+	}
+	return "ERROR"
+}
+
+// logPolicy decides which events are written. Each module (fetch, auth,
+// notify, ...) has a level, and debug events are additionally sampled so
+// that a busy fetcher cannot bury everything else. Every message is
+// redacted before it is written.
+type logPolicy struct {
+	mu           sync.Mutex
+	logger       *log.Logger
+	defaultLevel logLevel
+	levels       map[string]logLevel
+	sampleEvery  int
+	counts       map[string]int
+}
+
+var logs = &logPolicy{
+	logger:       log.New(os.Stdout, "", log.LstdFlags),
+	defaultLevel: levelInfo,
+	levels:       map[string]logLevel{},
+	sampleEvery:  1,
+	counts:       map[string]int{},
+}
+
+// configureLoggingFromEnv reads LOG_LEVEL, e.g. "info,fetch=debug,auth=warn"
+// where a bare level is the default for every module, and LOG_DEBUG_SAMPLE,
+// which keeps one in every N occurrences of each debug message.
+func configureLoggingFromEnv() error {
+	logs.mu.Lock()
+	defer logs.mu.Unlock()
+	for _, part := range strings.Split(os.Getenv("LOG_LEVEL"), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		module, name, ok := strings.Cut(part, "=")
+		if !ok {
+			module, name = "", part
+		}
+		level, known := logLevelNames[strings.ToLower(name)]
+		if !known {
+			return fmt.Errorf("LOG_LEVEL: unknown level %q", name)
+		}
+		if module == "" {
+			logs.defaultLevel = level
+		} else {
+			logs.levels[module] = level
+		}
+	}
+	if sample := os.Getenv("LOG_DEBUG_SAMPLE"); sample != "" {
+		n, err := strconv.Atoi(sample)
+		if err != nil || n < 1 {
+			return fmt.Errorf("LOG_DEBUG_SAMPLE must be a positive integer")
+		}
+		logs.sampleEvery = n
+	}
+	return nil
+}
+
+func (p *logPolicy) logf(level logLevel, module, format string, args ...any) {
+	p.mu.Lock()
+	threshold, ok := p.levels[module]
+	if !ok {
+		threshold = p.defaultLevel
+	}
+	if level < threshold {
+		p.mu.Unlock()
+		return
+	}
+	// Debug events are sampled per message template rather than per
+	// message, so each kind of event keeps appearing.
+	if level == levelDebug && p.sampleEvery > 1 {
+		key := module + "\x00" + format
+		p.counts[key]++
+		if (p.counts[key]-1)%p.sampleEvery != 0 {
+			p.mu.Unlock()
+			return
+		}
+	}
+	p.mu.Unlock()
+	p.logger.Printf("%s %s: %s", level, module, redact(fmt.Sprintf(format, args...)))
+}
+
+func logDebug(module, format string, args ...any) {
+	logs.logf(levelDebug, module, format, args...)
+}
+
+func logInfo(module, format string, args ...any) {
+	logs.logf(levelInfo, module, format, args...)
+}
+
+func logWarn(module, format string, args ...any) {
+	logs.logf(levelWarn, module, format, args...)
+}
+
+func logError(module, format string, args ...any) {
+	logs.logf(levelError, module, format, args...)
+}
+
+var redactions = []struct {
+	re          *regexp.Regexp
+	replacement string
+}{
+	// Authorization header values.
+	{regexp.MustCompile(`(?i)\b(ApiKey|Bearer|Basic)\s+[^\s"',]+`), "$1 [REDACTED]"},
+	// key=value and "key": "value" pairs for credential-like keys.
+	{regexp.MustCompile(`(?i)\b((?:api_?key|password|passwd|secret|token|auth|authorization|access_token)["']?\s*[:=]\s*["']?)[^\s"'&,;]+`), "$1[REDACTED]"},
+	// Passwords in URLs, such as DB_URL or SMTP credentials.
+	{regexp.MustCompile(`(://[^/\s:@]+:)[^@\s/]+@`), "$1[REDACTED]@"},
+	// Bare API keys, which are 64 hex characters.
+	{regexp.MustCompile(`\b[0-9a-fA-F]{64}\b`), "[REDACTED]"},
+}
+
+// redact removes API keys and credentials from a log message.
+func redact(s string) string {
+	for _, r := range redactions {
+		s = r.re.ReplaceAllString(s, r.replacement)
+	}
+	return s
+}
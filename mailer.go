@@ -0,0 +1,91 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// mailer sends plain-text mail through the SMTP relay configured with the
+// SMTP_* environment variables.
+type mailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// newMailerFromEnv returns nil when SMTP_ADDR is not set, which leaves every
+// mail-sending feature disabled.
+func newMailerFromEnv() *mailer {
+	addr := os.Getenv("SMTP_ADDR")
+	if addr == "" {
+		return nil
+	}
+	m := &mailer{
+		addr: addr,
+		from: os.Getenv("SMTP_FROM"),
+	}
+	username := os.Getenv("SMTP_USERNAME")
+	if username != "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			host = addr
+		}
+		m.auth = smtp.PlainAuth("", username, os.Getenv("SMTP_PASSWORD"), host)
+	}
+	return m
+}
+
+func (m *mailer) send(to string, subject string, body string) error {
+	subject = strings.NewReplacer("\r", "", "\n", " ").Replace(subject)
+	msg := strings.Builder{}
+	fmt.Fprintf(&msg, "From: %s\r\n", m.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{to}, []byte(msg.String()))
+}
+
+// verify connects to the relay and authenticates, as send would, without
+// sending anything.
+func (m *mailer) verify() error {
+	if m.from == "" {
+		return fmt.Errorf("SMTP_FROM is not set")
+	}
+	host, _, err := net.SplitHostPort(m.addr)
+	if err != nil {
+		return fmt.Errorf("SMTP_ADDR must be host:port")
+	}
+	conn, err := net.DialTimeout("tcp", m.addr, 10*time.Second)
+	if err != nil {
+		return err
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		err = c.StartTLS(&tls.Config{ServerName: host})
+		if err != nil {
+			return err
+		}
+	}
+	if m.auth != nil {
+		err = c.Auth(m.auth)
+		if err != nil {
+			return err
+		}
+	}
+	return c.Quit()
+}
@@ -5,26 +5,54 @@ import (
 	"database/sql"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	"github.com/pmwals09/rss-aggregator/internal/clock"
 	"github.com/pmwals09/rss-aggregator/internal/database"
 )
 
 type authedHandler func(http.ResponseWriter, *http.Request, database.User)
 type apiConfig struct {
-	DB *database.Queries
+	DB                  database.Store
+	Mailer              *mailer
+	WebPush             *webPushConfig
+	PostEmailDailyLimit int
+	SubscribeKey        []byte
+	HTTPClient          *http.Client
+	NotifyClient        *http.Client
+	AllowPrivate        bool
+	FetchFeed           fetchFunc
+	Credentials         *credentialBox
+	Content             contentPolicy
+	Auth                authBackend
+	SCIMToken           string
+	Clock               clock.Clock
+	Stats               *workerStats
+	Maintenance         *maintenanceState
+	Snapshots           snapshotConfig
+	FeedPauseAfter      int
+	FetchWorkers        int
+	DefaultSchedule     string
+	UnfollowedGrace     time.Duration
+	SLO                 sloConfig
+	Metrics             *sloMetrics
+	DBBreaker           *dbBreaker
+	Shedder             *loadShedder
 }
 type feedData struct {
 	XMLName xml.Name `xml:"rss"`
@@ -34,26 +62,81 @@ type feedData struct {
 	Channel struct {
 		Text  string `xml:",chardata"`
 		Title string `xml:"title"`
-		Link  struct {
+		// AtomLinks takes atom:link elements, such as a self link, so
+		// they don't overwrite the channel's own link.
+		AtomLinks []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"http://www.w3.org/2005/Atom link"`
+		Link struct {
 			Text string `xml:",chardata"`
 			Href string `xml:"href,attr"`
 			Rel  string `xml:"rel,attr"`
 			Type string `xml:"type,attr"`
 		} `xml:"link"`
-		Description   string `xml:"description"`
-		Generator     string `xml:"generator"`
-		Language      string `xml:"language"`
-		LastBuildDate string `xml:"lastBuildDate"`
-		Item          []struct {
-			Text        string `xml:",chardata"`
-			Title       string `xml:"title"`
-			Link        string `xml:"link"`
-			PubDate     string `xml:"pubDate"`
-			Guid        string `xml:"guid"`
-			Description string `xml:"description"`
-		} `xml:"item"`
+		Description string `xml:"description"`
+		Image       struct {
+			URL string `xml:"url"`
+		} `xml:"image"`
+		Generator     string     `xml:"generator"`
+		Language      string     `xml:"language"`
+		LastBuildDate string     `xml:"lastBuildDate"`
+		Item          []feedItem `xml:"item"`
 	} `xml:"channel"`
 	FeedID uuid.UUID `xml:"feed_id"`
+	// Cache is the validators the server sent with this body, for the next
+	// conditional GET. NotModified means the server answered 304 and there
+	// is no body at all. Body and ContentType are what was fetched, kept for
+	// fetch snapshots. MovedTo is where the feed now lives if the server
+	// permanently redirected the request. StatusCode is the HTTP status the
+	// server answered with, if it answered at all.
+	Cache       cacheValidators `xml:"-"`
+	NotModified bool            `xml:"-"`
+	Body        []byte          `xml:"-"`
+	ContentType string          `xml:"-"`
+	MovedTo     string          `xml:"-"`
+	StatusCode  int             `xml:"-"`
+}
+
+type feedItem struct {
+	Text        string        `xml:",chardata"`
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	PubDate     string        `xml:"pubDate"`
+	Guid        string        `xml:"guid"`
+	Description string        `xml:"description"`
+	Category    []string      `xml:"category"`
+	Enclosure   feedEnclosure `xml:"enclosure"`
+	Author      string        `xml:"author"`
+	Creator     string        `xml:"http://purl.org/dc/elements/1.1/ creator"`
+}
+
+// feedEnclosure is an item's attached media, such as a podcast episode.
+// Length is kept as sent, since feeds fill it with anything from byte
+// counts to "0" to durations.
+type feedEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// enclosureLength parses an enclosure length as a byte count, or 0 if it is
+// not one.
+func enclosureLength(s string) int64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// itemGuid is what identifies an item within its feed: its GUID, or its
+// link for feeds that do not give one.
+func itemGuid(item feedItem) string {
+	if guid := strings.TrimSpace(item.Guid); guid != "" {
+		return guid
+	}
+	return item.Link
 }
 
 func (ac *apiConfig) middlewareAuth(next authedHandler) http.HandlerFunc {
@@ -64,49 +147,358 @@ func (ac *apiConfig) middlewareAuth(next authedHandler) http.HandlerFunc {
 			return
 		}
 		fields := strings.Fields(authorization)
-		name, key := fields[0], fields[1]
-		if name != "ApiKey" {
+		if len(fields) != 2 || fields[0] != "ApiKey" {
+			logWarn("auth", "Malformed Authorization header on %s %s", r.Method, r.URL.Path)
+			respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		user, err := ac.DB.GetUserByApiKey(r.Context(), fields[1])
+		if errors.Is(err, sql.ErrNoRows) {
+			logWarn("auth", "Unknown API key on %s %s", r.Method, r.URL.Path)
 			respondWithError(w, http.StatusUnauthorized, "Unauthorized")
 			return
 		}
-		user, err := ac.DB.GetUserByApiKey(r.Context(), key)
 		if err != nil {
+			logError("auth", "Could not look up API key: %v", err)
 			respondWithError(w, http.StatusUnauthorized, "Unauthorized")
 			return
 		}
+		if user.BannedAt.Valid {
+			logWarn("auth", "Suspended user %s on %s %s", user.ID, r.Method, r.URL.Path)
+			respondWithError(w, http.StatusForbidden, "Account suspended")
+			return
+		}
 
 		next(w, r, user)
 	}
 }
 
+func (ac *apiConfig) middlewareAdmin(next authedHandler) http.HandlerFunc {
+	return ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		if !u.IsAdmin {
+			respondWithError(w, http.StatusForbidden, "Forbidden")
+			return
+		}
+		next(w, r, u)
+	})
+}
+
 func main() {
+	demo := flag.Bool("demo", false, "run against an in-memory store with sample feeds instead of Postgres")
+	flag.Parse()
+
 	err := godotenv.Load()
-	if err != nil {
+	if flag.Arg(0) == "doctor" {
+		err = runDoctor(flag.Args()[1:], err)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err != nil && !*demo {
 		fmt.Println("Error loading environment file")
 		os.Exit(1)
 		return
 	}
+	err = configureLoggingFromEnv()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+		return
+	}
 	port := os.Getenv("PORT")
-	dbURL := os.Getenv("DB_URL")
 
-	db, err := sql.Open("postgres", dbURL)
+	allowPrivate, err := newAllowPrivateAddressesFromEnv()
 	if err != nil {
-		fmt.Println("Error connecting to database")
-		os.Exit(2)
+		fmt.Println(err)
+		os.Exit(1)
 		return
 	}
 
-	dbQueries := database.New(db)
+	httpClient, err := newFetchClientFromEnv(allowPrivate)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+		return
+	}
 
-	ac := apiConfig{dbQueries}
+	var db *sql.DB
+	var store database.Store
+	var dbBreaker *dbBreaker
+	fetchFeed := httpFetchFeed(httpClient)
+	if *demo {
+		store, err = newDemoStore(context.Background())
+		if err != nil {
+			fmt.Println("Error seeding demo store: ", err)
+			os.Exit(2)
+			return
+		}
+		fetchFeed = fakeFetchFeed
+		if port == "" {
+			port = "8080"
+		}
+	} else {
+		db, err = sql.Open("postgres", os.Getenv("DB_URL"))
+		if err != nil {
+			fmt.Println("Error connecting to database")
+			os.Exit(2)
+			return
+		}
+		store = database.NewStore(db)
+		dbBreaker, err = newDBBreakerFromEnv()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+			return
+		}
+		if dbBreaker != nil {
+			store = database.NewGuardedStore(db, dbBreaker)
+		}
+	}
 
+	switch flag.Arg(0) {
+	case "seed":
+		err = runSeed(store, flag.Args()[1:])
+		if err != nil {
+			fmt.Println("Error seeding: ", err)
+			os.Exit(1)
+		}
+		return
+	case "backup":
+		err = runBackup(db, flag.Args()[1:])
+		if err != nil {
+			fmt.Println("Error backing up: ", err)
+			os.Exit(1)
+		}
+		return
+	case "restore":
+		err = runRestore(db, flag.Args()[1:])
+		if err != nil {
+			fmt.Println("Error restoring: ", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	postEmailDailyLimit := defaultPostEmailDailyLimit
+	if limit := os.Getenv("POST_EMAIL_DAILY_LIMIT"); limit != "" {
+		postEmailDailyLimit, err = strconv.Atoi(limit)
+		if err != nil {
+			fmt.Println("Invalid POST_EMAIL_DAILY_LIMIT")
+			os.Exit(1)
+			return
+		}
+	}
+
+	fetchFeed, err = newPoliteFetchFeedFromEnv(fetchFeed)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+		return
+	}
+
+	fetchFeed, err = newChaosFetchFeedFromEnv(fetchFeed)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+		return
+	}
+
+	maintenance, err := newMaintenanceStateFromEnv()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+		return
+	}
+
+	shedder, err := newLoadShedderFromEnv()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+		return
+	}
+
+	integrityInterval, err := newIntegrityCheckIntervalFromEnv()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+		return
+	}
+
+	healthDigestInterval, err := newHealthDigestIntervalFromEnv()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+		return
+	}
+
+	snapshots, err := newSnapshotConfigFromEnv()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+		return
+	}
+
+	feedPauseAfter, err := newFeedPauseAfterFromEnv()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+		return
+	}
+
+	fetchWorkers, err := newFetchWorkersFromEnv()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+		return
+	}
+
+	defaultSchedule, err := newDefaultScheduleFromEnv()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+		return
+	}
+
+	unfollowedGrace, err := newUnfollowedGraceFromEnv()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+		return
+	}
+
+	slo, err := newSLOConfigFromEnv()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+		return
+	}
+
+	notifyClient := newNotifyClient(allowPrivate)
+	webPush, err := newWebPushConfigFromEnv(notifyClient)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+		return
+	}
+
+	credentials, err := newCredentialBoxFromEnv()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+		return
+	}
+
+	content, err := newContentPolicyFromEnv()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+		return
+	}
+
+	auth, err := newAuthBackendFromEnv()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+		return
+	}
+
+	scimToken, err := newSCIMTokenFromEnv()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+		return
+	}
+
+	exports, err := newExportConfigFromEnv()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+		return
+	}
+
+	ac := apiConfig{
+		DB:                  store,
+		FetchFeed:           fetchFeed,
+		Credentials:         credentials,
+		Content:             content,
+		Auth:                auth,
+		SCIMToken:           scimToken,
+		Mailer:              newMailerFromEnv(),
+		WebPush:             webPush,
+		PostEmailDailyLimit: postEmailDailyLimit,
+		SubscribeKey:        newSubscribeKeyFromEnv(),
+		HTTPClient:          httpClient,
+		NotifyClient:        notifyClient,
+		AllowPrivate:        allowPrivate,
+		Clock:               clock.Real{},
+		Stats:               &workerStats{},
+		Maintenance:         maintenance,
+		Snapshots:           snapshots,
+		FeedPauseAfter:      feedPauseAfter,
+		FetchWorkers:        fetchWorkers,
+		DefaultSchedule:     defaultSchedule,
+		UnfollowedGrace:     unfollowedGrace,
+		SLO:                 slo,
+		Metrics:             newSLOMetrics(clock.Real{}),
+		Shedder:             shedder,
+		DBBreaker:           dbBreaker,
+	}
+
+	// A one-shot fetch needs the same configuration as the server, so it is
+	// handled here rather than with the other commands.
+	if flag.Arg(0) == "fetch" {
+		err = runFetch(ac, flag.Args()[1:])
+		if err != nil {
+			fmt.Println("Error fetching: ", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if dbBreaker != nil {
+		go dbBreaker.probe(context.Background(), db)
+	}
 	go getFeedsWorker(ac)
+	go snoozeWorker(ac)
+	go iconWorker(ac)
+	if integrityInterval > 0 {
+		go integrityWorker(ac, integrityInterval)
+	}
+	if healthDigestInterval > 0 {
+		go healthDigestWorker(ac, healthDigestInterval)
+	}
+	if exports.Schedule != nil {
+		go exportWorker(ac, exports)
+	}
 
 	r := chi.NewRouter()
-	r.Use(cors.Handler(cors.Options{}))
+	// Browser extensions and bookmarklets call the API cross-origin with an
+	// Authorization header, so preflights must allow it.
+	r.Use(cors.Handler(cors.Options{
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", requestPriorityHeader},
+		MaxAge:         300,
+	}))
+	r.Use(ac.middlewareMetrics)
+	r.Use(ac.middlewareMaintenance)
+	r.Use(ac.middlewareDBBreaker)
+	r.Use(ac.middlewareLoadShedding)
 	v1 := chi.NewRouter()
 	v1.Get("/readiness", func(w http.ResponseWriter, r *http.Request) {
-		respondWithJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		if ac.DBBreaker == nil {
+			respondWithJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+			return
+		}
+		// Not ready while the database is unreachable, so that load
+		// balancers send requests elsewhere.
+		breaker := ac.DBBreaker.state()
+		if breaker.State == "open" {
+			respondWithJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "unavailable", "db_breaker": breaker.State})
+			return
+		}
+		respondWithJSON(w, http.StatusOK, map[string]string{"status": "ok", "db_breaker": breaker.State})
 	})
 	v1.Get("/err", func(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusInternalServerError, "Internal Server Error")
@@ -114,27 +506,322 @@ func main() {
 	v1.Post("/users", func(w http.ResponseWriter, r *http.Request) {
 		handleUsersPost(w, r, ac)
 	})
+	v1.Post("/login", func(w http.ResponseWriter, r *http.Request) {
+		handleLoginPost(w, r, ac)
+	})
 	v1.Get("/users", ac.middlewareAuth(handleUsersGet))
+	v1.Patch("/users", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleUsersPatch(w, r, u, ac)
+	}))
+	v1.Post("/users/merge", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleUsersMerge(w, r, u, ac)
+	}))
+	v1.Get("/users/settings", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleUserSettingsExport(w, r, u, ac)
+	}))
+	v1.Post("/users/settings", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleUserSettingsImport(w, r, u, ac)
+	}))
+	v1.Get("/users/check", func(w http.ResponseWriter, r *http.Request) {
+		handleUsersCheck(w, r, ac)
+	})
 	v1.Post("/feeds", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
 		handleFeedsPost(w, r, u, ac)
 	}))
 	v1.Get("/feeds", func(w http.ResponseWriter, r *http.Request) {
 		handleFeedsGet(w, r, ac)
 	})
+	v1.Patch("/feeds/{feedID}", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleFeedsPatch(w, r, u, ac)
+	}))
+	v1.Get("/feeds/{feedID}/health", func(w http.ResponseWriter, r *http.Request) {
+		handleFeedHealthGet(w, r, ac)
+	})
+	v1.Get("/feeds/{feedID}/fetches", func(w http.ResponseWriter, r *http.Request) {
+		handleFeedFetchesGet(w, r, ac)
+	})
+	v1.Get("/feeds/{feedID}/icon", func(w http.ResponseWriter, r *http.Request) {
+		handleFeedIconGet(w, r, ac)
+	})
+	v1.Get("/feeds/{feedID}/posts", func(w http.ResponseWriter, r *http.Request) {
+		handleFeedPostsGet(w, r, ac)
+	})
+	v1.Post("/feeds/{feedID}/refresh", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleFeedRefreshPost(w, r, u, ac)
+	}))
+	v1.Post("/feeds/{feedID}/report", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleFeedReportPost(w, r, u, ac)
+	}))
+	v1.Get("/feeds/{feedID}/url_history", func(w http.ResponseWriter, r *http.Request) {
+		handleFeedUrlHistoryGet(w, r, ac)
+	})
 	v1.Post("/feed_follows", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
 		handleFollowsPost(w, r, u, ac)
 	}))
 	v1.Delete("/feed_follows/{feedFollowID}", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
 		handleFollowsDelete(w, r, u, ac)
 	}))
+	v1.Patch("/feed_follows/{feedFollowID}", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleFollowsPatch(w, r, u, ac)
+	}))
 	v1.Get("/feed_follows", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
 		handleFollowsGet(w, r, u, ac)
 	}))
 	v1.Get("/posts", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
 		handlePostsGet(w, r, u, ac)
 	}))
+	v1.Get("/posts/{postID}/content", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handlePostContentGet(w, r, u, ac)
+	}))
+	v1.Post("/posts/{postID}/snooze", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handlePostSnooze(w, r, u, ac)
+	}))
+	v1.Delete("/posts/{postID}/snooze", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handlePostUnsnooze(w, r, u, ac)
+	}))
+	v1.Get("/posts/{postID}/suggested_tags", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handlePostSuggestedTags(w, r, u, ac)
+	}))
+	v1.Post("/posts/{postID}/email", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handlePostEmail(w, r, u, ac)
+	}))
+	v1.Post("/mutes/authors", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAuthorMutesPost(w, r, u, ac)
+	}))
+	v1.Get("/mutes/authors", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAuthorMutesGet(w, r, u, ac)
+	}))
+	v1.Delete("/mutes/authors/{muteID}", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAuthorMutesDelete(w, r, u, ac)
+	}))
+	v1.Post("/rules", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handlePostRulesPost(w, r, u, ac)
+	}))
+	v1.Get("/rules", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handlePostRulesGet(w, r, u, ac)
+	}))
+	v1.Post("/rules/preview", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handlePostRulesPreview(w, r, u, ac)
+	}))
+	v1.Delete("/rules/{ruleID}", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handlePostRulesDelete(w, r, u, ac)
+	}))
+	v1.Get("/subscribe", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleSubscribeGet(w, r, u, ac)
+	}))
+	v1.Post("/subscribe", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleSubscribePost(w, r, u, ac)
+	}))
+	v1.Options("/subscribe", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	v1.Get("/image_proxy", func(w http.ResponseWriter, r *http.Request) {
+		handleImageProxyGet(w, r, ac)
+	})
+	v1.Post("/inbox", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleInboxPost(w, r, u, ac)
+	}))
+	v1.Post("/queue", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleQueuePost(w, r, u, ac)
+	}))
+	v1.Get("/queue", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleQueueGet(w, r, u, ac)
+	}))
+	v1.Put("/queue/order", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleQueueOrderPut(w, r, u, ac)
+	}))
+	v1.Delete("/queue/{postID}", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleQueueDelete(w, r, u, ac)
+	}))
+	v1.Post("/notification_channels", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleNotificationChannelsPost(w, r, u, ac)
+	}))
+	v1.Get("/notification_channels", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleNotificationChannelsGet(w, r, u, ac)
+	}))
+	v1.Delete("/notification_channels/{channelID}", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleNotificationChannelsDelete(w, r, u, ac)
+	}))
+	v1.Post("/notification_channels/{channelID}/rotate_secret", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleNotificationChannelRotateSecret(w, r, u, ac)
+	}))
+	v1.Get("/webhooks/{channelID}/deliveries", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleWebhookDeliveriesGet(w, r, u, ac)
+	}))
+	v1.Post("/webhooks/{channelID}/deliveries/{deliveryID}/redeliver", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleWebhookRedeliver(w, r, u, ac)
+	}))
+	v1.Post("/republishes", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleRepublishesPost(w, r, u, ac)
+	}))
+	v1.Get("/republishes", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleRepublishesGet(w, r, u, ac)
+	}))
+	v1.Delete("/republishes/{republishID}", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleRepublishesDelete(w, r, u, ac)
+	}))
+	v1.Get("/republished/{token}", func(w http.ResponseWriter, r *http.Request) {
+		handleRepublishedGet(w, r, ac)
+	})
+	v1.Post("/virtual_feeds", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleVirtualFeedsPost(w, r, u, ac)
+	}))
+	v1.Get("/virtual_feeds", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleVirtualFeedsGet(w, r, u, ac)
+	}))
+	v1.Get("/push/vapid_public_key", func(w http.ResponseWriter, r *http.Request) {
+		handlePushPublicKeyGet(w, r, ac)
+	})
+	v1.Post("/push/subscriptions", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handlePushSubscriptionsPost(w, r, u, ac)
+	}))
+	v1.Delete("/push/subscriptions/{subscriptionID}", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handlePushSubscriptionsDelete(w, r, u, ac)
+	}))
+	v1.Get("/triggers/new_posts", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleTriggerNewPosts(w, r, u, ac)
+	}))
+	v1.Get("/triggers/new_posts/sample", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleTriggerNewPostsSample(w, r, u, ac)
+	}))
+	v1.Get("/starter_packs", func(w http.ResponseWriter, r *http.Request) {
+		handleStarterPacksGet(w, r, ac)
+	})
+	v1.Post("/starter_packs/{starterPackID}/follow", ac.middlewareAuth(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleStarterPackFollow(w, r, u, ac)
+	}))
+
+	admin := chi.NewRouter()
+	admin.Get("/audit_log", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminAuditLogGet(w, r, u, ac)
+	}))
+	admin.Get("/diagnostics", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminDiagnosticsGet(w, r, u, ac)
+	}))
+	admin.Get("/domain_rules", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminDomainRulesGet(w, r, u, ac)
+	}))
+	admin.Post("/domain_rules", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminDomainRulesPost(w, r, u, ac)
+	}))
+	admin.Delete("/domain_rules/{ruleID}", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminDomainRulesDelete(w, r, u, ac)
+	}))
+	admin.Get("/feed_health", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminFeedHealthGet(w, r, u, ac)
+	}))
+	admin.Patch("/feeds/{feedID}", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminFeedsPatch(w, r, u, ac)
+	}))
+	admin.Post("/feeds/{feedID}/parse_debug", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminFeedParseDebug(w, r, u, ac)
+	}))
+	admin.Post("/feeds/{feedID}/resume", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminFeedResume(w, r, u, ac)
+	}))
+	admin.Get("/feeds/{feedID}/snapshots", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminFetchSnapshotsGet(w, r, u, ac)
+	}))
+	admin.Get("/feeds/{feedID}/snapshots/{snapshotID}", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminFetchSnapshotGet(w, r, u, ac)
+	}))
+	admin.Get("/integrity", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminIntegrityGet(w, r, u, ac)
+	}))
+	admin.Post("/integrity/repair", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminIntegrityRepair(w, r, u, ac)
+	}))
+	admin.Get("/maintenance", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminMaintenanceGet(w, r, u, ac)
+	}))
+	admin.Put("/maintenance", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminMaintenancePut(w, r, u, ac)
+	}))
+	admin.Delete("/posts", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminPostsDelete(w, r, u, ac)
+	}))
+	admin.Patch("/posts/{postID}", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminPostsPatch(w, r, u, ac)
+	}))
+	admin.Get("/posts/{postID}/revisions", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminPostRevisionsGet(w, r, u, ac)
+	}))
+	admin.Get("/reports", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminReportsGet(w, r, u, ac)
+	}))
+	admin.Post("/reports/{reportID}/resolve", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminReportResolve(w, r, u, ac)
+	}))
+	admin.Post("/users/merge", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminUsersMerge(w, r, u, ac)
+	}))
+	admin.Post("/seed", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminSeedPost(w, r, u, ac)
+	}))
+	admin.Get("/slo", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminSLOGet(w, r, u, ac)
+	}))
+	admin.Get("/storage", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminStorageGet(w, r, u, ac)
+	}))
+	admin.Post("/starter_packs", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminStarterPacksPost(w, r, u, ac)
+	}))
+	admin.Delete("/starter_packs/{starterPackID}", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminStarterPacksDelete(w, r, u, ac)
+	}))
+	admin.Post("/starter_packs/{starterPackID}/feeds", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminStarterPackFeedsPost(w, r, u, ac)
+	}))
+	admin.Delete("/starter_packs/{starterPackID}/feeds/{feedID}", ac.middlewareAdmin(func(w http.ResponseWriter, r *http.Request, u database.User) {
+		handleAdminStarterPackFeedsDelete(w, r, u, ac)
+	}))
 	r.Mount("/v1", v1)
 
+	// SCIM lets an identity provider create, update and deprovision users.
+	scim := chi.NewRouter()
+	scim.Get("/ServiceProviderConfig", ac.middlewareSCIM(handleSCIMServiceProviderConfigGet))
+	scim.Get("/Users", ac.middlewareSCIM(func(w http.ResponseWriter, r *http.Request) {
+		handleSCIMUsersGet(w, r, ac)
+	}))
+	scim.Post("/Users", ac.middlewareSCIM(func(w http.ResponseWriter, r *http.Request) {
+		handleSCIMUsersPost(w, r, ac)
+	}))
+	scim.Get("/Users/{userID}", ac.middlewareSCIM(func(w http.ResponseWriter, r *http.Request) {
+		handleSCIMUserGet(w, r, ac)
+	}))
+	scim.Put("/Users/{userID}", ac.middlewareSCIM(func(w http.ResponseWriter, r *http.Request) {
+		handleSCIMUserPut(w, r, ac)
+	}))
+	scim.Patch("/Users/{userID}", ac.middlewareSCIM(func(w http.ResponseWriter, r *http.Request) {
+		handleSCIMUserPatch(w, r, ac)
+	}))
+	scim.Delete("/Users/{userID}", ac.middlewareSCIM(func(w http.ResponseWriter, r *http.Request) {
+		handleSCIMUserDelete(w, r, ac)
+	}))
+	r.Mount("/scim/v2", scim)
+
+	// With ADMIN_ADDR set, operational routes are served only on that
+	// address, which is meant to be reachable from inside the cluster and
+	// not from the internet. Otherwise they share the public port. pprof is
+	// unauthenticated, so it is only ever served on ADMIN_ADDR.
+	if adminAddr := os.Getenv("ADMIN_ADDR"); adminAddr != "" {
+		internal := chi.NewRouter()
+		internal.Mount("/v1/admin", admin)
+		internal.Mount("/debug", middleware.Profiler())
+		go func() {
+			logInfo("api", "Serving admin routes on %s", adminAddr)
+			is := http.Server{
+				Addr:    adminAddr,
+				Handler: internal,
+			}
+			log.Fatal(is.ListenAndServe())
+		}()
+	} else {
+		v1.Mount("/admin", admin)
+	}
+
 	s := http.Server{
 		Addr:    fmt.Sprintf(":%s", port),
 		Handler: r,
@@ -157,6 +844,11 @@ func respondWithError(w http.ResponseWriter, code int, msg string) {
 	respondWithJSON(w, code, map[string]string{"error": msg})
 }
 
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
 func handleUsersPost(w http.ResponseWriter, r *http.Request, ac apiConfig) {
 	type usersRequest struct {
 		Name string `json:"name"`
@@ -167,33 +859,62 @@ func handleUsersPost(w http.ResponseWriter, r *http.Request, ac apiConfig) {
 	err := decoder.Decode(&newUsersReq)
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, "Could not decode json request")
-		fmt.Println(err)
+		logDebug("api", "Could not decode user request: %v", err)
+		return
+	}
+	name := strings.TrimSpace(newUsersReq.Name)
+	if name == "" {
+		respondWithError(w, http.StatusBadRequest, "Name is required")
 		return
 	}
 	user := database.CreateUserParams{
 		ID:        uuid.New(),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
-		Name:      newUsersReq.Name,
+		Name:      name,
 	}
 	newUser, err := ac.DB.CreateUser(r.Context(), user)
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "Name already taken")
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Error creating user")
-		fmt.Println(err)
+		logError("api", "Could not create user: %v", err)
 		return
 	}
-	respondWithJSON(w, http.StatusCreated, newUser)
+	respondWithJSON(w, http.StatusCreated, newUserResponse(newUser))
+}
+
+func handleUsersCheck(w http.ResponseWriter, r *http.Request, ac apiConfig) {
+	name := strings.TrimSpace(r.URL.Query().Get("name"))
+	if name == "" {
+		respondWithError(w, http.StatusBadRequest, "Name is required")
+		return
+	}
+	exists, err := ac.DB.UserNameExists(r.Context(), name)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to check name")
+		return
+	}
+	type checkResponse struct {
+		Name      string `json:"name"`
+		Available bool   `json:"available"`
+	}
+	respondWithJSON(w, http.StatusOK, checkResponse{Name: name, Available: !exists})
 }
 
 func handleUsersGet(w http.ResponseWriter, r *http.Request, u database.User) {
-	respondWithJSON(w, http.StatusOK, u)
+	respondWithJSON(w, http.StatusOK, newUserResponse(u))
 	return
 }
 
 func handleFeedsPost(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
 	type feedsPostRequest struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
+		Name        string           `json:"name"`
+		URL         string           `json:"url"`
+		Sensitive   bool             `json:"sensitive"`
+		Credentials *feedCredentials `json:"credentials"`
 	}
 	decoder := json.NewDecoder(r.Body)
 	defer r.Body.Close()
@@ -203,16 +924,107 @@ func handleFeedsPost(w http.ResponseWriter, r *http.Request, u database.User, ac
 		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
 		return
 	}
-	newFeed, err := ac.DB.CreateFeed(
-		r.Context(),
-		database.CreateFeedParams{
-			ID:        uuid.New(),
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-			Name:      newFeedsPostRequest.Name,
-			Url:       newFeedsPostRequest.URL,
-			UserID:    u.ID,
+	rules, err := ac.DB.ListDomainRules(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to check feed domain")
+		return
+	}
+	err = checkFeedDomain(rules, newFeedsPostRequest.URL)
+	if errors.Is(err, errDomainNotAllowed) {
+		respondWithError(w, http.StatusForbidden, "Feed domain is not allowed on this instance")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid feed URL")
+		return
+	}
+	err = checkFeedAddress(r.Context(), newFeedsPostRequest.URL, ac.AllowPrivate)
+	if errors.Is(err, errUnsupportedScheme) {
+		respondWithError(w, http.StatusBadRequest, "Feed URL must be http or https")
+		return
+	}
+	if errors.Is(err, errPrivateAddress) {
+		respondWithError(w, http.StatusForbidden, "Feed URL points to a private address")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid feed URL")
+		return
+	}
+	type createFeedResponse struct {
+		Feed       feedResponse       `json:"feed"`
+		FeedFollow feedFollowResponse `json:"feed_follow"`
+	}
+	// A feed already on the instance, perhaps under another spelling of its
+	// URL or one it has moved away from, is followed rather than added
+	// again. A feed added with credentials is private to whoever added it,
+	// so it is never matched this way; adding the URL with credentials
+	// always makes a separate feed.
+	followExisting := func(existing database.Feed) {
+		follow, err := followFeed(r.Context(), ac.DB, u.ID, existing.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to save feed follow")
+			return
+		}
+		respondWithJSON(w, http.StatusOK, createFeedResponse{
+			Feed:       newFeedResponse(existing),
+			FeedFollow: newFeedFollowResponse(follow),
 		})
+	}
+	private := newFeedsPostRequest.Credentials != nil
+	if !private {
+		existing, err := getFeedByURL(r.Context(), ac.DB, newFeedsPostRequest.URL)
+		if err == nil {
+			followExisting(existing)
+			return
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusInternalServerError, "Unable to check existing feeds")
+			return
+		}
+	}
+	params := database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Name:      strings.TrimSpace(newFeedsPostRequest.Name),
+		Url:       normalizeFeedURL(newFeedsPostRequest.URL),
+		UserID:    u.ID,
+		Sensitive: newFeedsPostRequest.Sensitive,
+	}
+	var headers http.Header
+	if c := newFeedsPostRequest.Credentials; c != nil {
+		if ac.Credentials == nil {
+			respondWithError(w, http.StatusBadRequest, "Feed credentials are not enabled on this instance")
+			return
+		}
+		if err := c.validate(); err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		params.Credentials, err = ac.Credentials.seal(params.ID, *c)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to save feed")
+			return
+		}
+		headers = http.Header{"Authorization": {c.authorization()}}
+	}
+	// A feed that cannot be fetched yet, say because it needs fetch headers
+	// set after it is added, is still created, with what the user gave.
+	fd, err := ac.FetchFeed(r.Context(), params.Url, cacheValidators{}, headers)
+	if err != nil {
+		logWarn("fetch", "Could not fetch new feed %s: %v", params.Url, err)
+	}
+	fillFeedMetadata(&params, fd)
+	newFeed, err := ac.DB.CreateFeed(r.Context(), params)
+	if isUniqueViolation(err) && !private {
+		// Someone else added it while this feed was being fetched.
+		existing, err := getFeedByURL(r.Context(), ac.DB, params.Url)
+		if err == nil {
+			followExisting(existing)
+			return
+		}
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Unable to save feed")
 		return
@@ -230,23 +1042,87 @@ func handleFeedsPost(w http.ResponseWriter, r *http.Request, u database.User, ac
 		respondWithError(w, http.StatusInternalServerError, "Unable to save feed follow")
 		return
 	}
-	type createFeedResponse struct {
-		Feed       database.Feed       `json:"feed"`
-		FeedFollow database.FeedFollow `json:"feed_follow"`
-	}
 	respondWithJSON(w, http.StatusOK, createFeedResponse{
-		Feed:       newFeed,
-		FeedFollow: newFeedFollow,
+		Feed:       newFeedResponse(newFeed),
+		FeedFollow: newFeedFollowResponse(newFeedFollow),
 	})
 }
 
+// handleFeedsGet lists the feeds on the server, by ID. Given a limit or a
+// cursor, it responds with one page of them and the cursor for the next.
 func handleFeedsGet(w http.ResponseWriter, r *http.Request, ac apiConfig) {
-	feeds, err := ac.DB.ListFeeds(r.Context())
+	languages, err := requestLanguages(r, ac)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid language code")
+		return
+	}
+	page, err := parsePageRequest(r, "feeds", defaultPageSize)
+	if err != nil {
+		respondWithPageError(w, err)
+		return
+	}
+	afterID := uuid.NullUUID{}
+	limit := sql.NullInt32{}
+	if page.Paged {
+		limit = sql.NullInt32{Int32: int32(page.Limit + 1), Valid: true}
+	}
+	if page.Cursor != nil {
+		afterID = uuid.NullUUID{UUID: page.Cursor.ID, Valid: true}
+	}
+	var feeds []database.Feed
+	if len(languages) > 0 {
+		feeds, err = ac.DB.ListFeedsByLanguage(r.Context(), database.ListFeedsByLanguageParams{
+			Languages: languages,
+			AfterID:   afterID,
+			Limit:     limit,
+		})
+	} else {
+		feeds, err = ac.DB.ListFeeds(r.Context(), database.ListFeedsParams{AfterID: afterID, Limit: limit})
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve feeds")
 		return
 	}
-	respondWithJSON(w, http.StatusOK, feeds)
+	var nextCursor *string
+	if page.Paged {
+		found := len(feeds)
+		feeds = feeds[:min(found, page.Limit)]
+		if len(feeds) > 0 {
+			nextCursor = nextPageCursor(found, page.Limit, pageCursor{List: "feeds", ID: feeds[len(feeds)-1].ID})
+		}
+	}
+	w.Header().Set("Vary", "Accept")
+	if format := negotiateFormat(r); format != formatJSON {
+		f := syndicationFeed{Title: "Feeds", Link: requestURL(r), Description: "Feeds on this server", Updated: time.Now()}
+		for _, feed := range feeds {
+			f.Items = append(f.Items, syndicationItem{
+				ID:        feed.ID,
+				Title:     feed.Name,
+				Link:      feed.Url,
+				Published: feed.CreatedAt,
+				Updated:   feed.UpdatedAt,
+			})
+		}
+		respondWithFeed(w, format, f)
+		return
+	}
+	followIntervals, err := followFetchIntervals(r.Context(), ac)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve feeds")
+		return
+	}
+	responses := newFeedResponses(feeds)
+	now := ac.Clock.Now()
+	for i, f := range feeds {
+		if next, ok := ac.nextFetchAt(f, followIntervals[f.ID], now); ok {
+			responses[i].NextFetchAt = &next
+		}
+	}
+	if page.Paged {
+		respondWithJSON(w, http.StatusOK, pageResponse{Items: responses, NextCursor: nextCursor})
+		return
+	}
+	respondWithJSON(w, http.StatusOK, responses)
 }
 
 func handleFollowsPost(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
@@ -267,11 +1143,20 @@ func handleFollowsPost(w http.ResponseWriter, r *http.Request, u database.User,
 		return
 	}
 
-	newFeedId, err := uuid.Parse(req.FeedId)
+	newFeedId, err := parseFeedID(r.Context(), ac.DB, req.FeedId)
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, "Invalid feed ID")
 		return
 	}
+	feed, err := ac.DB.GetFeed(r.Context(), newFeedId)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && (feed.Kind == "inbox" || isPrivateFeed(feed)) && feed.UserID != u.ID) {
+		respondWithError(w, http.StatusNotFound, "Feed not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve feed")
+		return
+	}
 	params := database.CreateFeedFollowParams{
 		ID:        uuid.New(),
 		CreatedAt: time.Now(),
@@ -284,7 +1169,7 @@ func handleFollowsPost(w http.ResponseWriter, r *http.Request, u database.User,
 		respondWithError(w, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
-	respondWithJSON(w, http.StatusOK, follow)
+	respondWithJSON(w, http.StatusOK, newFeedFollowResponse(follow))
 	return
 }
 
@@ -309,146 +1194,354 @@ func handleFollowsDelete(w http.ResponseWriter, r *http.Request, u database.User
 	return
 }
 
+const (
+	priorityHigh   = "high"
+	priorityNormal = "normal"
+	priorityLow    = "low"
+)
+
+// handleFollowsPatch changes a follow's priority or how its posts are
+// ordered. Priority decides how often an adaptively scheduled feed is
+// polled, going by its highest priority follower: every 5 minutes for
+// high, 15 for normal and an hour for low. Only high priority follows get
+// web push, and low priority follows get no notifications at all. Posts
+// have no read state, so there are no unread counts for it to change;
+// low priority posts are listed like any other.
+func handleFollowsPatch(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	feedFollowUUID, err := uuid.Parse(chi.URLParam(r, "feedFollowID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	type followsPatchRequest struct {
+		Priority        *string `json:"priority"`
+		OrderByIngested *bool   `json:"order_by_ingested"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := followsPatchRequest{}
+	err = decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	params := database.UpdateFeedFollowParams{
+		UpdatedAt: time.Now(),
+		ID:        feedFollowUUID,
+		UserID:    u.ID,
+	}
+	if req.Priority != nil {
+		switch *req.Priority {
+		case priorityHigh, priorityNormal, priorityLow:
+		default:
+			respondWithError(w, http.StatusBadRequest, "Priority must be high, normal, or low")
+			return
+		}
+		params.Priority = sql.NullString{String: *req.Priority, Valid: true}
+	}
+	if req.OrderByIngested != nil {
+		params.OrderByIngested = sql.NullBool{Bool: *req.OrderByIngested, Valid: true}
+	}
+	follow, err := ac.DB.UpdateFeedFollow(r.Context(), params)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Feed follow not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to update follow")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, newFeedFollowResponse(follow))
+}
+
+// handleFollowsGet lists the user's follows. Given a limit or a cursor, it
+// responds with one page of them, oldest first, and the cursor for the
+// next.
 func handleFollowsGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
-	feedFollows, err := ac.DB.GetUserFeedFollows(r.Context(), u.ID)
+	page, err := parsePageRequest(r, "feed_follows", defaultPageSize)
+	if err != nil {
+		respondWithPageError(w, err)
+		return
+	}
+	if !page.Paged {
+		feedFollows, err := ac.DB.GetUserFeedFollows(r.Context(), u.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to retrieve follows")
+			return
+		}
+		respondWithJSON(w, http.StatusOK, newFeedFollowResponses(feedFollows))
+		return
+	}
+	params := database.GetUserFeedFollowsPageParams{UserID: u.ID, Limit: int32(page.Limit + 1)}
+	if c := page.Cursor; c != nil {
+		if c.At == nil {
+			respondWithPageError(w, errInvalidCursor)
+			return
+		}
+		params.AfterAt = sql.NullTime{Time: *c.At, Valid: true}
+		params.AfterID = uuid.NullUUID{UUID: c.ID, Valid: true}
+	}
+	feedFollows, err := ac.DB.GetUserFeedFollowsPage(r.Context(), params)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve follows")
 		return
 	}
-	respondWithJSON(w, http.StatusOK, feedFollows)
-	return
+	found := len(feedFollows)
+	feedFollows = feedFollows[:min(found, page.Limit)]
+	var nextCursor *string
+	if len(feedFollows) > 0 {
+		last := feedFollows[len(feedFollows)-1]
+		nextCursor = nextPageCursor(found, page.Limit, pageCursor{List: "feed_follows", At: &last.CreatedAt, ID: last.ID})
+	}
+	respondWithJSON(w, http.StatusOK, pageResponse{Items: newFeedFollowResponses(feedFollows), NextCursor: nextCursor})
 }
 
+// handlePostsGet lists the user's timeline. order_by=published_at or
+// order_by=ingested_at sorts newest first by that time; follows marked
+// order_by_ingested always sort by ingestion time, for feeds whose dates
+// cannot be trusted. Without order_by, posts sort oldest update first.
+// order=asc or order=desc reverses either default. since and until keep the
+// posts whose sort time is after since and no later than until, so a client
+// can sync what is new since its last fetch. feed_id narrows it to some of
+// the user's feeds. Given a limit or a cursor, the JSON response is a page
+// with the cursor for the next.
 func handlePostsGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	orderBy := r.URL.Query().Get("order_by")
+	switch orderBy {
+	case "", "ingested_at", "published_at":
+	default:
+		respondWithError(w, http.StatusBadRequest, "order_by must be ingested_at or published_at")
+		return
+	}
+	// as_of lists the timeline as it stood at a past moment: only posts
+	// ingested and follows made by then, with titles and URLs from before
+	// any later correction.
+	asOf := time.Now()
+	pastAsOf := false
+	if v := r.URL.Query().Get("as_of"); v != "" {
+		t, err := parseAsOf(v)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "as_of must be an RFC 3339 timestamp or a date")
+			return
+		}
+		asOf, pastAsOf = t, t.Before(asOf)
+	}
+	var since, until sql.NullTime
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, err := parseAsOf(v)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "since must be an RFC 3339 timestamp or a date")
+			return
+		}
+		since = sql.NullTime{Time: t, Valid: true}
+	}
+	if v := r.URL.Query().Get("until"); v != "" {
+		t, err := parseAsOf(v)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "until must be an RFC 3339 timestamp or a date")
+			return
+		}
+		until = sql.NullTime{Time: t, Valid: true}
+	}
+	order := r.URL.Query().Get("order")
+	switch order {
+	case "":
+		order = "asc"
+		if orderBy != "" {
+			order = "desc"
+		}
+	case "asc", "desc":
+	default:
+		respondWithError(w, http.StatusBadRequest, "order must be asc or desc")
+		return
+	}
+	feedIDs, err := requestFeedIDs(r, ac)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid feed ID")
+		return
+	}
+	list := "posts:" + orderBy + ":" + order
+	page, err := parsePageRequest(r, list, defaultPostsPageSize)
+	if err != nil {
+		respondWithPageError(w, err)
+		return
+	}
 	getPostArgs := database.GetPostsByUserParams{
-		UserID: u.ID,
-		Limit:  10,
+		UserID:   u.ID,
+		AsOf:     asOf,
+		OrderBy:  orderBy,
+		Since:    since,
+		Until:    until,
+		SortDesc: order == "desc",
+		FeedIds:  feedIDs,
+		Limit:    int32(page.Limit),
+	}
+	if page.Paged {
+		getPostArgs.Limit++
+	}
+	if c := page.Cursor; c != nil {
+		if c.At == nil {
+			respondWithPageError(w, errInvalidCursor)
+			return
+		}
+		getPostArgs.AfterAt = sql.NullTime{Time: *c.At, Valid: true}
+		getPostArgs.AfterID = uuid.NullUUID{UUID: c.ID, Valid: true}
 	}
 	posts, err := ac.DB.GetPostsByUser(r.Context(), getPostArgs)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "There was a problem getting the user's posts")
 		return
 	}
+	found := len(posts)
+	posts = posts[:min(found, page.Limit)]
+	var nextCursor *string
+	if page.Paged && len(posts) > 0 {
+		last := posts[len(posts)-1]
+		at := postSortTime(last, orderBy)
+		nextCursor = nextPageCursor(found, page.Limit, pageCursor{List: list, At: &at, ID: last.ID})
+	}
 	type response struct {
-		ID          uuid.UUID
-		CreatedAt   time.Time
-		UpdatedAt   time.Time
-		Title       string
-		Url         string
-		Description *string
-		PublishedAt *time.Time
-		FeedID      uuid.UUID
-		UserID      uuid.UUID
+		postResponse
+		UserID  uuid.UUID `json:"user_id"`
+		Blurred bool      `json:"blurred"`
+	}
+	ctx := r.Context()
+	rules, err := loadPostRules(ctx, ac, u.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve rules")
+		return
 	}
 	responses := make([]response, 0, len(posts))
 	for _, post := range posts {
 		r := response{
-			ID:        post.ID,
-			CreatedAt: post.CreatedAt,
-			UpdatedAt: post.UpdatedAt,
-			Title:     post.Title,
-			Url:       post.Url,
-			FeedID:    post.FeedID,
-			UserID:    u.ID,
-		}
-
-		if !post.Description.Valid {
-			r.Description = nil
-		} else {
-			r.Description = &post.Description.String
+			postResponse: postResponse{
+				ID:          post.ID,
+				PublicID:    post.PublicID,
+				CreatedAt:   post.CreatedAt,
+				UpdatedAt:   post.UpdatedAt,
+				Title:       post.Title,
+				Url:         post.Url,
+				Description: nullStringPtr(post.Description),
+				PublishedAt: nullTimePtr(post.PublishedAt),
+				FeedID:      post.FeedID,
+				Sensitive:   post.Sensitive || post.FeedSensitive,
+				Enclosure:   newEnclosureResponse(post.EnclosureUrl, post.EnclosureType, post.EnclosureLength),
+				Author:      post.Author,
+			},
+			UserID: u.ID,
 		}
-		if !post.PublishedAt.Valid {
-			r.PublishedAt = nil
-		} else {
-			r.PublishedAt = &post.PublishedAt.Time
+		r.Blurred = r.Sensitive && !u.ShowSensitive
+		if pastAsOf && post.UpdatedAt.After(asOf) {
+			r.Title, r.Url, err = titleAndUrlAsOf(ctx, ac, post.ID, post.Title, post.Url, asOf)
+			if err != nil {
+				respondWithError(w, http.StatusInternalServerError, "Unable to retrieve post revisions")
+				return
+			}
 		}
+		r.Title = rules.rewriteTitle(post.FeedID, r.Title)
 
 		responses = append(responses, r)
 	}
+	w.Header().Set("Vary", "Accept")
+	if format := negotiateFormat(r); format != formatJSON {
+		f := syndicationFeed{Title: "Posts for " + u.Name, Link: requestURL(r), Description: "Posts from the feeds " + u.Name + " follows", Updated: asOf}
+		for _, post := range responses {
+			f.Items = append(f.Items, postSyndicationItem(post.postResponse, post.Blurred))
+		}
+		respondWithFeed(w, format, f)
+		return
+	}
+	if page.Paged {
+		respondWithJSON(w, http.StatusOK, pageResponse{Items: responses, NextCursor: nextCursor})
+		return
+	}
 	respondWithJSON(w, http.StatusOK, responses)
 	return
 }
 
-func getFeed(url string) (feedData, error) {
+// maxFeedIDFilters bounds how many feeds a timeline can be narrowed to at
+// once.
+const maxFeedIDFilters = 100
+
+// requestFeedIDs reads the feed_id query parameter, which narrows a
+// timeline to some of the user's feeds. It may be repeated or hold several
+// comma-separated IDs, each a UUID or a public ID.
+func requestFeedIDs(r *http.Request, ac apiConfig) ([]uuid.UUID, error) {
+	var feedIDs []uuid.UUID
+	for _, v := range r.URL.Query()["feed_id"] {
+		for _, s := range strings.Split(v, ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			feedID, err := parseFeedID(r.Context(), ac.DB, s)
+			if err != nil {
+				return nil, err
+			}
+			feedIDs = append(feedIDs, feedID)
+		}
+	}
+	if len(feedIDs) > maxFeedIDFilters {
+		return nil, fmt.Errorf("too many feed IDs")
+	}
+	return feedIDs, nil
+}
+
+// postSortTime is the time post is sorted by in a timeline in the given
+// order, as GetPostsByUser sorts it.
+func postSortTime(post database.GetPostsByUserRow, orderBy string) time.Time {
+	switch {
+	case orderBy == "ingested_at":
+		return post.CreatedAt
+	case orderBy == "published_at" && !post.OrderByIngested && post.PublishedAt.Valid:
+		return post.PublishedAt.Time
+	case orderBy == "published_at":
+		return post.CreatedAt
+	}
+	return post.UpdatedAt
+}
+
+// getFeedConditional fetches url, sending If-None-Match and
+// If-Modified-Since when the previous fetch left validators, and skips
+// parsing when the server says nothing changed.
+func getFeedConditional(ctx context.Context, client *http.Client, url string, cache cacheValidators, headers http.Header) (feedData, error) {
 	fd := feedData{}
-	res, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fd, err
+	}
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+	cache.apply(req)
+	res, err := client.Do(req)
 	if err != nil {
-		fmt.Println(err)
 		return fd, err
 	}
-	body, err := io.ReadAll(res.Body)
 	defer res.Body.Close()
-	err = xml.Unmarshal(body, &fd)
+	movedTo := permanentRedirectTarget(res)
+	if res.StatusCode == http.StatusNotModified {
+		logDebug("fetch", "%s not modified", url)
+		return feedData{NotModified: true, Cache: cache, MovedTo: movedTo, StatusCode: res.StatusCode}, nil
+	}
+	if res.StatusCode >= 400 {
+		fd.StatusCode = res.StatusCode
+		return fd, fmt.Errorf("fetching %s: %s", url, res.Status)
+	}
+	body, err := io.ReadAll(res.Body)
 	if err != nil {
+		fd.StatusCode = res.StatusCode
 		return fd, err
 	}
-	fmt.Println(fd.Channel.Title)
-	return fd, nil
-}
-
-func getFeedsWorker(ac apiConfig) {
-	fmt.Println("Starting feeds worker...")
-	errorChan := make(chan error)
-	feedChan := make(chan feedData)
-	done := make(chan struct{})
-	for range time.Tick(time.Minute) {
-		feeds, err := ac.DB.GetNextFeedsToFetch(context.Background(), 10)
-		if err != nil {
-			fmt.Println("Could not get next feeds: ", err)
-			break
-		}
-		fmt.Println("Processing latest batch of feeds...")
-		wg := sync.WaitGroup{}
-		for _, feed := range feeds {
-			wg.Add(1)
-			fmt.Printf("Processing %s feed\n", feed.Name)
-			go func(f database.Feed) {
-				defer wg.Done()
-				feedData, err := getFeed(f.Url)
-				feedData.FeedID = feed.ID
-				if err != nil {
-					errorChan <- err
-				}
-				feedChan <- feedData
-			}(feed)
-		}
-		go func() {
-			wg.Wait()
-			done <- struct{}{}
-		}()
-
-		select {
-		case err := <-errorChan:
-			fmt.Println(err)
-		case feed := <-feedChan:
-			for _, item := range feed.Channel.Item {
-				fmt.Printf("Adding %s to posts...\n", item.Title)
-				createParams := database.CreatePostParams{
-					ID:        uuid.New(),
-					CreatedAt: time.Now(),
-					UpdatedAt: time.Now(),
-					Title:     item.Title,
-					Url:       item.Link,
-					FeedID:    feed.FeedID,
-				}
-				if item.Description != "" {
-					createParams.Description = sql.NullString{String: item.Description, Valid: true}
-				}
-				createParams.Description = sql.NullString{String: "", Valid: false}
-
-				if item.PubDate == "" {
-					createParams.PublishedAt = sql.NullTime{Time: time.Now(), Valid: false}
-				}
-				pubTime, err := time.Parse(time.RFC1123Z, item.PubDate)
-				if err != nil {
-					createParams.PublishedAt = sql.NullTime{Time: time.Now(), Valid: false}
-				}
-				createParams.PublishedAt = sql.NullTime{Time: pubTime, Valid: true}
-
-				ac.DB.CreatePost(context.Background(), createParams)
-			}
-		case <-done:
-			break
-		}
+	fd, err = parseFeed(feedBodyToUTF8(body, res.Header.Get("Content-Type")))
+	fd.StatusCode = res.StatusCode
+	fd.Body = body
+	fd.ContentType = res.Header.Get("Content-Type")
+	fd.MovedTo = movedTo
+	if err != nil {
+		return fd, err
 	}
+	fd.Cache = cacheValidatorsFrom(res)
+	logDebug("fetch", "Fetched %s (%d items)", fd.Channel.Title, len(fd.Channel.Item))
+	return fd, nil
 }
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const defaultMaintenanceRetryAfter = 5 * time.Minute
+
+// maintenanceState is this instance's maintenance switch. While it is on,
+// write requests get 503 with Retry-After, reads keep working, and the
+// background workers skip their ticks.
+type maintenanceState struct {
+	mu         sync.RWMutex
+	enabled    bool
+	message    string
+	retryAfter time.Duration
+	since      time.Time
+}
+
+type maintenanceStatus struct {
+	Enabled           bool       `json:"enabled"`
+	Message           string     `json:"message,omitempty"`
+	RetryAfterSeconds int        `json:"retry_after_seconds"`
+	Since             *time.Time `json:"since"`
+}
+
+// newMaintenanceStateFromEnv starts in maintenance mode when
+// MAINTENANCE_MODE is true, so an instance can be brought up for a migration
+// without ever accepting writes.
+func newMaintenanceStateFromEnv() (*maintenanceState, error) {
+	m := &maintenanceState{retryAfter: defaultMaintenanceRetryAfter}
+	if v := os.Getenv("MAINTENANCE_RETRY_AFTER"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("Invalid MAINTENANCE_RETRY_AFTER")
+		}
+		m.retryAfter = d
+	}
+	if v := os.Getenv("MAINTENANCE_MODE"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid MAINTENANCE_MODE")
+		}
+		m.set(enabled, "", 0)
+	}
+	return m, nil
+}
+
+func (m *maintenanceState) active() bool {
+	if m == nil {
+		return false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.enabled
+}
+
+func (m *maintenanceState) set(enabled bool, message string, retryAfter time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if enabled && !m.enabled {
+		m.since = time.Now()
+	}
+	m.enabled = enabled
+	m.message = message
+	if retryAfter > 0 {
+		m.retryAfter = retryAfter
+	}
+}
+
+func (m *maintenanceState) status() maintenanceStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s := maintenanceStatus{
+		Enabled:           m.enabled,
+		Message:           m.message,
+		RetryAfterSeconds: int(m.retryAfter.Seconds()),
+	}
+	if m.enabled {
+		since := m.since
+		s.Since = &since
+	}
+	return s
+}
+
+// middlewareMaintenance rejects writes while maintenance mode is on. Admin
+// routes are exempt so that maintenance can be switched off again.
+func (ac *apiConfig) middlewareMaintenance(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !ac.Maintenance.active() || strings.HasPrefix(r.URL.Path, "/v1/admin/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		status := ac.Maintenance.status()
+		message := "Down for maintenance, try again later"
+		if status.Message != "" {
+			message = status.Message
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(status.RetryAfterSeconds))
+		respondWithError(w, http.StatusServiceUnavailable, message)
+	})
+}
+
+func handleAdminMaintenanceGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	respondWithJSON(w, http.StatusOK, ac.Maintenance.status())
+}
+
+func handleAdminMaintenancePut(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	type maintenanceRequest struct {
+		Enabled           bool   `json:"enabled"`
+		Message           string `json:"message"`
+		RetryAfterSeconds int    `json:"retry_after_seconds"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := maintenanceRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	if req.RetryAfterSeconds < 0 {
+		respondWithError(w, http.StatusBadRequest, "retry_after_seconds must not be negative")
+		return
+	}
+
+	ac.Maintenance.set(req.Enabled, strings.TrimSpace(req.Message), time.Duration(req.RetryAfterSeconds)*time.Second)
+	status := ac.Maintenance.status()
+	// The database may be the thing under maintenance, so a failed audit
+	// entry must not stop the switch from being flipped.
+	err = recordAudit(r.Context(), ac.DB, u.ID, "maintenance.set", "instance", uuid.Nil, status)
+	if err != nil {
+		logError("api", "Could not record maintenance audit entry: %v", err)
+	}
+	logWarn("api", "Maintenance mode set to %t by %s", status.Enabled, u.ID)
+	respondWithJSON(w, http.StatusOK, status)
+}
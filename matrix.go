@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// matrixNotifier posts notifications to a Matrix room through the
+// client-server API, authenticating with the access token of a bot or user
+// account that has already joined the room.
+type matrixNotifier struct {
+	HomeserverURL string `json:"homeserver_url"`
+	AccessToken   string `json:"access_token"`
+	RoomID        string `json:"room_id"`
+
+	client *http.Client
+}
+
+// newMatrixNotifier decodes a Matrix channel's config. Messages are sent
+// with client, and a homeserver on a private address is refused unless
+// allowPrivate is set.
+func newMatrixNotifier(ctx context.Context, config json.RawMessage, client *http.Client, allowPrivate bool) (*matrixNotifier, error) {
+	m := &matrixNotifier{client: client}
+	err := json.Unmarshal(config, m)
+	if err != nil {
+		return nil, errors.New("invalid matrix config")
+	}
+	if m.HomeserverURL == "" || m.AccessToken == "" || m.RoomID == "" {
+		return nil, errors.New("matrix config requires homeserver_url, access_token, and room_id")
+	}
+	if _, err := url.ParseRequestURI(m.HomeserverURL); err != nil {
+		return nil, errors.New("invalid matrix homeserver_url")
+	}
+	err = checkFeedAddress(ctx, m.HomeserverURL, allowPrivate)
+	if errors.Is(err, errPrivateAddress) {
+		return nil, errors.New("matrix homeserver_url points to a private address")
+	}
+	if err != nil {
+		return nil, errors.New("invalid matrix homeserver_url")
+	}
+	return m, nil
+}
+
+func (m *matrixNotifier) notify(ctx context.Context, n notification) error {
+	endpoint := fmt.Sprintf(
+		"%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimSuffix(m.HomeserverURL, "/"),
+		url.PathEscape(m.RoomID),
+		uuid.New(),
+	)
+	body, err := json.Marshal(map[string]string{
+		"msgtype": "m.text",
+		"body":    n.Text,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+m.AccessToken)
+	req.Header.Set("Content-Type", "application/json")
+	res, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("matrix homeserver returned %s", res.Status)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// notification is what a notifier delivers. Chat channels send Text;
+// webhooks can shape the rest into their own payload.
+type notification struct {
+	Event     string             `json:"event"`
+	Text      string             `json:"text"`
+	FeedID    *uuid.UUID         `json:"feed_id,omitempty"`
+	FeedTitle string             `json:"feed_title,omitempty"`
+	Posts     []notificationPost `json:"posts"`
+}
+
+type notificationPost struct {
+	ID    uuid.UUID `json:"id"`
+	Title string    `json:"title"`
+	Url   string    `json:"url"`
+}
+
+// notifier delivers a notification to one channel.
+type notifier interface {
+	notify(ctx context.Context, n notification) error
+}
+
+// newNotifier decodes a channel's stored config for its kind. It is used both
+// to validate new channels and to deliver notifications.
+func (ac *apiConfig) newNotifier(ctx context.Context, kind string, config json.RawMessage) (notifier, error) {
+	switch kind {
+	case "matrix":
+		return newMatrixNotifier(ctx, config, ac.NotifyClient, ac.AllowPrivate)
+	case "webhook":
+		return newWebhookNotifier(ctx, config, ac.NotifyClient, ac.AllowPrivate)
+	case "xmpp":
+		return newXMPPNotifier(config)
+	default:
+		return nil, fmt.Errorf("unknown notification channel kind %q", kind)
+	}
+}
+
+// maxPostsPerNotification caps how many posts are listed in one batched
+// message; the remainder is summarised as a count.
+const maxPostsPerNotification = 10
+
+// formatNotification batches the new posts from a single fetch of a feed into
+// one message, so a busy feed produces one notification per fetch rather than
+// one per post.
+func formatNotification(feedTitle string, posts []database.Post) string {
+	msg := strings.Builder{}
+	if len(posts) == 1 {
+		fmt.Fprintf(&msg, "New post in %s:\n", feedTitle)
+	} else {
+		fmt.Fprintf(&msg, "%d new posts in %s:\n", len(posts), feedTitle)
+	}
+	for i, post := range posts {
+		if i == maxPostsPerNotification {
+			fmt.Fprintf(&msg, "…and %d more\n", len(posts)-i)
+			break
+		}
+		fmt.Fprintf(&msg, "- %s %s\n", post.Title, post.Url)
+	}
+	return strings.TrimSuffix(msg.String(), "\n")
+}
+
+func (ac *apiConfig) notifyNewPosts(ctx context.Context, feedID uuid.UUID, feedTitle string, posts []database.Post) {
+	if len(posts) == 0 {
+		return
+	}
+	if ac.WebPush != nil {
+		ac.pushNewPosts(ctx, feedID, feedTitle, posts)
+	}
+	channels, err := ac.DB.GetNotificationChannelsForFeed(ctx, feedID)
+	if err != nil {
+		logError("notify", "Could not get notification channels: %v", err)
+		return
+	}
+	msg := notification{
+		Event:     "new_posts",
+		Text:      formatNotification(feedTitle, posts),
+		FeedID:    &feedID,
+		FeedTitle: feedTitle,
+		Posts:     make([]notificationPost, 0, len(posts)),
+	}
+	for _, post := range posts {
+		msg.Posts = append(msg.Posts, notificationPost{ID: post.ID, Title: post.Title, Url: post.Url})
+	}
+	for _, channel := range channels {
+		err := ac.sendNotification(ctx, channel, msg)
+		if err != nil {
+			logWarn("notify", "Could not notify channel %s: %v", channel.ID, err)
+		}
+	}
+}
+
+// sendNotification delivers msg to one channel. Webhook deliveries are
+// also logged, so they can be inspected and redelivered.
+func (ac *apiConfig) sendNotification(ctx context.Context, channel database.NotificationChannel, msg notification) error {
+	n, err := ac.newNotifier(ctx, channel.Kind, channel.Config)
+	if err != nil {
+		return err
+	}
+	wh, ok := n.(*webhookNotifier)
+	if !ok {
+		return n.notify(ctx, msg)
+	}
+	body, err := wh.payload(msg)
+	if err != nil {
+		return err
+	}
+	_, err = ac.deliverWebhook(ctx, channel.ID, wh, msg.Event, uuid.New(), body, false)
+	return err
+}
+
+func handleNotificationChannelsPost(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	type notificationChannelRequest struct {
+		Kind   string          `json:"kind"`
+		Config json.RawMessage `json:"config"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := notificationChannelRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	_, err = ac.newNotifier(r.Context(), req.Kind, req.Config)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Kind == "webhook" {
+		req.Config, err = withWebhookSecret(req.Config)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to generate webhook secret")
+			return
+		}
+	}
+	channel, err := ac.DB.CreateNotificationChannel(r.Context(), database.CreateNotificationChannelParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    u.ID,
+		Kind:      req.Kind,
+		Config:    req.Config,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save notification channel")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, newNotificationChannelResponse(channel))
+}
+
+func handleNotificationChannelsGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	channels, err := ac.DB.GetUserNotificationChannels(r.Context(), u.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve notification channels")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, newNotificationChannelResponses(channels))
+}
+
+func handleNotificationChannelsDelete(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	channelID, err := uuid.Parse(chi.URLParam(r, "channelID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	err = ac.DB.DeleteNotificationChannel(r.Context(), database.DeleteNotificationChannelParams{
+		ID:     channelID,
+		UserID: u.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Problem deleting notification channel")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageSize = 50
+	// defaultPostsPageSize is also how many posts the timeline had before
+	// it was paged.
+	defaultPostsPageSize = 10
+	maxPageSize          = 200
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// A pageCursor marks where a page of a list ended: the sort time, if the
+// list is sorted by one, and the ID of its last item. The next page starts
+// right after it, however many items have been added or removed since,
+// without the database skipping over the earlier pages as an offset would.
+// Clients only ever see it encoded, as an opaque token.
+type pageCursor struct {
+	// List names the list, and its order, the cursor belongs to, so that a
+	// cursor from one is not taken for another.
+	List string     `json:"l"`
+	At   *time.Time `json:"t,omitempty"`
+	ID   uuid.UUID  `json:"id"`
+}
+
+func (c pageCursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodePageCursor(list, token string) (pageCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return pageCursor{}, errInvalidCursor
+	}
+	c := pageCursor{}
+	if err := json.Unmarshal(b, &c); err != nil || c.List != list {
+		return pageCursor{}, errInvalidCursor
+	}
+	return c, nil
+}
+
+// pageRequest is a client's request for one page of a list.
+type pageRequest struct {
+	// Paged is false when the client asked for neither a cursor nor a
+	// limit, and so gets the list as it did before lists were paged.
+	Paged  bool
+	Limit  int
+	Cursor *pageCursor
+}
+
+// parsePageRequest reads the limit and cursor query parameters for the
+// list named list.
+func parsePageRequest(r *http.Request, list string, defaultLimit int) (pageRequest, error) {
+	query := r.URL.Query()
+	req := pageRequest{Limit: defaultLimit}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			return req, errors.New("invalid limit")
+		}
+		req.Paged, req.Limit = true, limit
+	}
+	if v := query.Get("cursor"); v != "" {
+		c, err := decodePageCursor(list, v)
+		if err != nil {
+			return req, err
+		}
+		req.Paged, req.Cursor = true, &c
+	}
+	return req, nil
+}
+
+func respondWithPageError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errInvalidCursor) {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	respondWithError(w, http.StatusBadRequest, "Invalid limit")
+}
+
+// pageResponse is one page of a list. NextCursor is null on the last page.
+type pageResponse struct {
+	Items      any     `json:"items"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+// nextPageCursor returns the cursor for the page after one whose last item
+// is last, if the query for the page, asked for one item more than the
+// limit, found more.
+func nextPageCursor(found, limit int, last pageCursor) *string {
+	if found <= limit {
+		return nil
+	}
+	token := last.encode()
+	return &token
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// This is just enough of Parquet to write exports: one row group, one
+// uncompressed PLAIN data page per column, and flat schemas of required or
+// optional booleans, int64 timestamps and UTF-8 strings. The footer is
+// Thrift's compact protocol, written by hand.
+
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetByteArray = 6
+
+	parquetRequired = 0
+	parquetOptional = 1
+
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+
+	parquetConvertedUTF8            = 0
+	parquetConvertedTimestampMicros = 10
+)
+
+// A parquetColumn collects one column's values as they are added, row by
+// row.
+type parquetColumn struct {
+	name     string
+	physical int32
+	// timestamp marks an int64 column as microseconds since the epoch in
+	// UTC; byte array columns are always UTF-8 strings.
+	timestamp bool
+	optional  bool
+	present   []bool
+	bools     []bool
+	data      bytes.Buffer
+}
+
+func newParquetColumn(name string, physical int32, optional bool) *parquetColumn {
+	return &parquetColumn{name: name, physical: physical, optional: optional}
+}
+
+func (c *parquetColumn) addString(s string, present bool) {
+	c.present = append(c.present, present)
+	if present {
+		binary.Write(&c.data, binary.LittleEndian, uint32(len(s)))
+		c.data.WriteString(s)
+	}
+}
+
+func (c *parquetColumn) addInt64(v int64, present bool) {
+	c.present = append(c.present, present)
+	if present {
+		binary.Write(&c.data, binary.LittleEndian, v)
+	}
+}
+
+func (c *parquetColumn) addBool(v bool) {
+	c.present = append(c.present, true)
+	c.bools = append(c.bools, v)
+}
+
+// page returns the column's data page body: the definition levels, for an
+// optional column, then the PLAIN-encoded values.
+func (c *parquetColumn) page() []byte {
+	var page bytes.Buffer
+	if c.optional {
+		levels := rleBools(c.present)
+		binary.Write(&page, binary.LittleEndian, uint32(len(levels)))
+		page.Write(levels)
+	}
+	if c.physical == parquetBoolean {
+		packed := make([]byte, (len(c.bools)+7)/8)
+		for i, b := range c.bools {
+			if b {
+				packed[i/8] |= 1 << (i % 8)
+			}
+		}
+		page.Write(packed)
+	} else {
+		page.Write(c.data.Bytes())
+	}
+	return page.Bytes()
+}
+
+// rleBools encodes one-bit levels with the RLE half of Parquet's
+// RLE/bit-packing hybrid: a run length header, then the run's value.
+func rleBools(levels []bool) []byte {
+	var out []byte
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		out = binary.AppendUvarint(out, uint64(j-i)<<1)
+		if levels[i] {
+			out = append(out, 1)
+		} else {
+			out = append(out, 0)
+		}
+		i = j
+	}
+	return out
+}
+
+// encodeParquet lays out a file holding columns, which must all have
+// numRows values, as a single row group.
+func encodeParquet(columns []*parquetColumn, numRows int) []byte {
+	var file bytes.Buffer
+	file.WriteString("PAR1")
+	type chunk struct {
+		offset, size int64
+	}
+	chunks := make([]chunk, len(columns))
+	for i, c := range columns {
+		page := c.page()
+		header := &compactWriter{}
+		header.i32(1, 0) // DATA_PAGE
+		header.i32(2, int32(len(page)))
+		header.i32(3, int32(len(page)))
+		header.structBegin(5)
+		header.i32(1, int32(numRows))
+		header.i32(2, parquetEncodingPlain)
+		header.i32(3, parquetEncodingRLE)
+		header.i32(4, parquetEncodingRLE)
+		header.structEnd()
+		header.stop()
+		chunks[i] = chunk{offset: int64(file.Len()), size: int64(header.buf.Len() + len(page))}
+		file.Write(header.buf.Bytes())
+		file.Write(page)
+	}
+
+	meta := &compactWriter{}
+	meta.i32(1, 1)
+	meta.listBegin(2, compactStruct, len(columns)+1)
+	meta.elemBegin()
+	meta.binary(4, "schema")
+	meta.i32(5, int32(len(columns)))
+	meta.elemEnd()
+	for _, c := range columns {
+		meta.elemBegin()
+		meta.i32(1, c.physical)
+		if c.optional {
+			meta.i32(3, parquetOptional)
+		} else {
+			meta.i32(3, parquetRequired)
+		}
+		meta.binary(4, c.name)
+		switch {
+		case c.physical == parquetByteArray:
+			meta.i32(6, parquetConvertedUTF8)
+			meta.structBegin(10)
+			meta.structBegin(1) // STRING
+			meta.structEnd()
+			meta.structEnd()
+		case c.timestamp:
+			meta.i32(6, parquetConvertedTimestampMicros)
+			meta.structBegin(10)
+			meta.structBegin(8) // TIMESTAMP
+			meta.boolean(1, true)
+			meta.structBegin(2)
+			meta.structBegin(2) // MICROS
+			meta.structEnd()
+			meta.structEnd()
+			meta.structEnd()
+			meta.structEnd()
+		}
+		meta.elemEnd()
+	}
+	meta.i64(3, int64(numRows))
+	meta.listBegin(4, compactStruct, 1)
+	meta.elemBegin()
+	meta.listBegin(1, compactStruct, len(columns))
+	var total int64
+	for i, c := range columns {
+		total += chunks[i].size
+		meta.elemBegin()
+		meta.i64(2, chunks[i].offset)
+		meta.structBegin(3)
+		meta.i32(1, c.physical)
+		meta.listBegin(2, compactI32, 2)
+		meta.varint(parquetEncodingPlain)
+		meta.varint(parquetEncodingRLE)
+		meta.listBegin(3, compactBinary, 1)
+		meta.rawBinary(c.name)
+		meta.i32(4, 0) // UNCOMPRESSED
+		meta.i64(5, int64(numRows))
+		meta.i64(6, chunks[i].size)
+		meta.i64(7, chunks[i].size)
+		meta.i64(9, chunks[i].offset)
+		meta.structEnd()
+		meta.elemEnd()
+	}
+	meta.i64(2, total)
+	meta.i64(3, int64(numRows))
+	meta.elemEnd()
+	meta.binary(6, "rss-aggregator")
+	meta.stop()
+
+	file.Write(meta.buf.Bytes())
+	binary.Write(&file, binary.LittleEndian, uint32(meta.buf.Len()))
+	file.WriteString("PAR1")
+	return file.Bytes()
+}
+
+const (
+	compactTrue   = 1
+	compactFalse  = 2
+	compactI32    = 5
+	compactI64    = 6
+	compactBinary = 8
+	compactList   = 9
+	compactStruct = 12
+)
+
+// compactWriter writes Thrift compact protocol structs. Field headers hold
+// the difference from the previous field's ID, so the last ID is kept for
+// each struct being written.
+type compactWriter struct {
+	buf  bytes.Buffer
+	last []int16
+}
+
+func (w *compactWriter) field(id int16, typ byte) {
+	if len(w.last) == 0 {
+		w.last = []int16{0}
+	}
+	top := &w.last[len(w.last)-1]
+	if delta := id - *top; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		w.buf.WriteByte(typ)
+		w.varint(int64(id))
+	}
+	*top = id
+}
+
+// varint writes a zigzag varint, as compact integers are.
+func (w *compactWriter) varint(v int64) {
+	w.buf.Write(binary.AppendUvarint(nil, uint64(v<<1^v>>63)))
+}
+
+func (w *compactWriter) i32(id int16, v int32) {
+	w.field(id, compactI32)
+	w.varint(int64(v))
+}
+
+func (w *compactWriter) i64(id int16, v int64) {
+	w.field(id, compactI64)
+	w.varint(v)
+}
+
+func (w *compactWriter) boolean(id int16, v bool) {
+	if v {
+		w.field(id, compactTrue)
+	} else {
+		w.field(id, compactFalse)
+	}
+}
+
+func (w *compactWriter) binary(id int16, s string) {
+	w.field(id, compactBinary)
+	w.rawBinary(s)
+}
+
+func (w *compactWriter) rawBinary(s string) {
+	w.buf.Write(binary.AppendUvarint(nil, uint64(len(s))))
+	w.buf.WriteString(s)
+}
+
+func (w *compactWriter) listBegin(id int16, elem byte, n int) {
+	w.field(id, compactList)
+	if n < 15 {
+		w.buf.WriteByte(byte(n)<<4 | elem)
+	} else {
+		w.buf.WriteByte(0xF0 | elem)
+		w.buf.Write(binary.AppendUvarint(nil, uint64(n)))
+	}
+}
+
+func (w *compactWriter) structBegin(id int16) {
+	w.field(id, compactStruct)
+	w.elemBegin()
+}
+
+func (w *compactWriter) structEnd() {
+	w.elemEnd()
+}
+
+// elemBegin and elemEnd bracket a struct that is a list element, which
+// has no field header of its own.
+func (w *compactWriter) elemBegin() {
+	if len(w.last) == 0 {
+		w.last = []int16{0}
+	}
+	w.last = append(w.last, 0)
+}
+
+func (w *compactWriter) elemEnd() {
+	w.stop()
+	w.last = w.last[:len(w.last)-1]
+}
+
+func (w *compactWriter) stop() {
+	w.buf.WriteByte(0)
+}
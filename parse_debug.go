@@ -0,0 +1,110 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// handleAdminFeedParseDebug fetches a feed and reports what the fetcher
+// would make of it: each item as parsed, how its date was read, and whether
+// it would be inserted or skipped as a duplicate. Nothing is written, not
+// even the fetch validators or a snapshot.
+func handleAdminFeedParseDebug(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	feedID, err := parseFeedID(r.Context(), ac.DB, chi.URLParam(r, "feedID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	feed, err := ac.DB.GetFeed(r.Context(), feedID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Feed not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve feed")
+		return
+	}
+	fd, err := ac.FetchFeed(r.Context(), feed.Url, cacheValidators{}, ac.fetchHeaders(feed))
+	if err != nil {
+		respondWithError(w, http.StatusBadGateway, fmt.Sprintf("Unable to fetch feed: %v", err))
+		return
+	}
+
+	type itemDebug struct {
+		Title             string     `json:"title"`
+		Author            string     `json:"author"`
+		Link              string     `json:"link"`
+		Guid              string     `json:"guid"`
+		PubDate           string     `json:"pub_date"`
+		PublishedAt       *time.Time `json:"published_at"`
+		DateLayout        string     `json:"date_layout,omitempty"`
+		DateError         string     `json:"date_error,omitempty"`
+		Categories        []string   `json:"categories"`
+		Sensitive         bool       `json:"sensitive"`
+		DescriptionLength int        `json:"description_length"`
+		Decision          string     `json:"decision"`
+	}
+	type response struct {
+		FeedID      uuid.UUID   `json:"feed_id"`
+		Url         string      `json:"url"`
+		Format      string      `json:"format"`
+		Title       string      `json:"title"`
+		Language    string      `json:"language"`
+		ItemCount   int         `json:"item_count"`
+		InsertCount int         `json:"insert_count"`
+		Items       []itemDebug `json:"items"`
+	}
+	resp := response{
+		FeedID:    feed.ID,
+		Url:       feed.Url,
+		Format:    feedFormat(fd.Body),
+		Title:     fd.Channel.Title,
+		Language:  fd.Channel.Language,
+		ItemCount: len(fd.Channel.Item),
+		Items:     make([]itemDebug, 0, len(fd.Channel.Item)),
+	}
+	seen := map[string]bool{}
+	for _, item := range fd.Channel.Item {
+		link := stripTrackingParams(item.Link)
+		d := itemDebug{
+			Title:             item.Title,
+			Author:            itemAuthor(item),
+			Link:              link,
+			Guid:              item.Guid,
+			PubDate:           item.PubDate,
+			Categories:        append([]string{}, item.Category...),
+			Sensitive:         hasSensitiveCategory(item.Category),
+			DescriptionLength: len(item.Description),
+		}
+		if t, layout, err := parseFeedDateLayout(item.PubDate); err == nil {
+			d.PublishedAt = &t
+			d.DateLayout = layout
+		} else {
+			d.DateError = err.Error()
+		}
+		exists, err := ac.DB.PostUrlExists(r.Context(), link)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to check existing posts")
+			return
+		}
+		switch {
+		case exists:
+			d.Decision = "duplicate"
+		case seen[link]:
+			d.Decision = "duplicate_in_fetch"
+		default:
+			d.Decision = "insert"
+			resp.InsertCount++
+		}
+		seen[link] = true
+		resp.Items = append(resp.Items, d)
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
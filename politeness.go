@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFetchHostRPS = 1.0
+	// maxLimiterHosts is how many hosts hostLimiter remembers before it
+	// forgets the ones whose turn has passed.
+	maxLimiterHosts = 1024
+)
+
+// hostLimiter spaces out requests to the same host, so that many feeds on
+// one site, all due in the same batch, don't arrive there at once.
+// Requests wait their turn in the order they ask for it.
+type hostLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     map[string]time.Time
+}
+
+func newHostLimiter(rps float64) *hostLimiter {
+	return &hostLimiter{
+		interval: time.Duration(float64(time.Second) / rps),
+		next:     map[string]time.Time{},
+	}
+}
+
+// wait blocks until host may be sent another request, or ctx is done.
+func (l *hostLimiter) wait(ctx context.Context, host string) error {
+	l.mu.Lock()
+	now := time.Now()
+	if len(l.next) >= maxLimiterHosts {
+		for h, t := range l.next {
+			if t.Before(now) {
+				delete(l.next, h)
+			}
+		}
+	}
+	turn := l.next[host]
+	if turn.Before(now) {
+		turn = now
+	}
+	l.next[host] = turn.Add(l.interval)
+	l.mu.Unlock()
+
+	d := turn.Sub(now)
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// politeFetchFeed wraps a fetcher so that each host gets at most the
+// limiter's rate of requests.
+func politeFetchFeed(l *hostLimiter, next fetchFunc) fetchFunc {
+	return func(ctx context.Context, feedURL string, cache cacheValidators, headers http.Header) (feedData, error) {
+		if u, err := url.Parse(feedURL); err == nil {
+			err := l.wait(ctx, strings.ToLower(u.Hostname()))
+			if err != nil {
+				return feedData{}, err
+			}
+		}
+		return next(ctx, feedURL, cache, headers)
+	}
+}
+
+// newPoliteFetchFeedFromEnv wraps next to allow FETCH_HOST_RPS requests a
+// second to each host, 1 by default. Zero turns the limit off.
+func newPoliteFetchFeedFromEnv(next fetchFunc) (fetchFunc, error) {
+	rps := defaultFetchHostRPS
+	if v := os.Getenv("FETCH_HOST_RPS"); v != "" {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("Invalid FETCH_HOST_RPS")
+		}
+		rps = n
+	}
+	if rps == 0 {
+		return next, nil
+	}
+	return politeFetchFeed(newHostLimiter(rps), next), nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const defaultPostEmailDailyLimit = 20
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+func handlePostEmail(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	if ac.Mailer == nil {
+		respondWithError(w, http.StatusServiceUnavailable, "Email is not configured on this instance")
+		return
+	}
+	postID, err := parsePostID(r.Context(), ac.DB, chi.URLParam(r, "postID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	type postEmailRequest struct {
+		To string `json:"to"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := postEmailRequest{}
+	err = decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	to, err := mail.ParseAddress(req.To)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid email address")
+		return
+	}
+
+	post, err := visiblePost(r.Context(), ac, u, postID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Post not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve post")
+		return
+	}
+
+	sent, err := ac.DB.CountPostEmailsSince(r.Context(), database.CountPostEmailsSinceParams{
+		UserID:    u.ID,
+		CreatedAt: time.Now().Add(-24 * time.Hour),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to check email limit")
+		return
+	}
+	if sent >= int64(ac.PostEmailDailyLimit) {
+		respondWithError(w, http.StatusTooManyRequests, "Daily email limit reached")
+		return
+	}
+
+	body := strings.Builder{}
+	fmt.Fprintf(&body, "%s shared a post with you:\n\n", u.Name)
+	fmt.Fprintf(&body, "%s\n%s\n", post.Title, post.Url)
+	if post.Description.Valid {
+		excerpt := plainTextExcerpt(post.Description.String, 300)
+		if excerpt != "" {
+			fmt.Fprintf(&body, "\n%s\n", excerpt)
+		}
+	}
+	err = ac.Mailer.send(to.Address, post.Title, body.String())
+	if err != nil {
+		logError("email", "Could not send post email: %v", err)
+		respondWithError(w, http.StatusBadGateway, "Unable to send email")
+		return
+	}
+
+	postEmail, err := ac.DB.CreatePostEmail(r.Context(), database.CreatePostEmailParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UserID:    u.ID,
+		PostID:    post.ID,
+		Recipient: to.Address,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Email sent but could not be recorded")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, newPostEmailResponse(postEmail))
+}
+
+// plainTextExcerpt strips markup from an item description and truncates it
+// to at most n runes, breaking on a word boundary where possible.
+func plainTextExcerpt(s string, n int) string {
+	text := html.UnescapeString(htmlTagPattern.ReplaceAllString(s, " "))
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	cut := string(runes[:n])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
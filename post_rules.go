@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const (
+	// maxPostRules bounds the rules a user can have, since every one runs
+	// over every title in their timeline.
+	maxPostRules = 100
+	// maxRulePatternLength bounds a rule's regular expression.
+	maxRulePatternLength = 500
+	// rulePreviewPosts is how many of the user's latest posts a rule is
+	// previewed against.
+	rulePreviewPosts = 50
+)
+
+// A post rule is a user's rule for how posts are shown to them, for all the
+// feeds they follow or for one. The only action so far is rewrite_title,
+// which replaces matches of Pattern in titles with Replacement, with $1 and
+// ${name} standing for submatches. Posts are shared between everyone who
+// follows a feed, so rules are applied to the user's own listings rather
+// than to the stored posts; they also apply to posts stored before the rule
+// was made.
+type postRuleResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	FeedID      *uuid.UUID `json:"feed_id"`
+	Action      string     `json:"action"`
+	Pattern     string     `json:"pattern"`
+	Replacement string     `json:"replacement"`
+}
+
+func newPostRuleResponse(rule database.PostRule) postRuleResponse {
+	return postRuleResponse{
+		ID:          rule.ID,
+		CreatedAt:   rule.CreatedAt,
+		FeedID:      nullUUIDPtr(rule.FeedID),
+		Action:      rule.Action,
+		Pattern:     rule.Pattern,
+		Replacement: rule.Replacement,
+	}
+}
+
+type compiledPostRule struct {
+	FeedID      uuid.NullUUID
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// postRules are a user's rules, compiled, in the order they are applied.
+type postRules []compiledPostRule
+
+// loadPostRules compiles the user's rules. Patterns are checked when rules
+// are made, so one that no longer compiles is skipped rather than failing
+// the whole listing.
+func loadPostRules(ctx context.Context, ac apiConfig, userID uuid.UUID) (postRules, error) {
+	rules, err := ac.DB.GetUserPostRules(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	compiled := make(postRules, 0, len(rules))
+	for _, rule := range rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			logWarn("rules", "Skipping rule %s with invalid pattern: %v", rule.ID, err)
+			continue
+		}
+		compiled = append(compiled, compiledPostRule{FeedID: rule.FeedID, Pattern: re, Replacement: rule.Replacement})
+	}
+	return compiled, nil
+}
+
+// rewriteTitle applies the rules for feedID to title in turn. A rewrite
+// that would leave nothing of the title keeps the title as it was.
+func (rules postRules) rewriteTitle(feedID uuid.UUID, title string) string {
+	rewritten := title
+	for _, rule := range rules {
+		if rule.FeedID.Valid && rule.FeedID.UUID != feedID {
+			continue
+		}
+		rewritten = strings.TrimSpace(rule.Pattern.ReplaceAllString(rewritten, rule.Replacement))
+	}
+	if rewritten == "" {
+		return title
+	}
+	return rewritten
+}
+
+type postRuleRequest struct {
+	FeedID      string `json:"feed_id"`
+	Action      string `json:"action"`
+	Pattern     string `json:"pattern"`
+	Replacement string `json:"replacement"`
+}
+
+// compilePostRule checks a rule's action and compiles its pattern. Its
+// errors are fit to show the user.
+func compilePostRule(action, pattern string) (*regexp.Regexp, error) {
+	if action != "rewrite_title" {
+		return nil, errors.New("Action must be rewrite_title")
+	}
+	if pattern == "" || len(pattern) > maxRulePatternLength {
+		return nil, errors.New("Pattern must be 1 to 500 characters")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.New("Invalid pattern: " + err.Error())
+	}
+	return re, nil
+}
+
+// parsePostRuleRequest decodes and checks a rule from the request body. On
+// failure it has already responded.
+func parsePostRuleRequest(w http.ResponseWriter, r *http.Request, ac apiConfig) (database.CreatePostRuleParams, *regexp.Regexp, bool) {
+	params := database.CreatePostRuleParams{}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := postRuleRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return params, nil, false
+	}
+	re, err := compilePostRule(req.Action, req.Pattern)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return params, nil, false
+	}
+	if req.FeedID != "" {
+		feedID, err := parseFeedID(r.Context(), ac.DB, req.FeedID)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid feed ID")
+			return params, nil, false
+		}
+		_, err = ac.DB.GetFeed(r.Context(), feedID)
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Feed not found")
+			return params, nil, false
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to retrieve feed")
+			return params, nil, false
+		}
+		params.FeedID = uuid.NullUUID{UUID: feedID, Valid: true}
+	}
+	params.Action = req.Action
+	params.Pattern = req.Pattern
+	params.Replacement = req.Replacement
+	return params, re, true
+}
+
+func handlePostRulesPost(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	params, _, ok := parsePostRuleRequest(w, r, ac)
+	if !ok {
+		return
+	}
+	rules, err := ac.DB.GetUserPostRules(r.Context(), u.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve rules")
+		return
+	}
+	if len(rules) >= maxPostRules {
+		respondWithError(w, http.StatusConflict, "Too many rules")
+		return
+	}
+	params.ID = uuid.New()
+	params.CreatedAt = ac.Clock.Now()
+	params.UserID = u.ID
+	rule, err := ac.DB.CreatePostRule(r.Context(), params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save rule")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, newPostRuleResponse(rule))
+}
+
+func handlePostRulesGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	rules, err := ac.DB.GetUserPostRules(r.Context(), u.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve rules")
+		return
+	}
+	resp := make([]postRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		resp = append(resp, newPostRuleResponse(rule))
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func handlePostRulesDelete(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	ruleID, err := uuid.Parse(chi.URLParam(r, "ruleID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	err = ac.DB.DeletePostRule(r.Context(), database.DeletePostRuleParams{
+		ID:     ruleID,
+		UserID: u.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Problem deleting rule")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePostRulesPreview shows what a rule would do, without saving it: the
+// titles among the user's latest posts that it would change, as they are
+// shown now, with the user's other rules, and as they would be shown with
+// it added.
+func handlePostRulesPreview(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	params, re, ok := parsePostRuleRequest(w, r, ac)
+	if !ok {
+		return
+	}
+	rules, err := loadPostRules(r.Context(), ac, u.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve rules")
+		return
+	}
+	var feedIDs []uuid.UUID
+	if params.FeedID.Valid {
+		feedIDs = []uuid.UUID{params.FeedID.UUID}
+	}
+	posts, err := ac.DB.GetPostsByUser(r.Context(), database.GetPostsByUserParams{
+		UserID:   u.ID,
+		AsOf:     time.Now(),
+		OrderBy:  "ingested_at",
+		SortDesc: true,
+		FeedIds:  feedIDs,
+		Limit:    rulePreviewPosts,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "There was a problem getting the user's posts")
+		return
+	}
+	withRule := append(slices.Clip(rules), compiledPostRule{FeedID: params.FeedID, Pattern: re, Replacement: params.Replacement})
+	type previewPost struct {
+		PostID         uuid.UUID `json:"post_id"`
+		FeedID         uuid.UUID `json:"feed_id"`
+		Title          string    `json:"title"`
+		RewrittenTitle string    `json:"rewritten_title"`
+	}
+	type response struct {
+		Checked int           `json:"checked"`
+		Changed []previewPost `json:"changed"`
+	}
+	resp := response{Checked: len(posts), Changed: []previewPost{}}
+	for _, post := range posts {
+		title := rules.rewriteTitle(post.FeedID, post.Title)
+		rewritten := withRule.rewriteTitle(post.FeedID, post.Title)
+		if rewritten != title {
+			resp.Changed = append(resp.Changed, previewPost{
+				PostID:         post.ID,
+				FeedID:         post.FeedID,
+				Title:          title,
+				RewrittenTitle: rewritten,
+			})
+		}
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+	"github.com/pmwals09/rss-aggregator/internal/publicid"
+)
+
+// Posts and feeds have two identifiers: the UUID primary key and a short
+// public ID for URLs (see internal/publicid). Path parameters accept either,
+// so existing clients keep working while new links use public IDs.
+//
+// An unknown public ID resolves to uuid.Nil, which matches nothing, so
+// handlers report it exactly as they would an unknown UUID.
+
+func parsePostID(ctx context.Context, q database.Querier, s string) (uuid.UUID, error) {
+	return parseID(s, func(publicID string) (uuid.UUID, error) {
+		post, err := q.GetPostByPublicID(ctx, publicID)
+		return post.ID, err
+	})
+}
+
+func parseFeedID(ctx context.Context, q database.Querier, s string) (uuid.UUID, error) {
+	return parseID(s, func(publicID string) (uuid.UUID, error) {
+		feed, err := q.GetFeedByPublicID(ctx, publicID)
+		return feed.ID, err
+	})
+}
+
+func parseID(s string, lookup func(publicID string) (uuid.UUID, error)) (uuid.UUID, error) {
+	id, err := uuid.Parse(s)
+	if err == nil {
+		return id, nil
+	}
+	if !publicid.Valid(s) {
+		return uuid.Nil, fmt.Errorf("invalid ID %q", s)
+	}
+	id, err = lookup(publicid.Normalize(s))
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, nil
+	}
+	return id, err
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+func handleQueuePost(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	type queuePostRequest struct {
+		PostID string `json:"post_id"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := queuePostRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	postID, err := parsePostID(r.Context(), ac.DB, req.PostID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+	_, err = visiblePost(r.Context(), ac, u, postID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Post not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve post")
+		return
+	}
+	item, err := ac.DB.EnqueuePost(r.Context(), database.EnqueuePostParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UserID:    u.ID,
+		PostID:    postID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to add post to queue")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, newQueueItemResponse(item))
+}
+
+func handleQueueGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	items, err := ac.DB.GetUserQueue(r.Context(), u.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve queue")
+		return
+	}
+	rules, err := loadPostRules(r.Context(), ac, u.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve rules")
+		return
+	}
+	type response struct {
+		Position int32 `json:"position"`
+		postResponse
+	}
+	responses := make([]response, 0, len(items))
+	for _, item := range items {
+		responses = append(responses, response{
+			Position: item.Position,
+			postResponse: postResponse{
+				ID:          item.ID,
+				PublicID:    item.PublicID,
+				CreatedAt:   item.CreatedAt,
+				UpdatedAt:   item.UpdatedAt,
+				Title:       rules.rewriteTitle(item.FeedID, item.Title),
+				Url:         item.Url,
+				Description: nullStringPtr(item.Description),
+				PublishedAt: nullTimePtr(item.PublishedAt),
+				FeedID:      item.FeedID,
+				Sensitive:   item.Sensitive,
+				Enclosure:   newEnclosureResponse(item.EnclosureUrl, item.EnclosureType, item.EnclosureLength),
+				Author:      item.Author,
+			},
+		})
+	}
+	respondWithJSON(w, http.StatusOK, responses)
+}
+
+// handleQueueOrderPut replaces the queue order. The request must list every
+// queued post exactly once, so a client working from a stale copy of the
+// queue gets a 409 instead of silently dropping items into the wrong place.
+func handleQueueOrderPut(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	type queueOrderRequest struct {
+		PostIDs []string `json:"post_ids"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := queueOrderRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	items, err := ac.DB.GetUserQueue(r.Context(), u.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve queue")
+		return
+	}
+	queued := make(map[uuid.UUID]bool, len(items))
+	for _, item := range items {
+		queued[item.ID] = true
+	}
+	postIDs := make([]uuid.UUID, 0, len(req.PostIDs))
+	for _, id := range req.PostIDs {
+		postID, err := parsePostID(r.Context(), ac.DB, id)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid post ID")
+			return
+		}
+		if !queued[postID] {
+			respondWithError(w, http.StatusConflict, "Order must list each queued post exactly once")
+			return
+		}
+		delete(queued, postID)
+		postIDs = append(postIDs, postID)
+	}
+	if len(queued) != 0 {
+		respondWithError(w, http.StatusConflict, "Order must list each queued post exactly once")
+		return
+	}
+	err = ac.DB.ReorderQueueItems(r.Context(), database.ReorderQueueItemsParams{
+		PostIds: postIDs,
+		UserID:  u.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to reorder queue")
+		return
+	}
+	handleQueueGet(w, r, u, ac)
+}
+
+func handleQueueDelete(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	postID, err := parsePostID(r.Context(), ac.DB, chi.URLParam(r, "postID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	err = ac.DB.DeleteQueueItem(r.Context(), database.DeleteQueueItemParams{
+		UserID: u.ID,
+		PostID: postID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Problem removing post from queue")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// rdfFeed is an RSS 1.0 document. Unlike RSS 2.0 its items are siblings of
+// the channel rather than children, and dates, language and categories come
+// from the Dublin Core namespace.
+type rdfFeed struct {
+	XMLName xml.Name `xml:"RDF"`
+	Channel struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+		Language    string `xml:"http://purl.org/dc/elements/1.1/ language"`
+		Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	} `xml:"channel"`
+	Items []struct {
+		About       string   `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+		Title       string   `xml:"title"`
+		Link        string   `xml:"link"`
+		Description string   `xml:"description"`
+		Date        string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+		Subject     []string `xml:"http://purl.org/dc/elements/1.1/ subject"`
+		Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	} `xml:"item"`
+}
+
+// rdfToFeedData converts an RSS 1.0 feed to feedData. The item's rdf:about
+// is its identifier and stands in for a missing link, which the spec
+// requires to be the same URL anyway.
+func rdfToFeedData(rf rdfFeed) feedData {
+	fd := feedData{}
+	fd.Channel.Title = strings.TrimSpace(rf.Channel.Title)
+	fd.Channel.Description = strings.TrimSpace(rf.Channel.Description)
+	fd.Channel.Link.Text = strings.TrimSpace(rf.Channel.Link)
+	fd.Channel.Link.Href = fd.Channel.Link.Text
+	fd.Channel.Language = rf.Channel.Language
+	fd.Channel.LastBuildDate = atomDateToRSS(rf.Channel.Date)
+	for _, it := range rf.Items {
+		item := feedItem{
+			Title:       strings.TrimSpace(it.Title),
+			Link:        strings.TrimSpace(it.Link),
+			Guid:        it.About,
+			Description: strings.TrimSpace(it.Description),
+			PubDate:     atomDateToRSS(it.Date),
+			Category:    it.Subject,
+			Creator:     it.Creator,
+		}
+		if item.Link == "" {
+			item.Link = it.About
+		}
+		fd.Channel.Item = append(fd.Channel.Item, item)
+	}
+	return fd
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func readRDFFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func TestParseFeedRDF(t *testing.T) {
+	body := readRDFFixture(t, "rss1.rdf")
+	if got := feedFormat(body); got != "rdf" {
+		t.Fatalf("feedFormat = %q, want rdf", got)
+	}
+	fd, err := parseFeed(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := fd.Channel
+	if ch.Title != "Example Journal" {
+		t.Errorf("title = %q", ch.Title)
+	}
+	if ch.Link.Href != "https://example.org/" {
+		t.Errorf("link = %q", ch.Link.Href)
+	}
+	if ch.Description != "Notes from an example site" {
+		t.Errorf("description = %q", ch.Description)
+	}
+	if ch.Language != "en-gb" {
+		t.Errorf("language = %q", ch.Language)
+	}
+	if ch.LastBuildDate != "Sat, 02 Mar 2024 08:30:00 +0000" {
+		t.Errorf("last build date = %q", ch.LastBuildDate)
+	}
+	if len(ch.Item) != 2 {
+		t.Fatalf("got %d items, want 2", len(ch.Item))
+	}
+
+	item := ch.Item[0]
+	if item.Title != "Second post" || item.Link != "https://example.org/posts/2" {
+		t.Errorf("item = %q %q", item.Title, item.Link)
+	}
+	if item.Guid != "https://example.org/posts/2" {
+		t.Errorf("guid = %q, want the rdf:about", item.Guid)
+	}
+	if item.Description != "The second one" {
+		t.Errorf("description = %q", item.Description)
+	}
+	if item.PubDate != "Sat, 02 Mar 2024 08:30:00 +0100" {
+		t.Errorf("pubDate = %q", item.PubDate)
+	}
+	if !slices.Equal(item.Category, []string{"go", "feeds"}) {
+		t.Errorf("categories = %q", item.Category)
+	}
+	if item.Creator != "Jane Doe" {
+		t.Errorf("creator = %q", item.Creator)
+	}
+	if ch.Item[1].Creator != "" || len(ch.Item[1].Category) != 0 {
+		t.Errorf("second item picked up the first's creator or subjects: %+v", ch.Item[1])
+	}
+}
+
+func TestParseFeedRDFWithoutLink(t *testing.T) {
+	fd, err := parseFeed(readRDFFixture(t, "rss1_no_link.rdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fd.Channel.Item) != 1 {
+		t.Fatalf("got %d items, want 1", len(fd.Channel.Item))
+	}
+	item := fd.Channel.Item[0]
+	if item.Link != "https://example.org/posts/1" {
+		t.Errorf("link = %q, want the rdf:about", item.Link)
+	}
+	if item.PubDate != "" {
+		t.Errorf("pubDate = %q, want empty", item.PubDate)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// permanentRedirectTarget follows the redirects that led to res and returns
+// the URL reached through permanent (301 or 308) hops only, or "" if the
+// first hop was not permanent. A 301 to a 302 moves the feed to the 302's
+// URL, not to wherever that temporarily points.
+func permanentRedirectTarget(res *http.Response) string {
+	reqs := []*http.Request{}
+	for req := res.Request; req != nil; {
+		reqs = append([]*http.Request{req}, reqs...)
+		if req.Response == nil {
+			break
+		}
+		req = req.Response.Request
+	}
+	target := ""
+	for _, req := range reqs[1:] {
+		status := req.Response.StatusCode
+		if status != http.StatusMovedPermanently && status != http.StatusPermanentRedirect {
+			break
+		}
+		target = req.URL.String()
+	}
+	return target
+}
+
+// moveFeed points a permanently redirected feed at its new URL and keeps
+// the old one in its history, so the redirect chain is not walked on every
+// fetch and subscribing to the old URL still finds this feed. If the new
+// URL is already another feed, or not allowed here, the feed stays put.
+func moveFeed(ctx context.Context, ac apiConfig, rules []database.DomainRule, f database.Feed, newURL string) {
+	if newURL == f.Url {
+		return
+	}
+	if err := checkFeedDomain(rules, newURL); err != nil {
+		logWarn("fetch", "Not moving %s to %s: %v", f.Name, newURL, err)
+		return
+	}
+	tx, err := ac.DB.Begin(ctx)
+	if err != nil {
+		logError("fetch", "Could not move %s: %v", f.Name, err)
+		return
+	}
+	defer tx.Rollback()
+
+	existing, err := tx.GetFeedByUrl(ctx, newURL)
+	if err == nil && existing.ID != f.ID {
+		logWarn("fetch", "Not moving %s to %s: that URL is already feed %s", f.Name, newURL, existing.ID)
+		return
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logError("fetch", "Could not move %s: %v", f.Name, err)
+		return
+	}
+	now := ac.Clock.Now()
+	err = tx.UpdateFeedUrl(ctx, database.UpdateFeedUrlParams{
+		ID:        f.ID,
+		Url:       newURL,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		logError("fetch", "Could not move %s: %v", f.Name, err)
+		return
+	}
+	err = tx.CreateFeedUrlHistoryEntry(ctx, database.CreateFeedUrlHistoryEntryParams{
+		ID:        uuid.New(),
+		FeedID:    f.ID,
+		OldUrl:    f.Url,
+		NewUrl:    newURL,
+		ChangedAt: now,
+	})
+	if err != nil {
+		logError("fetch", "Could not record move of %s: %v", f.Name, err)
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		logError("fetch", "Could not move %s: %v", f.Name, err)
+		return
+	}
+	logInfo("fetch", "Moved %s from %s to %s", f.Name, f.Url, newURL)
+}
+
+func handleFeedUrlHistoryGet(w http.ResponseWriter, r *http.Request, ac apiConfig) {
+	feed, ok := getVisibleFeed(w, r, ac)
+	if !ok {
+		return
+	}
+	history, err := ac.DB.ListFeedUrlHistory(r.Context(), feed.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve feed URL history")
+		return
+	}
+	type urlChange struct {
+		OldURL    string    `json:"old_url"`
+		NewURL    string    `json:"new_url"`
+		ChangedAt time.Time `json:"changed_at"`
+	}
+	resp := make([]urlChange, 0, len(history))
+	for _, h := range history {
+		resp = append(resp, urlChange{OldURL: h.OldUrl, NewURL: h.NewUrl, ChangedAt: h.ChangedAt})
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const (
+	maxKeywords      = 20
+	maxKeywordLength = 100
+	// republishPostsLimit is how many of the feed's newest posts a
+	// republished feed is filtered from.
+	republishPostsLimit = 100
+)
+
+// A republish serves one feed's posts, filtered by keyword, as RSS or Atom
+// at an unguessable URL, so readers elsewhere can subscribe to the filtered
+// feed without an API key.
+type republishResponse struct {
+	ID              uuid.UUID `json:"id"`
+	CreatedAt       time.Time `json:"created_at"`
+	FeedID          uuid.UUID `json:"feed_id"`
+	IncludeKeywords []string  `json:"include_keywords"`
+	ExcludeKeywords []string  `json:"exclude_keywords"`
+	Url             string    `json:"url"`
+}
+
+func newRepublishResponse(r *http.Request, rp database.FeedRepublish) republishResponse {
+	return republishResponse{
+		ID:              rp.ID,
+		CreatedAt:       rp.CreatedAt,
+		FeedID:          rp.FeedID,
+		IncludeKeywords: append([]string{}, rp.IncludeKeywords...),
+		ExcludeKeywords: append([]string{}, rp.ExcludeKeywords...),
+		Url:             requestOrigin(r) + "/v1/republished/" + rp.Token,
+	}
+}
+
+// normalizeKeywords lowercases and trims keywords, dropping blanks and
+// duplicates, since matching ignores case.
+func normalizeKeywords(keywords []string) ([]string, error) {
+	normalized := []string{}
+	for _, k := range keywords {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if k == "" || slices.Contains(normalized, k) {
+			continue
+		}
+		if len([]rune(k)) > maxKeywordLength {
+			return nil, fmt.Errorf("Keywords can be at most %d characters", maxKeywordLength)
+		}
+		normalized = append(normalized, k)
+	}
+	if len(normalized) > maxKeywords {
+		return nil, fmt.Errorf("At most %d keywords can be given", maxKeywords)
+	}
+	return normalized, nil
+}
+
+// matchesKeywords reports whether a post passes keyword filters: it must
+// mention at least one include keyword, if there are any, and no exclude
+// keyword. Titles and descriptions are searched, ignoring markup and case;
+// the keywords are expected to be normalized already.
+func matchesKeywords(include, exclude []string, p database.Post) bool {
+	text := strings.ToLower(p.Title + " " + html.UnescapeString(htmlTagPattern.ReplaceAllString(p.Description.String, " ")))
+	for _, k := range exclude {
+		if strings.Contains(text, k) {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, k := range include {
+		if strings.Contains(text, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func newRepublishToken() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func handleRepublishesPost(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	type republishRequest struct {
+		FeedID          string   `json:"feed_id"`
+		IncludeKeywords []string `json:"include_keywords"`
+		ExcludeKeywords []string `json:"exclude_keywords"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := republishRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	include, err := normalizeKeywords(req.IncludeKeywords)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	exclude, err := normalizeKeywords(req.ExcludeKeywords)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	feedID, err := parseFeedID(r.Context(), ac.DB, req.FeedID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid feed ID")
+		return
+	}
+	feed, err := ac.DB.GetFeed(r.Context(), feedID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && (feed.Kind != "remote" || isPrivateFeed(feed))) {
+		respondWithError(w, http.StatusNotFound, "Feed not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve feed")
+		return
+	}
+	republish, err := ac.DB.CreateFeedRepublish(r.Context(), database.CreateFeedRepublishParams{
+		ID:              uuid.New(),
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+		UserID:          u.ID,
+		FeedID:          feed.ID,
+		Token:           newRepublishToken(),
+		IncludeKeywords: include,
+		ExcludeKeywords: exclude,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save republish")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, newRepublishResponse(r, republish))
+}
+
+func handleRepublishesGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	republishes, err := ac.DB.GetUserFeedRepublishes(r.Context(), u.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve republishes")
+		return
+	}
+	resp := make([]republishResponse, 0, len(republishes))
+	for _, rp := range republishes {
+		resp = append(resp, newRepublishResponse(r, rp))
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func handleRepublishesDelete(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	republishID, err := uuid.Parse(chi.URLParam(r, "republishID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	err = ac.DB.DeleteFeedRepublish(r.Context(), database.DeleteFeedRepublishParams{
+		ID:     republishID,
+		UserID: u.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Problem deleting republish")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRepublishedGet serves a republish's filtered feed. It is RSS
+// unless the Accept header asks for Atom, since the URL is meant for feed
+// readers. As with the public feed posts listing, sensitive posts are left
+// out.
+func handleRepublishedGet(w http.ResponseWriter, r *http.Request, ac apiConfig) {
+	republish, err := ac.DB.GetFeedRepublishByToken(r.Context(), chi.URLParam(r, "token"))
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Republish not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve republish")
+		return
+	}
+	feed, err := ac.DB.GetFeed(r.Context(), republish.FeedID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve feed")
+		return
+	}
+	// The feed may have been given credentials since it was republished.
+	if isPrivateFeed(feed) {
+		respondWithError(w, http.StatusNotFound, "Republish not found")
+		return
+	}
+	posts := []database.Post{}
+	if !feed.Sensitive {
+		posts, err = ac.DB.GetPostsByFeed(r.Context(), database.GetPostsByFeedParams{
+			FeedID: feed.ID,
+			Limit:  republishPostsLimit,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to retrieve posts")
+			return
+		}
+	}
+
+	description := feed.Description
+	if description == "" {
+		description = "Posts from " + feed.Url
+	}
+	f := syndicationFeed{Title: feed.Name, Link: requestURL(r), Description: description, Updated: republish.UpdatedAt}
+	for _, post := range posts {
+		if post.Sensitive || !matchesKeywords(republish.IncludeKeywords, republish.ExcludeKeywords, post) {
+			continue
+		}
+		resp := newPostResponse(post)
+		if len(f.Items) == 0 {
+			f.Updated = resp.CreatedAt
+		}
+		f.Items = append(f.Items, postSyndicationItem(resp, false))
+	}
+	format := formatRSS
+	if negotiateFormat(r) == formatAtom {
+		format = formatAtom
+	}
+	w.Header().Set("Vary", "Accept")
+	respondWithFeed(w, format, f)
+}
@@ -0,0 +1,459 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// The types in this file are the API's wire format. Handlers convert
+// database rows to them instead of serialising sqlc's structs, so every
+// response uses snake_case keys and nullable columns come out as null or a
+// value rather than sql.Null* internals. A new column only reaches clients
+// once it is added here.
+
+type userResponse struct {
+	ID                 uuid.UUID `json:"id"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+	Name               string    `json:"name"`
+	ApiKey             string    `json:"api_key"`
+	IsAdmin            bool      `json:"is_admin"`
+	Email              *string   `json:"email"`
+	AvatarUrl          *string   `json:"avatar_url"`
+	Bio                *string   `json:"bio"`
+	ShowSensitive      bool      `json:"show_sensitive"`
+	PreferredLanguages []string  `json:"preferred_languages"`
+}
+
+func newUserResponse(u database.User) userResponse {
+	return userResponse{
+		ID:            u.ID,
+		CreatedAt:     u.CreatedAt,
+		UpdatedAt:     u.UpdatedAt,
+		Name:          u.Name,
+		ApiKey:        u.ApiKey,
+		IsAdmin:       u.IsAdmin,
+		Email:         nullStringPtr(u.Email),
+		AvatarUrl:     nullStringPtr(u.AvatarUrl),
+		Bio:           nullStringPtr(u.Bio),
+		ShowSensitive: u.ShowSensitive,
+		// Never null, so clients can treat it as a list unconditionally.
+		PreferredLanguages: append([]string{}, u.PreferredLanguages...),
+	}
+}
+
+type feedResponse struct {
+	ID                   uuid.UUID  `json:"id"`
+	PublicID             string     `json:"public_id"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+	Name                 string     `json:"name"`
+	Url                  string     `json:"url"`
+	Description          string     `json:"description"`
+	SiteUrl              string     `json:"site_url"`
+	UserID               uuid.UUID  `json:"user_id"`
+	LastFetchedAt        *time.Time `json:"last_fetched_at"`
+	DisabledAt           *time.Time `json:"disabled_at"`
+	Sensitive            bool       `json:"sensitive"`
+	Kind                 string     `json:"kind"`
+	Language             string     `json:"language"`
+	Country              string     `json:"country"`
+	FetchIntervalSeconds *int32     `json:"fetch_interval_seconds"`
+	FetchFailures        int32      `json:"fetch_failures"`
+	LastFetchStatus      *int32     `json:"last_fetch_status"`
+	LastFetchError       *string    `json:"last_fetch_error"`
+	RetryAt              *time.Time `json:"retry_at"`
+	PausedAt             *time.Time `json:"paused_at"`
+	FetchHeaders         []string   `json:"fetch_headers"`
+	Schedule             string     `json:"schedule"`
+	HasCredentials       bool       `json:"has_credentials"`
+	// NextFetchAt is only given in the feeds list, where it is left out
+	// for feeds that are not fetched on a schedule.
+	NextFetchAt *time.Time `json:"next_fetch_at,omitempty"`
+}
+
+func newFeedResponse(f database.Feed) feedResponse {
+	return feedResponse{
+		ID:                   f.ID,
+		PublicID:             f.PublicID,
+		CreatedAt:            f.CreatedAt,
+		UpdatedAt:            f.UpdatedAt,
+		Name:                 f.Name,
+		Url:                  f.Url,
+		Description:          f.Description,
+		SiteUrl:              f.SiteUrl,
+		UserID:               f.UserID,
+		LastFetchedAt:        nullTimePtr(f.LastFetchedAt),
+		DisabledAt:           nullTimePtr(f.DisabledAt),
+		Sensitive:            f.Sensitive,
+		Kind:                 f.Kind,
+		Language:             f.Language,
+		Country:              f.Country,
+		FetchIntervalSeconds: nullInt32Ptr(f.FetchIntervalSeconds),
+		FetchFailures:        f.FetchFailures,
+		LastFetchStatus:      nullInt32Ptr(f.LastFetchStatus),
+		LastFetchError:       nullStringPtr(sql.NullString{String: f.LastFetchError, Valid: f.LastFetchError != ""}),
+		RetryAt:              nullTimePtr(f.RetryAt),
+		PausedAt:             nullTimePtr(f.PausedAt),
+		FetchHeaders:         fetchHeaderNames(f.FetchHeaders),
+		Schedule:             f.Schedule,
+		HasCredentials:       f.Credentials != nil,
+	}
+}
+
+func newFeedResponses(feeds []database.Feed) []feedResponse {
+	responses := make([]feedResponse, 0, len(feeds))
+	for _, f := range feeds {
+		responses = append(responses, newFeedResponse(f))
+	}
+	return responses
+}
+
+type feedFollowResponse struct {
+	ID              uuid.UUID `json:"id"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	UserID          uuid.UUID `json:"user_id"`
+	FeedID          uuid.UUID `json:"feed_id"`
+	Priority        string    `json:"priority"`
+	OrderByIngested bool      `json:"order_by_ingested"`
+}
+
+func newFeedFollowResponse(f database.FeedFollow) feedFollowResponse {
+	return feedFollowResponse{
+		ID:              f.ID,
+		CreatedAt:       f.CreatedAt,
+		UpdatedAt:       f.UpdatedAt,
+		UserID:          f.UserID,
+		FeedID:          f.FeedID,
+		Priority:        f.Priority,
+		OrderByIngested: f.OrderByIngested,
+	}
+}
+
+func newFeedFollowResponses(follows []database.FeedFollow) []feedFollowResponse {
+	responses := make([]feedFollowResponse, 0, len(follows))
+	for _, f := range follows {
+		responses = append(responses, newFeedFollowResponse(f))
+	}
+	return responses
+}
+
+type postResponse struct {
+	ID          uuid.UUID          `json:"id"`
+	PublicID    string             `json:"public_id"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	Title       string             `json:"title"`
+	Url         string             `json:"url"`
+	Description *string            `json:"description"`
+	PublishedAt *time.Time         `json:"published_at"`
+	FeedID      uuid.UUID          `json:"feed_id"`
+	Sensitive   bool               `json:"sensitive"`
+	Enclosure   *enclosureResponse `json:"enclosure"`
+	Author      string             `json:"author"`
+}
+
+type enclosureResponse struct {
+	Url    string `json:"url"`
+	Type   string `json:"type"`
+	Length int64  `json:"length"`
+}
+
+// newEnclosureResponse is nil for posts without an enclosure.
+func newEnclosureResponse(url, mimeType string, length int64) *enclosureResponse {
+	if url == "" {
+		return nil
+	}
+	return &enclosureResponse{Url: url, Type: mimeType, Length: length}
+}
+
+func newPostResponse(p database.Post) postResponse {
+	return postResponse{
+		ID:          p.ID,
+		PublicID:    p.PublicID,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+		Title:       p.Title,
+		Url:         p.Url,
+		Description: nullStringPtr(p.Description),
+		PublishedAt: nullTimePtr(p.PublishedAt),
+		FeedID:      p.FeedID,
+		Sensitive:   p.Sensitive,
+		Enclosure:   newEnclosureResponse(p.EnclosureUrl, p.EnclosureType, p.EnclosureLength),
+		Author:      p.Author,
+	}
+}
+
+type postRevisionResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	PostID    uuid.UUID  `json:"post_id"`
+	EditorID  *uuid.UUID `json:"editor_id"`
+	Title     string     `json:"title"`
+	Url       string     `json:"url"`
+}
+
+func newPostRevisionResponses(revisions []database.PostRevision) []postRevisionResponse {
+	responses := make([]postRevisionResponse, 0, len(revisions))
+	for _, r := range revisions {
+		responses = append(responses, postRevisionResponse{
+			ID:        r.ID,
+			CreatedAt: r.CreatedAt,
+			PostID:    r.PostID,
+			EditorID:  nullUUIDPtr(r.EditorID),
+			Title:     r.Title,
+			Url:       r.Url,
+		})
+	}
+	return responses
+}
+
+type postSnoozeResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UserID     uuid.UUID  `json:"user_id"`
+	PostID     uuid.UUID  `json:"post_id"`
+	WakeAt     time.Time  `json:"wake_at"`
+	Notify     bool       `json:"notify"`
+	NotifiedAt *time.Time `json:"notified_at"`
+}
+
+func newPostSnoozeResponse(s database.PostSnooze) postSnoozeResponse {
+	return postSnoozeResponse{
+		ID:         s.ID,
+		CreatedAt:  s.CreatedAt,
+		UserID:     s.UserID,
+		PostID:     s.PostID,
+		WakeAt:     s.WakeAt,
+		Notify:     s.Notify,
+		NotifiedAt: nullTimePtr(s.NotifiedAt),
+	}
+}
+
+type postEmailResponse struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UserID    uuid.UUID `json:"user_id"`
+	PostID    uuid.UUID `json:"post_id"`
+	Recipient string    `json:"recipient"`
+}
+
+func newPostEmailResponse(e database.PostEmail) postEmailResponse {
+	return postEmailResponse{
+		ID:        e.ID,
+		CreatedAt: e.CreatedAt,
+		UserID:    e.UserID,
+		PostID:    e.PostID,
+		Recipient: e.Recipient,
+	}
+}
+
+type queueItemResponse struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UserID    uuid.UUID `json:"user_id"`
+	PostID    uuid.UUID `json:"post_id"`
+	Position  int32     `json:"position"`
+}
+
+func newQueueItemResponse(i database.ReadingQueueItem) queueItemResponse {
+	return queueItemResponse{
+		ID:        i.ID,
+		CreatedAt: i.CreatedAt,
+		UserID:    i.UserID,
+		PostID:    i.PostID,
+		Position:  i.Position,
+	}
+}
+
+type auditLogResponse struct {
+	ID         uuid.UUID       `json:"id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	ActorID    *uuid.UUID      `json:"actor_id"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   uuid.UUID       `json:"target_id"`
+	Details    json.RawMessage `json:"details"`
+}
+
+func newAuditLogResponses(entries []database.AuditLog) []auditLogResponse {
+	responses := make([]auditLogResponse, 0, len(entries))
+	for _, e := range entries {
+		responses = append(responses, auditLogResponse{
+			ID:         e.ID,
+			CreatedAt:  e.CreatedAt,
+			ActorID:    nullUUIDPtr(e.ActorID),
+			Action:     e.Action,
+			TargetType: e.TargetType,
+			TargetID:   e.TargetID,
+			Details:    e.Details,
+		})
+	}
+	return responses
+}
+
+type domainRuleResponse struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Domain    string    `json:"domain"`
+	Kind      string    `json:"kind"`
+}
+
+func newDomainRuleResponse(r database.DomainRule) domainRuleResponse {
+	return domainRuleResponse{
+		ID:        r.ID,
+		CreatedAt: r.CreatedAt,
+		Domain:    r.Domain,
+		Kind:      r.Kind,
+	}
+}
+
+func newDomainRuleResponses(rules []database.DomainRule) []domainRuleResponse {
+	responses := make([]domainRuleResponse, 0, len(rules))
+	for _, r := range rules {
+		responses = append(responses, newDomainRuleResponse(r))
+	}
+	return responses
+}
+
+type feedReportResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FeedID     uuid.UUID  `json:"feed_id"`
+	ReporterID uuid.UUID  `json:"reporter_id"`
+	Reason     string     `json:"reason"`
+	Comment    string     `json:"comment"`
+	Status     string     `json:"status"`
+	ResolvedBy *uuid.UUID `json:"resolved_by"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+}
+
+func newFeedReportResponse(r database.FeedReport) feedReportResponse {
+	return feedReportResponse{
+		ID:         r.ID,
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+		FeedID:     r.FeedID,
+		ReporterID: r.ReporterID,
+		Reason:     r.Reason,
+		Comment:    r.Comment,
+		Status:     r.Status,
+		ResolvedBy: nullUUIDPtr(r.ResolvedBy),
+		ResolvedAt: nullTimePtr(r.ResolvedAt),
+	}
+}
+
+func newFeedReportResponses(reports []database.FeedReport) []feedReportResponse {
+	responses := make([]feedReportResponse, 0, len(reports))
+	for _, r := range reports {
+		responses = append(responses, newFeedReportResponse(r))
+	}
+	return responses
+}
+
+type notificationChannelResponse struct {
+	ID        uuid.UUID       `json:"id"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	UserID    uuid.UUID       `json:"user_id"`
+	Kind      string          `json:"kind"`
+	Config    json.RawMessage `json:"config"`
+}
+
+func newNotificationChannelResponse(c database.NotificationChannel) notificationChannelResponse {
+	return notificationChannelResponse{
+		ID:        c.ID,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+		UserID:    c.UserID,
+		Kind:      c.Kind,
+		Config:    c.Config,
+	}
+}
+
+func newNotificationChannelResponses(channels []database.NotificationChannel) []notificationChannelResponse {
+	responses := make([]notificationChannelResponse, 0, len(channels))
+	for _, c := range channels {
+		responses = append(responses, newNotificationChannelResponse(c))
+	}
+	return responses
+}
+
+type pushSubscriptionResponse struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    uuid.UUID `json:"user_id"`
+	Endpoint  string    `json:"endpoint"`
+	P256dh    string    `json:"p256dh"`
+	Auth      string    `json:"auth"`
+}
+
+func newPushSubscriptionResponse(s database.PushSubscription) pushSubscriptionResponse {
+	return pushSubscriptionResponse{
+		ID:        s.ID,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+		UserID:    s.UserID,
+		Endpoint:  s.Endpoint,
+		P256dh:    s.P256dh,
+		Auth:      s.Auth,
+	}
+}
+
+type starterPackResponse struct {
+	ID          uuid.UUID `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+}
+
+func newStarterPackResponse(p database.StarterPack) starterPackResponse {
+	return starterPackResponse{
+		ID:          p.ID,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+		Name:        p.Name,
+		Description: p.Description,
+	}
+}
+
+type starterPackFeedsResponse struct {
+	starterPackResponse
+	Feeds []feedResponse `json:"feeds"`
+}
+
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func nullUUIDPtr(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	return &id.UUID
+}
+
+func nullInt32Ptr(n sql.NullInt32) *int32 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int32
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// Run with -update to rewrite the golden files after an intended change to
+// the wire format.
+var update = flag.Bool("update", false, "rewrite golden files")
+
+var (
+	goldenTime  = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	goldenLater = time.Date(2024, 3, 2, 8, 30, 0, 0, time.UTC)
+	goldenID1   = uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	goldenID2   = uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	goldenID3   = uuid.MustParse("33333333-3333-3333-3333-333333333333")
+)
+
+func TestResponsesGolden(t *testing.T) {
+	feed := database.Feed{
+		ID:                   goldenID1,
+		PublicID:             "f_abc123",
+		CreatedAt:            goldenTime,
+		UpdatedAt:            goldenLater,
+		Name:                 "Example",
+		Url:                  "https://example.com/feed.xml",
+		Description:          "An example feed",
+		SiteUrl:              "https://example.com",
+		UserID:               goldenID2,
+		LastFetchedAt:        sql.NullTime{Time: goldenLater, Valid: true},
+		Kind:                 "rss",
+		Language:             "en",
+		Country:              "us",
+		FetchIntervalSeconds: sql.NullInt32{Int32: 3600, Valid: true},
+		FetchFailures:        2,
+		LastFetchStatus:      sql.NullInt32{Int32: 503, Valid: true},
+		LastFetchError:       "unexpected status 503",
+		FetchHeaders:         json.RawMessage(`{"X-Token":"secret","Accept":"application/rss+xml"}`),
+		Schedule:             "interval",
+		Credentials:          []byte("sealed"),
+	}
+	post := database.Post{
+		ID:              goldenID3,
+		PublicID:        "p_def456",
+		CreatedAt:       goldenTime,
+		UpdatedAt:       goldenLater,
+		Title:           "Episode 1",
+		Url:             "https://example.com/1",
+		Description:     sql.NullString{String: "The first one", Valid: true},
+		PublishedAt:     sql.NullTime{Time: goldenTime, Valid: true},
+		FeedID:          goldenID1,
+		EnclosureUrl:    "https://example.com/1.mp3",
+		EnclosureType:   "audio/mpeg",
+		EnclosureLength: 12345,
+		Author:          "Jane",
+	}
+	bare := post
+	bare.Description = sql.NullString{}
+	bare.PublishedAt = sql.NullTime{}
+	bare.EnclosureUrl = ""
+
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{"user", newUserResponse(database.User{
+			ID:        goldenID1,
+			CreatedAt: goldenTime,
+			UpdatedAt: goldenLater,
+			Name:      "jane",
+			ApiKey:    "abc123",
+			Email:     sql.NullString{String: "jane@example.com", Valid: true},
+		})},
+		{"feed", newFeedResponse(feed)},
+		{"feed_follow", newFeedFollowResponse(database.FeedFollow{
+			ID:              goldenID1,
+			CreatedAt:       goldenTime,
+			UpdatedAt:       goldenLater,
+			UserID:          goldenID2,
+			FeedID:          goldenID3,
+			Priority:        "high",
+			OrderByIngested: true,
+		})},
+		{"post", newPostResponse(post)},
+		{"post_bare", newPostResponse(bare)},
+		{"post_revisions", newPostRevisionResponses([]database.PostRevision{{
+			ID:        goldenID1,
+			CreatedAt: goldenTime,
+			PostID:    goldenID3,
+			EditorID:  uuid.NullUUID{UUID: goldenID2, Valid: true},
+			Title:     "Episode one",
+			Url:       "https://example.com/1",
+		}})},
+		{"post_snooze", newPostSnoozeResponse(database.PostSnooze{
+			ID:        goldenID1,
+			CreatedAt: goldenTime,
+			UserID:    goldenID2,
+			PostID:    goldenID3,
+			WakeAt:    goldenLater,
+			Notify:    true,
+		})},
+		{"post_email", newPostEmailResponse(database.PostEmail{
+			ID:        goldenID1,
+			CreatedAt: goldenTime,
+			UserID:    goldenID2,
+			PostID:    goldenID3,
+			Recipient: "friend@example.com",
+		})},
+		{"queue_item", newQueueItemResponse(database.ReadingQueueItem{
+			ID:        goldenID1,
+			CreatedAt: goldenTime,
+			UserID:    goldenID2,
+			PostID:    goldenID3,
+			Position:  4,
+		})},
+		{"audit_log", newAuditLogResponses([]database.AuditLog{{
+			ID:         goldenID1,
+			CreatedAt:  goldenTime,
+			ActorID:    uuid.NullUUID{UUID: goldenID2, Valid: true},
+			Action:     "feed.disable",
+			TargetType: "feed",
+			TargetID:   goldenID3,
+			Details:    json.RawMessage(`{"reason":"spam"}`),
+		}})},
+		{"domain_rule", newDomainRuleResponse(database.DomainRule{
+			ID:        goldenID1,
+			CreatedAt: goldenTime,
+			Domain:    "example.com",
+			Kind:      "block",
+		})},
+		{"feed_report", newFeedReportResponse(database.FeedReport{
+			ID:         goldenID1,
+			CreatedAt:  goldenTime,
+			UpdatedAt:  goldenLater,
+			FeedID:     goldenID3,
+			ReporterID: goldenID2,
+			Reason:     "spam",
+			Comment:    "All ads",
+			Status:     "open",
+		})},
+		{"notification_channel", newNotificationChannelResponse(database.NotificationChannel{
+			ID:        goldenID1,
+			CreatedAt: goldenTime,
+			UpdatedAt: goldenLater,
+			UserID:    goldenID2,
+			Kind:      "webhook",
+			Config:    json.RawMessage(`{"url":"https://hooks.example.com/x"}`),
+		})},
+		{"push_subscription", newPushSubscriptionResponse(database.PushSubscription{
+			ID:        goldenID1,
+			CreatedAt: goldenTime,
+			UpdatedAt: goldenLater,
+			UserID:    goldenID2,
+			Endpoint:  "https://push.example.com/abc",
+			P256dh:    "key",
+			Auth:      "auth",
+		})},
+		{"starter_pack_feeds", starterPackFeedsResponse{
+			starterPackResponse: newStarterPackResponse(database.StarterPack{
+				ID:          goldenID2,
+				CreatedAt:   goldenTime,
+				UpdatedAt:   goldenLater,
+				Name:        "Podcasts",
+				Description: "A few to start with",
+			}),
+			Feeds: newFeedResponses([]database.Feed{feed}),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.MarshalIndent(tt.v, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+			path := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s does not match the golden file\ngot:\n%s\nwant:\n%s", tt.name, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// s3Bucket uploads objects to an S3 bucket, or to an S3-compatible store
+// when Endpoint is set, signing requests with AWS Signature Version 4.
+type s3Bucket struct {
+	Bucket       string
+	Region       string
+	Endpoint     string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+}
+
+var s3Client = &http.Client{Timeout: 5 * time.Minute}
+
+// objectURL returns the URL of key: virtual-hosted on AWS, path-style on
+// a custom endpoint, since most S3-compatible stores expect that.
+func (b s3Bucket) objectURL(key string) (*url.URL, error) {
+	if b.Endpoint == "" {
+		return url.Parse(fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", b.Bucket, b.Region, s3EscapePath(key)))
+	}
+	return url.Parse(strings.TrimRight(b.Endpoint, "/") + "/" + b.Bucket + "/" + s3EscapePath(key))
+}
+
+func (b s3Bucket) put(ctx context.Context, key, contentType string, body []byte) error {
+	u, err := b.objectURL(key)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	b.sign(req, body, time.Now().UTC())
+	res, err := s3Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1<<10))
+		return fmt.Errorf("uploading %s: %s: %s", key, res.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// sign adds the Signature Version 4 headers to req. Only the host and the
+// x-amz-* headers are signed.
+func (b s3Bucket) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	headers := [][2]string{
+		{"host", req.URL.Host},
+		{"x-amz-content-sha256", payloadHash},
+		{"x-amz-date", amzDate},
+	}
+	if b.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", b.SessionToken)
+		headers = append(headers, [2]string{"x-amz-security-token", b.SessionToken})
+	}
+	canonicalHeaders, signedHeaders := "", []string{}
+	for _, h := range headers {
+		canonicalHeaders += h[0] + ":" + strings.TrimSpace(h[1]) + "\n"
+		signedHeaders = append(signedHeaders, h[0])
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+	scope := day + "/" + b.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+	key := []byte("AWS4" + b.SecretKey)
+	for _, part := range []string{day, b.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.AccessKey, scope, strings.Join(signedHeaders, ";"), hex.EncodeToString(hmacSHA256(key, stringToSign))))
+}
+
+// s3EscapePath percent-encodes an object key as Signature Version 4
+// expects: everything but unreserved characters and "/".
+func s3EscapePath(key string) string {
+	var sb strings.Builder
+	for _, c := range []byte(key) {
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || strings.IndexByte("-_.~/", c) >= 0 {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
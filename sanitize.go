@@ -0,0 +1,399 @@
+package main
+
+import (
+	"html"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// allowedTags are the elements kept in sanitized descriptions, each with
+// the attributes it may keep. Anything else is dropped, but its text stays.
+var allowedTags = map[string][]string{
+	"a":          {"href", "title"},
+	"abbr":       {"title"},
+	"b":          nil,
+	"blockquote": {"cite"},
+	"br":         nil,
+	"caption":    nil,
+	"code":       nil,
+	"dd":         nil,
+	"del":        nil,
+	"div":        nil,
+	"dl":         nil,
+	"dt":         nil,
+	"em":         nil,
+	"figcaption": nil,
+	"figure":     nil,
+	"h1":         nil,
+	"h2":         nil,
+	"h3":         nil,
+	"h4":         nil,
+	"h5":         nil,
+	"h6":         nil,
+	"hr":         nil,
+	"i":          nil,
+	"img":        {"src", "alt", "title", "width", "height"},
+	"ins":        nil,
+	"kbd":        nil,
+	"li":         nil,
+	"mark":       nil,
+	"ol":         nil,
+	"p":          nil,
+	"pre":        nil,
+	"q":          {"cite"},
+	"s":          nil,
+	"small":      nil,
+	"span":       nil,
+	"strong":     nil,
+	"sub":        nil,
+	"sup":        nil,
+	"table":      nil,
+	"tbody":      nil,
+	"td":         {"colspan", "rowspan"},
+	"tfoot":      nil,
+	"th":         {"colspan", "rowspan"},
+	"thead":      nil,
+	"tr":         nil,
+	"u":          nil,
+	"ul":         nil,
+}
+
+// droppedTags are removed together with everything inside them.
+var droppedTags = map[string]bool{
+	"embed":    true,
+	"form":     true,
+	"head":     true,
+	"iframe":   true,
+	"math":     true,
+	"noscript": true,
+	"object":   true,
+	"script":   true,
+	"select":   true,
+	"style":    true,
+	"svg":      true,
+	"template": true,
+	"textarea": true,
+	"title":    true,
+}
+
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true}
+
+var urlAttrs = map[string]bool{"href": true, "src": true, "cite": true}
+
+type htmlAttr struct {
+	name, value string
+}
+
+// sanitizeOptions are the parts of sanitizing that depend on the
+// instance's content policy.
+type sanitizeOptions struct {
+	// IframeHosts are the hosts, such as video players, whose https
+	// iframes are kept, sandboxed. Every other iframe is dropped.
+	IframeHosts []string
+	// ImageURL, if set, rewrites each kept image's src, say to send it
+	// through the image proxy. Returning "" drops the image.
+	ImageURL func(src string) string
+}
+
+// sanitizeHTML reduces feed-supplied HTML to an allowlist of tags and
+// attributes, so descriptions are safe to render as they are served.
+// Scripts, styles, embeds, comments, event handlers, inline styles, links
+// to anything but http(s) or mailto, iframes from hosts opts does not
+// allow, and 1x1 tracking images are removed, and unbalanced tags are
+// closed or dropped.
+func sanitizeHTML(s string, opts sanitizeOptions) string {
+	out := strings.Builder{}
+	open := []string{}
+	for len(s) > 0 {
+		lt := strings.IndexByte(s, '<')
+		if lt < 0 {
+			out.WriteString(s)
+			break
+		}
+		out.WriteString(s[:lt])
+		s = s[lt:]
+
+		switch {
+		case strings.HasPrefix(s, "<!--"):
+			end := strings.Index(s[4:], "-->")
+			if end < 0 {
+				return closeTags(&out, open)
+			}
+			s = s[4+end+3:]
+			continue
+		case strings.HasPrefix(s, "<!"), strings.HasPrefix(s, "<?"):
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				return closeTags(&out, open)
+			}
+			s = s[end+1:]
+			continue
+		}
+
+		name, attrs, closing, rest, ok := parseTag(s)
+		if !ok {
+			out.WriteString("&lt;")
+			s = s[1:]
+			continue
+		}
+		s = rest
+		if name == "" {
+			// A tag left unterminated at the end of the input.
+			break
+		}
+
+		if droppedTags[name] {
+			if !closing {
+				s = skipPast(s, name)
+				if name == "iframe" {
+					out.WriteString(allowedIframe(attrs, opts.IframeHosts))
+				}
+			}
+			continue
+		}
+		allowed, isAllowed := allowedTags[name]
+		if !isAllowed {
+			continue
+		}
+		if closing {
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] == name {
+					for j := len(open) - 1; j >= i; j-- {
+						out.WriteString("</" + open[j] + ">")
+					}
+					open = open[:i]
+					break
+				}
+			}
+			continue
+		}
+
+		kept := keepAttrs(name, attrs, allowed)
+		if name == "img" && (kept == nil || isTrackingPixel(kept)) {
+			continue
+		}
+		if name == "img" && opts.ImageURL != nil {
+			kept = rewriteImageSrc(kept, opts.ImageURL)
+			if kept == nil {
+				continue
+			}
+		}
+		out.WriteString("<" + name)
+		for _, a := range kept {
+			out.WriteString(" " + a.name + `="` + html.EscapeString(a.value) + `"`)
+		}
+		if name == "a" {
+			out.WriteString(` rel="nofollow noopener noreferrer"`)
+		}
+		out.WriteString(">")
+		if !voidTags[name] {
+			open = append(open, name)
+		}
+	}
+	return closeTags(&out, open)
+}
+
+func closeTags(out *strings.Builder, open []string) string {
+	for i := len(open) - 1; i >= 0; i-- {
+		out.WriteString("</" + open[i] + ">")
+	}
+	return out.String()
+}
+
+// parseTag reads the tag at the start of s, which begins with '<'. It is
+// not ok if s does not start a tag at all, like "< 3". A tag with no
+// closing '>' consumes the rest of s and returns an empty name.
+func parseTag(s string) (name string, attrs []htmlAttr, closing bool, rest string, ok bool) {
+	i := 1
+	if i < len(s) && s[i] == '/' {
+		closing = true
+		i++
+	}
+	start := i
+	for i < len(s) && isTagNameByte(s[i], i == start) {
+		i++
+	}
+	if i == start {
+		return "", nil, false, s, false
+	}
+	name = strings.ToLower(s[start:i])
+	for {
+		for i < len(s) && (isHTMLSpace(s[i]) || s[i] == '/') {
+			i++
+		}
+		if i >= len(s) {
+			return "", nil, false, "", true
+		}
+		if s[i] == '>' {
+			return name, attrs, closing, s[i+1:], true
+		}
+		start := i
+		for i < len(s) && !isHTMLSpace(s[i]) && s[i] != '=' && s[i] != '>' && s[i] != '/' {
+			i++
+		}
+		attr := htmlAttr{name: strings.ToLower(s[start:i])}
+		for i < len(s) && isHTMLSpace(s[i]) {
+			i++
+		}
+		if i < len(s) && s[i] == '=' {
+			i++
+			for i < len(s) && isHTMLSpace(s[i]) {
+				i++
+			}
+			if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+				end := strings.IndexByte(s[i+1:], s[i])
+				if end < 0 {
+					return "", nil, false, "", true
+				}
+				attr.value = s[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				start := i
+				for i < len(s) && !isHTMLSpace(s[i]) && s[i] != '>' {
+					i++
+				}
+				attr.value = s[start:i]
+			}
+			attr.value = html.UnescapeString(attr.value)
+		}
+		attrs = append(attrs, attr)
+	}
+}
+
+// skipPast drops everything up to and including the close of element name.
+func skipPast(s, name string) string {
+	lower := strings.ToLower(s)
+	for from := 0; ; {
+		i := strings.Index(lower[from:], "</"+name)
+		if i < 0 {
+			return ""
+		}
+		from += i + 2 + len(name)
+		if from < len(s) && isTagNameByte(s[from], false) {
+			continue
+		}
+		end := strings.IndexByte(s[from:], '>')
+		if end < 0 {
+			return ""
+		}
+		return s[from+end+1:]
+	}
+}
+
+// keepAttrs filters attrs down to the allowed ones with safe values. It
+// returns nil for an img whose src did not survive.
+func keepAttrs(tag string, attrs []htmlAttr, allowed []string) []htmlAttr {
+	kept := []htmlAttr{}
+	for _, a := range attrs {
+		if !slices.Contains(allowed, a.name) {
+			continue
+		}
+		if urlAttrs[a.name] {
+			safe, ok := safeURL(a.value)
+			if !ok {
+				continue
+			}
+			a.value = safe
+		}
+		kept = append(kept, a)
+	}
+	if tag == "img" {
+		for _, a := range kept {
+			if a.name == "src" {
+				return kept
+			}
+		}
+		return nil
+	}
+	return kept
+}
+
+// rewriteImageSrc replaces an img's src with imageURL's rewrite of it. It
+// returns nil if the rewrite dropped the image.
+func rewriteImageSrc(attrs []htmlAttr, imageURL func(string) string) []htmlAttr {
+	for i, a := range attrs {
+		if a.name == "src" {
+			attrs[i].value = imageURL(a.value)
+			if attrs[i].value == "" {
+				return nil
+			}
+		}
+	}
+	return attrs
+}
+
+var iframeAttrs = []string{"src", "title", "width", "height"}
+
+// allowedIframe returns a sandboxed, empty iframe with the given attributes
+// if its src is an https URL on one of hosts, and "" otherwise. The
+// sandbox lets a player run its scripts but not navigate the page
+// embedding it.
+func allowedIframe(attrs []htmlAttr, hosts []string) string {
+	kept := []htmlAttr{}
+	allowed := false
+	for _, a := range attrs {
+		if !slices.Contains(iframeAttrs, a.name) {
+			continue
+		}
+		if a.name == "src" {
+			u, err := url.Parse(strings.TrimSpace(a.value))
+			if err != nil || u.Scheme != "https" || !slices.Contains(hosts, strings.ToLower(u.Hostname())) {
+				return ""
+			}
+			a.value = u.String()
+			allowed = true
+		}
+		kept = append(kept, a)
+	}
+	if !allowed {
+		return ""
+	}
+	out := strings.Builder{}
+	out.WriteString("<iframe")
+	for _, a := range kept {
+		out.WriteString(" " + a.name + `="` + html.EscapeString(a.value) + `"`)
+	}
+	out.WriteString(` sandbox="allow-scripts allow-same-origin allow-popups allow-presentation" allowfullscreen referrerpolicy="strict-origin" loading="lazy"></iframe>`)
+	return out.String()
+}
+
+// safeURL allows absolute http, https and mailto URLs, and relative ones.
+func safeURL(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	for _, r := range raw {
+		if r < 0x20 || r == 0x7f {
+			return "", false
+		}
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return raw, true
+	}
+	return "", false
+}
+
+func isTrackingPixel(attrs []htmlAttr) bool {
+	small := 0
+	for _, a := range attrs {
+		if (a.name == "width" || a.name == "height") && (a.value == "0" || a.value == "1" || a.value == "1px") {
+			small++
+		}
+	}
+	return small == 2
+}
+
+func isTagNameByte(c byte, first bool) bool {
+	if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' {
+		return true
+	}
+	return !first && '0' <= c && c <= '9'
+}
+
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const (
+	defaultFetchInterval = 15 * time.Minute
+	// pushFallbackInterval is how often a push-preferred feed is polled
+	// anyway, in case its updates stop arriving.
+	pushFallbackInterval = 6 * time.Hour
+	defaultSchedule      = "adaptive"
+	// defaultUnfollowedGrace is how long a feed nobody follows is still
+	// fetched, in case someone follows it again.
+	defaultUnfollowedGrace = 7 * 24 * time.Hour
+)
+
+// A scheduler decides when a feed is next due to be fetched. Each feed
+// uses the scheduler named by its schedule column, or the instance's
+// default when that is empty.
+type scheduler interface {
+	// nextFetch returns when f is next due, given the interval its
+	// followers' priorities ask for (0 if it has no followers). It returns
+	// false if f is never fetched on a schedule.
+	nextFetch(f database.Feed, followInterval time.Duration) (time.Time, bool)
+}
+
+var schedulers = map[string]scheduler{
+	"fixed":    fixedScheduler{},
+	"adaptive": adaptiveScheduler{},
+	"push":     pushScheduler{},
+	"manual":   manualScheduler{},
+}
+
+// fixedScheduler polls at the interval the feed's owner pinned, or every
+// 15 minutes, whatever its followers ask for.
+type fixedScheduler struct{}
+
+func (fixedScheduler) nextFetch(f database.Feed, _ time.Duration) (time.Time, bool) {
+	interval := defaultFetchInterval
+	if f.FetchIntervalSeconds.Valid {
+		interval = time.Duration(f.FetchIntervalSeconds.Int32) * time.Second
+	}
+	return f.LastFetchedAt.Time.Add(interval), true
+}
+
+// adaptiveScheduler polls at the pinned interval, or else as often as the
+// feed's highest priority follower asks, and more often while the feed is
+// suspected of dropping items between fetches. Unless the interval is
+// pinned, it polls rarely on weekdays the feed has learned to be quiet on.
+type adaptiveScheduler struct{}
+
+func (adaptiveScheduler) nextFetch(f database.Feed, followInterval time.Duration) (time.Time, bool) {
+	interval := defaultFetchInterval
+	if followInterval > 0 {
+		interval = followInterval
+	}
+	if f.FetchIntervalSeconds.Valid {
+		interval = time.Duration(f.FetchIntervalSeconds.Int32) * time.Second
+	}
+	if gap := time.Duration(f.GapIntervalSeconds) * time.Second; gap > 0 && gap < interval {
+		interval = gap
+	}
+	next := f.LastFetchedAt.Time.Add(interval)
+	if !f.FetchIntervalSeconds.Valid && interval < quietDayInterval && isQuietWeekday(f.QuietWeekdays, next.UTC().Weekday()) {
+		next = f.LastFetchedAt.Time.Add(quietDayInterval)
+	}
+	return next, true
+}
+
+// pushScheduler is for feeds whose updates are expected to arrive some
+// other way, such as a publisher triggering a fetch; it polls only as a
+// rare fallback.
+type pushScheduler struct{}
+
+func (pushScheduler) nextFetch(f database.Feed, _ time.Duration) (time.Time, bool) {
+	return f.LastFetchedAt.Time.Add(pushFallbackInterval), true
+}
+
+// manualScheduler never fetches on its own; the feed is only fetched when
+// someone asks, with "rssagg fetch" or a refresh.
+type manualScheduler struct{}
+
+func (manualScheduler) nextFetch(database.Feed, time.Duration) (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func validSchedule(name string) bool {
+	_, ok := schedulers[name]
+	return ok
+}
+
+// newDefaultScheduleFromEnv reads FETCH_SCHEDULE, the scheduler for feeds
+// that don't name one.
+func newDefaultScheduleFromEnv() (string, error) {
+	v := os.Getenv("FETCH_SCHEDULE")
+	if v == "" {
+		return defaultSchedule, nil
+	}
+	if !validSchedule(v) {
+		return "", fmt.Errorf("Invalid FETCH_SCHEDULE: must be fixed, adaptive, push or manual")
+	}
+	return v, nil
+}
+
+// newUnfollowedGraceFromEnv reads FETCH_UNFOLLOWED_GRACE, how long a feed
+// is still fetched after it loses its last follower. 0 keeps fetching such
+// feeds for good.
+func newUnfollowedGraceFromEnv() (time.Duration, error) {
+	v := os.Getenv("FETCH_UNFOLLOWED_GRACE")
+	if v == "" {
+		return defaultUnfollowedGrace, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("Invalid FETCH_UNFOLLOWED_GRACE")
+	}
+	return d, nil
+}
+
+// followFetchIntervals returns, by feed, the interval its highest
+// priority follower asks for.
+func followFetchIntervals(ctx context.Context, ac apiConfig) (map[uuid.UUID]time.Duration, error) {
+	rows, err := ac.DB.ListFollowFetchIntervals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	intervals := make(map[uuid.UUID]time.Duration, len(rows))
+	for _, row := range rows {
+		intervals[row.FeedID] = time.Duration(row.IntervalSeconds) * time.Second
+	}
+	return intervals, nil
+}
+
+func (ac apiConfig) scheduler(f database.Feed) scheduler {
+	s, ok := schedulers[f.Schedule]
+	if !ok {
+		s = schedulers[ac.DefaultSchedule]
+	}
+	return s
+}
+
+// nextFetchAt returns when the fetch worker will next pick f up, as of
+// now, or false if it will not until someone intervenes: the feed is
+// disabled, paused, manually scheduled, unfollowed for too long or not
+// fetched at all.
+func (ac apiConfig) nextFetchAt(f database.Feed, followInterval time.Duration, now time.Time) (time.Time, bool) {
+	if f.Kind != "remote" || f.DisabledAt.Valid || f.PausedAt.Valid || ac.unfollowedTooLong(f, now) {
+		return time.Time{}, false
+	}
+	next, scheduled := ac.scheduler(f).nextFetch(f, followInterval)
+	if !scheduled {
+		return time.Time{}, false
+	}
+	if f.RetryAt.Valid && f.RetryAt.Time.After(next) {
+		next = f.RetryAt.Time
+	}
+	if next.Before(now) {
+		next = now
+	}
+	return next, true
+}
+
+// dueFeeds returns up to limit feeds that are due at now, those with the
+// most active followers first and, among those with as many, the least
+// recently fetched. Feeds that are disabled, paused or backing off are
+// never due, and nor are feeds that have had no followers for longer than
+// the grace period.
+func dueFeeds(ctx context.Context, ac apiConfig, now time.Time, limit int) ([]database.Feed, error) {
+	feeds, err := ac.DB.ListSchedulableFeeds(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	followIntervals, err := followFetchIntervals(ctx, ac)
+	if err != nil {
+		return nil, err
+	}
+	followers, err := activeFollowerCounts(ctx, ac)
+	if err != nil {
+		return nil, err
+	}
+	trackUnfollowedFeeds(ctx, ac, feeds, followers, now)
+	due := []database.Feed{}
+	for _, f := range feeds {
+		if ac.unfollowedTooLong(f, now) {
+			continue
+		}
+		next, scheduled := ac.scheduler(f).nextFetch(f, followIntervals[f.ID])
+		if !scheduled || next.After(now) {
+			continue
+		}
+		due = append(due, f)
+	}
+	// Feeds come least recently fetched first, which the stable sort keeps.
+	sort.SliceStable(due, func(i, j int) bool {
+		return followers[due[i].ID] > followers[due[j].ID]
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+// activeFollowerCounts returns, by feed, how many users who are not banned
+// follow it.
+func activeFollowerCounts(ctx context.Context, ac apiConfig) (map[uuid.UUID]int64, error) {
+	rows, err := ac.DB.ListActiveFollowerCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[uuid.UUID]int64, len(rows))
+	for _, row := range rows {
+		counts[row.FeedID] = row.Followers
+	}
+	return counts, nil
+}
+
+// trackUnfollowedFeeds records when feeds lost their last follower, and
+// forgets it for feeds that have been followed again, which are then due
+// straight away.
+func trackUnfollowedFeeds(ctx context.Context, ac apiConfig, feeds []database.Feed, followers map[uuid.UUID]int64, now time.Time) {
+	for i, f := range feeds {
+		followed := followers[f.ID] > 0
+		if followed != f.UnfollowedAt.Valid {
+			continue
+		}
+		unfollowedAt := sql.NullTime{Time: now, Valid: !followed}
+		err := ac.DB.SetFeedUnfollowedAt(ctx, database.SetFeedUnfollowedAtParams{ID: f.ID, UnfollowedAt: unfollowedAt})
+		if err != nil {
+			logError("fetch", "Could not record followers of %s feed: %v", f.Name, err)
+			continue
+		}
+		feeds[i].UnfollowedAt = unfollowedAt
+	}
+}
+
+// unfollowedTooLong reports whether f has had no followers for longer than
+// the grace period, and so is no longer fetched.
+func (ac apiConfig) unfollowedTooLong(f database.Feed, now time.Time) bool {
+	return ac.UnfollowedGrace > 0 && f.UnfollowedAt.Valid && now.Sub(f.UnfollowedAt.Time) >= ac.UnfollowedGrace
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/clock"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+	"github.com/pmwals09/rss-aggregator/internal/memstore"
+)
+
+// clockTestStart is where the fake clock starts. It is a Friday, well in
+// the past, so nothing can pass by accident on the wall clock.
+var clockTestStart = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// newClockTestConfig returns an apiConfig on a fresh memory store whose
+// clock only moves when the test advances it.
+func newClockTestConfig() (apiConfig, *clock.Fake) {
+	fake := clock.NewFake(clockTestStart)
+	return apiConfig{
+		DB:              memstore.New(),
+		Clock:           fake,
+		DefaultSchedule: defaultSchedule,
+		UnfollowedGrace: defaultUnfollowedGrace,
+	}, fake
+}
+
+// seedClockTestFeed creates a user and a feed last fetched at the clock's
+// current time, and has the user follow it if follow is set.
+func seedClockTestFeed(t *testing.T, ac apiConfig, follow bool) (database.User, database.Feed) {
+	t.Helper()
+	ctx := context.Background()
+	now := ac.Clock.Now()
+	user, err := ac.DB.CreateUser(ctx, database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Name:      "clock-" + uuid.NewString(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed, err := ac.DB.CreateFeed(ctx, database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Name:      "Clock feed",
+		Url:       "https://example.com/" + uuid.NewString() + "/rss",
+		UserID:    user.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if follow {
+		_, err = ac.DB.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			UserID:    user.ID,
+			FeedID:    feed.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = ac.DB.MarkFeedFetched(ctx, database.MarkFeedFetchedParams{
+		LastFetchedAt: sql.NullTime{Time: now, Valid: true},
+		ID:            feed.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed, err = ac.DB.GetFeed(ctx, feed.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user, feed
+}
+
+func countDueFeeds(t *testing.T, ac apiConfig) int {
+	t.Helper()
+	due, err := dueFeeds(context.Background(), ac, ac.Clock.Now(), fetchBatchSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(due)
+}
+
+func TestDueFeedsFollowTheClock(t *testing.T) {
+	ac, fake := newClockTestConfig()
+	seedClockTestFeed(t, ac, true)
+
+	if n := countDueFeeds(t, ac); n != 0 {
+		t.Fatalf("%d feeds due straight after a fetch, want 0", n)
+	}
+	fake.Advance(defaultFetchInterval - time.Minute)
+	if n := countDueFeeds(t, ac); n != 0 {
+		t.Fatalf("%d feeds due a minute early, want 0", n)
+	}
+	fake.Advance(time.Minute)
+	if n := countDueFeeds(t, ac); n != 1 {
+		t.Fatalf("%d feeds due after the interval, want 1", n)
+	}
+}
+
+func TestDueFeedsDropUnfollowedFeedsAfterGrace(t *testing.T) {
+	ac, fake := newClockTestConfig()
+	seedClockTestFeed(t, ac, false)
+
+	// The first look records when the feed was found unfollowed.
+	if n := countDueFeeds(t, ac); n != 0 {
+		t.Fatalf("%d feeds due straight after a fetch, want 0", n)
+	}
+	fake.Advance(time.Hour)
+	if n := countDueFeeds(t, ac); n != 1 {
+		t.Fatalf("%d feeds due within the grace period, want 1", n)
+	}
+	fake.Advance(ac.UnfollowedGrace)
+	if n := countDueFeeds(t, ac); n != 0 {
+		t.Fatalf("%d feeds due after the grace period, want 0", n)
+	}
+}
+
+func TestNextFetchAt(t *testing.T) {
+	ac, fake := newClockTestConfig()
+	_, feed := seedClockTestFeed(t, ac, true)
+	fetched := fake.Now()
+
+	tests := []struct {
+		name           string
+		schedule       string
+		followInterval time.Duration
+		retryAt        time.Time
+		advance        time.Duration
+		want           time.Time
+		wantScheduled  bool
+	}{
+		{"fixed", "fixed", 5 * time.Minute, time.Time{}, 0, fetched.Add(defaultFetchInterval), true},
+		{"adaptive", "adaptive", 5 * time.Minute, time.Time{}, 0, fetched.Add(5 * time.Minute), true},
+		{"adaptive unfollowed", "adaptive", 0, time.Time{}, 0, fetched.Add(defaultFetchInterval), true},
+		{"push", "push", 5 * time.Minute, time.Time{}, 0, fetched.Add(pushFallbackInterval), true},
+		{"manual", "manual", 5 * time.Minute, time.Time{}, 0, time.Time{}, false},
+		{"backing off", "fixed", 0, fetched.Add(time.Hour), 0, fetched.Add(time.Hour), true},
+		{"overdue", "fixed", 0, time.Time{}, time.Hour, fetched.Add(time.Hour), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake.Set(fetched.Add(tt.advance))
+			f := feed
+			f.Schedule = tt.schedule
+			f.RetryAt = sql.NullTime{Time: tt.retryAt, Valid: !tt.retryAt.IsZero()}
+			got, scheduled := ac.nextFetchAt(f, tt.followInterval, fake.Now())
+			if scheduled != tt.wantScheduled || !got.Equal(tt.want) {
+				t.Errorf("nextFetchAt = %s, %t; want %s, %t", got, scheduled, tt.want, tt.wantScheduled)
+			}
+		})
+	}
+}
@@ -0,0 +1,549 @@
+package main
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const (
+	scimUserSchema     = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimListSchema     = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimErrorSchema    = "urn:ietf:params:scim:api:messages:2.0:Error"
+	scimConfigSchema   = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	maxSCIMPageSize    = 100
+	minSCIMTokenLength = 32
+	// scimIdentityBackend marks users provisioned over SCIM, so their first
+	// password login can claim them rather than create another user.
+	scimIdentityBackend = "scim"
+)
+
+// scimUserNameFilter is the one filter identity providers send to find a
+// user before creating them.
+var scimUserNameFilter = regexp.MustCompile(`(?i)^\s*userName\s+eq\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+// newSCIMTokenFromEnv reads SCIM_TOKEN, the bearer token the identity
+// provider authenticates with. SCIM is off when it is not set.
+func newSCIMTokenFromEnv() (string, error) {
+	token := os.Getenv("SCIM_TOKEN")
+	if token != "" && len(token) < minSCIMTokenLength {
+		return "", fmt.Errorf("Invalid SCIM_TOKEN: must be at least %d characters", minSCIMTokenLength)
+	}
+	return token, nil
+}
+
+func (ac *apiConfig) middlewareSCIM(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if ac.SCIMToken == "" {
+			respondWithSCIMError(w, http.StatusNotFound, "", "SCIM is not enabled")
+			return
+		}
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(ac.SCIMToken)) != 1 {
+			logWarn("auth", "Bad SCIM token on %s %s", r.Method, r.URL.Path)
+			respondWithSCIMError(w, http.StatusUnauthorized, "", "Unauthorized")
+			return
+		}
+		next(w, r)
+	}
+}
+
+type scimEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type scimMeta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location"`
+}
+
+// scimUser is a user as SCIM sees them. userName is the user's name,
+// their primary email their email, and active whether they are not
+// suspended. Other attributes are accepted and ignored.
+type scimUser struct {
+	Schemas  []string    `json:"schemas"`
+	ID       string      `json:"id,omitempty"`
+	UserName string      `json:"userName"`
+	Active   bool        `json:"active"`
+	Emails   []scimEmail `json:"emails,omitempty"`
+	Meta     *scimMeta   `json:"meta,omitempty"`
+}
+
+// UnmarshalJSON defaults active to true, as a user created without saying
+// is active.
+func (s *scimUser) UnmarshalJSON(data []byte) error {
+	type plain scimUser
+	p := plain{Active: true}
+	err := json.Unmarshal(data, &p)
+	*s = scimUser(p)
+	return err
+}
+
+func newSCIMUser(r *http.Request, u database.User) scimUser {
+	s := scimUser{
+		Schemas:  []string{scimUserSchema},
+		ID:       u.ID.String(),
+		UserName: u.Name,
+		Active:   !u.BannedAt.Valid,
+		Meta: &scimMeta{
+			ResourceType: "User",
+			Created:      u.CreatedAt,
+			LastModified: u.UpdatedAt,
+			Location:     requestOrigin(r) + "/scim/v2/Users/" + u.ID.String(),
+		},
+	}
+	if u.Email.Valid {
+		s.Emails = []scimEmail{{Value: u.Email.String, Type: "work", Primary: true}}
+	}
+	return s
+}
+
+// email returns the user's primary email, or their first if none is
+// marked primary.
+func (s scimUser) email() string {
+	for _, e := range s.Emails {
+		if e.Primary {
+			return e.Value
+		}
+	}
+	if len(s.Emails) > 0 {
+		return s.Emails[0].Value
+	}
+	return ""
+}
+
+func respondWithSCIM(w http.ResponseWriter, status int, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+// respondWithSCIMError responds with a SCIM error. scimType, if given,
+// is one of the error types SCIM defines, such as "uniqueness".
+func respondWithSCIMError(w http.ResponseWriter, status int, scimType string, detail string) {
+	type scimError struct {
+		Schemas  []string `json:"schemas"`
+		Status   string   `json:"status"`
+		ScimType string   `json:"scimType,omitempty"`
+		Detail   string   `json:"detail"`
+	}
+	respondWithSCIM(w, status, scimError{
+		Schemas:  []string{scimErrorSchema},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+func handleSCIMServiceProviderConfigGet(w http.ResponseWriter, r *http.Request) {
+	type supported struct {
+		Supported bool `json:"supported"`
+	}
+	respondWithSCIM(w, http.StatusOK, map[string]interface{}{
+		"schemas":        []string{scimConfigSchema},
+		"patch":          supported{true},
+		"bulk":           map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]interface{}{"supported": true, "maxResults": maxSCIMPageSize},
+		"changePassword": supported{false},
+		"sort":           supported{false},
+		"etag":           supported{false},
+		"authenticationSchemes": []map[string]string{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer token",
+			"description": "The token set as SCIM_TOKEN",
+		}},
+	})
+}
+
+// handleSCIMUsersGet lists users a page at a time. The only filter
+// supported is userName eq, which is what identity providers use to match
+// existing users.
+func handleSCIMUsersGet(w http.ResponseWriter, r *http.Request, ac apiConfig) {
+	startIndex, count := 1, maxSCIMPageSize
+	if v := r.URL.Query().Get("startIndex"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			respondWithSCIMError(w, http.StatusBadRequest, "invalidValue", "Invalid startIndex")
+			return
+		}
+		startIndex = max(n, 1)
+	}
+	if v := r.URL.Query().Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			respondWithSCIMError(w, http.StatusBadRequest, "invalidValue", "Invalid count")
+			return
+		}
+		count = min(max(n, 0), maxSCIMPageSize)
+	}
+
+	var users []database.User
+	var total int64
+	if filter := r.URL.Query().Get("filter"); filter != "" {
+		m := scimUserNameFilter.FindStringSubmatch(filter)
+		if m == nil {
+			respondWithSCIMError(w, http.StatusBadRequest, "invalidFilter", "Only userName eq filters are supported")
+			return
+		}
+		name, _ := strconv.Unquote(`"` + m[1] + `"`)
+		user, err := ac.DB.GetUserByName(r.Context(), name)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			respondWithSCIMError(w, http.StatusInternalServerError, "", "Unable to retrieve users")
+			return
+		}
+		if err == nil {
+			total = 1
+			if startIndex == 1 && count > 0 {
+				users = append(users, user)
+			}
+		}
+	} else {
+		var err error
+		total, err = ac.DB.CountUsers(r.Context())
+		if err == nil {
+			users, err = ac.DB.ListUsers(r.Context(), database.ListUsersParams{
+				Limit:  int32(count),
+				Offset: int32(startIndex - 1),
+			})
+		}
+		if err != nil {
+			respondWithSCIMError(w, http.StatusInternalServerError, "", "Unable to retrieve users")
+			return
+		}
+	}
+	resources := make([]scimUser, 0, len(users))
+	for _, u := range users {
+		resources = append(resources, newSCIMUser(r, u))
+	}
+	respondWithSCIM(w, http.StatusOK, map[string]interface{}{
+		"schemas":      []string{scimListSchema},
+		"totalResults": total,
+		"startIndex":   startIndex,
+		"itemsPerPage": len(resources),
+		"Resources":    resources,
+	})
+}
+
+// getSCIMUser loads the user the request's path names, responding with
+// an error if there is none.
+func getSCIMUser(w http.ResponseWriter, r *http.Request, ac apiConfig) (database.User, bool) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		respondWithSCIMError(w, http.StatusNotFound, "", "User not found")
+		return database.User{}, false
+	}
+	user, err := ac.DB.GetUser(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithSCIMError(w, http.StatusNotFound, "", "User not found")
+		return database.User{}, false
+	}
+	if err != nil {
+		respondWithSCIMError(w, http.StatusInternalServerError, "", "Unable to retrieve user")
+		return database.User{}, false
+	}
+	return user, true
+}
+
+func handleSCIMUserGet(w http.ResponseWriter, r *http.Request, ac apiConfig) {
+	user, ok := getSCIMUser(w, r, ac)
+	if !ok {
+		return
+	}
+	respondWithSCIM(w, http.StatusOK, newSCIMUser(r, user))
+}
+
+func handleSCIMUsersPost(w http.ResponseWriter, r *http.Request, ac apiConfig) {
+	req := scimUser{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		respondWithSCIMError(w, http.StatusBadRequest, "invalidSyntax", "Unable to decode json")
+		return
+	}
+	name := strings.TrimSpace(req.UserName)
+	if name == "" {
+		respondWithSCIMError(w, http.StatusBadRequest, "invalidValue", "userName is required")
+		return
+	}
+	tx, err := ac.DB.Begin(r.Context())
+	if err != nil {
+		respondWithSCIMError(w, http.StatusInternalServerError, "", "Unable to create user")
+		return
+	}
+	defer tx.Rollback()
+	user, err := tx.CreateUser(r.Context(), database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Name:      name,
+	})
+	if isUniqueViolation(err) {
+		respondWithSCIMError(w, http.StatusConflict, "uniqueness", "userName is already taken")
+		return
+	}
+	if err == nil {
+		err = tx.CreateUserIdentity(r.Context(), database.CreateUserIdentityParams{
+			Backend:   scimIdentityBackend,
+			Subject:   user.ID.String(),
+			UserID:    user.ID,
+			CreatedAt: user.CreatedAt,
+		})
+	}
+	if err != nil {
+		respondWithSCIMError(w, http.StatusInternalServerError, "", "Unable to create user")
+		return
+	}
+	user, ok := applySCIMUser(w, r, tx, user, req)
+	if !ok {
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		respondWithSCIMError(w, http.StatusInternalServerError, "", "Unable to create user")
+		return
+	}
+	logInfo("auth", "Provisioned user %s (%s) over SCIM", user.ID, user.Name)
+	respondWithSCIM(w, http.StatusCreated, newSCIMUser(r, user))
+}
+
+// handleSCIMUserPut replaces a user's SCIM attributes: an email left out
+// is cleared.
+func handleSCIMUserPut(w http.ResponseWriter, r *http.Request, ac apiConfig) {
+	req := scimUser{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		respondWithSCIMError(w, http.StatusBadRequest, "invalidSyntax", "Unable to decode json")
+		return
+	}
+	updateSCIMUser(w, r, ac, func(scimUser) (scimUser, error) { return req, nil })
+}
+
+// handleSCIMUserPatch applies PatchOp operations to a user's attributes.
+// Operations on attributes that are not stored are ignored.
+func handleSCIMUserPatch(w http.ResponseWriter, r *http.Request, ac apiConfig) {
+	type patchRequest struct {
+		Operations []struct {
+			Op    string          `json:"op"`
+			Path  string          `json:"path"`
+			Value json.RawMessage `json:"value"`
+		} `json:"Operations"`
+	}
+	req := patchRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		respondWithSCIMError(w, http.StatusBadRequest, "invalidSyntax", "Unable to decode json")
+		return
+	}
+	updateSCIMUser(w, r, ac, func(s scimUser) (scimUser, error) {
+		for _, op := range req.Operations {
+			switch strings.ToLower(op.Op) {
+			case "add", "replace":
+				var err error
+				s, err = patchSCIMAttribute(s, op.Path, op.Value)
+				if err != nil {
+					return s, err
+				}
+			case "remove":
+				if strings.HasPrefix(strings.ToLower(op.Path), "emails") {
+					s.Emails = nil
+				}
+			default:
+				return s, fmt.Errorf("Unsupported op %q", op.Op)
+			}
+		}
+		return s, nil
+	})
+}
+
+// patchSCIMAttribute sets the attribute at path, or with no path, the
+// attributes in value, an object.
+func patchSCIMAttribute(s scimUser, path string, value json.RawMessage) (scimUser, error) {
+	if path == "" {
+		attrs := map[string]json.RawMessage{}
+		err := json.Unmarshal(value, &attrs)
+		if err != nil {
+			return s, errors.New("Patch without a path needs an object value")
+		}
+		for name, v := range attrs {
+			s, err = patchSCIMAttribute(s, name, v)
+			if err != nil {
+				return s, err
+			}
+		}
+		return s, nil
+	}
+	var err error
+	switch lower := strings.ToLower(path); {
+	case lower == "username":
+		err = json.Unmarshal(value, &s.UserName)
+	case lower == "active":
+		// Some providers send active as the string "True" or "False".
+		var str string
+		if json.Unmarshal(value, &str) == nil {
+			s.Active, err = strconv.ParseBool(str)
+		} else {
+			err = json.Unmarshal(value, &s.Active)
+		}
+	case lower == "emails":
+		err = json.Unmarshal(value, &s.Emails)
+	case strings.HasPrefix(lower, "emails[") && strings.HasSuffix(lower, "].value"):
+		var email string
+		err = json.Unmarshal(value, &email)
+		s.Emails = []scimEmail{{Value: email, Primary: true}}
+	}
+	if err != nil {
+		return s, fmt.Errorf("Invalid value for %s", path)
+	}
+	return s, nil
+}
+
+// updateSCIMUser changes the user the path names to what change makes of
+// their current attributes.
+func updateSCIMUser(w http.ResponseWriter, r *http.Request, ac apiConfig, change func(scimUser) (scimUser, error)) {
+	user, ok := getSCIMUser(w, r, ac)
+	if !ok {
+		return
+	}
+	updated, err := change(newSCIMUser(r, user))
+	if err != nil {
+		respondWithSCIMError(w, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+	tx, err := ac.DB.Begin(r.Context())
+	if err != nil {
+		respondWithSCIMError(w, http.StatusInternalServerError, "", "Unable to update user")
+		return
+	}
+	defer tx.Rollback()
+	user, ok = applySCIMUser(w, r, tx, user, updated)
+	if !ok {
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		respondWithSCIMError(w, http.StatusInternalServerError, "", "Unable to update user")
+		return
+	}
+	respondWithSCIM(w, http.StatusOK, newSCIMUser(r, user))
+}
+
+// applySCIMUser stores s's attributes on u. Deactivating a user
+// suspends them, replaces their API key, so that reactivating them does
+// not bring the old one back, and drops their follows, so their feeds are
+// no longer fetched on their behalf.
+func applySCIMUser(w http.ResponseWriter, r *http.Request, tx database.Tx, u database.User, s scimUser) (database.User, bool) {
+	ctx := r.Context()
+	now := time.Now()
+	name := strings.TrimSpace(s.UserName)
+	if name == "" {
+		respondWithSCIMError(w, http.StatusBadRequest, "invalidValue", "userName is required")
+		return u, false
+	}
+	email := sql.NullString{}
+	if e := s.email(); e != "" {
+		addr, err := mail.ParseAddress(e)
+		if err != nil {
+			respondWithSCIMError(w, http.StatusBadRequest, "invalidValue", "Invalid email address")
+			return u, false
+		}
+		email = sql.NullString{String: addr.Address, Valid: true}
+	}
+	if name != u.Name || email != u.Email {
+		var err error
+		u, err = tx.UpdateUserProfile(ctx, database.UpdateUserProfileParams{
+			ID:                 u.ID,
+			Name:               name,
+			Email:              email,
+			AvatarUrl:          u.AvatarUrl,
+			Bio:                u.Bio,
+			ShowSensitive:      u.ShowSensitive,
+			UpdatedAt:          now,
+			PreferredLanguages: u.PreferredLanguages,
+		})
+		if isUniqueViolation(err) {
+			respondWithSCIMError(w, http.StatusConflict, "uniqueness", "userName is already taken")
+			return u, false
+		}
+		if err != nil {
+			respondWithSCIMError(w, http.StatusInternalServerError, "", "Unable to update user")
+			return u, false
+		}
+	}
+	if s.Active == !u.BannedAt.Valid {
+		return u, true
+	}
+	err := tx.SetUserBanned(ctx, database.SetUserBannedParams{
+		ID:        u.ID,
+		BannedAt:  sql.NullTime{Time: now, Valid: !s.Active},
+		UpdatedAt: now,
+	})
+	if err == nil && !s.Active {
+		err = tx.RotateUserApiKey(ctx, database.RotateUserApiKeyParams{ID: u.ID, UpdatedAt: now})
+	}
+	if err == nil && !s.Active {
+		err = tx.DeleteUserFeedFollows(ctx, u.ID)
+	}
+	if err == nil {
+		u, err = tx.GetUser(ctx, u.ID)
+	}
+	if err != nil {
+		respondWithSCIMError(w, http.StatusInternalServerError, "", "Unable to update user")
+		return u, false
+	}
+	if s.Active {
+		logInfo("auth", "Reactivated user %s (%s) over SCIM", u.ID, u.Name)
+	} else {
+		logInfo("auth", "Deprovisioned user %s (%s) over SCIM", u.ID, u.Name)
+	}
+	return u, true
+}
+
+// handleSCIMUserDelete deprovisions a user the same way as setting
+// active to false. The user is not deleted: feeds they added are shared
+// with their other followers, and deleting the user would delete those
+// feeds and their posts for everyone.
+func handleSCIMUserDelete(w http.ResponseWriter, r *http.Request, ac apiConfig) {
+	user, ok := getSCIMUser(w, r, ac)
+	if !ok {
+		return
+	}
+	tx, err := ac.DB.Begin(r.Context())
+	if err != nil {
+		respondWithSCIMError(w, http.StatusInternalServerError, "", "Unable to delete user")
+		return
+	}
+	defer tx.Rollback()
+	s := newSCIMUser(r, user)
+	s.Active = false
+	_, ok = applySCIMUser(w, r, tx, user, s)
+	if !ok {
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		respondWithSCIMError(w, http.StatusInternalServerError, "", "Unable to delete user")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+func TestSCIMUserDeleteKeepsSharedFeeds(t *testing.T) {
+	ac, _ := newClockTestConfig()
+	ctx := context.Background()
+	owner, feed := seedClockTestFeed(t, ac, true)
+	post := seedClockTestPost(t, ac, feed)
+	follower, err := ac.DB.CreateUser(ctx, database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: clockTestStart,
+		UpdatedAt: clockTestStart,
+		Name:      "follower",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ac.DB.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: clockTestStart,
+		UpdatedAt: clockTestStart,
+		UserID:    follower.ID,
+		FeedID:    feed.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodDelete, "/scim/v2/Users/"+owner.ID.String(), nil)
+	w := httptest.NewRecorder()
+	handleSCIMUserDelete(w, withURLParam(r, "userID", owner.ID.String()), ac)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204: %s", w.Code, w.Body.String())
+	}
+
+	deprovisioned, err := ac.DB.GetUser(ctx, owner.ID)
+	if err != nil {
+		t.Fatalf("deprovisioned user is gone: %v", err)
+	}
+	if !deprovisioned.BannedAt.Valid || deprovisioned.ApiKey == owner.ApiKey {
+		t.Errorf("deprovisioned user is not suspended with a new API key")
+	}
+	ownerFollows, err := ac.DB.GetUserFeedFollows(ctx, owner.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ownerFollows) != 0 {
+		t.Errorf("deprovisioned user still has %d follows", len(ownerFollows))
+	}
+	follows, err := ac.DB.GetUserFeedFollows(ctx, follower.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(follows) != 1 || follows[0].FeedID != feed.ID {
+		t.Errorf("follower lost the deprovisioned user's feed: %+v", follows)
+	}
+	if _, err := ac.DB.GetPost(ctx, post.ID); err != nil {
+		t.Errorf("post of the deprovisioned user's feed is gone: %v", err)
+	}
+}
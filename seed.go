@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// fixture is the JSON format read by the seed command and the admin seed
+// endpoint. Feeds refer to their owner and followers by user name, and posts
+// refer to their feed by URL, so a fixture can be written by hand.
+type fixture struct {
+	Users []struct {
+		Name    string `json:"name"`
+		IsAdmin bool   `json:"is_admin"`
+	} `json:"users"`
+	Feeds []struct {
+		Name      string   `json:"name"`
+		URL       string   `json:"url"`
+		Owner     string   `json:"owner"`
+		Followers []string `json:"followers"`
+	} `json:"feeds"`
+	Posts []struct {
+		FeedURL     string     `json:"feed_url"`
+		Title       string     `json:"title"`
+		URL         string     `json:"url"`
+		Description string     `json:"description"`
+		PublishedAt *time.Time `json:"published_at"`
+	} `json:"posts"`
+}
+
+type seededUser struct {
+	ID      uuid.UUID `json:"id"`
+	Name    string    `json:"name"`
+	ApiKey  string    `json:"api_key"`
+	IsAdmin bool      `json:"is_admin"`
+}
+
+type seedResult struct {
+	Users []seededUser `json:"users"`
+	Feeds int          `json:"feeds"`
+	Posts int          `json:"posts"`
+}
+
+// loadFixture inserts a fixture in one transaction. It is meant for empty
+// or throwaway databases: a user name, feed URL, or post URL that already
+// exists aborts the whole load.
+func loadFixture(ctx context.Context, store database.Store, fx fixture) (seedResult, error) {
+	result := seedResult{Users: []seededUser{}}
+	tx, err := store.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	users := map[string]uuid.UUID{}
+	for _, fu := range fx.Users {
+		user, err := tx.CreateUser(ctx, database.CreateUserParams{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			Name:      strings.TrimSpace(fu.Name),
+		})
+		if err != nil {
+			return result, fmt.Errorf("user %q: %w", fu.Name, err)
+		}
+		if fu.IsAdmin {
+			err = tx.SetUserAdmin(ctx, database.SetUserAdminParams{ID: user.ID, IsAdmin: true, UpdatedAt: now})
+			if err != nil {
+				return result, fmt.Errorf("user %q: %w", fu.Name, err)
+			}
+		}
+		users[strings.ToLower(user.Name)] = user.ID
+		result.Users = append(result.Users, seededUser{ID: user.ID, Name: user.Name, ApiKey: user.ApiKey, IsAdmin: fu.IsAdmin})
+	}
+	lookupUser := func(name string) (uuid.UUID, error) {
+		id, ok := users[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return id, fmt.Errorf("unknown user %q", name)
+		}
+		return id, nil
+	}
+
+	feeds := map[string]uuid.UUID{}
+	for _, ff := range fx.Feeds {
+		ownerID, err := lookupUser(ff.Owner)
+		if err != nil {
+			return result, fmt.Errorf("feed %q: %w", ff.URL, err)
+		}
+		feed, err := tx.CreateFeed(ctx, database.CreateFeedParams{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			Name:      ff.Name,
+			Url:       ff.URL,
+			UserID:    ownerID,
+		})
+		if err != nil {
+			return result, fmt.Errorf("feed %q: %w", ff.URL, err)
+		}
+		feeds[feed.Url] = feed.ID
+		for _, follower := range ff.Followers {
+			followerID, err := lookupUser(follower)
+			if err != nil {
+				return result, fmt.Errorf("feed %q: %w", ff.URL, err)
+			}
+			_, err = tx.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+				ID:        uuid.New(),
+				CreatedAt: now,
+				UpdatedAt: now,
+				UserID:    followerID,
+				FeedID:    feed.ID,
+			})
+			if err != nil {
+				return result, fmt.Errorf("feed %q: %w", ff.URL, err)
+			}
+		}
+		result.Feeds++
+	}
+
+	for _, fp := range fx.Posts {
+		feedID, ok := feeds[fp.FeedURL]
+		if !ok {
+			return result, fmt.Errorf("post %q: unknown feed %q", fp.URL, fp.FeedURL)
+		}
+		params := database.CreatePostParams{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			Title:     fp.Title,
+			Url:       fp.URL,
+			FeedID:    feedID,
+		}
+		if fp.Description != "" {
+			params.Description = sql.NullString{String: fp.Description, Valid: true}
+		}
+		if fp.PublishedAt != nil {
+			params.PublishedAt = sql.NullTime{Time: *fp.PublishedAt, Valid: true}
+		}
+		_, err := tx.CreatePost(ctx, params)
+		if err != nil {
+			return result, fmt.Errorf("post %q: %w", fp.URL, err)
+		}
+		result.Posts++
+	}
+	return result, tx.Commit()
+}
+
+// runSeed implements "rssagg seed <fixture.json>".
+func runSeed(store database.Store, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: rssagg seed <fixture.json>")
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	fx := fixture{}
+	err = json.Unmarshal(data, &fx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	result, err := loadFixture(context.Background(), store, fx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Seeded %d users, %d feeds, %d posts\n", len(result.Users), result.Feeds, result.Posts)
+	for _, u := range result.Users {
+		fmt.Printf("  %s: %s\n", u.Name, u.ApiKey)
+	}
+	return nil
+}
+
+func handleAdminSeedPost(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	fx := fixture{}
+	err := decoder.Decode(&fx)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	result, err := loadFixture(r.Context(), ac.DB, fx)
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, result)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// sensitiveCategories are item categories that publishers commonly use to
+// mark adult or otherwise sensitive content. Posts carrying one of them are
+// flagged when they are scraped, independently of any feed-level flag.
+var sensitiveCategories = []string{"nsfw", "adult", "18+", "content warning"}
+
+func hasSensitiveCategory(categories []string) bool {
+	for _, c := range categories {
+		c = strings.ToLower(strings.TrimSpace(c))
+		for _, s := range sensitiveCategories {
+			if c == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// handleAdminFeedsPatch flags or unflags a whole feed as sensitive. The flag
+// applies to every post from the feed when it is read, so it takes effect
+// for posts that were scraped before the change as well.
+func handleAdminFeedsPatch(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	feedID, err := parseFeedID(r.Context(), ac.DB, chi.URLParam(r, "feedID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	type adminFeedsPatchRequest struct {
+		Sensitive *bool `json:"sensitive"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := adminFeedsPatchRequest{}
+	err = decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	if req.Sensitive == nil {
+		respondWithError(w, http.StatusBadRequest, "Nothing to update")
+		return
+	}
+
+	tx, err := ac.DB.Begin(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to update feed")
+		return
+	}
+	defer tx.Rollback()
+
+	feed, err := tx.SetFeedSensitive(r.Context(), database.SetFeedSensitiveParams{
+		ID:        feedID,
+		Sensitive: *req.Sensitive,
+		UpdatedAt: time.Now(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Feed not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to update feed")
+		return
+	}
+	err = recordAudit(r.Context(), tx, u.ID, "feed.update", "feed", feed.ID, map[string]bool{"sensitive": feed.Sensitive})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to record audit log entry")
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to update feed")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, newFeedResponse(feed))
+}
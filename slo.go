@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/pmwals09/rss-aggregator/internal/clock"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const (
+	defaultLatencyBudget      = 500 * time.Millisecond
+	defaultAvailabilityTarget = 0.995
+	sloWindowHours            = 24
+)
+
+// latencyBounds are the upper bounds of the latency histogram buckets. The
+// p95 reported is the bound of the bucket it falls in, which is precise
+// enough to compare against a budget.
+var latencyBounds = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// sloConfig holds the latency budget for each endpoint, keyed like
+// "GET /v1/posts", and the availability every endpoint is held to.
+type sloConfig struct {
+	Budgets            map[string]time.Duration
+	DefaultBudget      time.Duration
+	AvailabilityTarget float64
+}
+
+func (c sloConfig) budget(endpoint string) time.Duration {
+	if b, ok := c.Budgets[endpoint]; ok {
+		return b
+	}
+	return c.DefaultBudget
+}
+
+// parseSLOBudgets reads SLO_BUDGETS, a comma-separated list of
+// endpoint=duration pairs such as "GET /v1/posts=200ms,default=1s".
+func parseSLOBudgets(s string) (map[string]time.Duration, time.Duration, error) {
+	budgets := map[string]time.Duration{}
+	def := defaultLatencyBudget
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		endpoint, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, 0, fmt.Errorf("SLO_BUDGETS: %q is not endpoint=duration", part)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d <= 0 {
+			return nil, 0, fmt.Errorf("SLO_BUDGETS: invalid duration for %s", endpoint)
+		}
+		endpoint = strings.Join(strings.Fields(endpoint), " ")
+		if endpoint == "default" {
+			def = d
+			continue
+		}
+		budgets[endpoint] = d
+	}
+	return budgets, def, nil
+}
+
+func newSLOConfigFromEnv() (sloConfig, error) {
+	c := sloConfig{
+		Budgets:            map[string]time.Duration{},
+		DefaultBudget:      defaultLatencyBudget,
+		AvailabilityTarget: defaultAvailabilityTarget,
+	}
+	if v := os.Getenv("SLO_BUDGETS"); v != "" {
+		budgets, def, err := parseSLOBudgets(v)
+		if err != nil {
+			return c, err
+		}
+		c.Budgets = budgets
+		c.DefaultBudget = def
+	}
+	if v := os.Getenv("SLO_AVAILABILITY_TARGET"); v != "" {
+		var target float64
+		_, err := fmt.Sscanf(v, "%g", &target)
+		if err != nil || target <= 0 || target > 1 {
+			return c, fmt.Errorf("Invalid SLO_AVAILABILITY_TARGET")
+		}
+		c.AvailabilityTarget = target
+	}
+	return c, nil
+}
+
+// hourBucket is one hour of RED metrics for one endpoint.
+type hourBucket struct {
+	Hour      int64
+	Requests  int64
+	Errors    int64
+	Latencies [12]int64
+}
+
+// sloMetrics keeps the last 24 hours of request rate, errors and duration
+// per endpoint in memory. It resets when the process restarts, which is
+// fine for the rough visibility it is meant to give a small instance.
+type sloMetrics struct {
+	mu        sync.Mutex
+	clock     clock.Clock
+	endpoints map[string]*[sloWindowHours]hourBucket
+}
+
+func newSLOMetrics(c clock.Clock) *sloMetrics {
+	return &sloMetrics{clock: c, endpoints: map[string]*[sloWindowHours]hourBucket{}}
+}
+
+func (m *sloMetrics) record(endpoint string, status int, d time.Duration) {
+	hour := m.clock.Now().Unix() / 3600
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ring, ok := m.endpoints[endpoint]
+	if !ok {
+		ring = &[sloWindowHours]hourBucket{}
+		m.endpoints[endpoint] = ring
+	}
+	b := &ring[hour%sloWindowHours]
+	if b.Hour != hour {
+		*b = hourBucket{Hour: hour}
+	}
+	b.Requests++
+	if status >= 500 {
+		b.Errors++
+	}
+	i := sort.Search(len(latencyBounds), func(i int) bool { return d <= latencyBounds[i] })
+	b.Latencies[i]++
+}
+
+type sloEndpointSummary struct {
+	Endpoint          string  `json:"endpoint"`
+	Requests          int64   `json:"requests"`
+	Errors            int64   `json:"errors"`
+	RequestsPerMinute float64 `json:"requests_per_minute"`
+	Availability      float64 `json:"availability"`
+	P95Ms             int64   `json:"p95_ms"`
+	BudgetMs          int64   `json:"budget_ms"`
+	WithinBudget      bool    `json:"within_budget"`
+	MeetsAvailability bool    `json:"meets_availability"`
+}
+
+// summary totals the window for every endpoint seen in it. A p95 beyond
+// the largest bucket is reported as -1.
+func (m *sloMetrics) summary(c sloConfig) []sloEndpointSummary {
+	hour := m.clock.Now().Unix() / 3600
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	summaries := []sloEndpointSummary{}
+	for endpoint, ring := range m.endpoints {
+		total := hourBucket{}
+		for _, b := range ring {
+			if b.Hour <= hour-sloWindowHours {
+				continue
+			}
+			total.Requests += b.Requests
+			total.Errors += b.Errors
+			for i, n := range b.Latencies {
+				total.Latencies[i] += n
+			}
+		}
+		if total.Requests == 0 {
+			continue
+		}
+		s := sloEndpointSummary{
+			Endpoint:          endpoint,
+			Requests:          total.Requests,
+			Errors:            total.Errors,
+			RequestsPerMinute: float64(total.Requests) / (sloWindowHours * 60),
+			Availability:      1 - float64(total.Errors)/float64(total.Requests),
+			P95Ms:             -1,
+			BudgetMs:          c.budget(endpoint).Milliseconds(),
+		}
+		var seen int64
+		for i, n := range total.Latencies {
+			seen += n
+			if seen*100 >= total.Requests*95 {
+				if i < len(latencyBounds) {
+					s.P95Ms = latencyBounds[i].Milliseconds()
+				}
+				break
+			}
+		}
+		s.WithinBudget = s.P95Ms >= 0 && s.P95Ms <= s.BudgetMs
+		s.MeetsAvailability = s.Availability >= c.AvailabilityTarget
+		summaries = append(summaries, s)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Endpoint < summaries[j].Endpoint })
+	return summaries
+}
+
+// middlewareMetrics records every routed request under its route pattern,
+// so /v1/posts/{postID}/snooze is one endpoint however many posts there
+// are. Requests that match no route are not recorded.
+func (ac *apiConfig) middlewareMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+		rctx := chi.RouteContext(r.Context())
+		if rctx == nil || rctx.RoutePattern() == "" {
+			return
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		ac.Metrics.record(r.Method+" "+rctx.RoutePattern(), status, time.Since(start))
+	})
+}
+
+func handleAdminSLOGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	type response struct {
+		WindowHours        int                  `json:"window_hours"`
+		AvailabilityTarget float64              `json:"availability_target"`
+		Endpoints          []sloEndpointSummary `json:"endpoints"`
+	}
+	respondWithJSON(w, http.StatusOK, response{
+		WindowHours:        sloWindowHours,
+		AvailabilityTarget: ac.SLO.AvailabilityTarget,
+		Endpoints:          ac.Metrics.summary(ac.SLO),
+	})
+}
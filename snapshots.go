@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const defaultSnapshotMaxBytes = 1 << 20
+
+// snapshotConfig controls how many raw fetched bodies are kept per feed, so
+// that a strange post can be traced back to exactly what the feed served.
+// Keep is 0, and nothing is stored, unless FETCH_SNAPSHOTS is set.
+type snapshotConfig struct {
+	Keep     int
+	MaxBytes int
+}
+
+func newSnapshotConfigFromEnv() (snapshotConfig, error) {
+	c := snapshotConfig{MaxBytes: defaultSnapshotMaxBytes}
+	if v := os.Getenv("FETCH_SNAPSHOTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return c, fmt.Errorf("Invalid FETCH_SNAPSHOTS")
+		}
+		c.Keep = n
+	}
+	if v := os.Getenv("FETCH_SNAPSHOT_MAX_BYTES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return c, fmt.Errorf("Invalid FETCH_SNAPSHOT_MAX_BYTES")
+		}
+		c.MaxBytes = n
+	}
+	return c, nil
+}
+
+// saveFetchSnapshot stores the body fd was parsed from, gzipped and cut
+// off at MaxBytes, and drops the feed's older snapshots beyond Keep. It is
+// called whether or not the body parsed, since the failures are the
+// interesting ones.
+func saveFetchSnapshot(ctx context.Context, ac apiConfig, feedID uuid.UUID, fd feedData) {
+	if ac.Snapshots.Keep == 0 || len(fd.Body) == 0 {
+		return
+	}
+	body := fd.Body
+	truncated := len(body) > ac.Snapshots.MaxBytes
+	if truncated {
+		body = body[:ac.Snapshots.MaxBytes]
+	}
+	buf := bytes.Buffer{}
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(body)
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		logError("fetch", "Could not compress snapshot: %v", err)
+		return
+	}
+	err = ac.DB.CreateFetchSnapshot(ctx, database.CreateFetchSnapshotParams{
+		ID:          uuid.New(),
+		FeedID:      feedID,
+		FetchedAt:   ac.Clock.Now(),
+		ContentType: fd.ContentType,
+		Size:        int32(len(fd.Body)),
+		Truncated:   truncated,
+		Body:        buf.Bytes(),
+	})
+	if err != nil {
+		logError("fetch", "Could not save snapshot: %v", err)
+		return
+	}
+	err = ac.DB.PruneFetchSnapshots(ctx, database.PruneFetchSnapshotsParams{
+		FeedID: feedID,
+		Keep:   int32(ac.Snapshots.Keep),
+	})
+	if err != nil {
+		logError("fetch", "Could not prune snapshots: %v", err)
+	}
+}
+
+type fetchSnapshotResponse struct {
+	ID          uuid.UUID `json:"id"`
+	FeedID      uuid.UUID `json:"feed_id"`
+	FetchedAt   time.Time `json:"fetched_at"`
+	ContentType string    `json:"content_type"`
+	Size        int32     `json:"size"`
+	Truncated   bool      `json:"truncated"`
+}
+
+func handleAdminFetchSnapshotsGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	feedID, err := parseFeedID(r.Context(), ac.DB, chi.URLParam(r, "feedID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	snapshots, err := ac.DB.ListFetchSnapshots(r.Context(), feedID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve snapshots")
+		return
+	}
+	responses := make([]fetchSnapshotResponse, 0, len(snapshots))
+	for _, s := range snapshots {
+		responses = append(responses, fetchSnapshotResponse{
+			ID:          s.ID,
+			FeedID:      s.FeedID,
+			FetchedAt:   s.FetchedAt,
+			ContentType: s.ContentType,
+			Size:        s.Size,
+			Truncated:   s.Truncated,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, responses)
+}
+
+// handleAdminFetchSnapshotGet downloads one snapshot as the feed served it,
+// decompressed and with its original content type.
+func handleAdminFetchSnapshotGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	feedID, err := parseFeedID(r.Context(), ac.DB, chi.URLParam(r, "feedID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	snapshotID, err := uuid.Parse(chi.URLParam(r, "snapshotID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	snapshot, err := ac.DB.GetFetchSnapshot(r.Context(), database.GetFetchSnapshotParams{ID: snapshotID, FeedID: feedID})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Snapshot not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve snapshot")
+		return
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(snapshot.Body))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to read snapshot")
+		return
+	}
+	body, err := io.ReadAll(zr)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to read snapshot")
+		return
+	}
+	contentType := snapshot.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", snapshot.ID.String()))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
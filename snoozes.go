@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// handlePostSnooze hides a post from the user's listings until the given
+// time. There is no read state, so waking does not mark the post unread:
+// it shows in listings again, in its usual place rather than at the top,
+// and if notify is set the user's notification channels are told it is
+// back.
+func handlePostSnooze(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	postID, err := parsePostID(r.Context(), ac.DB, chi.URLParam(r, "postID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	type snoozeRequest struct {
+		Until  time.Time `json:"until"`
+		Notify bool      `json:"notify"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := snoozeRequest{}
+	err = decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	if !req.Until.After(ac.Clock.Now()) {
+		respondWithError(w, http.StatusBadRequest, "Snooze time must be in the future")
+		return
+	}
+	_, err = visiblePost(r.Context(), ac, u, postID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Post not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve post")
+		return
+	}
+	snooze, err := ac.DB.UpsertPostSnooze(r.Context(), database.UpsertPostSnoozeParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UserID:    u.ID,
+		PostID:    postID,
+		WakeAt:    req.Until.Local(),
+		Notify:    req.Notify,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to snooze post")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, newPostSnoozeResponse(snooze))
+}
+
+// handlePostUnsnooze wakes a snoozed post now, without a notification.
+func handlePostUnsnooze(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	postID, err := parsePostID(r.Context(), ac.DB, chi.URLParam(r, "postID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	err = ac.DB.DeletePostSnooze(r.Context(), database.DeletePostSnoozeParams{
+		UserID: u.ID,
+		PostID: postID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Problem removing snooze")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// snoozeWorker sends a notification to the user's channels when a snoozed
+// post that asked for one wakes up. Hiding and resurfacing the post itself
+// needs no worker; listings compare wake_at against the current time.
+func snoozeWorker(ac apiConfig) {
+	logInfo("snooze", "Starting snooze worker...")
+	for range ac.Clock.Tick(time.Minute) {
+		if ac.Maintenance.active() {
+			continue
+		}
+		ctx := context.Background()
+		due, err := ac.DB.GetDueSnoozeNotifications(ctx, ac.Clock.Now())
+		if err != nil {
+			logError("snooze", "Could not get due snoozes: %v", err)
+			continue
+		}
+		for _, snooze := range due {
+			channels, err := ac.DB.GetUserNotificationChannels(ctx, snooze.UserID)
+			if err != nil {
+				logError("snooze", "Could not get notification channels: %v", err)
+				continue
+			}
+			msg := notification{
+				Event: "snooze_wake",
+				Text:  fmt.Sprintf("Snoozed post is back:\n- %s %s", snooze.Title, snooze.Url),
+				Posts: []notificationPost{{ID: snooze.PostID, Title: snooze.Title, Url: snooze.Url}},
+			}
+			for _, channel := range channels {
+				err := ac.sendNotification(ctx, channel, msg)
+				if err != nil {
+					logWarn("snooze", "Could not notify channel %s: %v", channel.ID, err)
+				}
+			}
+			err = ac.DB.MarkSnoozeNotified(ctx, database.MarkSnoozeNotifiedParams{
+				NotifiedAt: sql.NullTime{Time: ac.Clock.Now(), Valid: true},
+				ID:         snooze.ID,
+			})
+			if err != nil {
+				logError("snooze", "Could not mark snooze notified: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+func seedClockTestPost(t *testing.T, ac apiConfig, feed database.Feed) database.Post {
+	t.Helper()
+	now := ac.Clock.Now()
+	post, err := ac.DB.CreatePost(context.Background(), database.CreatePostParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Title:     "Snoozed post",
+		Url:       "https://example.com/" + uuid.NewString(),
+		FeedID:    feed.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return post
+}
+
+// withURLParam sets a path parameter on r, as the router would, for
+// calling a handler directly.
+func withURLParam(r *http.Request, key, value string) *http.Request {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		rctx = chi.NewRouteContext()
+	}
+	rctx.URLParams.Add(key, value)
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestPostSnoozeUntilUsesTheClock(t *testing.T) {
+	ac, _ := newClockTestConfig()
+	user, feed := seedClockTestFeed(t, ac, true)
+	post := seedClockTestPost(t, ac, feed)
+
+	tests := []struct {
+		name  string
+		until time.Time
+		want  int
+	}{
+		// Both are long past on the wall clock; only the fake one decides.
+		{"future", clockTestStart.Add(time.Hour), http.StatusOK},
+		{"now", clockTestStart, http.StatusBadRequest},
+		{"past", clockTestStart.Add(-time.Minute), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"until":"` + tt.until.Format(time.RFC3339) + `"}`
+			r := httptest.NewRequest(http.MethodPost, "/v1/posts/"+post.ID.String()+"/snooze", strings.NewReader(body))
+			r = withURLParam(r, "postID", post.ID.String())
+			w := httptest.NewRecorder()
+			handlePostSnooze(w, r, user, ac)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSnoozeWorkerNotifiesOnWake(t *testing.T) {
+	ac, fake := newClockTestConfig()
+	user, feed := seedClockTestFeed(t, ac, true)
+	post := seedClockTestPost(t, ac, feed)
+	ctx := context.Background()
+	wakeAt := clockTestStart.Add(30 * time.Minute)
+	_, err := ac.DB.UpsertPostSnooze(ctx, database.UpsertPostSnoozeParams{
+		ID:        uuid.New(),
+		CreatedAt: clockTestStart,
+		UserID:    user.ID,
+		PostID:    post.ID,
+		WakeAt:    wakeAt,
+		Notify:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending := func() bool {
+		due, err := ac.DB.GetDueSnoozeNotifications(ctx, wakeAt.Add(24*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(due) == 1
+	}
+
+	go snoozeWorker(ac)
+	// The worker ticks once a fake minute. Step a minute at a time, giving
+	// it a moment to take each tick, as a real minute would.
+	step := func() {
+		fake.Advance(time.Minute)
+		time.Sleep(5 * time.Millisecond)
+	}
+	for fake.Now().Before(wakeAt.Add(-time.Minute)) {
+		step()
+		if !pending() {
+			t.Fatalf("notified at %s, before the post woke at %s", fake.Now(), wakeAt)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for pending() {
+		if time.Now().After(deadline) {
+			t.Fatalf("not notified by %s, though the post woke at %s", fake.Now(), wakeAt)
+		}
+		step()
+	}
+}
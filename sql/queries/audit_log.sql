@@ -0,0 +1,7 @@
+-- name: CreateAuditLogEntry :one
+INSERT INTO audit_log (id, created_at, actor_id, action, target_type, target_id, details)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: ListAuditLog :many
+SELECT * FROM audit_log ORDER BY created_at DESC LIMIT $1;
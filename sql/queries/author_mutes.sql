@@ -0,0 +1,10 @@
+-- name: CreateAuthorMute :one
+INSERT INTO author_mutes (id, created_at, user_id, author)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetUserAuthorMutes :many
+SELECT * FROM author_mutes WHERE user_id = $1 ORDER BY created_at;
+
+-- name: DeleteAuthorMute :exec
+DELETE FROM author_mutes WHERE id = $1 AND user_id = $2;
@@ -0,0 +1,11 @@
+-- name: CreateDomainRule :one
+INSERT INTO domain_rules (id, created_at, domain, kind)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ListDomainRules :many
+SELECT * FROM domain_rules ORDER BY kind, domain;
+
+-- name: DeleteDomainRule :one
+DELETE FROM domain_rules WHERE id = $1
+RETURNING *;
@@ -0,0 +1,52 @@
+-- name: CreateFeedFetch :exec
+INSERT INTO feed_fetches (id, feed_id, fetched_at, status_code, duration_ms, item_count, error)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: PruneFeedFetches :exec
+DELETE FROM feed_fetches
+WHERE feed_fetches.feed_id = sqlc.arg('feed_id')
+AND id NOT IN (
+  SELECT kept.id FROM feed_fetches AS kept
+  WHERE kept.feed_id = sqlc.arg('feed_id')
+  ORDER BY kept.fetched_at DESC
+  LIMIT sqlc.arg('keep')
+);
+
+-- name: ListFeedFetches :many
+SELECT * FROM feed_fetches
+WHERE feed_id = $1
+ORDER BY fetched_at DESC
+LIMIT $2;
+
+-- name: ListNewlyFailingFeeds :many
+SELECT feeds.id, feeds.name, feeds.url, feeds.fetch_failures, feeds.last_fetch_error,
+MIN(feed_fetches.fetched_at)::timestamp AS failing_since
+FROM feeds
+INNER JOIN feed_fetches
+ON feed_fetches.feed_id = feeds.id
+WHERE feeds.fetch_failures > 0
+AND feeds.paused_at IS NULL
+AND feed_fetches.error <> ''
+AND feed_fetches.fetched_at > COALESCE((
+  SELECT MAX(ok.fetched_at) FROM feed_fetches AS ok
+  WHERE ok.feed_id = feeds.id AND ok.error = ''
+), '-infinity'::timestamp)
+GROUP BY feeds.id
+HAVING MIN(feed_fetches.fetched_at) > sqlc.arg('since')::timestamp
+ORDER BY failing_since;
+
+-- name: ListFeedPostingStats :many
+SELECT feeds.id, feeds.name, feeds.url, feeds.last_fetched_at,
+COUNT(posts.id) AS post_count,
+MIN(posts.created_at)::timestamp AS first_post_at,
+MAX(posts.created_at)::timestamp AS last_post_at
+FROM feeds
+INNER JOIN posts
+ON posts.feed_id = feeds.id
+WHERE feeds.kind = 'remote'
+AND feeds.disabled_at IS NULL
+AND feeds.paused_at IS NULL
+AND feeds.fetch_failures = 0
+GROUP BY feeds.id
+HAVING COUNT(posts.id) >= sqlc.arg('min_posts')::bigint
+ORDER BY feeds.id;
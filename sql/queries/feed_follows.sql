@@ -3,8 +3,32 @@ INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
 VALUES ($1, $2, $3, $4, $5)
 RETURNING *;
 
+-- name: CountOtherFeedFollowers :one
+SELECT COUNT(*) FROM feed_follows WHERE feed_id = $1 AND user_id <> $2;
+
 -- name: DeleteFeedFollow :exec
 DELETE FROM feed_follows WHERE id = $1;
 
 -- name: GetUserFeedFollows :many
 SELECT * FROM feed_follows WHERE user_id = $1;
+
+-- name: GetUserFeedFollowsPage :many
+SELECT * FROM feed_follows
+WHERE user_id = sqlc.arg('user_id')
+AND (sqlc.narg('after_at')::timestamp IS NULL OR (created_at, id) > (sqlc.narg('after_at'), sqlc.narg('after_id')::uuid))
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit');
+
+-- name: UpdateFeedFollow :one
+UPDATE feed_follows
+SET priority = COALESCE(sqlc.narg('priority'), priority),
+    order_by_ingested = COALESCE(sqlc.narg('order_by_ingested'), order_by_ingested),
+    updated_at = sqlc.arg('updated_at')
+WHERE id = sqlc.arg('id') AND user_id = sqlc.arg('user_id')
+RETURNING *;
+
+-- name: DeleteUserFeedFollows :exec
+DELETE FROM feed_follows WHERE user_id = $1;
+
+-- name: UserFollowsFeed :one
+SELECT EXISTS(SELECT 1 FROM feed_follows WHERE user_id = $1 AND feed_id = $2);
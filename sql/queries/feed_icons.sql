@@ -0,0 +1,16 @@
+-- name: UpsertFeedIcon :exec
+INSERT INTO feed_icons (feed_id, source_url, content_type, data, fetched_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (feed_id) DO UPDATE
+SET source_url = EXCLUDED.source_url, content_type = EXCLUDED.content_type, data = EXCLUDED.data, fetched_at = EXCLUDED.fetched_at;
+
+-- name: GetFeedIcon :one
+SELECT * FROM feed_icons WHERE feed_id = $1;
+
+-- name: ListFeedsNeedingIcons :many
+SELECT feeds.* FROM feeds
+LEFT JOIN feed_icons ON feed_icons.feed_id = feeds.id
+WHERE feeds.kind = 'remote'
+AND (feed_icons.feed_id IS NULL OR feed_icons.fetched_at < sqlc.arg('stale_before'))
+ORDER BY feed_icons.fetched_at NULLS FIRST
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,15 @@
+-- name: CreateFeedReport :one
+INSERT INTO feed_reports (id, created_at, updated_at, feed_id, reporter_id, reason, comment)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetFeedReport :one
+SELECT * FROM feed_reports WHERE id = $1;
+
+-- name: ListFeedReportsByStatus :many
+SELECT * FROM feed_reports WHERE status = $1 ORDER BY created_at;
+
+-- name: ResolveFeedReport :one
+UPDATE feed_reports SET status = $2, resolved_by = $3, resolved_at = $4, updated_at = $4
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,13 @@
+-- name: CreateFeedRepublish :one
+INSERT INTO feed_republishes (id, created_at, updated_at, user_id, feed_id, token, include_keywords, exclude_keywords)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetUserFeedRepublishes :many
+SELECT * FROM feed_republishes WHERE user_id = $1 ORDER BY created_at;
+
+-- name: GetFeedRepublishByToken :one
+SELECT * FROM feed_republishes WHERE token = $1;
+
+-- name: DeleteFeedRepublish :exec
+DELETE FROM feed_republishes WHERE id = $1 AND user_id = $2;
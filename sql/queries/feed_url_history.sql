@@ -0,0 +1,8 @@
+-- name: CreateFeedUrlHistoryEntry :exec
+INSERT INTO feed_url_history (id, feed_id, old_url, new_url, changed_at)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ListFeedUrlHistory :many
+SELECT * FROM feed_url_history
+WHERE feed_id = $1
+ORDER BY changed_at DESC;
@@ -1,13 +1,148 @@
 -- name: CreateFeed :one
-INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, sensitive, description, site_url, language, country, credentials)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 RETURNING *;
 
 -- name: ListFeeds :many
-SELECT * FROM feeds ORDER BY id;
+SELECT * FROM feeds
+WHERE kind = 'remote' AND credentials IS NULL
+AND (sqlc.narg('after_id')::uuid IS NULL OR id > sqlc.narg('after_id'))
+ORDER BY id
+LIMIT sqlc.narg('limit');
 
--- name: GetNextFeedsToFetch :many
-SELECT * FROM feeds ORDER BY last_fetched_at NULLS FIRST LIMIT $1;
+-- name: ListFeedsPausedSince :many
+SELECT * FROM feeds
+WHERE paused_at > sqlc.arg('since')::timestamp
+ORDER BY paused_at;
+
+-- name: ListSchedulableFeeds :many
+SELECT * FROM feeds
+WHERE kind = 'remote'
+AND disabled_at IS NULL
+AND paused_at IS NULL
+AND (retry_at IS NULL OR retry_at <= sqlc.arg('now')::timestamp)
+ORDER BY last_fetched_at NULLS FIRST;
+
+-- name: ListFollowFetchIntervals :many
+SELECT feed_id, MIN(CASE priority
+  WHEN 'high' THEN 300
+  WHEN 'low' THEN 3600
+  ELSE 900
+END)::int AS interval_seconds
+FROM feed_follows
+GROUP BY feed_id;
+
+-- name: ListActiveFollowerCounts :many
+SELECT feed_follows.feed_id, COUNT(*) AS followers
+FROM feed_follows
+JOIN users ON users.id = feed_follows.user_id
+WHERE users.banned_at IS NULL
+GROUP BY feed_follows.feed_id;
 
 -- name: MarkFeedFetched :exec
 UPDATE feeds SET last_fetched_at = $1, updated_at = $1 WHERE id = $2;
+
+-- name: GetFeed :one
+SELECT * FROM feeds WHERE id = $1;
+
+-- name: SetFeedDisabled :exec
+UPDATE feeds SET disabled_at = $2, updated_at = $3 WHERE id = $1;
+
+-- name: SetFeedSensitive :one
+UPDATE feeds SET sensitive = $2, updated_at = $3 WHERE id = $1
+RETURNING *;
+
+-- name: GetInboxFeed :one
+SELECT * FROM feeds WHERE user_id = $1 AND kind = 'inbox'
+ORDER BY created_at
+LIMIT 1;
+
+-- name: CreateInboxFeed :one
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, kind)
+VALUES ($1, $2, $3, $4, $5, $6, 'inbox')
+RETURNING *;
+
+-- name: GetFeedByUrl :one
+SELECT * FROM feeds
+WHERE credentials IS NULL
+AND (url = $1
+OR id = (
+  SELECT feed_id FROM feed_url_history
+  WHERE old_url = $1
+  ORDER BY changed_at DESC
+  LIMIT 1
+))
+ORDER BY url = $1 DESC
+LIMIT 1;
+
+-- name: GetFeedByPublicID :one
+SELECT * FROM feeds WHERE public_id = $1;
+
+-- name: ListFeedsByLanguage :many
+SELECT * FROM feeds
+WHERE kind = 'remote' AND credentials IS NULL AND language = ANY(sqlc.arg('languages')::text[])
+AND (sqlc.narg('after_id')::uuid IS NULL OR id > sqlc.narg('after_id'))
+ORDER BY id
+LIMIT sqlc.narg('limit');
+
+-- name: SetFeedLocale :exec
+UPDATE feeds SET language = $2, country = $3, updated_at = $4
+WHERE id = $1 AND (language <> $2 OR country <> $3);
+
+-- name: SetFeedCacheValidators :exec
+UPDATE feeds SET etag = $2, last_modified = $3 WHERE id = $1;
+
+-- name: RecordFeedGap :exec
+UPDATE feeds SET missed_items_at = $2, gap_interval_seconds = $3 WHERE id = $1;
+
+-- name: FeedHasPosts :one
+SELECT EXISTS(SELECT 1 FROM posts WHERE feed_id = $1);
+
+-- name: CountFeedPostsByWeekday :many
+SELECT EXTRACT(DOW FROM published_at)::int AS weekday, COUNT(*) AS post_count
+FROM posts
+WHERE feed_id = $1 AND published_at >= sqlc.arg('since')::timestamp
+GROUP BY weekday;
+
+-- name: SetFeedQuietWeekdays :exec
+UPDATE feeds SET quiet_weekdays = $2 WHERE id = $1;
+
+-- name: SetFeedFetchHeaders :one
+UPDATE feeds SET fetch_headers = $2, updated_at = $3 WHERE id = $1
+RETURNING *;
+
+-- name: SetFeedCredentials :one
+UPDATE feeds SET credentials = $2, updated_at = $3 WHERE id = $1
+RETURNING *;
+
+-- name: SetFeedFetchInterval :one
+UPDATE feeds SET fetch_interval_seconds = $2, updated_at = $3 WHERE id = $1
+RETURNING *;
+
+-- name: SetFeedSchedule :one
+UPDATE feeds SET schedule = $2, updated_at = $3 WHERE id = $1
+RETURNING *;
+
+-- name: RecordFeedFetchFailure :exec
+UPDATE feeds SET fetch_failures = $2, last_fetch_error = $3, retry_at = $4, paused_at = $5
+WHERE id = $1;
+
+-- name: ClearFeedFetchFailures :exec
+UPDATE feeds SET fetch_failures = 0, last_fetch_error = '', retry_at = NULL
+WHERE id = $1;
+
+-- name: SetFeedLastFetchStatus :exec
+UPDATE feeds SET last_fetch_status = $2 WHERE id = $1;
+
+-- name: ResumeFeed :one
+UPDATE feeds
+SET paused_at = NULL, fetch_failures = 0, last_fetch_error = '', retry_at = NULL, updated_at = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateFeedUrl :exec
+UPDATE feeds SET url = $2, updated_at = $3
+WHERE id = $1;
+
+-- name: SetFeedUnfollowedAt :exec
+UPDATE feeds SET unfollowed_at = $2 WHERE id = $1;
@@ -0,0 +1,21 @@
+-- name: CreateFetchSnapshot :exec
+INSERT INTO fetch_snapshots (id, feed_id, fetched_at, content_type, size, truncated, body)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: PruneFetchSnapshots :exec
+DELETE FROM fetch_snapshots
+WHERE fetch_snapshots.feed_id = sqlc.arg('feed_id')
+AND id NOT IN (
+  SELECT kept.id FROM fetch_snapshots AS kept
+  WHERE kept.feed_id = sqlc.arg('feed_id')
+  ORDER BY kept.fetched_at DESC
+  LIMIT sqlc.arg('keep')
+);
+
+-- name: ListFetchSnapshots :many
+SELECT id, feed_id, fetched_at, content_type, size, truncated FROM fetch_snapshots
+WHERE feed_id = $1
+ORDER BY fetched_at DESC;
+
+-- name: GetFetchSnapshot :one
+SELECT * FROM fetch_snapshots WHERE id = $1 AND feed_id = $2;
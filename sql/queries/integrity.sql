@@ -0,0 +1,57 @@
+-- name: CountOrphanFeedFollows :one
+SELECT COUNT(*) FROM feed_follows ff
+WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = ff.user_id)
+   OR NOT EXISTS (SELECT 1 FROM feeds f WHERE f.id = ff.feed_id);
+
+-- name: DeleteOrphanFeedFollows :execrows
+DELETE FROM feed_follows ff
+WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = ff.user_id)
+   OR NOT EXISTS (SELECT 1 FROM feeds f WHERE f.id = ff.feed_id);
+
+-- name: CountOrphanPosts :one
+SELECT COUNT(*) FROM posts p
+WHERE NOT EXISTS (SELECT 1 FROM feeds f WHERE f.id = p.feed_id);
+
+-- name: DeleteOrphanPosts :execrows
+DELETE FROM posts p
+WHERE NOT EXISTS (SELECT 1 FROM feeds f WHERE f.id = p.feed_id);
+
+-- name: CountOrphanPostSnoozes :one
+SELECT COUNT(*) FROM post_snoozes s
+WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = s.user_id)
+   OR NOT EXISTS (SELECT 1 FROM posts p WHERE p.id = s.post_id);
+
+-- name: DeleteOrphanPostSnoozes :execrows
+DELETE FROM post_snoozes s
+WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = s.user_id)
+   OR NOT EXISTS (SELECT 1 FROM posts p WHERE p.id = s.post_id);
+
+-- name: CountOrphanQueueItems :one
+SELECT COUNT(*) FROM reading_queue_items q
+WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = q.user_id)
+   OR NOT EXISTS (SELECT 1 FROM posts p WHERE p.id = q.post_id);
+
+-- name: DeleteOrphanQueueItems :execrows
+DELETE FROM reading_queue_items q
+WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = q.user_id)
+   OR NOT EXISTS (SELECT 1 FROM posts p WHERE p.id = q.post_id);
+
+-- name: CountOrphanPostEmails :one
+SELECT COUNT(*) FROM post_emails e
+WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = e.user_id)
+   OR NOT EXISTS (SELECT 1 FROM posts p WHERE p.id = e.post_id);
+
+-- name: DeleteOrphanPostEmails :execrows
+DELETE FROM post_emails e
+WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = e.user_id)
+   OR NOT EXISTS (SELECT 1 FROM posts p WHERE p.id = e.post_id);
+
+-- name: CountForeignInboxFollows :one
+SELECT COUNT(*) FROM feed_follows ff
+JOIN feeds f ON f.id = ff.feed_id
+WHERE f.kind = 'inbox' AND f.user_id <> ff.user_id;
+
+-- name: DeleteForeignInboxFollows :execrows
+DELETE FROM feed_follows ff
+USING feeds f
+WHERE f.id = ff.feed_id AND f.kind = 'inbox' AND f.user_id <> ff.user_id;
@@ -0,0 +1,24 @@
+-- name: CreateNotificationChannel :one
+INSERT INTO notification_channels (id, created_at, updated_at, user_id, kind, config)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetUserNotificationChannels :many
+SELECT * FROM notification_channels WHERE user_id = $1 ORDER BY created_at;
+
+-- name: DeleteNotificationChannel :exec
+DELETE FROM notification_channels WHERE id = $1 AND user_id = $2;
+
+-- name: GetNotificationChannelsForFeed :many
+SELECT notification_channels.* FROM notification_channels
+INNER JOIN feed_follows
+ON notification_channels.user_id = feed_follows.user_id
+WHERE feed_follows.feed_id = $1 AND feed_follows.priority <> 'low';
+
+-- name: GetNotificationChannel :one
+SELECT * FROM notification_channels WHERE id = $1 AND user_id = $2;
+
+-- name: UpdateNotificationChannelConfig :one
+UPDATE notification_channels SET config = $3, updated_at = $4
+WHERE id = $1 AND user_id = $2
+RETURNING *;
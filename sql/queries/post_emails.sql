@@ -0,0 +1,7 @@
+-- name: CreatePostEmail :one
+INSERT INTO post_emails (id, created_at, user_id, post_id, recipient)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: CountPostEmailsSince :one
+SELECT COUNT(*) FROM post_emails WHERE user_id = $1 AND created_at >= $2;
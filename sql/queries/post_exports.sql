@@ -0,0 +1,18 @@
+-- name: CreatePostExport :one
+INSERT INTO post_exports (id, created_at, destination, format, exported_after, exported_until, post_count, location)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetLatestPostExport :one
+SELECT * FROM post_exports
+WHERE destination = $1
+ORDER BY exported_until DESC
+LIMIT 1;
+
+-- name: ListPostsForExport :many
+SELECT posts.*, feeds.url AS feed_url FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE posts.created_at > sqlc.arg('after')
+AND posts.created_at <= sqlc.arg('until')
+AND posts.source_post_id IS NULL
+ORDER BY posts.created_at, posts.id;
@@ -0,0 +1,7 @@
+-- name: CreatePostRevision :one
+INSERT INTO post_revisions (id, created_at, post_id, editor_id, title, url)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetPostRevisions :many
+SELECT * FROM post_revisions WHERE post_id = $1 ORDER BY created_at DESC;
@@ -0,0 +1,10 @@
+-- name: CreatePostRule :one
+INSERT INTO post_rules (id, created_at, user_id, feed_id, action, pattern, replacement)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetUserPostRules :many
+SELECT * FROM post_rules WHERE user_id = $1 ORDER BY created_at, id;
+
+-- name: DeletePostRule :exec
+DELETE FROM post_rules WHERE id = $1 AND user_id = $2;
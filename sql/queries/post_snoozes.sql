@@ -0,0 +1,18 @@
+-- name: UpsertPostSnooze :one
+INSERT INTO post_snoozes (id, created_at, user_id, post_id, wake_at, notify)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET wake_at = EXCLUDED.wake_at, notify = EXCLUDED.notify, notified_at = NULL
+RETURNING *;
+
+-- name: DeletePostSnooze :exec
+DELETE FROM post_snoozes WHERE user_id = $1 AND post_id = $2;
+
+-- name: GetDueSnoozeNotifications :many
+SELECT post_snoozes.*, posts.title, posts.url FROM post_snoozes
+INNER JOIN posts
+ON post_snoozes.post_id = posts.id
+WHERE post_snoozes.notify AND post_snoozes.notified_at IS NULL AND post_snoozes.wake_at <= $1;
+
+-- name: MarkSnoozeNotified :exec
+UPDATE post_snoozes SET notified_at = $1 WHERE id = $2;
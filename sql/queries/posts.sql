@@ -1,12 +1,109 @@
 -- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, sensitive, guid, inbox)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $5, EXISTS(SELECT 1 FROM feeds WHERE id = $8 AND kind = 'inbox'))
 RETURNING *;
 
 -- name: GetPostsByUser :many
-SELECT * FROM posts
+SELECT posts.*, feed_follows.*, feeds.sensitive AS feed_sensitive FROM posts
 INNER JOIN feed_follows
 ON posts.feed_id = feed_follows.feed_id
-WHERE feed_follows.user_id = $1
-ORDER BY posts.updated_at NULLS LAST
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+CROSS JOIN LATERAL (
+  SELECT CASE sqlc.arg('order_by')::text
+    WHEN 'ingested_at' THEN posts.created_at
+    WHEN 'published_at' THEN CASE
+      WHEN feed_follows.order_by_ingested THEN posts.created_at
+      ELSE COALESCE(posts.published_at, posts.created_at)
+    END
+    ELSE posts.updated_at
+  END AS sort_at
+) AS sort
+WHERE feed_follows.user_id = sqlc.arg('user_id')
+AND posts.created_at <= sqlc.arg('as_of')::timestamp
+AND feed_follows.created_at <= sqlc.arg('as_of')::timestamp
+AND NOT EXISTS (
+  SELECT 1 FROM post_snoozes
+  WHERE post_snoozes.post_id = posts.id
+  AND post_snoozes.user_id = feed_follows.user_id
+  AND post_snoozes.created_at <= sqlc.arg('as_of')::timestamp
+  AND post_snoozes.wake_at > sqlc.arg('as_of')::timestamp
+)
+AND NOT EXISTS (
+  SELECT 1 FROM author_mutes
+  WHERE author_mutes.user_id = feed_follows.user_id
+  AND lower(author_mutes.author) = lower(posts.author)
+  AND author_mutes.created_at <= sqlc.arg('as_of')::timestamp
+)
+AND (sqlc.narg('since')::timestamp IS NULL OR sort.sort_at > sqlc.narg('since'))
+AND (sqlc.narg('until')::timestamp IS NULL OR sort.sort_at <= sqlc.narg('until'))
+AND (sqlc.narg('after_at')::timestamp IS NULL OR CASE WHEN sqlc.arg('sort_desc')::boolean
+  THEN (sort.sort_at, posts.id) < (sqlc.narg('after_at'), sqlc.narg('after_id')::uuid)
+  ELSE (sort.sort_at, posts.id) > (sqlc.narg('after_at'), sqlc.narg('after_id')::uuid)
+END)
+AND (COALESCE(cardinality(sqlc.arg('feed_ids')::uuid[]), 0) = 0 OR posts.feed_id = ANY(sqlc.arg('feed_ids')::uuid[]))
+ORDER BY CASE WHEN sqlc.arg('sort_desc')::boolean THEN sort.sort_at END DESC,
+CASE WHEN sqlc.arg('sort_desc')::boolean THEN posts.id END DESC,
+sort.sort_at, posts.id
+LIMIT sqlc.arg('limit');
+
+-- name: GetPost :one
+SELECT * FROM posts WHERE id = $1;
+
+-- name: GetPostsByFeed :many
+SELECT * FROM posts
+WHERE feed_id = $1
+ORDER BY created_at DESC
 LIMIT $2;
+
+-- name: GetRecentPostsByUser :many
+SELECT * FROM posts
+WHERE feed_id IN (
+  SELECT feed_follows.feed_id FROM feed_follows WHERE feed_follows.user_id = sqlc.arg('user_id')
+)
+AND (sqlc.narg('feed_id')::uuid IS NULL OR feed_id = sqlc.narg('feed_id'))
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit');
+
+-- name: UpdatePostMetadata :one
+UPDATE posts SET title = $2, url = $3, sensitive = $4, updated_at = $5
+WHERE id = $1
+RETURNING *;
+
+-- name: GetPostByPublicID :one
+SELECT * FROM posts WHERE public_id = $1;
+
+-- name: PostUrlExists :one
+SELECT EXISTS(SELECT 1 FROM posts WHERE url = $1 AND source_post_id IS NULL AND NOT inbox);
+
+-- name: CountPostsForDeletion :one
+SELECT COUNT(*) FROM posts
+WHERE (sqlc.narg('feed_id')::uuid IS NULL OR feed_id = sqlc.narg('feed_id'))
+AND (sqlc.narg('before')::timestamp IS NULL OR created_at < sqlc.narg('before'));
+
+-- name: DeletePostsBatch :execrows
+DELETE FROM posts
+WHERE id IN (
+  SELECT id FROM posts
+  WHERE (sqlc.narg('feed_id')::uuid IS NULL OR feed_id = sqlc.narg('feed_id'))
+  AND (sqlc.narg('before')::timestamp IS NULL OR created_at < sqlc.narg('before'))
+  LIMIT sqlc.arg('limit')
+);
+
+-- name: UpsertPost :one
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, sensitive, guid, enclosure_url, enclosure_type, enclosure_length, author)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+ON CONFLICT (feed_id, guid) DO UPDATE
+SET title = EXCLUDED.title,
+  url = EXCLUDED.url,
+  description = EXCLUDED.description,
+  published_at = EXCLUDED.published_at,
+  enclosure_url = EXCLUDED.enclosure_url,
+  enclosure_type = EXCLUDED.enclosure_type,
+  enclosure_length = EXCLUDED.enclosure_length,
+  author = EXCLUDED.author,
+  updated_at = EXCLUDED.updated_at
+WHERE (posts.title, posts.url, posts.description, posts.published_at, posts.enclosure_url, posts.enclosure_type, posts.enclosure_length, posts.author)
+  IS DISTINCT FROM (EXCLUDED.title, EXCLUDED.url, EXCLUDED.description, EXCLUDED.published_at, EXCLUDED.enclosure_url, EXCLUDED.enclosure_type, EXCLUDED.enclosure_length, EXCLUDED.author)
+AND NOT EXISTS (SELECT 1 FROM post_revisions WHERE post_revisions.post_id = posts.id)
+RETURNING *;
@@ -0,0 +1,18 @@
+-- name: CreatePushSubscription :one
+INSERT INTO push_subscriptions (id, created_at, updated_at, user_id, endpoint, p256dh, auth)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (endpoint) DO UPDATE
+SET updated_at = EXCLUDED.updated_at, user_id = EXCLUDED.user_id, p256dh = EXCLUDED.p256dh, auth = EXCLUDED.auth
+RETURNING *;
+
+-- name: DeletePushSubscription :exec
+DELETE FROM push_subscriptions WHERE id = $1 AND user_id = $2;
+
+-- name: DeletePushSubscriptionByEndpoint :exec
+DELETE FROM push_subscriptions WHERE endpoint = $1;
+
+-- name: GetHighPriorityPushSubscriptionsForFeed :many
+SELECT push_subscriptions.* FROM push_subscriptions
+INNER JOIN feed_follows
+ON push_subscriptions.user_id = feed_follows.user_id
+WHERE feed_follows.feed_id = $1 AND feed_follows.priority = 'high';
@@ -0,0 +1,24 @@
+-- name: EnqueuePost :one
+INSERT INTO reading_queue_items (id, created_at, user_id, post_id, position)
+VALUES ($1, $2, $3, $4, (
+  SELECT COALESCE(MAX(position), 0) + 1 FROM reading_queue_items WHERE user_id = $3
+))
+ON CONFLICT (user_id, post_id) DO UPDATE SET post_id = EXCLUDED.post_id
+RETURNING *;
+
+-- name: GetUserQueue :many
+SELECT reading_queue_items.position, posts.* FROM reading_queue_items
+INNER JOIN posts
+ON reading_queue_items.post_id = posts.id
+WHERE reading_queue_items.user_id = $1
+ORDER BY reading_queue_items.position;
+
+-- name: ReorderQueueItems :exec
+UPDATE reading_queue_items
+SET position = new_order.position
+FROM unnest(sqlc.arg('post_ids')::uuid[]) WITH ORDINALITY AS new_order(post_id, position)
+WHERE reading_queue_items.user_id = sqlc.arg('user_id')
+AND reading_queue_items.post_id = new_order.post_id;
+
+-- name: DeleteQueueItem :exec
+DELETE FROM reading_queue_items WHERE user_id = $1 AND post_id = $2;
@@ -0,0 +1,28 @@
+-- name: CreateStarterPack :one
+INSERT INTO starter_packs (id, created_at, updated_at, name, description)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListStarterPacks :many
+SELECT * FROM starter_packs ORDER BY name;
+
+-- name: GetStarterPack :one
+SELECT * FROM starter_packs WHERE id = $1;
+
+-- name: DeleteStarterPack :exec
+DELETE FROM starter_packs WHERE id = $1;
+
+-- name: AddStarterPackFeed :exec
+INSERT INTO starter_pack_feeds (starter_pack_id, feed_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveStarterPackFeed :exec
+DELETE FROM starter_pack_feeds WHERE starter_pack_id = $1 AND feed_id = $2;
+
+-- name: GetStarterPackFeeds :many
+SELECT feeds.* FROM feeds
+INNER JOIN starter_pack_feeds
+ON feeds.id = starter_pack_feeds.feed_id
+WHERE starter_pack_feeds.starter_pack_id = $1
+ORDER BY feeds.name;
@@ -0,0 +1,33 @@
+-- name: ListTableSizes :many
+SELECT relname::text AS table_name, n_live_tup::bigint AS row_count, pg_total_relation_size(relid)::bigint AS bytes
+FROM pg_stat_user_tables
+ORDER BY bytes DESC;
+
+-- name: ListFeedStorage :many
+SELECT f.id, f.name, f.url, f.user_id,
+  (SELECT COUNT(*) FROM posts p WHERE p.feed_id = f.id)::bigint AS post_count,
+  (SELECT COALESCE(SUM(octet_length(p.title) + octet_length(p.url) + COALESCE(octet_length(p.description), 0)), 0)
+    FROM posts p WHERE p.feed_id = f.id)::bigint AS post_bytes,
+  (SELECT COUNT(*) FROM fetch_snapshots s WHERE s.feed_id = f.id)::bigint AS snapshot_count,
+  (SELECT COALESCE(SUM(octet_length(s.body)), 0) FROM fetch_snapshots s WHERE s.feed_id = f.id)::bigint AS snapshot_bytes
+FROM feeds f
+ORDER BY post_bytes + snapshot_bytes DESC, f.id
+LIMIT $1;
+
+-- name: ListUserStorage :many
+SELECT u.id, u.name,
+  (SELECT COUNT(*) FROM feeds f WHERE f.user_id = u.id)::bigint AS feed_count,
+  (SELECT COUNT(*) FROM feed_follows ff WHERE ff.user_id = u.id)::bigint AS follow_count,
+  (SELECT COUNT(*) FROM reading_queue_items q WHERE q.user_id = u.id)::bigint AS saved_item_count,
+  (SELECT COUNT(*) FROM posts p JOIN feeds f ON f.id = p.feed_id WHERE f.user_id = u.id)::bigint AS post_count,
+  (SELECT COALESCE(SUM(octet_length(p.title) + octet_length(p.url) + COALESCE(octet_length(p.description), 0)), 0)
+    FROM posts p JOIN feeds f ON f.id = p.feed_id WHERE f.user_id = u.id)::bigint AS post_bytes,
+  (SELECT COALESCE(SUM(octet_length(s.body)), 0)
+    FROM fetch_snapshots s JOIN feeds f ON f.id = s.feed_id WHERE f.user_id = u.id)::bigint AS snapshot_bytes
+FROM users u
+ORDER BY post_bytes + snapshot_bytes DESC, u.id
+LIMIT $1;
+
+-- name: GetSnapshotStorage :one
+SELECT COUNT(*)::bigint AS snapshot_count, COALESCE(SUM(octet_length(body)), 0)::bigint AS bytes
+FROM fetch_snapshots;
@@ -0,0 +1,17 @@
+-- name: CreateUserIdentity :exec
+INSERT INTO user_identities (backend, subject, user_id, created_at)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetUserByIdentity :one
+SELECT users.* FROM users
+JOIN user_identities ON user_identities.user_id = users.id
+WHERE user_identities.backend = $1 AND user_identities.subject = $2;
+
+-- name: GetUnclaimedSCIMUser :one
+SELECT users.* FROM users
+JOIN user_identities ON user_identities.user_id = users.id
+WHERE user_identities.backend = 'scim' AND lower(users.name) = lower(sqlc.arg('name'))
+AND NOT EXISTS (
+  SELECT 1 FROM user_identities AS claimed
+  WHERE claimed.user_id = users.id AND claimed.backend = sqlc.arg('backend')
+);
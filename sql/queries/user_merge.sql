@@ -0,0 +1,57 @@
+-- name: MoveUserFeedFollows :exec
+UPDATE feed_follows SET user_id = sqlc.arg('target_id'), updated_at = sqlc.arg('updated_at')
+WHERE user_id = sqlc.arg('source_id')
+AND feed_id NOT IN (SELECT feed_id FROM feed_follows WHERE user_id = sqlc.arg('target_id'));
+
+-- name: MoveUserQueueItems :exec
+UPDATE reading_queue_items
+SET user_id = sqlc.arg('target_id'), position = position + (
+  SELECT COALESCE(MAX(position), 0) FROM reading_queue_items WHERE user_id = sqlc.arg('target_id')
+)
+WHERE user_id = sqlc.arg('source_id')
+AND post_id NOT IN (SELECT post_id FROM reading_queue_items WHERE user_id = sqlc.arg('target_id'));
+
+-- name: MoveUserPostSnoozes :exec
+UPDATE post_snoozes SET user_id = sqlc.arg('target_id')
+WHERE user_id = sqlc.arg('source_id')
+AND post_id NOT IN (SELECT post_id FROM post_snoozes WHERE user_id = sqlc.arg('target_id'));
+
+-- name: MoveUserAuthorMutes :exec
+UPDATE author_mutes SET user_id = sqlc.arg('target_id')
+WHERE user_id = sqlc.arg('source_id')
+AND lower(author) NOT IN (SELECT lower(author) FROM author_mutes WHERE user_id = sqlc.arg('target_id'));
+
+-- name: MoveUserNotificationChannels :exec
+UPDATE notification_channels SET user_id = sqlc.arg('target_id') WHERE user_id = sqlc.arg('source_id');
+
+-- name: MoveUserPostRules :exec
+UPDATE post_rules SET user_id = sqlc.arg('target_id') WHERE user_id = sqlc.arg('source_id');
+
+-- name: MoveUserPushSubscriptions :exec
+UPDATE push_subscriptions SET user_id = sqlc.arg('target_id') WHERE user_id = sqlc.arg('source_id');
+
+-- name: MoveUserPostEmails :exec
+UPDATE post_emails SET user_id = sqlc.arg('target_id') WHERE user_id = sqlc.arg('source_id');
+
+-- name: MoveUserFeeds :exec
+UPDATE feeds SET user_id = sqlc.arg('target_id') WHERE user_id = sqlc.arg('source_id') AND kind <> 'inbox';
+
+-- name: MoveUserIdentities :exec
+UPDATE user_identities SET user_id = sqlc.arg('target_id') WHERE user_id = sqlc.arg('source_id');
+
+-- name: MoveUserFeedRepublishes :exec
+UPDATE feed_republishes SET user_id = sqlc.arg('target_id'), updated_at = sqlc.arg('updated_at') WHERE user_id = sqlc.arg('source_id');
+
+-- name: MoveFeedPosts :exec
+UPDATE posts SET feed_id = sqlc.arg('target_id')
+WHERE feed_id = sqlc.arg('source_id')
+AND guid NOT IN (SELECT guid FROM posts WHERE feed_id = sqlc.arg('target_id'));
+
+-- name: MoveUserFeedReports :exec
+UPDATE feed_reports SET reporter_id = sqlc.arg('target_id') WHERE reporter_id = sqlc.arg('source_id');
+
+-- name: MoveUserReportResolutions :exec
+UPDATE feed_reports SET resolved_by = sqlc.arg('target_id')::uuid WHERE resolved_by = sqlc.arg('source_id')::uuid;
+
+-- name: MoveUserAuditLog :exec
+UPDATE audit_log SET actor_id = sqlc.arg('target_id')::uuid WHERE actor_id = sqlc.arg('source_id')::uuid;
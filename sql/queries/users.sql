@@ -5,3 +5,38 @@ RETURNING *;
 
 -- name: GetUserByApiKey :one
 SELECT * FROM users WHERE api_key = $1;
+
+-- name: UserNameExists :one
+SELECT EXISTS(SELECT 1 FROM users WHERE lower(name) = lower(sqlc.arg('name')));
+
+-- name: UpdateUserProfile :one
+UPDATE users SET name = $2, email = $3, avatar_url = $4, bio = $5, show_sensitive = $6, updated_at = $7, preferred_languages = $8
+WHERE id = $1
+RETURNING *;
+
+-- name: GetUser :one
+SELECT * FROM users WHERE id = $1;
+
+-- name: DeleteUser :exec
+DELETE FROM users WHERE id = $1;
+
+-- name: SetUserBanned :exec
+UPDATE users SET banned_at = $2, updated_at = $3 WHERE id = $1;
+
+-- name: SetUserAdmin :exec
+UPDATE users SET is_admin = $2, updated_at = $3 WHERE id = $1;
+
+-- name: GetUserByName :one
+SELECT * FROM users WHERE lower(name) = lower($1);
+
+-- name: ListAdmins :many
+SELECT * FROM users WHERE is_admin AND banned_at IS NULL ORDER BY created_at, id;
+
+-- name: ListUsers :many
+SELECT * FROM users ORDER BY created_at, id LIMIT $1 OFFSET $2;
+
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users;
+
+-- name: RotateUserApiKey :exec
+UPDATE users SET api_key = encode(sha256(random()::text::bytea), 'hex'), updated_at = $2 WHERE id = $1;
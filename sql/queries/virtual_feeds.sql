@@ -0,0 +1,37 @@
+-- name: CreateVirtualFeed :one
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, description, kind)
+VALUES ($1, $2, $3, $4, $5, $6, $7, 'virtual')
+RETURNING *;
+
+-- name: CreateVirtualFeedFilter :one
+INSERT INTO virtual_feed_filters (feed_id, dedupe, include_keywords, exclude_keywords)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: AddVirtualFeedSource :exec
+INSERT INTO virtual_feed_sources (virtual_feed_id, source_feed_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: GetUserVirtualFeeds :many
+SELECT * FROM feeds WHERE user_id = $1 AND kind = 'virtual' ORDER BY created_at;
+
+-- name: GetVirtualFeedFilter :one
+SELECT * FROM virtual_feed_filters WHERE feed_id = $1;
+
+-- name: GetVirtualFeedSourceIDs :many
+SELECT source_feed_id FROM virtual_feed_sources
+WHERE virtual_feed_id = $1
+ORDER BY source_feed_id;
+
+-- name: GetVirtualFeedsForSource :many
+SELECT virtual_feed_filters.* FROM virtual_feed_filters
+INNER JOIN virtual_feed_sources
+ON virtual_feed_filters.feed_id = virtual_feed_sources.virtual_feed_id
+WHERE virtual_feed_sources.source_feed_id = $1;
+
+-- name: CreateVirtualFeedPost :one
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, sensitive, guid, enclosure_url, enclosure_type, enclosure_length, source_post_id, author)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+ON CONFLICT (feed_id, guid) DO NOTHING
+RETURNING *;
@@ -0,0 +1,22 @@
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (id, channel_id, delivery_id, created_at, event, request_body, status_code, error, duration_ms, response_body, redelivery)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries WHERE id = $1 AND channel_id = $2;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE channel_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: PruneWebhookDeliveries :exec
+DELETE FROM webhook_deliveries
+WHERE channel_id = $1 AND id NOT IN (
+  SELECT id FROM webhook_deliveries AS kept
+  WHERE kept.channel_id = $1
+  ORDER BY kept.created_at DESC
+  LIMIT $2
+);
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE starter_packs (
+  id UUID NOT NULL PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+  name TEXT NOT NULL,
+  description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE starter_pack_feeds (
+  starter_pack_id UUID NOT NULL,
+  feed_id UUID NOT NULL,
+  PRIMARY KEY(starter_pack_id, feed_id),
+  FOREIGN KEY(starter_pack_id) REFERENCES starter_packs(id) ON DELETE CASCADE,
+  FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE starter_pack_feeds;
+DROP TABLE starter_packs;
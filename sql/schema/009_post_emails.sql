@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE post_emails (
+  id UUID NOT NULL PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  user_id UUID NOT NULL,
+  post_id UUID NOT NULL,
+  recipient TEXT NOT NULL,
+  FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+  FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE post_emails;
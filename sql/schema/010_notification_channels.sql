@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE notification_channels (
+  id UUID NOT NULL PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+  user_id UUID NOT NULL,
+  kind TEXT NOT NULL,
+  config JSONB NOT NULL,
+  FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE notification_channels;
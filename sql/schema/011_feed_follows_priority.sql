@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE feed_follows ADD COLUMN priority TEXT NOT NULL DEFAULT 'normal';
+
+-- +goose Down
+ALTER TABLE feed_follows DROP COLUMN priority;
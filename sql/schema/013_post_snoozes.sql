@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE post_snoozes (
+  id UUID NOT NULL PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  user_id UUID NOT NULL,
+  post_id UUID NOT NULL,
+  wake_at TIMESTAMP NOT NULL,
+  notify BOOLEAN NOT NULL DEFAULT false,
+  notified_at TIMESTAMP,
+  UNIQUE(user_id, post_id),
+  FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+  FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE post_snoozes;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE reading_queue_items (
+  id UUID NOT NULL PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  user_id UUID NOT NULL,
+  post_id UUID NOT NULL,
+  position INTEGER NOT NULL,
+  UNIQUE(user_id, post_id),
+  FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+  FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE reading_queue_items;
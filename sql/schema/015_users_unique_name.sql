@@ -0,0 +1,15 @@
+-- +goose Up
+-- Earlier versions allowed duplicate names, so suffix every duplicate after
+-- the oldest with a piece of its ID before enforcing uniqueness.
+UPDATE users SET name = name || '-' || left(id::text, 8)
+WHERE id IN (
+  SELECT id FROM (
+    SELECT id, row_number() OVER (PARTITION BY lower(name) ORDER BY created_at) AS n
+    FROM users
+  ) AS ranked
+  WHERE n > 1
+);
+CREATE UNIQUE INDEX users_name_key ON users (lower(name));
+
+-- +goose Down
+DROP INDEX users_name_key;
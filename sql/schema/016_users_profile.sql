@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN email TEXT;
+ALTER TABLE users ADD COLUMN avatar_url TEXT;
+ALTER TABLE users ADD COLUMN bio TEXT;
+CREATE UNIQUE INDEX users_email_key ON users (lower(email));
+
+-- +goose Down
+DROP INDEX users_email_key;
+ALTER TABLE users DROP COLUMN bio;
+ALTER TABLE users DROP COLUMN avatar_url;
+ALTER TABLE users DROP COLUMN email;
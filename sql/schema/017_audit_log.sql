@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE audit_log (
+  id UUID NOT NULL PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  actor_id UUID,
+  action TEXT NOT NULL,
+  target_type TEXT NOT NULL,
+  target_id UUID NOT NULL,
+  details JSONB NOT NULL DEFAULT '{}',
+  FOREIGN KEY(actor_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- +goose Down
+DROP TABLE audit_log;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE post_revisions (
+  id UUID NOT NULL PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  post_id UUID NOT NULL,
+  editor_id UUID,
+  title TEXT NOT NULL,
+  url TEXT NOT NULL,
+  FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE,
+  FOREIGN KEY(editor_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- +goose Down
+DROP TABLE post_revisions;
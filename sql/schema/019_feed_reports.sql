@@ -0,0 +1,24 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN disabled_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN banned_at TIMESTAMP;
+
+CREATE TABLE feed_reports (
+  id UUID NOT NULL PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+  feed_id UUID NOT NULL,
+  reporter_id UUID NOT NULL,
+  reason TEXT NOT NULL,
+  comment TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL DEFAULT 'open',
+  resolved_by UUID,
+  resolved_at TIMESTAMP,
+  FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE,
+  FOREIGN KEY(reporter_id) REFERENCES users(id) ON DELETE CASCADE,
+  FOREIGN KEY(resolved_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- +goose Down
+DROP TABLE feed_reports;
+ALTER TABLE users DROP COLUMN banned_at;
+ALTER TABLE feeds DROP COLUMN disabled_at;
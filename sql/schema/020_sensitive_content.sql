@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN sensitive BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE posts ADD COLUMN sensitive BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN show_sensitive BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN show_sensitive;
+ALTER TABLE posts DROP COLUMN sensitive;
+ALTER TABLE feeds DROP COLUMN sensitive;
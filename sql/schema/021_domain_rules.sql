@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE domain_rules (
+  id UUID NOT NULL PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  domain TEXT NOT NULL UNIQUE,
+  kind TEXT NOT NULL CHECK (kind IN ('allow', 'deny'))
+);
+
+-- +goose Down
+DROP TABLE domain_rules;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN kind TEXT NOT NULL DEFAULT 'remote';
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN kind;
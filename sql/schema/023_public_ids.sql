@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- gen_public_id generates a ULID: 48 bits of milliseconds since the epoch and
+-- 80 random bits, in Crockford base32.
+CREATE FUNCTION gen_public_id(ts TIMESTAMP) RETURNS TEXT AS $$
+DECLARE
+  alphabet TEXT := '0123456789ABCDEFGHJKMNPQRSTVWXYZ';
+  ms BIGINT := floor(extract(epoch FROM ts) * 1000);
+  id TEXT := '';
+BEGIN
+  FOR i IN 1..10 LOOP
+    id := substr(alphabet, (ms % 32)::INT + 1, 1) || id;
+    ms := ms / 32;
+  END LOOP;
+  FOR i IN 1..16 LOOP
+    id := id || substr(alphabet, floor(random() * 32)::INT + 1, 1);
+  END LOOP;
+  RETURN id;
+END;
+$$ LANGUAGE plpgsql VOLATILE;
+-- +goose StatementEnd
+
+ALTER TABLE feeds ADD COLUMN public_id TEXT;
+UPDATE feeds SET public_id = gen_public_id(created_at);
+ALTER TABLE feeds
+  ALTER COLUMN public_id SET DEFAULT gen_public_id(now()::TIMESTAMP),
+  ALTER COLUMN public_id SET NOT NULL,
+  ADD CONSTRAINT feeds_public_id_key UNIQUE (public_id);
+
+ALTER TABLE posts ADD COLUMN public_id TEXT;
+UPDATE posts SET public_id = gen_public_id(created_at);
+ALTER TABLE posts
+  ALTER COLUMN public_id SET DEFAULT gen_public_id(now()::TIMESTAMP),
+  ALTER COLUMN public_id SET NOT NULL,
+  ADD CONSTRAINT posts_public_id_key UNIQUE (public_id);
+
+-- +goose Down
+ALTER TABLE posts DROP COLUMN public_id;
+ALTER TABLE feeds DROP COLUMN public_id;
+DROP FUNCTION gen_public_id(TIMESTAMP);
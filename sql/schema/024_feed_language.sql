@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN language TEXT NOT NULL DEFAULT '';
+ALTER TABLE feeds ADD COLUMN country TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN preferred_languages TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN preferred_languages;
+ALTER TABLE feeds DROP COLUMN country;
+ALTER TABLE feeds DROP COLUMN language;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN missed_items_at TIMESTAMP;
+ALTER TABLE feeds ADD COLUMN gap_interval_seconds INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN gap_interval_seconds;
+ALTER TABLE feeds DROP COLUMN missed_items_at;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE fetch_snapshots (
+  id UUID NOT NULL PRIMARY KEY,
+  feed_id UUID NOT NULL,
+  fetched_at TIMESTAMP NOT NULL,
+  content_type TEXT NOT NULL,
+  size INTEGER NOT NULL,
+  truncated BOOLEAN NOT NULL,
+  body BYTEA NOT NULL,
+  FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE fetch_snapshots;
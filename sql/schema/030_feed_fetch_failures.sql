@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN fetch_failures INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE feeds ADD COLUMN last_fetch_error TEXT NOT NULL DEFAULT '';
+ALTER TABLE feeds ADD COLUMN retry_at TIMESTAMP;
+ALTER TABLE feeds ADD COLUMN paused_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN paused_at;
+ALTER TABLE feeds DROP COLUMN retry_at;
+ALTER TABLE feeds DROP COLUMN last_fetch_error;
+ALTER TABLE feeds DROP COLUMN fetch_failures;
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE feed_url_history (
+  id UUID NOT NULL PRIMARY KEY,
+  feed_id UUID NOT NULL,
+  old_url TEXT NOT NULL,
+  new_url TEXT NOT NULL,
+  changed_at TIMESTAMP NOT NULL,
+  FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+);
+CREATE INDEX feed_url_history_old_url_idx ON feed_url_history(old_url);
+
+-- +goose Down
+DROP TABLE feed_url_history;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Posts are identified within their feed by the item's GUID, or its link if
+-- it has none. Existing posts never stored a GUID, so they get their link.
+ALTER TABLE posts ADD COLUMN guid TEXT;
+UPDATE posts SET guid = url;
+ALTER TABLE posts
+  ALTER COLUMN guid SET NOT NULL,
+  ADD CONSTRAINT posts_feed_id_guid_key UNIQUE (feed_id, guid);
+
+-- +goose Down
+ALTER TABLE posts DROP COLUMN guid;
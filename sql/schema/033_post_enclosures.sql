@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE posts
+  ADD COLUMN enclosure_url TEXT NOT NULL DEFAULT '',
+  ADD COLUMN enclosure_type TEXT NOT NULL DEFAULT '',
+  ADD COLUMN enclosure_length BIGINT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE posts
+  DROP COLUMN enclosure_length,
+  DROP COLUMN enclosure_type,
+  DROP COLUMN enclosure_url;
@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE webhook_deliveries (
+  id UUID NOT NULL PRIMARY KEY,
+  channel_id UUID NOT NULL,
+  delivery_id UUID NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  event TEXT NOT NULL,
+  request_body TEXT NOT NULL,
+  status_code INTEGER NOT NULL,
+  error TEXT NOT NULL,
+  duration_ms INTEGER NOT NULL,
+  response_body TEXT NOT NULL,
+  redelivery BOOLEAN NOT NULL,
+  FOREIGN KEY(channel_id) REFERENCES notification_channels(id) ON DELETE CASCADE
+);
+CREATE INDEX webhook_deliveries_channel_id_created_at_idx ON webhook_deliveries(channel_id, created_at);
+
+-- +goose Down
+DROP TABLE webhook_deliveries;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN fetch_headers JSONB NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN fetch_headers;
@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE feed_icons (
+  feed_id UUID NOT NULL PRIMARY KEY,
+  source_url TEXT NOT NULL,
+  content_type TEXT NOT NULL,
+  data BYTEA NOT NULL,
+  fetched_at TIMESTAMP NOT NULL,
+  FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE feed_icons;
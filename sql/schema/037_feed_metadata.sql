@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE feeds ADD COLUMN site_url TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN site_url;
+ALTER TABLE feeds DROP COLUMN description;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE feed_republishes (
+  id UUID NOT NULL PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+  user_id UUID NOT NULL,
+  feed_id UUID NOT NULL,
+  token TEXT NOT NULL UNIQUE,
+  include_keywords TEXT[] NOT NULL DEFAULT '{}',
+  exclude_keywords TEXT[] NOT NULL DEFAULT '{}',
+  FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+  FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE feed_republishes;
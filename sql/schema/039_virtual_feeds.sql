@@ -0,0 +1,31 @@
+-- +goose Up
+CREATE TABLE virtual_feed_filters (
+  feed_id UUID NOT NULL PRIMARY KEY,
+  dedupe BOOLEAN NOT NULL DEFAULT false,
+  include_keywords TEXT[] NOT NULL DEFAULT '{}',
+  exclude_keywords TEXT[] NOT NULL DEFAULT '{}',
+  FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+);
+
+CREATE TABLE virtual_feed_sources (
+  virtual_feed_id UUID NOT NULL,
+  source_feed_id UUID NOT NULL,
+  PRIMARY KEY(virtual_feed_id, source_feed_id),
+  FOREIGN KEY(virtual_feed_id) REFERENCES feeds(id) ON DELETE CASCADE,
+  FOREIGN KEY(source_feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+);
+
+-- Posts copied into a virtual feed share their source's URL, so only
+-- original posts need a unique one.
+ALTER TABLE posts ADD COLUMN source_post_id UUID REFERENCES posts(id) ON DELETE CASCADE;
+ALTER TABLE posts DROP CONSTRAINT posts_url_key;
+CREATE UNIQUE INDEX posts_url_key ON posts (url) WHERE source_post_id IS NULL;
+
+-- +goose Down
+DELETE FROM posts WHERE source_post_id IS NOT NULL;
+DROP INDEX posts_url_key;
+ALTER TABLE posts ADD CONSTRAINT posts_url_key UNIQUE (url);
+ALTER TABLE posts DROP COLUMN source_post_id;
+
+DROP TABLE virtual_feed_sources;
+DROP TABLE virtual_feed_filters;
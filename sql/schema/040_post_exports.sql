@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE post_exports (
+  id UUID NOT NULL PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  destination TEXT NOT NULL,
+  format TEXT NOT NULL,
+  exported_after TIMESTAMP NOT NULL,
+  exported_until TIMESTAMP NOT NULL,
+  post_count INT NOT NULL,
+  location TEXT NOT NULL
+);
+
+CREATE INDEX post_exports_destination_idx ON post_exports (destination, exported_until);
+
+-- +goose Down
+DROP TABLE post_exports;
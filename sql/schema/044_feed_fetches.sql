@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE feed_fetches (
+  id UUID NOT NULL PRIMARY KEY,
+  feed_id UUID NOT NULL REFERENCES feeds (id) ON DELETE CASCADE,
+  fetched_at TIMESTAMP NOT NULL,
+  status_code INTEGER,
+  duration_ms INTEGER NOT NULL,
+  item_count INTEGER NOT NULL,
+  error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX feed_fetches_feed_id_idx ON feed_fetches (feed_id, fetched_at DESC);
+
+-- +goose Down
+DROP TABLE feed_fetches;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN last_fetch_status INTEGER;
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN last_fetch_status;
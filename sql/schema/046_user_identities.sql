@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE user_identities (
+  backend TEXT NOT NULL,
+  subject TEXT NOT NULL,
+  user_id UUID NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  PRIMARY KEY(backend, subject),
+  FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE user_identities;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN unfollowed_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN unfollowed_at;
@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN author TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE author_mutes (
+  id UUID NOT NULL PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  user_id UUID NOT NULL,
+  author TEXT NOT NULL,
+  FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX author_mutes_user_id_author_idx ON author_mutes (user_id, lower(author));
+
+-- +goose Down
+DROP TABLE author_mutes;
+
+ALTER TABLE posts DROP COLUMN author;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE post_rules (
+  id UUID NOT NULL PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  user_id UUID NOT NULL,
+  feed_id UUID,
+  action TEXT NOT NULL CHECK (action IN ('rewrite_title')),
+  pattern TEXT NOT NULL,
+  replacement TEXT NOT NULL DEFAULT '',
+  FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+  FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+);
+
+CREATE INDEX post_rules_user_id_idx ON post_rules (user_id);
+
+-- +goose Down
+DROP TABLE post_rules;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- A feed fetched with its owner's credentials is private to them, so
+-- another user adding the same URL gets a feed of their own.
+ALTER TABLE feeds DROP CONSTRAINT feeds_url_key;
+CREATE UNIQUE INDEX feeds_url_key ON feeds (url) WHERE credentials IS NULL;
+
+-- +goose Down
+DROP INDEX feeds_url_key;
+ALTER TABLE feeds ADD CONSTRAINT feeds_url_key UNIQUE (url);
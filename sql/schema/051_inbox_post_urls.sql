@@ -0,0 +1,18 @@
+-- +goose Up
+-- A link saved to an inbox is the user's own copy, so it neither stops a
+-- feed from ingesting a post at the same URL nor is stopped by one. Each
+-- inbox still holds a link once, by (feed_id, guid).
+ALTER TABLE posts ADD COLUMN inbox BOOLEAN NOT NULL DEFAULT false;
+UPDATE posts SET inbox = true WHERE feed_id IN (SELECT id FROM feeds WHERE kind = 'inbox');
+DROP INDEX posts_url_key;
+CREATE UNIQUE INDEX posts_url_key ON posts (url) WHERE source_post_id IS NULL AND NOT inbox;
+
+-- +goose Down
+DELETE FROM posts WHERE inbox AND id NOT IN (
+  SELECT DISTINCT ON (url) id FROM posts
+  WHERE source_post_id IS NULL
+  ORDER BY url, inbox, created_at
+);
+DROP INDEX posts_url_key;
+CREATE UNIQUE INDEX posts_url_key ON posts (url) WHERE source_post_id IS NULL;
+ALTER TABLE posts DROP COLUMN inbox;
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+var (
+	errUnsupportedScheme = errors.New("feed url must be http or https")
+	errPrivateAddress    = errors.New("feed url points to a private address")
+)
+
+// nonPublicPrefixes are ranges that are not reachable on the public
+// internet but are not caught by netip.Addr's own predicates.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// isPrivateAddr reports whether addr is one the server must not be made to
+// connect to on a user's behalf: loopback, private, link-local (which
+// includes cloud metadata services), multicast, or otherwise not public.
+func isPrivateAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() || addr.IsUnspecified() {
+		return true
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// newAllowPrivateAddressesFromEnv reads FETCH_ALLOW_PRIVATE_ADDRESSES, which
+// lets feeds on private addresses be added and fetched, for instances that
+// only aggregate feeds on their own network. It is off by default.
+func newAllowPrivateAddressesFromEnv() (bool, error) {
+	v := os.Getenv("FETCH_ALLOW_PRIVATE_ADDRESSES")
+	if v == "" {
+		return false, nil
+	}
+	allow, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("Invalid FETCH_ALLOW_PRIVATE_ADDRESSES")
+	}
+	return allow, nil
+}
+
+// checkFeedAddress rejects feed URLs that are not http or https or whose
+// host is, or resolves to, a private address. A host that does not resolve
+// yet is let through: the fetch client checks the address it connects to
+// in any case, which also covers hosts whose DNS changes after they are
+// added.
+func checkFeedAddress(ctx context.Context, rawURL string, allowPrivate bool) error {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Hostname() == "" {
+		return fmt.Errorf("invalid feed url %q", rawURL)
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return errUnsupportedScheme
+	}
+	if allowPrivate {
+		return nil
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errPrivateAddress
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if isPrivateAddr(addr) {
+			return errPrivateAddress
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if isPrivateAddr(addr) {
+			return errPrivateAddress
+		}
+	}
+	return nil
+}
+
+// denyPrivateDials is a net.Dialer Control function that refuses
+// connections to private addresses. It sees the address after DNS
+// resolution, so a public name pointed at a private address is refused
+// too.
+func denyPrivateDials(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if isPrivateAddr(addr) {
+		return fmt.Errorf("refusing to connect to private address %s", addr)
+	}
+	return nil
+}
+
+// guardedDialer dials through Guarded, which refuses private addresses,
+// except to the proxies in Proxies, given as host:port, which the operator
+// chose and which may well be on a private network.
+type guardedDialer struct {
+	Plain   *net.Dialer
+	Guarded *net.Dialer
+	Proxies map[string]bool
+}
+
+func newGuardedDialer(d *net.Dialer, proxies []*url.URL) guardedDialer {
+	guarded := *d
+	guarded.Control = denyPrivateDials
+	g := guardedDialer{Plain: d, Guarded: &guarded, Proxies: map[string]bool{}}
+	for _, p := range proxies {
+		if p == nil || p.Hostname() == "" {
+			continue
+		}
+		port := p.Port()
+		if port == "" {
+			switch p.Scheme {
+			case "https":
+				port = "443"
+			case "socks5", "socks5h":
+				port = "1080"
+			default:
+				port = "80"
+			}
+		}
+		g.Proxies[net.JoinHostPort(p.Hostname(), port)] = true
+	}
+	return g
+}
+
+func (g guardedDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if g.Proxies[address] {
+		return g.Plain.DialContext(ctx, network, address)
+	}
+	return g.Guarded.DialContext(ctx, network, address)
+}
+
+// proxiedAddressCheck refuses requests that would go through a proxy to a
+// private address. The guarded dialer only sees the proxy's address on
+// such requests, and the proxy resolves and connects to the target itself,
+// so the target is checked here instead, on every hop, since the client
+// calls RoundTrip again for each redirect. It cannot see what the proxy's
+// own DNS makes of the host, so a host that only the proxy can resolve is
+// let through, as checkFeedAddress lets through hosts that do not resolve.
+type proxiedAddressCheck struct {
+	next  http.RoundTripper
+	proxy func(*http.Request) (*url.URL, error)
+}
+
+func (t proxiedAddressCheck) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.proxy != nil {
+		if p, err := t.proxy(req); err == nil && p != nil {
+			if err := checkFeedAddress(req.Context(), req.URL.String(), false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return t.next.RoundTrip(req)
+}
+
+// envProxies returns the proxies named by HTTP_PROXY and HTTPS_PROXY, in
+// either case, which net/http uses when FETCH_PROXY is not set.
+func envProxies() []*url.URL {
+	var proxies []*url.URL
+	for _, name := range []string{"HTTP_PROXY", "http_proxy", "HTTPS_PROXY", "https_proxy"} {
+		v := os.Getenv(name)
+		if v == "" {
+			continue
+		}
+		u, err := url.Parse(v)
+		if err != nil || u.Host == "" {
+			// net/http also accepts a bare host:port.
+			u, err = url.Parse("http://" + v)
+		}
+		if err == nil {
+			proxies = append(proxies, u)
+		}
+	}
+	return proxies
+}
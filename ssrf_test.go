@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestProxiedFetchesRefusePrivateTargets(t *testing.T) {
+	var proxied atomic.Int32
+	// The proxy answers every request itself. A request for the public
+	// address is redirected to the metadata service.
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Add(1)
+		if r.URL.Host == "93.184.216.34" {
+			http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+	t.Setenv("FETCH_PROXY", proxy.URL)
+
+	client, err := newFetchClientFromEnv(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Get("http://127.0.0.1:9/feed")
+	if !errors.Is(err, errPrivateAddress) {
+		t.Errorf("private target: err = %v, want %v", err, errPrivateAddress)
+	}
+	if n := proxied.Load(); n != 0 {
+		t.Errorf("private target reached the proxy")
+	}
+	_, err = client.Get("http://93.184.216.34/feed")
+	if !errors.Is(err, errPrivateAddress) {
+		t.Errorf("redirect to a private target: err = %v, want %v", err, errPrivateAddress)
+	}
+	if n := proxied.Load(); n != 1 {
+		t.Errorf("proxy saw %d requests, want only the first hop", n)
+	}
+
+	client, err = newFetchClientFromEnv(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get("http://127.0.0.1:9/feed")
+	if err != nil {
+		t.Fatalf("private target with private addresses allowed: %v", err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// starterPackFeeds returns the feeds of a pack that anyone may follow.
+// Private and inbox feeds cannot be added to a pack, but one may have
+// become private since it was added.
+func starterPackFeeds(ctx context.Context, ac apiConfig, packID uuid.UUID) ([]database.Feed, error) {
+	feeds, err := ac.DB.GetStarterPackFeeds(ctx, packID)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(feeds, func(f database.Feed) bool {
+		return isPrivateFeed(f) || f.Kind == "inbox"
+	}), nil
+}
+
+// parseStarterPackFeed resolves a feed ID, UUID or public ID, given for a
+// starter pack, responding with an error if there is no such feed or it
+// cannot be in a pack.
+func parseStarterPackFeed(w http.ResponseWriter, r *http.Request, ac apiConfig, id string) (uuid.UUID, bool) {
+	feedID, err := parseFeedID(r.Context(), ac.DB, id)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid feed ID")
+		return uuid.Nil, false
+	}
+	feed, err := ac.DB.GetFeed(r.Context(), feedID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Feed not found")
+		return uuid.Nil, false
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve feed")
+		return uuid.Nil, false
+	}
+	if isPrivateFeed(feed) || feed.Kind == "inbox" {
+		respondWithError(w, http.StatusBadRequest, "Private and inbox feeds cannot be in a starter pack")
+		return uuid.Nil, false
+	}
+	return feed.ID, true
+}
+
+func handleStarterPacksGet(w http.ResponseWriter, r *http.Request, ac apiConfig) {
+	packs, err := ac.DB.ListStarterPacks(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve starter packs")
+		return
+	}
+	responses := make([]starterPackFeedsResponse, 0, len(packs))
+	for _, pack := range packs {
+		feeds, err := starterPackFeeds(r.Context(), ac, pack.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to retrieve starter packs")
+			return
+		}
+		responses = append(responses, starterPackFeedsResponse{newStarterPackResponse(pack), newFeedResponses(feeds)})
+	}
+	respondWithJSON(w, http.StatusOK, responses)
+}
+
+func handleStarterPackFollow(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	packID, err := uuid.Parse(chi.URLParam(r, "starterPackID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	_, err = ac.DB.GetStarterPack(r.Context(), packID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Starter pack not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve starter pack")
+		return
+	}
+	feeds, err := starterPackFeeds(r.Context(), ac, packID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve starter pack")
+		return
+	}
+	existing, err := ac.DB.GetUserFeedFollows(r.Context(), u.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve follows")
+		return
+	}
+	followed := make(map[uuid.UUID]bool, len(existing))
+	for _, follow := range existing {
+		followed[follow.FeedID] = true
+	}
+
+	// Feeds the user already follows are skipped so that following a pack
+	// twice, or two overlapping packs, never produces duplicate follows.
+	newFollows := []database.FeedFollow{}
+	for _, feed := range feeds {
+		if followed[feed.ID] {
+			continue
+		}
+		follow, err := ac.DB.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			UserID:    u.ID,
+			FeedID:    feed.ID,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to save feed follow")
+			return
+		}
+		newFollows = append(newFollows, follow)
+	}
+	respondWithJSON(w, http.StatusOK, newFeedFollowResponses(newFollows))
+}
+
+func handleAdminStarterPacksPost(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	type starterPackRequest struct {
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		FeedIDs     []string `json:"feed_ids"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := starterPackRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	if req.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "Name is required")
+		return
+	}
+	feedIDs := make([]uuid.UUID, 0, len(req.FeedIDs))
+	for _, id := range req.FeedIDs {
+		feedID, ok := parseStarterPackFeed(w, r, ac, id)
+		if !ok {
+			return
+		}
+		feedIDs = append(feedIDs, feedID)
+	}
+
+	pack, err := ac.DB.CreateStarterPack(r.Context(), database.CreateStarterPackParams{
+		ID:          uuid.New(),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save starter pack")
+		return
+	}
+	for _, feedID := range feedIDs {
+		err = ac.DB.AddStarterPackFeed(r.Context(), database.AddStarterPackFeedParams{
+			StarterPackID: pack.ID,
+			FeedID:        feedID,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to add feed to starter pack")
+			return
+		}
+	}
+	respondWithJSON(w, http.StatusCreated, newStarterPackResponse(pack))
+}
+
+func handleAdminStarterPacksDelete(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	packID, err := uuid.Parse(chi.URLParam(r, "starterPackID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	err = ac.DB.DeleteStarterPack(r.Context(), packID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Problem deleting starter pack")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func handleAdminStarterPackFeedsPost(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	packID, err := uuid.Parse(chi.URLParam(r, "starterPackID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	type starterPackFeedRequest struct {
+		FeedID string `json:"feed_id"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := starterPackFeedRequest{}
+	err = decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	_, err = ac.DB.GetStarterPack(r.Context(), packID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Starter pack not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve starter pack")
+		return
+	}
+	feedID, ok := parseStarterPackFeed(w, r, ac, req.FeedID)
+	if !ok {
+		return
+	}
+	err = ac.DB.AddStarterPackFeed(r.Context(), database.AddStarterPackFeedParams{
+		StarterPackID: packID,
+		FeedID:        feedID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to add feed to starter pack")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func handleAdminStarterPackFeedsDelete(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	packID, err := uuid.Parse(chi.URLParam(r, "starterPackID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	feedID, err := parseFeedID(r.Context(), ac.DB, chi.URLParam(r, "feedID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	err = ac.DB.RemoveStarterPackFeed(r.Context(), database.RemoveStarterPackFeedParams{
+		StarterPackID: packID,
+		FeedID:        feedID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Problem removing feed from starter pack")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
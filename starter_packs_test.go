@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+func TestAdminStarterPackFeeds(t *testing.T) {
+	ac, _ := newClockTestConfig()
+	ctx := context.Background()
+	admin, public := seedClockTestFeed(t, ac, true)
+	private, err := ac.DB.CreateFeed(ctx, database.CreateFeedParams{
+		ID:          uuid.New(),
+		CreatedAt:   clockTestStart,
+		UpdatedAt:   clockTestStart,
+		Name:        "Private feed",
+		Url:         "https://example.com/private/rss",
+		UserID:      admin.ID,
+		Credentials: []byte("sealed"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pack, err := ac.DB.CreateStarterPack(ctx, database.CreateStarterPackParams{
+		ID:        uuid.New(),
+		CreatedAt: clockTestStart,
+		UpdatedAt: clockTestStart,
+		Name:      "Starter",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		packID uuid.UUID
+		feedID string
+		want   int
+	}{
+		{"public ID", pack.ID, public.PublicID, http.StatusNoContent},
+		{"UUID", pack.ID, public.ID.String(), http.StatusNoContent},
+		{"private feed", pack.ID, private.ID.String(), http.StatusBadRequest},
+		{"unknown feed", pack.ID, uuid.NewString(), http.StatusNotFound},
+		{"unknown pack", uuid.New(), public.ID.String(), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"feed_id":"` + tt.feedID + `"}`
+			r := httptest.NewRequest(http.MethodPost, "/v1/admin/starter_packs/"+tt.packID.String()+"/feeds", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			handleAdminStarterPackFeedsPost(w, withURLParam(r, "starterPackID", tt.packID.String()), admin, ac)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+
+	// A feed that became private after it was added is left out.
+	err = ac.DB.AddStarterPackFeed(ctx, database.AddStarterPackFeedParams{StarterPackID: pack.ID, FeedID: private.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	handleStarterPacksGet(w, httptest.NewRequest(http.MethodGet, "/v1/starter_packs", nil), ac)
+	packs := []starterPackFeedsResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &packs); err != nil {
+		t.Fatal(err)
+	}
+	if len(packs) != 1 || len(packs[0].Feeds) != 1 || packs[0].Feeds[0].ID != public.ID {
+		t.Errorf("starter packs = %+v, want only the public feed", packs)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const defaultStorageLimit = 20
+
+// handleAdminStorageGet reports where the space goes: every table's size and
+// row count, the fetch snapshot blobs, and the feeds and users that account
+// for the most stored posts and snapshots. Post bytes count only the text
+// columns, so they undercount what Postgres uses but rank feeds fairly.
+func handleAdminStorageGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	limit := defaultStorageLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = parsed
+	}
+	tables, err := ac.DB.ListTableSizes(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve table sizes")
+		return
+	}
+	snapshots, err := ac.DB.GetSnapshotStorage(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve snapshot storage")
+		return
+	}
+	feeds, err := ac.DB.ListFeedStorage(r.Context(), int32(limit))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve feed storage")
+		return
+	}
+	users, err := ac.DB.ListUserStorage(r.Context(), int32(limit))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve user storage")
+		return
+	}
+
+	type tableStorage struct {
+		Table string `json:"table"`
+		Rows  int64  `json:"rows"`
+		Bytes int64  `json:"bytes"`
+	}
+	type blobStorage struct {
+		Snapshots int64 `json:"snapshots"`
+		Bytes     int64 `json:"bytes"`
+	}
+	type feedStorage struct {
+		FeedID        uuid.UUID `json:"feed_id"`
+		Name          string    `json:"name"`
+		Url           string    `json:"url"`
+		UserID        uuid.UUID `json:"user_id"`
+		Posts         int64     `json:"posts"`
+		PostBytes     int64     `json:"post_bytes"`
+		Snapshots     int64     `json:"snapshots"`
+		SnapshotBytes int64     `json:"snapshot_bytes"`
+	}
+	type userStorage struct {
+		UserID        uuid.UUID `json:"user_id"`
+		Name          string    `json:"name"`
+		Feeds         int64     `json:"feeds"`
+		Follows       int64     `json:"follows"`
+		SavedItems    int64     `json:"saved_items"`
+		Posts         int64     `json:"posts"`
+		PostBytes     int64     `json:"post_bytes"`
+		SnapshotBytes int64     `json:"snapshot_bytes"`
+	}
+	type response struct {
+		Tables    []tableStorage `json:"tables"`
+		BlobStore blobStorage    `json:"blob_store"`
+		Feeds     []feedStorage  `json:"feeds"`
+		Users     []userStorage  `json:"users"`
+	}
+	resp := response{
+		Tables:    make([]tableStorage, 0, len(tables)),
+		BlobStore: blobStorage{Snapshots: snapshots.SnapshotCount, Bytes: snapshots.Bytes},
+		Feeds:     make([]feedStorage, 0, len(feeds)),
+		Users:     make([]userStorage, 0, len(users)),
+	}
+	for _, t := range tables {
+		resp.Tables = append(resp.Tables, tableStorage{Table: t.TableName, Rows: t.RowCount, Bytes: t.Bytes})
+	}
+	for _, f := range feeds {
+		resp.Feeds = append(resp.Feeds, feedStorage{
+			FeedID:        f.ID,
+			Name:          f.Name,
+			Url:           f.Url,
+			UserID:        f.UserID,
+			Posts:         f.PostCount,
+			PostBytes:     f.PostBytes,
+			Snapshots:     f.SnapshotCount,
+			SnapshotBytes: f.SnapshotBytes,
+		})
+	}
+	for _, u := range users {
+		resp.Users = append(resp.Users, userStorage{
+			UserID:        u.ID,
+			Name:          u.Name,
+			Feeds:         u.FeedCount,
+			Follows:       u.FollowCount,
+			SavedItems:    u.SavedItemCount,
+			Posts:         u.PostCount,
+			PostBytes:     u.PostBytes,
+			SnapshotBytes: u.SnapshotBytes,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
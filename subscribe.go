@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// The quick-subscribe flow is two calls so that a bookmarklet or extension
+// can never subscribe a user by loading a single URL: GET /v1/subscribe
+// resolves the page to a feed and returns a short-lived confirmation token,
+// and POST /v1/subscribe redeems the token once the user has confirmed.
+
+const subscribeTokenTTL = 10 * time.Minute
+
+const maxDiscoveryBytes = 1 << 20
+
+var (
+	linkTagPattern  = regexp.MustCompile(`(?is)<link\s[^>]*>`)
+	linkAttrPattern = regexp.MustCompile(`(?is)([a-z-]+)\s*=\s*("[^"]*"|'[^']*'|[^\s>]+)`)
+)
+
+var errSubscribeTokenInvalid = errors.New("invalid or expired subscribe token")
+
+// newSubscribeKeyFromEnv reads the HMAC key for confirmation tokens. Without
+// SUBSCRIBE_TOKEN_SECRET a random key is generated, which only means tokens
+// do not survive a restart.
+func newSubscribeKeyFromEnv() []byte {
+	if secret := os.Getenv("SUBSCRIBE_TOKEN_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	key := make([]byte, 32)
+	rand.Read(key)
+	return key
+}
+
+type subscribeClaims struct {
+	UserID    uuid.UUID `json:"u"`
+	FeedURL   string    `json:"f"`
+	ExpiresAt int64     `json:"e"`
+}
+
+func signSubscribeToken(key []byte, claims subscribeClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+func verifySubscribeToken(key []byte, token string, userID uuid.UUID) (subscribeClaims, error) {
+	claims := subscribeClaims{}
+	encodedPayload, encodedSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return claims, errSubscribeTokenInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return claims, errSubscribeTokenInvalid
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil {
+		return claims, errSubscribeTokenInvalid
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return claims, errSubscribeTokenInvalid
+	}
+	err = json.Unmarshal(payload, &claims)
+	if err != nil || claims.UserID != userID || time.Now().Unix() > claims.ExpiresAt {
+		return claims, errSubscribeTokenInvalid
+	}
+	return claims, nil
+}
+
+// discoverFeed resolves a page URL to an RSS or Atom feed. The URL may already be a
+// feed; otherwise the page's <link rel="alternate"> tags are searched.
+func discoverFeed(ctx context.Context, ac apiConfig, pageURL *url.URL) (string, feedData, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL.String(), nil)
+	if err != nil {
+		return "", feedData{}, err
+	}
+	res, err := ac.HTTPClient.Do(req)
+	if err != nil {
+		return "", feedData{}, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxDiscoveryBytes))
+	if err != nil {
+		return "", feedData{}, err
+	}
+	if fd, err := parseFeed(feedBodyToUTF8(body, res.Header.Get("Content-Type"))); err == nil {
+		return pageURL.String(), fd, nil
+	}
+
+	for _, tag := range linkTagPattern.FindAllString(string(body), -1) {
+		attrs := map[string]string{}
+		for _, m := range linkAttrPattern.FindAllStringSubmatch(tag, -1) {
+			attrs[strings.ToLower(m[1])] = strings.Trim(m[2], `"'`)
+		}
+		if !strings.Contains(strings.ToLower(attrs["rel"]), "alternate") || !isFeedMediaType(attrs["type"]) {
+			continue
+		}
+		href, err := pageURL.Parse(attrs["href"])
+		if err != nil {
+			continue
+		}
+		fd, err := ac.FetchFeed(ctx, href.String(), cacheValidators{}, nil)
+		if err != nil {
+			return "", feedData{}, err
+		}
+		return href.String(), fd, nil
+	}
+	return "", feedData{}, fmt.Errorf("no feed found at %s", pageURL)
+}
+
+func handleSubscribeGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	pageURL, err := url.Parse(r.URL.Query().Get("url"))
+	if err != nil || (pageURL.Scheme != "http" && pageURL.Scheme != "https") || pageURL.Host == "" {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL")
+		return
+	}
+	rules, err := ac.DB.ListDomainRules(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to check feed domain")
+		return
+	}
+	if err := checkFeedDomain(rules, pageURL.String()); err != nil {
+		respondWithError(w, http.StatusForbidden, "Feed domain is not allowed on this instance")
+		return
+	}
+	if err := checkFeedAddress(r.Context(), pageURL.String(), ac.AllowPrivate); err != nil {
+		respondWithError(w, http.StatusForbidden, "URL points to a private address")
+		return
+	}
+
+	type subscribeResponse struct {
+		FeedURL   string     `json:"feed_url"`
+		Title     string     `json:"title"`
+		FeedID    *uuid.UUID `json:"feed_id"`
+		Following bool       `json:"following"`
+		Token     string     `json:"token"`
+		ExpiresAt time.Time  `json:"expires_at"`
+	}
+	resp := subscribeResponse{}
+	feed, err := getFeedByURL(r.Context(), ac.DB, pageURL.String())
+	if err == nil {
+		resp.FeedURL = feed.Url
+		resp.Title = feed.Name
+	} else if errors.Is(err, sql.ErrNoRows) {
+		feedURL, fd, err := discoverFeed(r.Context(), ac, pageURL)
+		if err != nil {
+			respondWithError(w, http.StatusUnprocessableEntity, "No feed found at that URL")
+			return
+		}
+		if err := checkFeedDomain(rules, feedURL); err != nil {
+			respondWithError(w, http.StatusForbidden, "Feed domain is not allowed on this instance")
+			return
+		}
+		if err := checkFeedAddress(r.Context(), feedURL, ac.AllowPrivate); err != nil {
+			respondWithError(w, http.StatusForbidden, "Feed URL points to a private address")
+			return
+		}
+		resp.FeedURL = feedURL
+		resp.Title = strings.TrimSpace(fd.Channel.Title)
+		if resp.Title == "" {
+			resp.Title = pageURL.Host
+		}
+		feed, err = getFeedByURL(r.Context(), ac.DB, feedURL)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusInternalServerError, "Unable to retrieve feed")
+			return
+		}
+	} else {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve feed")
+		return
+	}
+
+	if feed.ID != uuid.Nil {
+		resp.FeedID = &feed.ID
+		resp.Title = feed.Name
+		follows, err := ac.DB.GetUserFeedFollows(r.Context(), u.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to retrieve follows")
+			return
+		}
+		for _, follow := range follows {
+			resp.Following = resp.Following || follow.FeedID == feed.ID
+		}
+	}
+	resp.ExpiresAt = time.Now().Add(subscribeTokenTTL)
+	resp.Token, err = signSubscribeToken(ac.SubscribeKey, subscribeClaims{
+		UserID:    u.ID,
+		FeedURL:   resp.FeedURL,
+		ExpiresAt: resp.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to create subscribe token")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func handleSubscribePost(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	type subscribeRequest struct {
+		Token string `json:"token"`
+		Name  string `json:"name"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := subscribeRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	claims, err := verifySubscribeToken(ac.SubscribeKey, req.Token, u.ID)
+	if err != nil {
+		respondWithError(w, http.StatusForbidden, "Invalid or expired subscribe token")
+		return
+	}
+	rules, err := ac.DB.ListDomainRules(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to check feed domain")
+		return
+	}
+	if err := checkFeedDomain(rules, claims.FeedURL); err != nil {
+		respondWithError(w, http.StatusForbidden, "Feed domain is not allowed on this instance")
+		return
+	}
+
+	tx, err := ac.DB.Begin(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to subscribe")
+		return
+	}
+	defer tx.Rollback()
+
+	feed, err := getFeedByURL(r.Context(), tx, claims.FeedURL)
+	if errors.Is(err, sql.ErrNoRows) {
+		name := strings.TrimSpace(req.Name)
+		if name == "" {
+			name = claims.FeedURL
+		}
+		feed, err = tx.CreateFeed(r.Context(), database.CreateFeedParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			Name:      name,
+			Url:       normalizeFeedURL(claims.FeedURL),
+			UserID:    u.ID,
+		})
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save feed")
+		return
+	}
+	follows, err := tx.GetUserFeedFollows(r.Context(), u.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve follows")
+		return
+	}
+	for _, follow := range follows {
+		if follow.FeedID == feed.ID {
+			respondWithJSON(w, http.StatusOK, newFeedFollowResponse(follow))
+			return
+		}
+	}
+	follow, err := tx.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    u.ID,
+		FeedID:    feed.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save feed follow")
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to subscribe")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, newFeedFollowResponse(follow))
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/xml"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Listing endpoints answer in JSON unless the Accept header prefers a feed
+// format, so RSS-only tools can subscribe to them directly.
+const (
+	formatJSON = "json"
+	formatRSS  = "rss"
+	formatAtom = "atom"
+)
+
+var acceptFormats = map[string]string{
+	"application/json":     formatJSON,
+	"application/*":        formatJSON,
+	"*/*":                  formatJSON,
+	"application/rss+xml":  formatRSS,
+	"application/xml":      formatRSS,
+	"text/xml":             formatRSS,
+	"application/atom+xml": formatAtom,
+}
+
+// negotiateFormat picks the response format the Accept header ranks
+// highest, taking the first listed on a tie. Without an Accept header, or
+// with nothing we can produce in it, the answer is JSON.
+func negotiateFormat(r *http.Request) string {
+	best, bestQ := formatJSON, -1.0
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		format, ok := acceptFormats[mediaType]
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+		}
+		if q > 0 && q > bestQ {
+			best, bestQ = format, q
+		}
+	}
+	return best
+}
+
+// syndicationFeed is a listing to be rendered as RSS or Atom.
+type syndicationFeed struct {
+	Title       string
+	Link        string
+	Description string
+	Updated     time.Time
+	Items       []syndicationItem
+}
+
+type syndicationItem struct {
+	ID          uuid.UUID
+	Title       string
+	Link        string
+	Description string
+	Published   time.Time
+	Updated     time.Time
+	Enclosure   *enclosureResponse
+}
+
+type rssOutput struct {
+	XMLName xml.Name `xml:"rss"`
+	Version string   `xml:"version,attr"`
+	Channel struct {
+		Title         string          `xml:"title"`
+		Link          string          `xml:"link"`
+		Description   string          `xml:"description"`
+		LastBuildDate string          `xml:"lastBuildDate"`
+		Items         []rssOutputItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rssOutputItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description,omitempty"`
+	GUID        struct {
+		IsPermaLink string `xml:"isPermaLink,attr"`
+		Value       string `xml:",chardata"`
+	} `xml:"guid"`
+	PubDate   string `xml:"pubDate,omitempty"`
+	Enclosure *struct {
+		URL    string `xml:"url,attr"`
+		Type   string `xml:"type,attr"`
+		Length int64  `xml:"length,attr"`
+	} `xml:"enclosure"`
+}
+
+type atomOutput struct {
+	XMLName xml.Name          `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string            `xml:"title"`
+	ID      string            `xml:"id"`
+	Updated string            `xml:"updated"`
+	Links   []atomOutputLink  `xml:"link"`
+	Entries []atomOutputEntry `xml:"entry"`
+}
+
+type atomOutputEntry struct {
+	Title     string           `xml:"title"`
+	ID        string           `xml:"id"`
+	Updated   string           `xml:"updated"`
+	Published string           `xml:"published,omitempty"`
+	Links     []atomOutputLink `xml:"link"`
+	Summary   *struct {
+		Type  string `xml:"type,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"summary"`
+}
+
+type atomOutputLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length int64  `xml:"length,attr,omitempty"`
+}
+
+func newRSSOutput(f syndicationFeed) rssOutput {
+	out := rssOutput{Version: "2.0"}
+	out.Channel.Title = f.Title
+	out.Channel.Link = f.Link
+	out.Channel.Description = f.Description
+	out.Channel.LastBuildDate = f.Updated.UTC().Format(time.RFC1123Z)
+	out.Channel.Items = make([]rssOutputItem, 0, len(f.Items))
+	for _, it := range f.Items {
+		item := rssOutputItem{Title: it.Title, Link: it.Link, Description: it.Description}
+		item.GUID.IsPermaLink = "false"
+		item.GUID.Value = "urn:uuid:" + it.ID.String()
+		if !it.Published.IsZero() {
+			item.PubDate = it.Published.UTC().Format(time.RFC1123Z)
+		}
+		if it.Enclosure != nil {
+			item.Enclosure = &struct {
+				URL    string `xml:"url,attr"`
+				Type   string `xml:"type,attr"`
+				Length int64  `xml:"length,attr"`
+			}{URL: it.Enclosure.Url, Type: it.Enclosure.Type, Length: it.Enclosure.Length}
+		}
+		out.Channel.Items = append(out.Channel.Items, item)
+	}
+	return out
+}
+
+func newAtomOutput(f syndicationFeed) atomOutput {
+	out := atomOutput{
+		Title:   f.Title,
+		ID:      f.Link,
+		Updated: f.Updated.UTC().Format(time.RFC3339),
+		Links:   []atomOutputLink{{Href: f.Link, Rel: "self"}},
+		Entries: make([]atomOutputEntry, 0, len(f.Items)),
+	}
+	for _, it := range f.Items {
+		entry := atomOutputEntry{
+			Title:   it.Title,
+			ID:      "urn:uuid:" + it.ID.String(),
+			Updated: it.Updated.UTC().Format(time.RFC3339),
+			Links:   []atomOutputLink{{Href: it.Link, Rel: "alternate"}},
+		}
+		if !it.Published.IsZero() {
+			entry.Published = it.Published.UTC().Format(time.RFC3339)
+		}
+		if it.Description != "" {
+			entry.Summary = &struct {
+				Type  string `xml:"type,attr"`
+				Value string `xml:",chardata"`
+			}{Type: "html", Value: it.Description}
+		}
+		if it.Enclosure != nil {
+			entry.Links = append(entry.Links, atomOutputLink{
+				Href:   it.Enclosure.Url,
+				Rel:    "enclosure",
+				Type:   it.Enclosure.Type,
+				Length: it.Enclosure.Length,
+			})
+		}
+		out.Entries = append(out.Entries, entry)
+	}
+	return out
+}
+
+// respondWithFeed writes f as RSS 2.0 or Atom, whichever format names.
+func respondWithFeed(w http.ResponseWriter, format string, f syndicationFeed) {
+	var payload any = newRSSOutput(f)
+	contentType := "application/rss+xml; charset=utf-8"
+	if format == formatAtom {
+		payload = newAtomOutput(f)
+		contentType = "application/atom+xml; charset=utf-8"
+	}
+	data, err := xml.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error writing XML")
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(xml.Header))
+	w.Write(data)
+}
+
+// requestURL rebuilds the absolute URL a request was made to, for a
+// rendered feed's self link.
+func requestURL(r *http.Request) string {
+	return requestOrigin(r) + r.URL.RequestURI()
+}
+
+// requestOrigin is the scheme and host a request was made to, for building
+// links to other endpoints.
+func requestOrigin(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// postSyndicationItem renders a post as a feed item. A blurred post keeps
+// its title and link but not its description.
+func postSyndicationItem(p postResponse, blurred bool) syndicationItem {
+	item := syndicationItem{
+		ID:        p.ID,
+		Title:     p.Title,
+		Link:      p.Url,
+		Updated:   p.UpdatedAt,
+		Enclosure: p.Enclosure,
+	}
+	if p.Description != nil && !blurred {
+		item.Description = *p.Description
+	}
+	if p.PublishedAt != nil {
+		item.Published = *p.PublishedAt
+	}
+	return item
+}
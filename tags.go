@@ -0,0 +1,137 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"html"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const (
+	suggestedTagCount = 5
+	tagCorpusSize     = 500
+)
+
+var stopWords = map[string]bool{
+	"a": true, "about": true, "after": true, "all": true, "also": true, "an": true,
+	"and": true, "any": true, "are": true, "as": true, "at": true, "be": true,
+	"because": true, "been": true, "but": true, "by": true, "can": true, "could": true,
+	"did": true, "do": true, "does": true, "for": true, "from": true, "get": true,
+	"had": true, "has": true, "have": true, "he": true, "her": true, "his": true,
+	"how": true, "i": true, "if": true, "in": true, "into": true, "is": true,
+	"it": true, "its": true, "just": true, "like": true, "more": true, "most": true,
+	"my": true, "new": true, "no": true, "not": true, "now": true, "of": true,
+	"on": true, "one": true, "only": true, "or": true, "other": true, "our": true,
+	"out": true, "over": true, "read": true, "she": true, "so": true, "some": true,
+	"than": true, "that": true, "the": true, "their": true, "them": true, "then": true,
+	"there": true, "these": true, "they": true, "this": true, "to": true, "up": true,
+	"us": true, "use": true, "using": true, "was": true, "we": true, "were": true,
+	"what": true, "when": true, "which": true, "who": true, "will": true, "with": true,
+	"would": true, "you": true, "your": true,
+}
+
+// tagTerms splits post text into lowercase candidate terms, dropping markup,
+// stop words, numbers, and very short tokens.
+func tagTerms(s string) []string {
+	text := html.UnescapeString(htmlTagPattern.ReplaceAllString(s, " "))
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	})
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		w = strings.Trim(w, "-")
+		if len([]rune(w)) < 3 || stopWords[w] || strings.IndexFunc(w, unicode.IsLetter) == -1 {
+			continue
+		}
+		terms = append(terms, w)
+	}
+	return terms
+}
+
+// suggestTags ranks the terms of doc by TF-IDF against corpus and returns the
+// top n. Terms that appear in nearly every document of the corpus (feed
+// boilerplate, the blog's own name) score low and drop out naturally.
+func suggestTags(doc string, corpus []string, n int) []string {
+	tf := map[string]int{}
+	for _, term := range tagTerms(doc) {
+		tf[term]++
+	}
+	df := map[string]int{}
+	for _, other := range corpus {
+		seen := map[string]bool{}
+		for _, term := range tagTerms(other) {
+			if tf[term] > 0 && !seen[term] {
+				seen[term] = true
+				df[term]++
+			}
+		}
+	}
+
+	type scoredTerm struct {
+		term  string
+		score float64
+	}
+	scored := make([]scoredTerm, 0, len(tf))
+	for term, count := range tf {
+		idf := math.Log(float64(len(corpus)+1) / float64(df[term]+1))
+		scored = append(scored, scoredTerm{term, float64(count) * (idf + 1)})
+	}
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].score != scored[j].score {
+			return scored[i].score > scored[j].score
+		}
+		return scored[i].term < scored[j].term
+	})
+
+	tags := make([]string, 0, n)
+	for _, s := range scored {
+		if len(tags) == n {
+			break
+		}
+		tags = append(tags, s.term)
+	}
+	return tags
+}
+
+func postText(title string, description sql.NullString) string {
+	return title + " " + description.String
+}
+
+func handlePostSuggestedTags(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	postID, err := parsePostID(r.Context(), ac.DB, chi.URLParam(r, "postID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	post, err := visiblePost(r.Context(), ac, u, postID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Post not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve post")
+		return
+	}
+	recent, err := ac.DB.GetRecentPostsByUser(r.Context(), database.GetRecentPostsByUserParams{
+		UserID: u.ID,
+		Limit:  tagCorpusSize,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "There was a problem getting the user's posts")
+		return
+	}
+	corpus := make([]string, 0, len(recent))
+	for _, p := range recent {
+		corpus = append(corpus, postText(p.Title, p.Description))
+	}
+	respondWithJSON(w, http.StatusOK, map[string][]string{
+		"tags": suggestTags(postText(post.Title, post.Description), corpus, suggestedTagCount),
+	})
+}
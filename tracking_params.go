@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+)
+
+// trackingParams are query parameters that only identify where a click
+// came from. Parameters starting with utm_ are tracking parameters too.
+var trackingParams = map[string]bool{
+	"fbclid":      true,
+	"gclid":       true,
+	"dclid":       true,
+	"gbraid":      true,
+	"wbraid":      true,
+	"msclkid":     true,
+	"yclid":       true,
+	"igshid":      true,
+	"mc_cid":      true,
+	"mc_eid":      true,
+	"_hsenc":      true,
+	"_hsmi":       true,
+	"mkt_tok":     true,
+	"vero_id":     true,
+	"oly_anon_id": true,
+	"oly_enc_id":  true,
+}
+
+func isTrackingParam(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasPrefix(name, "utm_") || trackingParams[name]
+}
+
+// stripTrackingParams removes tracking parameters from a post's link, so
+// that the same post linked from different campaigns is stored once and
+// readers who open it don't pass the tracking on. The other parameters are
+// kept as they were, in their order. Links that do not parse are returned
+// as they are.
+func stripTrackingParams(link string) string {
+	link = strings.TrimSpace(link)
+	u, err := url.Parse(link)
+	if err != nil || u.RawQuery == "" {
+		return link
+	}
+	kept := []string{}
+	for _, param := range strings.Split(u.RawQuery, "&") {
+		name, _, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if param != "" && !isTrackingParam(name) {
+			kept = append(kept, param)
+		}
+	}
+	u.RawQuery = strings.Join(kept, "&")
+	u.ForceQuery = false
+	return u.String()
+}
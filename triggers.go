@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// triggerPost is the item shape served to polling automation platforms such
+// as Zapier and IFTTT. They deduplicate on "id" and expect the newest items
+// first, so both the fields and their order here are part of the contract.
+type triggerPost struct {
+	ID          uuid.UUID  `json:"id"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Description string     `json:"description"`
+	PublishedAt *time.Time `json:"published_at"`
+	FeedID      uuid.UUID  `json:"feed_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+const triggerPageSize = 50
+
+func handleTriggerNewPosts(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	params := database.GetRecentPostsByUserParams{
+		UserID: u.ID,
+		Limit:  triggerPageSize,
+	}
+	if feedID := r.URL.Query().Get("feed_id"); feedID != "" {
+		id, err := parseFeedID(r.Context(), ac.DB, feedID)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid feed ID")
+			return
+		}
+		params.FeedID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+	posts, err := ac.DB.GetRecentPostsByUser(r.Context(), params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "There was a problem getting the user's posts")
+		return
+	}
+	items := make([]triggerPost, 0, len(posts))
+	for _, post := range posts {
+		item := triggerPost{
+			ID:          post.ID,
+			Title:       post.Title,
+			URL:         post.Url,
+			Description: post.Description.String,
+			FeedID:      post.FeedID,
+			CreatedAt:   post.CreatedAt,
+			PublishedAt: nullTimePtr(post.PublishedAt),
+		}
+		items = append(items, item)
+	}
+	respondWithJSON(w, http.StatusOK, items)
+}
+
+// handleTriggerNewPostsSample serves a fixed example item so that users can
+// map fields while setting up an automation before any real posts exist.
+func handleTriggerNewPostsSample(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	publishedAt := time.Date(2024, time.January, 15, 9, 30, 0, 0, time.UTC)
+	respondWithJSON(w, http.StatusOK, []triggerPost{
+		{
+			ID:          uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+			Title:       "Example post title",
+			URL:         "https://example.com/blog/example-post",
+			Description: "A short excerpt of the example post.",
+			PublishedAt: &publishedAt,
+			FeedID:      uuid.MustParse("00000000-0000-0000-0000-000000000002"),
+			CreatedAt:   publishedAt.Add(5 * time.Minute),
+		},
+	})
+}
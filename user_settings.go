@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// userSettingsVersion is the version of the settings document. Imports of
+// other versions are refused rather than half understood.
+const userSettingsVersion = 1
+
+// userSettings is a user's settings as a document that can be exported
+// from one instance and imported into another: their preferences, their
+// post rules and the authors they have muted. Rules for one feed name it by
+// URL, since feed IDs differ between instances.
+type userSettings struct {
+	Version      int                `json:"version"`
+	ExportedAt   time.Time          `json:"exported_at"`
+	Preferences  userPreferences    `json:"preferences"`
+	Rules        []userSettingsRule `json:"rules"`
+	MutedAuthors []string           `json:"muted_authors"`
+}
+
+type userPreferences struct {
+	ShowSensitive      bool     `json:"show_sensitive"`
+	PreferredLanguages []string `json:"preferred_languages"`
+}
+
+type userSettingsRule struct {
+	FeedUrl     string `json:"feed_url,omitempty"`
+	Action      string `json:"action"`
+	Pattern     string `json:"pattern"`
+	Replacement string `json:"replacement"`
+}
+
+func handleUserSettingsExport(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	settings := userSettings{
+		Version:    userSettingsVersion,
+		ExportedAt: ac.Clock.Now(),
+		Preferences: userPreferences{
+			ShowSensitive:      u.ShowSensitive,
+			PreferredLanguages: u.PreferredLanguages,
+		},
+		Rules:        []userSettingsRule{},
+		MutedAuthors: []string{},
+	}
+	if settings.Preferences.PreferredLanguages == nil {
+		settings.Preferences.PreferredLanguages = []string{}
+	}
+	rules, err := ac.DB.GetUserPostRules(r.Context(), u.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve rules")
+		return
+	}
+	for _, rule := range rules {
+		item := userSettingsRule{Action: rule.Action, Pattern: rule.Pattern, Replacement: rule.Replacement}
+		if rule.FeedID.Valid {
+			feed, err := ac.DB.GetFeed(r.Context(), rule.FeedID.UUID)
+			if err != nil {
+				respondWithError(w, http.StatusInternalServerError, "Unable to retrieve feed")
+				return
+			}
+			item.FeedUrl = feed.Url
+		}
+		settings.Rules = append(settings.Rules, item)
+	}
+	mutes, err := ac.DB.GetUserAuthorMutes(r.Context(), u.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve muted authors")
+		return
+	}
+	for _, m := range mutes {
+		settings.MutedAuthors = append(settings.MutedAuthors, m.Author)
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="settings.json"`)
+	respondWithJSON(w, http.StatusOK, settings)
+}
+
+// importSkip is a rule or muted author an import left out, and why.
+type importSkip struct {
+	Item   string `json:"item"`
+	Reason string `json:"reason"`
+}
+
+type userSettingsImportResponse struct {
+	User                 userResponse `json:"user"`
+	RulesImported        int          `json:"rules_imported"`
+	MutedAuthorsImported int          `json:"muted_authors_imported"`
+	Skipped              []importSkip `json:"skipped"`
+}
+
+// handleUserSettingsImport applies an exported settings document to the
+// user. Preferences are replaced; rules and muted authors are added to the
+// user's own, leaving out those the user already has. A rule for a feed
+// this instance does not know is left out too, as is anything over the
+// limits, and the response says what was left out and why. Everything is
+// applied in one transaction, so a failed import changes nothing.
+func handleUserSettingsImport(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	settings := userSettings{}
+	err := decoder.Decode(&settings)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	if settings.Version != userSettingsVersion {
+		respondWithError(w, http.StatusBadRequest, "Unsupported settings version")
+		return
+	}
+	languages, err := normalizeLanguages(settings.Preferences.PreferredLanguages)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid language code")
+		return
+	}
+
+	ctx := r.Context()
+	tx, err := ac.DB.Begin(ctx)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to import settings")
+		return
+	}
+	defer tx.Rollback()
+
+	updated, err := tx.UpdateUserProfile(ctx, database.UpdateUserProfileParams{
+		ID:                 u.ID,
+		Name:               u.Name,
+		Email:              u.Email,
+		AvatarUrl:          u.AvatarUrl,
+		Bio:                u.Bio,
+		ShowSensitive:      settings.Preferences.ShowSensitive,
+		UpdatedAt:          time.Now(),
+		PreferredLanguages: languages,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to update user")
+		return
+	}
+	resp := userSettingsImportResponse{Skipped: []importSkip{}}
+	resp.RulesImported, err = importPostRules(ctx, ac, tx, u.ID, settings.Rules, &resp.Skipped)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to import rules")
+		return
+	}
+	resp.MutedAuthorsImported, err = importAuthorMutes(ctx, ac, tx, u.ID, settings.MutedAuthors, &resp.Skipped)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to import muted authors")
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to import settings")
+		return
+	}
+	resp.User = newUserResponse(updated)
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func importPostRules(ctx context.Context, ac apiConfig, tx database.Tx, userID uuid.UUID, rules []userSettingsRule, skipped *[]importSkip) (int, error) {
+	existing, err := tx.GetUserPostRules(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	imported := 0
+	for _, rule := range rules {
+		item := rule.Action + " " + rule.Pattern
+		if _, err := compilePostRule(rule.Action, rule.Pattern); err != nil {
+			*skipped = append(*skipped, importSkip{Item: item, Reason: err.Error()})
+			continue
+		}
+		params := database.CreatePostRuleParams{
+			ID:          uuid.New(),
+			CreatedAt:   ac.Clock.Now(),
+			UserID:      userID,
+			Action:      rule.Action,
+			Pattern:     rule.Pattern,
+			Replacement: rule.Replacement,
+		}
+		if rule.FeedUrl != "" {
+			feed, err := tx.GetFeedByUrl(ctx, rule.FeedUrl)
+			if errors.Is(err, sql.ErrNoRows) {
+				*skipped = append(*skipped, importSkip{Item: item, Reason: "No feed with URL " + rule.FeedUrl})
+				continue
+			}
+			if err != nil {
+				return imported, err
+			}
+			params.FeedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+		}
+		duplicate := false
+		for _, e := range existing {
+			if e.FeedID == params.FeedID && e.Action == params.Action && e.Pattern == params.Pattern && e.Replacement == params.Replacement {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			*skipped = append(*skipped, importSkip{Item: item, Reason: "Rule already exists"})
+			continue
+		}
+		if len(existing) >= maxPostRules {
+			*skipped = append(*skipped, importSkip{Item: item, Reason: "Too many rules"})
+			continue
+		}
+		created, err := tx.CreatePostRule(ctx, params)
+		if err != nil {
+			return imported, err
+		}
+		existing = append(existing, created)
+		imported++
+	}
+	return imported, nil
+}
+
+// importAuthorMutes checks for mutes the user already has before creating
+// any, since a unique violation would abort the transaction.
+func importAuthorMutes(ctx context.Context, ac apiConfig, tx database.Tx, userID uuid.UUID, authors []string, skipped *[]importSkip) (int, error) {
+	mutes, err := tx.GetUserAuthorMutes(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	muted := map[string]bool{}
+	for _, m := range mutes {
+		muted[strings.ToLower(m.Author)] = true
+	}
+	imported := 0
+	for _, raw := range authors {
+		author := normalizeAuthor(raw)
+		switch {
+		case author == "":
+			*skipped = append(*skipped, importSkip{Item: raw, Reason: "Author is required"})
+			continue
+		case len(author) > maxAuthorLength:
+			*skipped = append(*skipped, importSkip{Item: author, Reason: "Author is too long"})
+			continue
+		case muted[strings.ToLower(author)]:
+			*skipped = append(*skipped, importSkip{Item: author, Reason: "Author is already muted"})
+			continue
+		}
+		_, err := tx.CreateAuthorMute(ctx, database.CreateAuthorMuteParams{
+			ID:        uuid.New(),
+			CreatedAt: ac.Clock.Now(),
+			UserID:    userID,
+			Author:    author,
+		})
+		if err != nil {
+			return imported, err
+		}
+		muted[strings.ToLower(author)] = true
+		imported++
+	}
+	return imported, nil
+}
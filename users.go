@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// handleUsersPatch updates profile fields. Omitted fields are left alone and
+// an empty string clears an optional field.
+func handleUsersPatch(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	type usersPatchRequest struct {
+		Name               *string   `json:"name"`
+		Email              *string   `json:"email"`
+		AvatarURL          *string   `json:"avatar_url"`
+		Bio                *string   `json:"bio"`
+		ShowSensitive      *bool     `json:"show_sensitive"`
+		PreferredLanguages *[]string `json:"preferred_languages"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := usersPatchRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+
+	params := database.UpdateUserProfileParams{
+		ID:                 u.ID,
+		Name:               u.Name,
+		Email:              u.Email,
+		AvatarUrl:          u.AvatarUrl,
+		Bio:                u.Bio,
+		ShowSensitive:      u.ShowSensitive,
+		UpdatedAt:          time.Now(),
+		PreferredLanguages: u.PreferredLanguages,
+	}
+	if req.Name != nil {
+		params.Name = strings.TrimSpace(*req.Name)
+		if params.Name == "" {
+			respondWithError(w, http.StatusBadRequest, "Name is required")
+			return
+		}
+	}
+	if req.Email != nil {
+		params.Email = sql.NullString{}
+		if *req.Email != "" {
+			addr, err := mail.ParseAddress(*req.Email)
+			if err != nil {
+				respondWithError(w, http.StatusBadRequest, "Invalid email address")
+				return
+			}
+			params.Email = sql.NullString{String: addr.Address, Valid: true}
+		}
+	}
+	if req.AvatarURL != nil {
+		params.AvatarUrl = sql.NullString{}
+		if *req.AvatarURL != "" {
+			avatar, err := url.Parse(*req.AvatarURL)
+			if err != nil || (avatar.Scheme != "http" && avatar.Scheme != "https") || avatar.Host == "" {
+				respondWithError(w, http.StatusBadRequest, "Invalid avatar URL")
+				return
+			}
+			params.AvatarUrl = sql.NullString{String: avatar.String(), Valid: true}
+		}
+	}
+	if req.Bio != nil {
+		params.Bio = sql.NullString{String: *req.Bio, Valid: *req.Bio != ""}
+	}
+	if req.ShowSensitive != nil {
+		params.ShowSensitive = *req.ShowSensitive
+	}
+	if req.PreferredLanguages != nil {
+		params.PreferredLanguages, err = normalizeLanguages(*req.PreferredLanguages)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid language code")
+			return
+		}
+	}
+
+	updated, err := ac.DB.UpdateUserProfile(r.Context(), params)
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "Name or email already taken")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to update user")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, newUserResponse(updated))
+}
+
+// mergeUsers folds everything owned by source into target and deletes source,
+// in one transaction. Where both accounts hold the same thing (a follow of
+// the same feed, the same queued or snoozed post, the same muted author) the
+// target's copy wins. Source's inbox is merged into target's, and source's
+// reports and audit log entries are credited to target, so that the audit
+// trail survives the merge.
+func mergeUsers(ctx context.Context, ac apiConfig, sourceID, targetID uuid.UUID) error {
+	tx, err := ac.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.MoveUserFeedFollows(ctx, database.MoveUserFeedFollowsParams{
+		TargetID:  targetID,
+		UpdatedAt: time.Now(),
+		SourceID:  sourceID,
+	})
+	if err != nil {
+		return err
+	}
+	err = tx.MoveUserQueueItems(ctx, database.MoveUserQueueItemsParams{TargetID: targetID, SourceID: sourceID})
+	if err != nil {
+		return err
+	}
+	err = tx.MoveUserPostSnoozes(ctx, database.MoveUserPostSnoozesParams{TargetID: targetID, SourceID: sourceID})
+	if err != nil {
+		return err
+	}
+	err = tx.MoveUserAuthorMutes(ctx, database.MoveUserAuthorMutesParams{TargetID: targetID, SourceID: sourceID})
+	if err != nil {
+		return err
+	}
+	err = tx.MoveUserNotificationChannels(ctx, database.MoveUserNotificationChannelsParams{TargetID: targetID, SourceID: sourceID})
+	if err != nil {
+		return err
+	}
+	err = tx.MoveUserPostRules(ctx, database.MoveUserPostRulesParams{TargetID: targetID, SourceID: sourceID})
+	if err != nil {
+		return err
+	}
+	err = tx.MoveUserPushSubscriptions(ctx, database.MoveUserPushSubscriptionsParams{TargetID: targetID, SourceID: sourceID})
+	if err != nil {
+		return err
+	}
+	err = tx.MoveUserPostEmails(ctx, database.MoveUserPostEmailsParams{TargetID: targetID, SourceID: sourceID})
+	if err != nil {
+		return err
+	}
+	err = mergeInboxes(ctx, tx, sourceID, targetID)
+	if err != nil {
+		return err
+	}
+	err = tx.MoveUserFeeds(ctx, database.MoveUserFeedsParams{TargetID: targetID, SourceID: sourceID})
+	if err != nil {
+		return err
+	}
+	err = tx.MoveUserIdentities(ctx, database.MoveUserIdentitiesParams{TargetID: targetID, SourceID: sourceID})
+	if err != nil {
+		return err
+	}
+	err = tx.MoveUserFeedRepublishes(ctx, database.MoveUserFeedRepublishesParams{
+		TargetID:  targetID,
+		UpdatedAt: time.Now(),
+		SourceID:  sourceID,
+	})
+	if err != nil {
+		return err
+	}
+	err = tx.MoveUserFeedReports(ctx, database.MoveUserFeedReportsParams{TargetID: targetID, SourceID: sourceID})
+	if err != nil {
+		return err
+	}
+	err = tx.MoveUserReportResolutions(ctx, database.MoveUserReportResolutionsParams{TargetID: targetID, SourceID: sourceID})
+	if err != nil {
+		return err
+	}
+	err = tx.MoveUserAuditLog(ctx, database.MoveUserAuditLogParams{TargetID: targetID, SourceID: sourceID})
+	if err != nil {
+		return err
+	}
+	err = tx.DeleteUser(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// mergeInboxes moves the posts of source's inbox into target's, creating
+// target's if need be, so that target is left with one inbox. Links both
+// inboxes hold stay only in target's. Source's inbox itself is not moved,
+// and goes when source is deleted.
+func mergeInboxes(ctx context.Context, tx database.Tx, sourceID, targetID uuid.UUID) error {
+	source, err := tx.GetInboxFeed(ctx, sourceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	target, err := getOrCreateInboxFeed(ctx, tx, targetID)
+	if err != nil {
+		return err
+	}
+	return tx.MoveFeedPosts(ctx, database.MoveFeedPostsParams{TargetID: target.ID, SourceID: source.ID})
+}
+
+// handleUsersMerge lets a user absorb another account they own. Knowing the
+// other account's API key is the proof of ownership.
+func handleUsersMerge(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	type usersMergeRequest struct {
+		ApiKey string `json:"api_key"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := usersMergeRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	source, err := ac.DB.GetUserByApiKey(r.Context(), req.ApiKey)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid API key for account to merge")
+		return
+	}
+	if source.ID == u.ID {
+		respondWithError(w, http.StatusBadRequest, "Cannot merge an account into itself")
+		return
+	}
+	err = mergeUsers(r.Context(), ac, source.ID, u.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to merge accounts")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, newUserResponse(u))
+}
+
+func handleAdminUsersMerge(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	type adminUsersMergeRequest struct {
+		SourceUserID string `json:"source_user_id"`
+		TargetUserID string `json:"target_user_id"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := adminUsersMergeRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	sourceID, err := uuid.Parse(req.SourceUserID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid source user ID")
+		return
+	}
+	targetID, err := uuid.Parse(req.TargetUserID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid target user ID")
+		return
+	}
+	if sourceID == targetID {
+		respondWithError(w, http.StatusBadRequest, "Cannot merge an account into itself")
+		return
+	}
+	_, err = ac.DB.GetUser(r.Context(), sourceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Source user not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve user")
+		return
+	}
+	target, err := ac.DB.GetUser(r.Context(), targetID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Target user not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve user")
+		return
+	}
+	err = mergeUsers(r.Context(), ac, sourceID, targetID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to merge accounts")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, newUserResponse(target))
+}
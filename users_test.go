@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+func TestMergeUsersMergesInboxesAndKeepsAuditTrail(t *testing.T) {
+	ac, _ := newClockTestConfig()
+	ctx := context.Background()
+	newUser := func(name string) database.User {
+		u, err := ac.DB.CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), CreatedAt: clockTestStart, UpdatedAt: clockTestStart, Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+	saveLink := func(u database.User, url string) {
+		inbox, err := getOrCreateInboxFeed(ctx, ac.DB, u.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = ac.DB.CreatePost(ctx, database.CreatePostParams{
+			ID:        uuid.New(),
+			CreatedAt: clockTestStart,
+			UpdatedAt: clockTestStart,
+			Title:     url,
+			Url:       url,
+			FeedID:    inbox.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	source, target := newUser("source"), newUser("target")
+	saveLink(source, "https://example.com/both")
+	saveLink(source, "https://example.com/source-only")
+	saveLink(target, "https://example.com/both")
+	_, feed := seedClockTestFeed(t, ac, true)
+	report, err := ac.DB.CreateFeedReport(ctx, database.CreateFeedReportParams{
+		ID:         uuid.New(),
+		CreatedAt:  clockTestStart,
+		UpdatedAt:  clockTestStart,
+		FeedID:     feed.ID,
+		ReporterID: source.ID,
+		Reason:     "spam",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := ac.DB.CreateAuditLogEntry(ctx, database.CreateAuditLogEntryParams{
+		ID:         uuid.New(),
+		CreatedAt:  clockTestStart,
+		ActorID:    uuid.NullUUID{UUID: source.ID, Valid: true},
+		Action:     "feed.report",
+		TargetType: "feed",
+		TargetID:   feed.ID,
+		Details:    []byte(`{}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = mergeUsers(ctx, ac, source.ID, target.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inbox, err := ac.DB.GetInboxFeed(ctx, target.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	posts, err := ac.DB.GetPostsByFeed(ctx, database.GetPostsByFeedParams{FeedID: inbox.ID, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 2 {
+		t.Errorf("target inbox has %d posts, want 2", len(posts))
+	}
+	follows, err := ac.DB.GetUserFeedFollows(ctx, target.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range follows {
+		followed, err := ac.DB.GetFeed(ctx, f.FeedID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if followed.Kind == "inbox" && followed.ID != inbox.ID {
+			t.Errorf("target still follows the source's inbox %s", followed.ID)
+		}
+	}
+	reports, err := ac.DB.ListFeedReportsByStatus(ctx, report.Status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 || reports[0].ReporterID != target.ID {
+		t.Errorf("reports = %+v, want the source's credited to the target", reports)
+	}
+	entries, err := ac.DB.ListAuditLog(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].ID != entry.ID || entries[0].ActorID.UUID != target.ID {
+		t.Errorf("audit log = %+v, want the source's entry credited to the target", entries)
+	}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const (
+	maxVirtualFeedSources = 50
+	// virtualFeedBackfill is how many of each source's newest posts a new
+	// virtual feed starts with.
+	virtualFeedBackfill = 50
+)
+
+// A virtual feed has kind "virtual": it is never fetched, but combines the
+// posts of its source feeds, optionally filtered by keyword and deduped.
+// Matching posts are copied into it as the sources are fetched, so it can be
+// followed and exported like any feed.
+type virtualFeedResponse struct {
+	Feed            feedResponse `json:"feed"`
+	SourceFeedIDs   []uuid.UUID  `json:"source_feed_ids"`
+	Dedupe          bool         `json:"dedupe"`
+	IncludeKeywords []string     `json:"include_keywords"`
+	ExcludeKeywords []string     `json:"exclude_keywords"`
+	Url             string       `json:"url"`
+}
+
+func newVirtualFeedResponse(r *http.Request, feed database.Feed, filter database.VirtualFeedFilter, sourceIDs []uuid.UUID) virtualFeedResponse {
+	return virtualFeedResponse{
+		Feed:            newFeedResponse(feed),
+		SourceFeedIDs:   append([]uuid.UUID{}, sourceIDs...),
+		Dedupe:          filter.Dedupe,
+		IncludeKeywords: append([]string{}, filter.IncludeKeywords...),
+		ExcludeKeywords: append([]string{}, filter.ExcludeKeywords...),
+		Url:             requestOrigin(r) + "/v1/feeds/" + feed.ID.String() + "/posts",
+	}
+}
+
+// addToVirtualFeed copies a source feed's post into a virtual feed if it
+// passes the virtual feed's filters. The copy keeps the post's ingestion
+// time and is deleted with it. Copies are keyed by the source post, or, in
+// a virtual feed that dedupes, by title, since the same story cross-posted
+// to several blogs rarely shares a URL but usually shares a title.
+func addToVirtualFeed(ctx context.Context, q database.Querier, filter database.VirtualFeedFilter, source database.Feed, p database.Post) error {
+	if !matchesKeywords(filter.IncludeKeywords, filter.ExcludeKeywords, p) {
+		return nil
+	}
+	guid := p.ID.String()
+	if title := strings.ToLower(strings.Join(strings.Fields(p.Title), " ")); filter.Dedupe && title != "" {
+		guid = "title:" + title
+	}
+	_, err := q.CreateVirtualFeedPost(ctx, database.CreateVirtualFeedPostParams{
+		ID:              uuid.New(),
+		CreatedAt:       p.CreatedAt,
+		UpdatedAt:       p.UpdatedAt,
+		Title:           p.Title,
+		Url:             p.Url,
+		Description:     p.Description,
+		PublishedAt:     p.PublishedAt,
+		FeedID:          filter.FeedID,
+		Sensitive:       p.Sensitive || source.Sensitive,
+		Guid:            guid,
+		EnclosureUrl:    p.EnclosureUrl,
+		EnclosureType:   p.EnclosureType,
+		EnclosureLength: p.EnclosureLength,
+		SourcePostID:    uuid.NullUUID{UUID: p.ID, Valid: true},
+		Author:          p.Author,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return err
+}
+
+// addToVirtualFeeds copies a feed's newly fetched posts into the virtual
+// feeds it is a source of.
+func (ac *apiConfig) addToVirtualFeeds(ctx context.Context, sourceFeedID uuid.UUID, posts []database.Post) {
+	if len(posts) == 0 {
+		return
+	}
+	filters, err := ac.DB.GetVirtualFeedsForSource(ctx, sourceFeedID)
+	if err != nil {
+		logError("fetch", "Could not get virtual feeds for %s: %v", sourceFeedID, err)
+		return
+	}
+	if len(filters) == 0 {
+		return
+	}
+	source, err := ac.DB.GetFeed(ctx, sourceFeedID)
+	if err != nil {
+		logError("fetch", "Could not get feed %s: %v", sourceFeedID, err)
+		return
+	}
+	// Virtual feeds are public, so a source given credentials since is no
+	// longer copied from.
+	if isPrivateFeed(source) {
+		return
+	}
+	for _, filter := range filters {
+		for _, p := range posts {
+			err := addToVirtualFeed(ctx, ac.DB, filter, source, p)
+			if err != nil {
+				logError("fetch", "Could not add %s to virtual feed %s: %v", p.Title, filter.FeedID, err)
+			}
+		}
+	}
+}
+
+func handleVirtualFeedsPost(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	type virtualFeedRequest struct {
+		Name            string   `json:"name"`
+		Description     string   `json:"description"`
+		SourceFeedIDs   []string `json:"source_feed_ids"`
+		Dedupe          bool     `json:"dedupe"`
+		IncludeKeywords []string `json:"include_keywords"`
+		ExcludeKeywords []string `json:"exclude_keywords"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := virtualFeedRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		respondWithError(w, http.StatusBadRequest, "Name is required")
+		return
+	}
+	if len(req.SourceFeedIDs) == 0 || len(req.SourceFeedIDs) > maxVirtualFeedSources {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("A virtual feed needs between 1 and %d source feeds", maxVirtualFeedSources))
+		return
+	}
+	include, err := normalizeKeywords(req.IncludeKeywords)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	exclude, err := normalizeKeywords(req.ExcludeKeywords)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sources := []database.Feed{}
+	for _, s := range req.SourceFeedIDs {
+		feedID, err := parseFeedID(r.Context(), ac.DB, s)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid feed ID")
+			return
+		}
+		feed, err := ac.DB.GetFeed(r.Context(), feedID)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && (feed.Kind != "remote" || isPrivateFeed(feed))) {
+			respondWithError(w, http.StatusNotFound, fmt.Sprintf("Feed %s not found", s))
+			return
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to retrieve feed")
+			return
+		}
+		sources = append(sources, feed)
+	}
+
+	tx, err := ac.DB.Begin(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save virtual feed")
+		return
+	}
+	defer tx.Rollback()
+	id := uuid.New()
+	feed, err := tx.CreateVirtualFeed(r.Context(), database.CreateVirtualFeedParams{
+		ID:          id,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+		Name:        name,
+		Url:         "virtual:" + id.String(),
+		UserID:      u.ID,
+		Description: strings.TrimSpace(req.Description),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save virtual feed")
+		return
+	}
+	filter, err := tx.CreateVirtualFeedFilter(r.Context(), database.CreateVirtualFeedFilterParams{
+		FeedID:          feed.ID,
+		Dedupe:          req.Dedupe,
+		IncludeKeywords: include,
+		ExcludeKeywords: exclude,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save virtual feed")
+		return
+	}
+	for _, source := range sources {
+		err = tx.AddVirtualFeedSource(r.Context(), database.AddVirtualFeedSourceParams{
+			VirtualFeedID: feed.ID,
+			SourceFeedID:  source.ID,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to save virtual feed")
+			return
+		}
+		posts, err := tx.GetPostsByFeed(r.Context(), database.GetPostsByFeedParams{
+			FeedID: source.ID,
+			Limit:  virtualFeedBackfill,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to retrieve posts")
+			return
+		}
+		for _, p := range posts {
+			err = addToVirtualFeed(r.Context(), tx, filter, source, p)
+			if err != nil {
+				respondWithError(w, http.StatusInternalServerError, "Unable to save virtual feed posts")
+				return
+			}
+		}
+	}
+	follow, err := tx.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    u.ID,
+		FeedID:    feed.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save feed follow")
+		return
+	}
+	sourceIDs, err := tx.GetVirtualFeedSourceIDs(r.Context(), feed.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save virtual feed")
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save virtual feed")
+		return
+	}
+	type createVirtualFeedResponse struct {
+		VirtualFeed virtualFeedResponse `json:"virtual_feed"`
+		FeedFollow  feedFollowResponse  `json:"feed_follow"`
+	}
+	respondWithJSON(w, http.StatusCreated, createVirtualFeedResponse{
+		VirtualFeed: newVirtualFeedResponse(r, feed, filter, sourceIDs),
+		FeedFollow:  newFeedFollowResponse(follow),
+	})
+}
+
+func handleVirtualFeedsGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	feeds, err := ac.DB.GetUserVirtualFeeds(r.Context(), u.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve virtual feeds")
+		return
+	}
+	resp := make([]virtualFeedResponse, 0, len(feeds))
+	for _, feed := range feeds {
+		filter, err := ac.DB.GetVirtualFeedFilter(r.Context(), feed.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to retrieve virtual feeds")
+			return
+		}
+		sourceIDs, err := ac.DB.GetVirtualFeedSourceIDs(r.Context(), feed.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to retrieve virtual feeds")
+			return
+		}
+		resp = append(resp, newVirtualFeedResponse(r, feed, filter, sourceIDs))
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const (
+	webhookPayloadVersion = "1"
+	// webhookSecretGrace is how long a rotated-out secret keeps signing
+	// deliveries, so receivers can switch over without dropping any.
+	webhookSecretGrace   = 24 * time.Hour
+	minWebhookSecretSize = 16
+	// webhookResponseSnippetSize is how much of a receiver's response is
+	// kept in the delivery log.
+	webhookResponseSnippetSize = 1024
+)
+
+// webhookNotifier POSTs notifications as JSON to any URL. By default the
+// body is the notification itself; a template or a field map reshapes it,
+// so one kind covers Slack and Discord incoming webhooks and custom
+// services alike:
+//
+//	{"url": "...", "fields": {"text": "text"}}                      // Slack
+//	{"url": "...", "fields": {"content": "text"}}                   // Discord
+//	{"url": "...", "template": "{\"msg\": {{json .FeedTitle}}}"}    // custom
+//
+// Templates are Go text/template over the notification, with a json
+// function to quote values safely.
+//
+// Every delivery is signed with the channel's secret. Receivers verify it
+// from four headers:
+//
+//	Webhook-Id         unique per delivery; remember recent ones to drop replays
+//	Webhook-Timestamp  Unix seconds when the delivery was sent
+//	Webhook-Version    the payload version, currently 1
+//	Webhook-Signature  space-separated "v1=<hex>" signatures
+//
+// A v1 signature is the hex HMAC-SHA256, keyed with the secret, of
+// id + "." + timestamp + "." + body. Accept the delivery if any v1 value
+// matches (compare in constant time) and the timestamp is within a few
+// minutes of now. While a rotated-out secret is in its grace period,
+// deliveries carry a signature for each secret.
+type webhookNotifier struct {
+	URL      string            `json:"url"`
+	Template string            `json:"template"`
+	Fields   map[string]string `json:"fields"`
+
+	Secret                  string     `json:"secret"`
+	PreviousSecret          string     `json:"previous_secret"`
+	PreviousSecretExpiresAt *time.Time `json:"previous_secret_expires_at"`
+
+	tmpl   *template.Template
+	client *http.Client
+}
+
+// webhookFields are the notification values a field map can pick from.
+var webhookFields = map[string]func(n notification) any{
+	"event":      func(n notification) any { return n.Event },
+	"text":       func(n notification) any { return n.Text },
+	"feed_id":    func(n notification) any { return n.FeedID },
+	"feed_title": func(n notification) any { return n.FeedTitle },
+	"posts":      func(n notification) any { return n.Posts },
+}
+
+var webhookTemplateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// newWebhookNotifier decodes a webhook's config. Deliveries are sent with
+// client, and a URL on a private address is refused unless allowPrivate is
+// set; client refuses to connect to one in any case.
+func newWebhookNotifier(ctx context.Context, config json.RawMessage, client *http.Client, allowPrivate bool) (*webhookNotifier, error) {
+	wh := &webhookNotifier{client: client}
+	err := json.Unmarshal(config, wh)
+	if err != nil {
+		return nil, errors.New("invalid webhook config")
+	}
+	if wh.URL == "" {
+		return nil, errors.New("webhook config requires url")
+	}
+	u, err := url.ParseRequestURI(wh.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, errors.New("invalid webhook url")
+	}
+	err = checkFeedAddress(ctx, wh.URL, allowPrivate)
+	if errors.Is(err, errPrivateAddress) {
+		return nil, errors.New("webhook url points to a private address")
+	}
+	if err != nil {
+		return nil, errors.New("invalid webhook url")
+	}
+	if wh.Secret != "" && len(wh.Secret) < minWebhookSecretSize {
+		return nil, fmt.Errorf("webhook secret must be at least %d characters", minWebhookSecretSize)
+	}
+	if wh.Template != "" && len(wh.Fields) > 0 {
+		return nil, errors.New("webhook config takes a template or fields, not both")
+	}
+	for key, field := range wh.Fields {
+		if _, ok := webhookFields[field]; !ok {
+			return nil, fmt.Errorf("unknown webhook field %q for %q; use event, text, feed_id, feed_title, or posts", field, key)
+		}
+	}
+	if wh.Template != "" {
+		wh.tmpl, err = template.New("webhook").Funcs(webhookTemplateFuncs).Option("missingkey=error").Parse(wh.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook template: %v", err)
+		}
+		// Render a sample now, so a template that cannot produce JSON is
+		// rejected when the channel is created rather than on every post.
+		feedID := uuid.Nil
+		_, err = wh.payload(notification{
+			Event:     "new_posts",
+			Text:      "New post in Example:\n- Example https://example.com/",
+			FeedID:    &feedID,
+			FeedTitle: "Example",
+			Posts:     []notificationPost{{Title: "Example", Url: "https://example.com/"}},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return wh, nil
+}
+
+// payload renders the request body for n.
+func (wh *webhookNotifier) payload(n notification) ([]byte, error) {
+	if wh.tmpl != nil {
+		buf := bytes.Buffer{}
+		err := wh.tmpl.Execute(&buf, n)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook template: %v", err)
+		}
+		if !json.Valid(buf.Bytes()) {
+			return nil, errors.New("webhook template did not produce valid JSON")
+		}
+		return buf.Bytes(), nil
+	}
+	if len(wh.Fields) > 0 {
+		body := map[string]any{}
+		for key, field := range wh.Fields {
+			body[key] = webhookFields[field](n)
+		}
+		return json.Marshal(body)
+	}
+	return json.Marshal(n)
+}
+
+func (wh *webhookNotifier) notify(ctx context.Context, n notification) error {
+	body, err := wh.payload(n)
+	if err != nil {
+		return err
+	}
+	return wh.send(ctx, uuid.New(), body).err
+}
+
+// webhookAttempt is the outcome of one POST to a webhook.
+type webhookAttempt struct {
+	status   int
+	duration time.Duration
+	response string
+	err      error
+}
+
+// send POSTs body as delivery id, signed for the current time.
+func (wh *webhookNotifier) send(ctx context.Context, id uuid.UUID, body []byte) webhookAttempt {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return webhookAttempt{err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Webhook-Id", id.String())
+	req.Header.Set("Webhook-Timestamp", timestamp)
+	req.Header.Set("Webhook-Version", webhookPayloadVersion)
+	if sigs := wh.signatures(id.String(), timestamp, body, time.Now()); len(sigs) > 0 {
+		req.Header.Set("Webhook-Signature", strings.Join(sigs, " "))
+	}
+	start := time.Now()
+	res, err := wh.client.Do(req)
+	if err != nil {
+		return webhookAttempt{duration: time.Since(start), err: err}
+	}
+	defer res.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(res.Body, webhookResponseSnippetSize))
+	attempt := webhookAttempt{
+		status:   res.StatusCode,
+		duration: time.Since(start),
+		response: strings.ToValidUTF8(strings.ReplaceAll(string(snippet), "\x00", ""), "\uFFFD"),
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		attempt.err = fmt.Errorf("webhook returned %s", res.Status)
+	}
+	return attempt
+}
+
+// signatures signs a delivery with the current secret and, until it
+// expires, the previous one.
+func (wh *webhookNotifier) signatures(id, timestamp string, body []byte, now time.Time) []string {
+	secrets := []string{}
+	if wh.Secret != "" {
+		secrets = append(secrets, wh.Secret)
+	}
+	if wh.PreviousSecret != "" && wh.PreviousSecretExpiresAt != nil && now.Before(*wh.PreviousSecretExpiresAt) {
+		secrets = append(secrets, wh.PreviousSecret)
+	}
+	sigs := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(id + "." + timestamp + "."))
+		mac.Write(body)
+		sigs = append(sigs, "v1="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	return sigs
+}
+
+func newWebhookSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// withWebhookSecret gives a new webhook channel's config a generated secret
+// unless the user chose one.
+func withWebhookSecret(config json.RawMessage) (json.RawMessage, error) {
+	fields := map[string]any{}
+	err := json.Unmarshal(config, &fields)
+	if err != nil {
+		return nil, err
+	}
+	if s, _ := fields["secret"].(string); s != "" {
+		return config, nil
+	}
+	fields["secret"] = newWebhookSecret()
+	return json.Marshal(fields)
+}
+
+// rotateWebhookSecret replaces a webhook channel's secret, keeping the old
+// one signing alongside it for webhookSecretGrace.
+func rotateWebhookSecret(config json.RawMessage, now time.Time) (json.RawMessage, error) {
+	fields := map[string]any{}
+	err := json.Unmarshal(config, &fields)
+	if err != nil {
+		return nil, err
+	}
+	delete(fields, "previous_secret")
+	delete(fields, "previous_secret_expires_at")
+	if s, _ := fields["secret"].(string); s != "" {
+		fields["previous_secret"] = s
+		fields["previous_secret_expires_at"] = now.Add(webhookSecretGrace)
+	}
+	fields["secret"] = newWebhookSecret()
+	return json.Marshal(fields)
+}
+
+func handleNotificationChannelRotateSecret(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	channelID, err := uuid.Parse(chi.URLParam(r, "channelID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	channel, err := ac.DB.GetNotificationChannel(r.Context(), database.GetNotificationChannelParams{
+		ID:     channelID,
+		UserID: u.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Notification channel not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve notification channel")
+		return
+	}
+	if channel.Kind != "webhook" {
+		respondWithError(w, http.StatusBadRequest, "Only webhook channels have a secret")
+		return
+	}
+	config, err := rotateWebhookSecret(channel.Config, time.Now())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to rotate secret")
+		return
+	}
+	channel, err = ac.DB.UpdateNotificationChannelConfig(r.Context(), database.UpdateNotificationChannelConfigParams{
+		ID:        channel.ID,
+		UserID:    u.ID,
+		Config:    config,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to rotate secret")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, newNotificationChannelResponse(channel))
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+const (
+	// webhookDeliveriesKept is how many deliveries each webhook's log holds;
+	// older ones are pruned as new ones are recorded.
+	webhookDeliveriesKept         = 100
+	defaultWebhookDeliveriesLimit = 20
+)
+
+// deliverWebhook sends body to a webhook and logs the attempt. A
+// redelivery reuses the original delivery ID, so receivers that
+// deduplicate on Webhook-Id will not process it twice.
+//
+// The start of the receiver's response is logged only while the client
+// refuses private addresses. Where they are allowed, the log and
+// redelivery together would let a user read from any service on the
+// server's network.
+func (ac *apiConfig) deliverWebhook(ctx context.Context, channelID uuid.UUID, wh *webhookNotifier, event string, deliveryID uuid.UUID, body []byte, redelivery bool) (database.WebhookDelivery, error) {
+	attempt := wh.send(ctx, deliveryID, body)
+	if ac.AllowPrivate {
+		attempt.response = ""
+	}
+	params := database.CreateWebhookDeliveryParams{
+		ID:           uuid.New(),
+		ChannelID:    channelID,
+		DeliveryID:   deliveryID,
+		CreatedAt:    ac.Clock.Now(),
+		Event:        event,
+		RequestBody:  string(body),
+		StatusCode:   int32(attempt.status),
+		DurationMs:   int32(attempt.duration.Milliseconds()),
+		ResponseBody: attempt.response,
+		Redelivery:   redelivery,
+	}
+	if attempt.err != nil {
+		params.Error = attempt.err.Error()
+	}
+	delivery, err := ac.DB.CreateWebhookDelivery(ctx, params)
+	if err != nil {
+		logError("notify", "Could not log webhook delivery to %s: %v", channelID, err)
+		return database.WebhookDelivery(params), attempt.err
+	}
+	err = ac.DB.PruneWebhookDeliveries(ctx, database.PruneWebhookDeliveriesParams{
+		ChannelID: channelID,
+		Limit:     webhookDeliveriesKept,
+	})
+	if err != nil {
+		logError("notify", "Could not prune webhook deliveries for %s: %v", channelID, err)
+	}
+	return delivery, attempt.err
+}
+
+type webhookDeliveryResponse struct {
+	ID           uuid.UUID `json:"id"`
+	DeliveryID   uuid.UUID `json:"delivery_id"`
+	CreatedAt    time.Time `json:"created_at"`
+	Event        string    `json:"event"`
+	Redelivery   bool      `json:"redelivery"`
+	Success      bool      `json:"success"`
+	StatusCode   int32     `json:"status_code"`
+	Error        string    `json:"error"`
+	DurationMs   int32     `json:"duration_ms"`
+	RequestBody  string    `json:"request_body"`
+	ResponseBody string    `json:"response_body"`
+}
+
+func newWebhookDeliveryResponse(d database.WebhookDelivery) webhookDeliveryResponse {
+	return webhookDeliveryResponse{
+		ID:           d.ID,
+		DeliveryID:   d.DeliveryID,
+		CreatedAt:    d.CreatedAt,
+		Event:        d.Event,
+		Redelivery:   d.Redelivery,
+		Success:      d.Error == "",
+		StatusCode:   d.StatusCode,
+		Error:        d.Error,
+		DurationMs:   d.DurationMs,
+		RequestBody:  d.RequestBody,
+		ResponseBody: d.ResponseBody,
+	}
+}
+
+// getUserWebhook loads the webhook channel named in the path, responding
+// with an error and returning false if there is none for u.
+func getUserWebhook(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) (database.NotificationChannel, bool) {
+	channelID, err := uuid.Parse(chi.URLParam(r, "channelID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return database.NotificationChannel{}, false
+	}
+	channel, err := ac.DB.GetNotificationChannel(r.Context(), database.GetNotificationChannelParams{
+		ID:     channelID,
+		UserID: u.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && channel.Kind != "webhook") {
+		respondWithError(w, http.StatusNotFound, "Webhook not found")
+		return database.NotificationChannel{}, false
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve webhook")
+		return database.NotificationChannel{}, false
+	}
+	return channel, true
+}
+
+func handleWebhookDeliveriesGet(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	limit := defaultWebhookDeliveriesLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 || parsed > webhookDeliveriesKept {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = parsed
+	}
+	channel, ok := getUserWebhook(w, r, u, ac)
+	if !ok {
+		return
+	}
+	deliveries, err := ac.DB.ListWebhookDeliveries(r.Context(), database.ListWebhookDeliveriesParams{
+		ChannelID: channel.ID,
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve deliveries")
+		return
+	}
+	resp := make([]webhookDeliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		resp = append(resp, newWebhookDeliveryResponse(d))
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handleWebhookRedeliver sends a logged delivery's payload again, to the
+// webhook's current URL and signed with its current secret, and responds
+// with the new attempt whether or not it succeeded.
+func handleWebhookRedeliver(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	channel, ok := getUserWebhook(w, r, u, ac)
+	if !ok {
+		return
+	}
+	deliveryID, err := uuid.Parse(chi.URLParam(r, "deliveryID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	original, err := ac.DB.GetWebhookDelivery(r.Context(), database.GetWebhookDeliveryParams{
+		ID:        deliveryID,
+		ChannelID: channel.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Delivery not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve delivery")
+		return
+	}
+	wh, err := newWebhookNotifier(r.Context(), channel.Config, ac.NotifyClient, ac.AllowPrivate)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Invalid webhook config")
+		return
+	}
+	delivery, _ := ac.deliverWebhook(r.Context(), channel.ID, wh, original.Event, original.DeliveryID, []byte(original.RequestBody), true)
+	respondWithJSON(w, http.StatusCreated, newWebhookDeliveryResponse(delivery))
+}
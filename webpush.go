@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pmwals09/rss-aggregator/internal/database"
+)
+
+// webPushConfig holds the instance's VAPID identity (RFC 8292). The private
+// key is the raw P-256 scalar, base64url encoded, as produced by common
+// web-push tooling.
+type webPushConfig struct {
+	publicKey  []byte
+	privateKey *ecdsa.PrivateKey
+	subject    string
+	client     *http.Client
+}
+
+// newWebPushConfigFromEnv returns nil when VAPID_PRIVATE_KEY is not set,
+// which disables Web Push delivery. Pushes are sent with client.
+func newWebPushConfigFromEnv(client *http.Client) (*webPushConfig, error) {
+	encoded := os.Getenv("VAPID_PRIVATE_KEY")
+	if encoded == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return nil, errors.New("VAPID_PRIVATE_KEY is not base64url")
+	}
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, errors.New("VAPID_PRIVATE_KEY is not a P-256 private key")
+	}
+	pub := key.PublicKey().Bytes()
+	subject := os.Getenv("VAPID_SUBJECT")
+	if subject == "" {
+		return nil, errors.New("VAPID_SUBJECT is required when VAPID_PRIVATE_KEY is set")
+	}
+	return &webPushConfig{
+		publicKey: pub,
+		privateKey: &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(pub[1:33]),
+				Y:     new(big.Int).SetBytes(pub[33:]),
+			},
+			D: new(big.Int).SetBytes(raw),
+		},
+		subject: subject,
+		client:  client,
+	}, nil
+}
+
+var errPushSubscriptionGone = errors.New("push subscription is no longer valid")
+
+func (wp *webPushConfig) send(ctx context.Context, sub database.PushSubscription, payload []byte) error {
+	uaPublic, err := base64.RawURLEncoding.DecodeString(sub.P256dh)
+	if err != nil {
+		return err
+	}
+	authSecret, err := base64.RawURLEncoding.DecodeString(sub.Auth)
+	if err != nil {
+		return err
+	}
+	body, err := encryptWebPush(payload, uaPublic, authSecret)
+	if err != nil {
+		return err
+	}
+	authorization, err := wp.vapidAuthorization(sub.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", "86400")
+	res, err := wp.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusGone {
+		return errPushSubscriptionGone
+	}
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("push service returned %s", res.Status)
+	}
+	return nil
+}
+
+// vapidAuthorization builds the "vapid" Authorization header: an ES256 JWT
+// scoped to the push service's origin plus the instance public key.
+func (wp *webPushConfig) vapidAuthorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`))
+	claims, err := json.Marshal(map[string]interface{}{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+		"sub": wp.subject,
+	})
+	if err != nil {
+		return "", err
+	}
+	signingInput := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, wp.privateKey, hash[:])
+	if err != nil {
+		return "", err
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	jwt := signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+	return fmt.Sprintf("vapid t=%s, k=%s", jwt, base64.RawURLEncoding.EncodeToString(wp.publicKey)), nil
+}
+
+// encryptWebPush encrypts a push message body for one subscription using the
+// aes128gcm content coding from RFC 8291, as a single record.
+func encryptWebPush(payload, uaPublic, authSecret []byte) ([]byte, error) {
+	curve := ecdh.P256()
+	uaKey, err := curve.NewPublicKey(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+	asKey, err := curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asKey.PublicKey().Bytes()
+	ecdhSecret, err := asKey.ECDH(uaKey)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublic...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm := hkdfExpand(hkdfExtract(authSecret, ecdhSecret), keyInfo, 32)
+	prk := hkdfExtract(salt, ikm)
+	cek := hkdfExpand(prk, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdfExpand(prk, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// 0x02 marks the last (and only) record.
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	ciphertext := gcm.Seal(nil, nonce, plaintext, nil)
+
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, 4096)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+	return append(header, ciphertext...), nil
+}
+
+func hkdfExtract(salt, ikm []byte) []byte {
+	mac := hmac.New(sha256.New, salt)
+	mac.Write(ikm)
+	return mac.Sum(nil)
+}
+
+// hkdfExpand only produces a single HMAC block, which covers every length
+// Web Push needs.
+func hkdfExpand(prk, info []byte, length int) []byte {
+	mac := hmac.New(sha256.New, prk)
+	mac.Write(info)
+	mac.Write([]byte{0x01})
+	return mac.Sum(nil)[:length]
+}
+
+func (ac *apiConfig) pushNewPosts(ctx context.Context, feedID uuid.UUID, feedTitle string, posts []database.Post) {
+	subs, err := ac.DB.GetHighPriorityPushSubscriptionsForFeed(ctx, feedID)
+	if err != nil {
+		logError("notify", "Could not get push subscriptions: %v", err)
+		return
+	}
+	if len(subs) == 0 {
+		return
+	}
+	body := posts[0].Title
+	if len(posts) > 1 {
+		body = fmt.Sprintf("%d new posts", len(posts))
+	}
+	payload, err := json.Marshal(map[string]string{
+		"title": feedTitle,
+		"body":  body,
+		"url":   posts[0].Url,
+	})
+	if err != nil {
+		logError("notify", "Could not encode push payload: %v", err)
+		return
+	}
+	for _, sub := range subs {
+		err := ac.WebPush.send(ctx, sub, payload)
+		if errors.Is(err, errPushSubscriptionGone) {
+			ac.DB.DeletePushSubscriptionByEndpoint(ctx, sub.Endpoint)
+			continue
+		}
+		if err != nil {
+			logWarn("notify", "Could not push to subscription %s: %v", sub.ID, err)
+		}
+	}
+}
+
+func handlePushPublicKeyGet(w http.ResponseWriter, r *http.Request, ac apiConfig) {
+	if ac.WebPush == nil {
+		respondWithError(w, http.StatusServiceUnavailable, "Web Push is not configured on this instance")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"public_key": base64.RawURLEncoding.EncodeToString(ac.WebPush.publicKey),
+	})
+}
+
+func handlePushSubscriptionsPost(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	if ac.WebPush == nil {
+		respondWithError(w, http.StatusServiceUnavailable, "Web Push is not configured on this instance")
+		return
+	}
+	// The body mirrors the browser's PushSubscription.toJSON() output.
+	type pushSubscriptionRequest struct {
+		Endpoint string `json:"endpoint"`
+		Keys     struct {
+			P256dh string `json:"p256dh"`
+			Auth   string `json:"auth"`
+		} `json:"keys"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	req := pushSubscriptionRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode json")
+		return
+	}
+	endpoint, err := url.Parse(req.Endpoint)
+	if err != nil || endpoint.Scheme != "https" {
+		respondWithError(w, http.StatusBadRequest, "Invalid push endpoint")
+		return
+	}
+	if err := checkFeedAddress(r.Context(), req.Endpoint, ac.AllowPrivate); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid push endpoint")
+		return
+	}
+	p256dh, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(req.Keys.P256dh, "="))
+	if err != nil || len(p256dh) != 65 {
+		respondWithError(w, http.StatusBadRequest, "Invalid p256dh key")
+		return
+	}
+	auth, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(req.Keys.Auth, "="))
+	if err != nil || len(auth) != 16 {
+		respondWithError(w, http.StatusBadRequest, "Invalid auth secret")
+		return
+	}
+	sub, err := ac.DB.CreatePushSubscription(r.Context(), database.CreatePushSubscriptionParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    u.ID,
+		Endpoint:  req.Endpoint,
+		P256dh:    base64.RawURLEncoding.EncodeToString(p256dh),
+		Auth:      base64.RawURLEncoding.EncodeToString(auth),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save push subscription")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, newPushSubscriptionResponse(sub))
+}
+
+func handlePushSubscriptionsDelete(w http.ResponseWriter, r *http.Request, u database.User, ac apiConfig) {
+	subID, err := uuid.Parse(chi.URLParam(r, "subscriptionID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Bad path")
+		return
+	}
+	err = ac.DB.DeletePushSubscription(r.Context(), database.DeletePushSubscriptionParams{
+		ID:     subID,
+		UserID: u.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Problem deleting push subscription")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+// xmppNotifier sends notifications as XMPP chat messages. It opens a short
+// client session per delivery: STARTTLS, SASL PLAIN, resource binding, one
+// <message/>, then closes the stream. Server defaults to the JID's domain on
+// port 5222.
+type xmppNotifier struct {
+	JID      string `json:"jid"`
+	Password string `json:"password"`
+	To       string `json:"to"`
+	Server   string `json:"server"`
+}
+
+const xmppTimeout = 30 * time.Second
+
+type xmppFeatures struct {
+	StartTLS   *struct{} `xml:"starttls"`
+	Mechanisms []string  `xml:"mechanisms>mechanism"`
+	Bind       *struct{} `xml:"bind"`
+}
+
+type xmppMessage struct {
+	XMLName xml.Name `xml:"jabber:client message"`
+	To      string   `xml:"to,attr"`
+	Type    string   `xml:"type,attr"`
+	Body    string   `xml:"body"`
+}
+
+func newXMPPNotifier(config json.RawMessage) (*xmppNotifier, error) {
+	x := &xmppNotifier{}
+	err := json.Unmarshal(config, x)
+	if err != nil {
+		return nil, errors.New("invalid xmpp config")
+	}
+	if x.JID == "" || x.Password == "" || x.To == "" {
+		return nil, errors.New("xmpp config requires jid, password, and to")
+	}
+	if _, _, err := splitJID(x.JID); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func splitJID(jid string) (string, string, error) {
+	jid, _, _ = strings.Cut(jid, "/")
+	local, domain, ok := strings.Cut(jid, "@")
+	if !ok || local == "" || domain == "" {
+		return "", "", fmt.Errorf("invalid jid %q", jid)
+	}
+	return local, domain, nil
+}
+
+func (x *xmppNotifier) notify(ctx context.Context, n notification) error {
+	local, domain, err := splitJID(x.JID)
+	if err != nil {
+		return err
+	}
+	server := x.Server
+	if server == "" {
+		server = net.JoinHostPort(domain, "5222")
+	}
+
+	dialer := net.Dialer{Timeout: xmppTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", server)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(xmppTimeout)
+	}
+	conn.SetDeadline(deadline)
+
+	features, dec, err := xmppOpenStream(conn, domain)
+	if err != nil {
+		return err
+	}
+	if features.StartTLS == nil {
+		return errors.New("xmpp server does not offer STARTTLS")
+	}
+	fmt.Fprint(conn, "<starttls xmlns='urn:ietf:params:xml:ns:xmpp-tls'/>")
+	if err := xmppExpect(dec, "proceed"); err != nil {
+		return err
+	}
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: domain})
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return err
+	}
+
+	features, dec, err = xmppOpenStream(tlsConn, domain)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(features.Mechanisms, "PLAIN") {
+		return errors.New("xmpp server does not offer SASL PLAIN")
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte("\x00" + local + "\x00" + x.Password))
+	fmt.Fprintf(tlsConn, "<auth xmlns='urn:ietf:params:xml:ns:xmpp-sasl' mechanism='PLAIN'>%s</auth>", credentials)
+	if err := xmppExpect(dec, "success"); err != nil {
+		return err
+	}
+
+	features, dec, err = xmppOpenStream(tlsConn, domain)
+	if err != nil {
+		return err
+	}
+	if features.Bind != nil {
+		fmt.Fprint(tlsConn, "<iq type='set' id='bind_1'><bind xmlns='urn:ietf:params:xml:ns:xmpp-bind'/></iq>")
+		if err := xmppExpect(dec, "iq"); err != nil {
+			return err
+		}
+	}
+
+	msg, err := xml.Marshal(xmppMessage{To: x.To, Type: "chat", Body: n.Text})
+	if err != nil {
+		return err
+	}
+	_, err = tlsConn.Write(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(tlsConn, "</stream:stream>")
+	return err
+}
+
+// xmppOpenStream (re)starts the XML stream and returns the features the
+// server advertises for it, along with a decoder positioned after them.
+func xmppOpenStream(conn io.ReadWriter, domain string) (xmppFeatures, *xml.Decoder, error) {
+	features := xmppFeatures{}
+	_, err := fmt.Fprintf(conn, "<?xml version='1.0'?><stream:stream to='%s' xmlns='jabber:client' xmlns:stream='http://etherx.jabber.org/streams' version='1.0'>", domain)
+	if err != nil {
+		return features, nil, err
+	}
+	dec := xml.NewDecoder(conn)
+	start, err := xmppNextStart(dec)
+	if err != nil {
+		return features, nil, err
+	}
+	if start.Name.Local != "stream" {
+		return features, nil, fmt.Errorf("unexpected xmpp element <%s>", start.Name.Local)
+	}
+	start, err = xmppNextStart(dec)
+	if err != nil {
+		return features, nil, err
+	}
+	if start.Name.Local != "features" {
+		return features, nil, fmt.Errorf("unexpected xmpp element <%s>", start.Name.Local)
+	}
+	err = dec.DecodeElement(&features, &start)
+	return features, dec, err
+}
+
+// xmppExpect reads the next top-level element and fails unless it is the
+// named one, e.g. <proceed/> after STARTTLS or <success/> after auth.
+func xmppExpect(dec *xml.Decoder, name string) error {
+	start, err := xmppNextStart(dec)
+	if err != nil {
+		return err
+	}
+	if start.Name.Local != name {
+		return fmt.Errorf("xmpp server responded with <%s>, expected <%s>", start.Name.Local, name)
+	}
+	if name == "iq" {
+		for _, attr := range start.Attr {
+			if attr.Name.Local == "type" && attr.Value == "error" {
+				return errors.New("xmpp resource binding failed")
+			}
+		}
+	}
+	return dec.Skip()
+}
+
+func xmppNextStart(dec *xml.Decoder) (xml.StartElement, error) {
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start, nil
+		}
+	}
+}